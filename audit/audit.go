@@ -0,0 +1,143 @@
+// audit records the write operations done by Kiali on behalf of its users. The records are sent to
+// the configured sink, and the most recent ones are kept in memory to be queried.
+package audit
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/kiali/kiali/config"
+	"github.com/kiali/kiali/log"
+	"github.com/kiali/kiali/util"
+)
+
+// Outcomes of the audited operations
+const (
+	OutcomeFailure = "failure"
+	OutcomeSuccess = "success"
+)
+
+// Entry is the audit record of a write operation
+// swagger:model AuditEntry
+type Entry struct {
+	Timestamp time.Time `json:"timestamp"`
+	// The user on behalf of whom the operation was done
+	User string `json:"user"`
+	// The operation, e.g. create, update or delete
+	Verb      string `json:"verb"`
+	Cluster   string `json:"cluster,omitempty"`
+	Namespace string `json:"namespace,omitempty"`
+	// The type of the changed object, e.g. virtualservices or workloads
+	ObjectType string `json:"objectType,omitempty"`
+	Object     string `json:"object,omitempty"`
+	// The patch or the object sent by the user
+	Body string `json:"body,omitempty"`
+	// Whether the operation succeeded: success or failure
+	Outcome string `json:"outcome"`
+	// The error of a failed operation
+	Error string `json:"error,omitempty"`
+}
+
+// Query filters the recent entries. The empty fields match all the entries.
+type Query struct {
+	Cluster    string
+	Namespace  string
+	Object     string
+	ObjectType string
+	Outcome    string
+	User       string
+	Verb       string
+	// Since excludes the entries recorded before this time
+	Since time.Time
+	// Limit is the maximum number of entries returned, or zero for no limit
+	Limit int
+}
+
+func (q Query) matches(entry Entry) bool {
+	return (q.Cluster == "" || q.Cluster == entry.Cluster) &&
+		(q.Namespace == "" || q.Namespace == entry.Namespace) &&
+		(q.Object == "" || q.Object == entry.Object) &&
+		(q.ObjectType == "" || q.ObjectType == entry.ObjectType) &&
+		(q.Outcome == "" || q.Outcome == entry.Outcome) &&
+		(q.User == "" || q.User == entry.User) &&
+		(q.Verb == "" || q.Verb == entry.Verb) &&
+		!entry.Timestamp.Before(q.Since)
+}
+
+// auditor sends the records to its sink, and keeps the recent ones in a ring buffer
+type auditor struct {
+	mutex  sync.RWMutex
+	sink   Sink
+	recent []Entry
+	next   int
+	full   bool
+}
+
+var defaultAuditor = &auditor{sink: logSink{}, recent: make([]Entry, 1000)}
+
+// Configure replaces the sink and the buffer of the recent entries, as configured. The recent entries
+// are dropped.
+func Configure(conf config.AuditConfig) error {
+	sink, err := newSink(conf)
+	if err != nil {
+		return err
+	}
+	if conf.RecentEntries < 0 {
+		return fmt.Errorf("the number of recent audit entries is negative: %d", conf.RecentEntries)
+	}
+
+	defaultAuditor.mutex.Lock()
+	defer defaultAuditor.mutex.Unlock()
+	if err := defaultAuditor.sink.Close(); err != nil {
+		log.Warningf("Could not close the audit sink: %v", err)
+	}
+	defaultAuditor.sink = sink
+	defaultAuditor.recent = make([]Entry, conf.RecentEntries)
+	defaultAuditor.next = 0
+	defaultAuditor.full = false
+	return nil
+}
+
+// Record sends the entry to the sink, and keeps it with the recent entries. A sink error is logged:
+// the audited operation is already done.
+func Record(entry Entry) {
+	if entry.Timestamp.IsZero() {
+		entry.Timestamp = util.Clock.Now()
+	}
+
+	defaultAuditor.mutex.Lock()
+	defer defaultAuditor.mutex.Unlock()
+	if len(defaultAuditor.recent) > 0 {
+		defaultAuditor.recent[defaultAuditor.next] = entry
+		defaultAuditor.next = (defaultAuditor.next + 1) % len(defaultAuditor.recent)
+		defaultAuditor.full = defaultAuditor.full || defaultAuditor.next == 0
+	}
+	if err := defaultAuditor.sink.Write(entry); err != nil {
+		log.Errorf("Could not write the audit entry of the %s of [%s] by [%s]: %v", entry.Verb, entry.Object, entry.User, err)
+	}
+}
+
+// Recent returns the recent entries matching the query, newest first
+func Recent(query Query) []Entry {
+	defaultAuditor.mutex.RLock()
+	defer defaultAuditor.mutex.RUnlock()
+
+	count := defaultAuditor.next
+	if defaultAuditor.full {
+		count = len(defaultAuditor.recent)
+	}
+
+	entries := []Entry{}
+	for i := 1; i <= count; i++ {
+		entry := defaultAuditor.recent[(defaultAuditor.next-i+len(defaultAuditor.recent))%len(defaultAuditor.recent)]
+		if !query.matches(entry) {
+			continue
+		}
+		entries = append(entries, entry)
+		if query.Limit > 0 && len(entries) == query.Limit {
+			break
+		}
+	}
+	return entries
+}
@@ -0,0 +1,112 @@
+package audit
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kiali/kiali/config"
+	"github.com/kiali/kiali/util"
+)
+
+func setupAudit(t *testing.T, conf config.AuditConfig) {
+	require.NoError(t, Configure(conf))
+	util.Clock = util.ClockMock{Time: time.Date(2023, 5, 1, 12, 0, 0, 0, time.UTC)}
+	t.Cleanup(func() {
+		util.Clock = util.RealClock{}
+		require.NoError(t, Configure(config.NewConfig().Server.Audit))
+	})
+}
+
+func TestRecentEntriesAreKeptNewestFirst(t *testing.T) {
+	assert := assert.New(t)
+	setupAudit(t, config.AuditConfig{RecentEntries: 3, Sink: config.AuditSinkLog})
+
+	start := time.Date(2023, 5, 1, 0, 0, 0, 0, time.UTC)
+	for i, object := range []string{"reviews", "ratings", "details", "productpage"} {
+		Record(Entry{Timestamp: start.Add(time.Duration(i) * time.Minute), User: "jdoe", Verb: "update", Namespace: "bookinfo", ObjectType: "workloads", Object: object, Outcome: OutcomeSuccess})
+	}
+
+	// The oldest entry is dropped
+	entries := Recent(Query{})
+	require.Len(t, entries, 3)
+	assert.Equal("productpage", entries[0].Object)
+	assert.Equal("details", entries[1].Object)
+	assert.Equal("ratings", entries[2].Object)
+
+	assert.Len(Recent(Query{Limit: 2}), 2)
+	assert.Len(Recent(Query{Since: start.Add(2 * time.Minute)}), 2)
+	assert.Len(Recent(Query{Object: "details"}), 1)
+	assert.Empty(Recent(Query{User: "admin"}))
+	assert.Empty(Recent(Query{Outcome: OutcomeFailure}))
+}
+
+func TestFileSinkWritesJSONLines(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "audit.log")
+	setupAudit(t, config.AuditConfig{File: file, RecentEntries: 10, Sink: config.AuditSinkFile})
+
+	Record(Entry{User: "jdoe", Verb: "delete", Cluster: "east", Namespace: "bookinfo", ObjectType: "virtualservices", Object: "reviews", Outcome: OutcomeSuccess})
+	Record(Entry{User: "jdoe", Verb: "create", Cluster: "east", Namespace: "bookinfo", ObjectType: "gateways", Body: "{}", Outcome: OutcomeFailure, Error: "forbidden"})
+
+	f, err := os.Open(file)
+	require.NoError(t, err)
+	defer f.Close()
+
+	entries := []Entry{}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		entry := Entry{}
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &entry))
+		entries = append(entries, entry)
+	}
+	require.Len(t, entries, 2)
+	assert.Equal(t, "reviews", entries[0].Object)
+	assert.Equal(t, time.Date(2023, 5, 1, 12, 0, 0, 0, time.UTC), entries[0].Timestamp.UTC())
+	assert.Equal(t, OutcomeFailure, entries[1].Outcome)
+	assert.Equal(t, "forbidden", entries[1].Error)
+}
+
+func TestWebhookSinkPostsEntries(t *testing.T) {
+	received := make(chan Entry, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer secret", r.Header.Get("Authorization"))
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		entry := Entry{}
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&entry))
+		received <- entry
+	}))
+	defer server.Close()
+
+	setupAudit(t, config.AuditConfig{
+		RecentEntries:  10,
+		Sink:           config.AuditSinkWebhook,
+		WebhookHeaders: map[string]string{"Authorization": "Bearer secret"},
+		WebhookTimeout: 1,
+		WebhookURL:     server.URL,
+	})
+
+	Record(Entry{User: "jdoe", Verb: "update", Namespace: "bookinfo", ObjectType: "namespaces", Object: "bookinfo", Body: `{"metadata":{}}`, Outcome: OutcomeSuccess})
+
+	select {
+	case entry := <-received:
+		assert.Equal(t, "jdoe", entry.User)
+		assert.Equal(t, `{"metadata":{}}`, entry.Body)
+	case <-time.After(5 * time.Second):
+		assert.Fail(t, "The entry was not posted to the webhook")
+	}
+}
+
+func TestConfigureRejectsInvalidSinks(t *testing.T) {
+	assert.Error(t, Configure(config.AuditConfig{Sink: "syslog"}))
+	assert.Error(t, Configure(config.AuditConfig{Sink: config.AuditSinkFile}))
+	assert.Error(t, Configure(config.AuditConfig{Sink: config.AuditSinkWebhook}))
+	assert.Error(t, Configure(config.AuditConfig{Sink: config.AuditSinkLog, RecentEntries: -1}))
+}
@@ -0,0 +1,156 @@
+package audit
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/kiali/kiali/config"
+	"github.com/kiali/kiali/log"
+)
+
+// webhookQueueSize is how many entries can wait to be posted to the webhook. Further entries are dropped.
+const webhookQueueSize = 1000
+
+// Sink writes the audit entries
+type Sink interface {
+	Write(entry Entry) error
+	Close() error
+}
+
+func newSink(conf config.AuditConfig) (Sink, error) {
+	switch conf.Sink {
+	case "", config.AuditSinkLog:
+		return logSink{}, nil
+	case config.AuditSinkStdout:
+		return &jsonSink{writer: os.Stdout}, nil
+	case config.AuditSinkFile:
+		if conf.File == "" {
+			return nil, errors.New("the audit file is required by the file sink")
+		}
+		file, err := os.OpenFile(conf.File, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+		if err != nil {
+			return nil, fmt.Errorf("cannot open the audit file: %w", err)
+		}
+		return &jsonSink{writer: file, closer: file}, nil
+	case config.AuditSinkWebhook:
+		if conf.WebhookURL == "" {
+			return nil, errors.New("the webhook URL is required by the webhook sink")
+		}
+		return newWebhookSink(conf), nil
+	default:
+		return nil, fmt.Errorf("invalid audit sink [%s]", conf.Sink)
+	}
+}
+
+// logSink writes the entries to the Kiali log
+type logSink struct{}
+
+func (logSink) Write(entry Entry) error {
+	log.Infof("AUDIT User [%s] Verb [%s] Cluster [%s] Namespace [%s] Type [%s] Name [%s] Outcome [%s] Body [%s] Error [%s]",
+		entry.User, entry.Verb, entry.Cluster, entry.Namespace, entry.ObjectType, entry.Object, entry.Outcome, entry.Body, entry.Error)
+	return nil
+}
+
+func (logSink) Close() error {
+	return nil
+}
+
+// jsonSink writes the entries as JSON lines
+type jsonSink struct {
+	mutex  sync.Mutex
+	writer io.Writer
+	closer io.Closer
+}
+
+func (s *jsonSink) Write(entry Entry) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return json.NewEncoder(s.writer).Encode(entry)
+}
+
+func (s *jsonSink) Close() error {
+	if s.closer == nil {
+		return nil
+	}
+	return s.closer.Close()
+}
+
+// webhookSink posts each entry as JSON to a webhook. The entries are posted in the background, in order,
+// so that the audited requests aren't delayed by the webhook.
+type webhookSink struct {
+	client  *http.Client
+	headers map[string]string
+	url     string
+	queue   chan Entry
+	done    chan struct{}
+}
+
+func newWebhookSink(conf config.AuditConfig) *webhookSink {
+	s := &webhookSink{
+		client:  &http.Client{Timeout: time.Duration(conf.WebhookTimeout) * time.Second},
+		headers: conf.WebhookHeaders,
+		url:     conf.WebhookURL,
+		queue:   make(chan Entry, webhookQueueSize),
+		done:    make(chan struct{}),
+	}
+	go s.run()
+	return s
+}
+
+func (s *webhookSink) Write(entry Entry) error {
+	select {
+	case s.queue <- entry:
+		return nil
+	default:
+		return errors.New("the webhook queue is full, the entry is dropped")
+	}
+}
+
+// Close stops the sink once the queued entries are posted
+func (s *webhookSink) Close() error {
+	close(s.queue)
+	<-s.done
+	return nil
+}
+
+func (s *webhookSink) run() {
+	defer close(s.done)
+	for entry := range s.queue {
+		if err := s.post(entry); err != nil {
+			log.Errorf("Could not post the audit entry of the %s of [%s] by [%s] to the webhook: %v", entry.Verb, entry.Object, entry.User, err)
+		}
+	}
+}
+
+func (s *webhookSink) post(entry Entry) error {
+	body, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	request, err := http.NewRequest(http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", "application/json")
+	for name, value := range s.headers {
+		request.Header.Set(name, value)
+	}
+
+	response, err := s.client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	_, _ = io.Copy(io.Discard, response.Body)
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		return fmt.Errorf("the webhook responded with status %s", response.Status)
+	}
+	return nil
+}
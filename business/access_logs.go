@@ -0,0 +1,324 @@
+package business
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/nitishm/engarde/pkg/parser"
+	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/kiali/kiali/kubernetes"
+	"github.com/kiali/kiali/models"
+	"github.com/kiali/kiali/observability"
+	"github.com/kiali/kiali/util"
+)
+
+// defaultAccessLogStatsWindow is the time window used for access log stats when none is requested
+const defaultAccessLogStatsWindow = 10 * time.Minute
+
+// AccessLogFilter holds the conditions that proxy access log entries must meet to be returned
+type AccessLogFilter struct {
+	Authority     string   // exact authority (host) of the request
+	ResponseFlags []string // any of these Envoy response flags (e.g. UH, UF, NR)
+	StatusCodes   []string // any of these response codes, also accepts classes such as 5xx
+}
+
+// IsEmpty returns true when the filter has no conditions
+func (f AccessLogFilter) IsEmpty() bool {
+	return f.Authority == "" && len(f.ResponseFlags) == 0 && len(f.StatusCodes) == 0
+}
+
+// Matches returns true when the access log entry meets all the conditions of the filter.
+// Entries that are not access logs never match a non-empty filter.
+func (f AccessLogFilter) Matches(al *parser.AccessLog) bool {
+	if f.IsEmpty() {
+		return true
+	}
+	if al == nil {
+		return false
+	}
+	if f.Authority != "" && !strings.EqualFold(f.Authority, al.Authority) {
+		return false
+	}
+	if len(f.StatusCodes) > 0 && !matchesStatusCode(f.StatusCodes, al.StatusCode) {
+		return false
+	}
+	if len(f.ResponseFlags) > 0 && !matchesResponseFlags(f.ResponseFlags, al.ResponseFlags) {
+		return false
+	}
+	return true
+}
+
+func matchesStatusCode(codes []string, statusCode string) bool {
+	for _, code := range codes {
+		code = strings.ToLower(code)
+		if len(code) == 3 && strings.HasSuffix(code, "xx") {
+			if strings.HasPrefix(statusCode, code[:1]) && len(statusCode) == 3 {
+				return true
+			}
+		} else if from, to, ok := strings.Cut(code, "-"); ok {
+			// Range, e.g. 500-504
+			if len(statusCode) == 3 && len(from) == 3 && len(to) == 3 && statusCode >= from && statusCode <= to {
+				return true
+			}
+		} else if code == statusCode {
+			return true
+		}
+	}
+	return false
+}
+
+func matchesResponseFlags(flags []string, responseFlags string) bool {
+	for _, entryFlag := range splitResponseFlags(responseFlags) {
+		for _, flag := range flags {
+			if strings.EqualFold(flag, entryFlag) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// splitResponseFlags splits the Envoy response flags of an access log entry. "-" means no flags.
+// Newer Istio log formats are followed by the response code details, which the parser appends to the flags.
+func splitResponseFlags(responseFlags string) []string {
+	fields := strings.Fields(responseFlags)
+	if len(fields) == 0 || fields[0] == "-" {
+		return nil
+	}
+	return strings.Split(fields[0], ",")
+}
+
+// isAccessLogError returns true for server errors and requests that did not get any response
+func isAccessLogError(al *parser.AccessLog) bool {
+	return al.StatusCode == "0" || strings.HasPrefix(al.StatusCode, "5")
+}
+
+// latencySamples holds the durations and errors of a group of access log entries
+type latencySamples struct {
+	durations []float64
+	errors    int
+}
+
+func (s *latencySamples) toLatency(name string) models.AccessLogLatency {
+	sorted := util.SortedCopy(s.durations)
+	return models.AccessLogLatency{
+		Name:       name,
+		Count:      len(s.durations),
+		ErrorCount: s.errors,
+		ResponseTimes: []models.Stat{
+			{Name: "avg", Value: util.Average(sorted)},
+			{Name: "0.5", Value: util.Percentile(sorted, 50)},
+			{Name: "0.9", Value: util.Percentile(sorted, 90)},
+			{Name: "0.99", Value: util.Percentile(sorted, 99)},
+		},
+	}
+}
+
+// accessLogAggregator accumulates access log entries to compute AccessLogStats
+type accessLogAggregator struct {
+	entries       int
+	endTime       int64
+	responseFlags map[string]int
+	routes        map[string]*latencySamples
+	statusCodes   map[string]int
+	upstreams     map[string]*latencySamples
+}
+
+func newAccessLogAggregator() *accessLogAggregator {
+	return &accessLogAggregator{
+		responseFlags: map[string]int{},
+		routes:        map[string]*latencySamples{},
+		statusCodes:   map[string]int{},
+		upstreams:     map[string]*latencySamples{},
+	}
+}
+
+func addLatencySample(samples map[string]*latencySamples, key string, duration float64, isError bool) {
+	s, ok := samples[key]
+	if !ok {
+		s = &latencySamples{}
+		samples[key] = s
+	}
+	s.durations = append(s.durations, duration)
+	if isError {
+		s.errors++
+	}
+}
+
+func (a *accessLogAggregator) add(entry *LogEntry) {
+	al := entry.AccessLog
+	if al == nil {
+		return
+	}
+	a.entries++
+	if entry.TimestampUnix > a.endTime {
+		a.endTime = entry.TimestampUnix
+	}
+	a.statusCodes[al.StatusCode]++
+	for _, flag := range splitResponseFlags(al.ResponseFlags) {
+		a.responseFlags[flag]++
+	}
+
+	duration, err := strconv.ParseFloat(al.Duration, 64)
+	if err != nil {
+		return
+	}
+	isError := isAccessLogError(al)
+	if al.UpstreamService != "" && al.UpstreamService != "-" {
+		addLatencySample(a.upstreams, al.UpstreamService, duration, isError)
+	}
+	if al.UriPath != "" {
+		addLatencySample(a.routes, strings.TrimSpace(al.Method+" "+al.UriPath), duration, isError)
+	}
+}
+
+// sortedBySlowest converts the samples to latencies sorted by p90, slowest first, keeping at most limit items
+func sortedBySlowest(samples map[string]*latencySamples, limit int) []models.AccessLogLatency {
+	latencies := make([]models.AccessLogLatency, 0, len(samples))
+	for name, s := range samples {
+		latencies = append(latencies, s.toLatency(name))
+	}
+	// ResponseTimes[2] is the p90
+	sort.Slice(latencies, func(i, j int) bool {
+		if latencies[i].ResponseTimes[2].Value != latencies[j].ResponseTimes[2].Value {
+			return latencies[i].ResponseTimes[2].Value > latencies[j].ResponseTimes[2].Value
+		}
+		return latencies[i].Name < latencies[j].Name
+	})
+	if limit > 0 && len(latencies) > limit {
+		latencies = latencies[:limit]
+	}
+	return latencies
+}
+
+func (a *accessLogAggregator) stats(limit int) *models.AccessLogStats {
+	flags := make([]models.AccessLogCount, 0, len(a.responseFlags))
+	for name, count := range a.responseFlags {
+		flags = append(flags, models.AccessLogCount{Name: name, Count: count})
+	}
+	sort.Slice(flags, func(i, j int) bool {
+		if flags[i].Count != flags[j].Count {
+			return flags[i].Count > flags[j].Count
+		}
+		return flags[i].Name < flags[j].Name
+	})
+	if limit > 0 && len(flags) > limit {
+		flags = flags[:limit]
+	}
+
+	return &models.AccessLogStats{
+		Entries:          a.entries,
+		EndTime:          a.endTime,
+		StatusCodes:      a.statusCodes,
+		ResponseFlags:    flags,
+		SlowestUpstreams: sortedBySlowest(a.upstreams, limit),
+		Routes:           sortedBySlowest(a.routes, limit),
+	}
+}
+
+// proxyLogStreams returns the proxy container log streams of the pods
+func proxyLogStreams(namespace string, pods models.Pods) []containerLogStream {
+	streams := []containerLogStream{}
+	for _, pod := range pods {
+		for _, c := range append(pod.IstioContainers, pod.Containers...) {
+			if c.IsProxy {
+				streams = append(streams, containerLogStream{namespace: namespace, pod: pod.Name, container: c.Name, isProxy: true})
+			}
+		}
+	}
+	return streams
+}
+
+// computeAccessLogStats reads the proxy access logs of the pods in the requested time window and aggregates them.
+// When no start time is requested, the window ends now.
+func (in *WorkloadService) computeAccessLogStats(ctx context.Context, cluster, namespace string, pods models.Pods, opts *LogOptions, limit int) (*models.AccessLogStats, error) {
+	userClient, ok := in.userClients[cluster]
+	if !ok {
+		return nil, fmt.Errorf("user client for cluster [%s] not found", cluster)
+	}
+
+	streams := proxyLogStreams(namespace, pods)
+	if len(streams) == 0 {
+		return nil, kubernetes.NewNotFound("istio-proxy", "Kiali", "Container")
+	}
+
+	statsOpts := *opts
+	statsOpts.Follow = false
+	if statsOpts.Duration == nil {
+		window := defaultAccessLogStatsWindow
+		statsOpts.Duration = &window
+	}
+	if statsOpts.SinceTime == nil {
+		statsOpts.SinceTime = &meta_v1.Time{Time: time.Now().Add(-*statsOpts.Duration)}
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	events := make(chan containerLogEvent, len(streams))
+	for i, stream := range streams {
+		go in.readContainerLogs(ctx, userClient, i, stream, &statsOpts, events)
+	}
+
+	aggregator := newAccessLogAggregator()
+	for openStreams := len(streams); openStreams > 0; {
+		select {
+		case event := <-events:
+			if event.entry == nil {
+				openStreams--
+			} else {
+				aggregator.add(event.entry)
+			}
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	stats := aggregator.stats(limit)
+	stats.StartTime = statsOpts.SinceTime.UnixMilli()
+	if stats.EndTime == 0 {
+		stats.EndTime = statsOpts.SinceTime.Add(*statsOpts.Duration).UnixMilli()
+	}
+	return stats, nil
+}
+
+// GetPodAccessLogStats returns statistics computed from the proxy access logs of a pod
+func (in *WorkloadService) GetPodAccessLogStats(ctx context.Context, cluster, namespace, name string, opts *LogOptions, limit int) (*models.AccessLogStats, error) {
+	var end observability.EndFunc
+	ctx, end = observability.StartSpan(ctx, "GetPodAccessLogStats",
+		observability.Attribute("package", "business"),
+		observability.Attribute("cluster", cluster),
+		observability.Attribute("namespace", namespace),
+		observability.Attribute("pod", name),
+	)
+	defer end()
+
+	pod, err := in.GetPod(cluster, namespace, name)
+	if err != nil {
+		return nil, err
+	}
+	return in.computeAccessLogStats(ctx, cluster, namespace, models.Pods{pod}, opts, limit)
+}
+
+// GetWorkloadAccessLogStats returns statistics computed from the proxy access logs of all the pods of a workload
+func (in *WorkloadService) GetWorkloadAccessLogStats(ctx context.Context, cluster, namespace, workload string, opts *LogOptions, limit int) (*models.AccessLogStats, error) {
+	var end observability.EndFunc
+	ctx, end = observability.StartSpan(ctx, "GetWorkloadAccessLogStats",
+		observability.Attribute("package", "business"),
+		observability.Attribute("cluster", cluster),
+		observability.Attribute("namespace", namespace),
+		observability.Attribute("workload", workload),
+	)
+	defer end()
+
+	wk, err := in.fetchWorkload(ctx, WorkloadCriteria{Cluster: cluster, Namespace: namespace, WorkloadName: workload})
+	if err != nil {
+		return nil, err
+	}
+	return in.computeAccessLogStats(ctx, cluster, namespace, wk.Pods, opts, limit)
+}
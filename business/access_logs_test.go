@@ -0,0 +1,90 @@
+package business
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/nitishm/engarde/pkg/parser"
+	osproject_v1 "github.com/openshift/api/project/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/kiali/kiali/config"
+	"github.com/kiali/kiali/kubernetes/kubetest"
+	"github.com/kiali/kiali/models"
+)
+
+const fakeAccessLogs = `2021-02-01T21:34:35+00:00 [2021-02-01T21:34:35.533Z] "GET /hotels/Ljubljana HTTP/1.1" 200 - via_upstream - "-" 0 99 14 14 "-" "Go-http-client/1.1" "7e7e2dd0-0a96-4535-950b-e303805b7e27" "hotels.travel-agency:8000" "10.129.0.72:8000" inbound|8000|| 127.0.0.1:33704 10.129.0.72:8000 10.128.0.79:39880 outbound_.8000_._.hotels.travel-agency.svc.cluster.local default
+2021-02-01T21:34:36+00:00 [2021-02-01T21:34:36.533Z] "GET /hotels/Paris HTTP/1.1" 200 - via_upstream - "-" 0 99 40 40 "-" "Go-http-client/1.1" "8e7e2dd0-0a96-4535-950b-e303805b7e27" "hotels.travel-agency:8000" "10.129.0.72:8000" inbound|8000|| 127.0.0.1:33704 10.129.0.72:8000 10.128.0.79:39880 outbound_.8000_._.hotels.travel-agency.svc.cluster.local default
+2021-02-01T21:34:37+00:00 [2021-02-01T21:34:37.533Z] "GET /cars/Paris HTTP/1.1" 503 UH no_healthy_upstream - "-" 0 19 2 - "-" "Go-http-client/1.1" "9e7e2dd0-0a96-4535-950b-e303805b7e27" "cars.travel-agency:8000" "-" outbound|8000||cars.travel-agency.svc.cluster.local - 10.96.0.1:8000 10.128.0.79:39882 - default
+2021-02-01T21:34:38+00:00 some application message`
+
+func TestAccessLogFilter(t *testing.T) {
+	assert := assert.New(t)
+
+	al := &parser.AccessLog{Authority: "cars.travel-agency:8000", StatusCode: "503", ResponseFlags: "UH,UF"}
+
+	assert.True(AccessLogFilter{}.Matches(al))
+	assert.True(AccessLogFilter{}.Matches(nil))
+	assert.False(AccessLogFilter{StatusCodes: []string{"5xx"}}.Matches(nil))
+	assert.True(AccessLogFilter{StatusCodes: []string{"5xx"}}.Matches(al))
+	assert.True(AccessLogFilter{StatusCodes: []string{"404", "503"}}.Matches(al))
+	assert.False(AccessLogFilter{StatusCodes: []string{"4xx"}}.Matches(al))
+	assert.True(AccessLogFilter{ResponseFlags: []string{"uf"}}.Matches(al))
+	assert.False(AccessLogFilter{ResponseFlags: []string{"NR"}}.Matches(al))
+	assert.True(AccessLogFilter{Authority: "cars.travel-agency:8000", StatusCodes: []string{"5xx"}}.Matches(al))
+	assert.False(AccessLogFilter{Authority: "hotels.travel-agency:8000", StatusCodes: []string{"5xx"}}.Matches(al))
+	assert.False(AccessLogFilter{ResponseFlags: []string{"UH"}}.Matches(&parser.AccessLog{ResponseFlags: "-"}))
+}
+
+func TestComputeAccessLogStats(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	k8s := &multiPodLogStreamer{
+		logs: map[string]string{
+			"details-1/istio-proxy": fakeAccessLogs,
+			"details-1/details":     `2021-02-01T21:34:35+00:00 INFO not an access log`,
+		},
+		ClientInterface: kubetest.NewFakeK8sClient(&osproject_v1.Project{ObjectMeta: meta_v1.ObjectMeta{Name: "Namespace"}}),
+	}
+	conf := config.NewConfig()
+	SetupBusinessLayer(t, k8s, *conf)
+	svc := setupWorkloadService(k8s, conf)
+
+	pods := models.Pods{
+		{Name: "details-1", Containers: []*models.ContainerInfo{{Name: "details"}}, IstioContainers: []*models.ContainerInfo{{Name: "istio-proxy", IsProxy: true}}},
+	}
+	sinceTime := meta_v1.NewTime(time.Date(2021, 2, 1, 21, 30, 0, 0, time.UTC))
+	duration := time.Hour
+	opts := &LogOptions{Duration: &duration}
+	opts.SinceTime = &sinceTime
+
+	stats, err := svc.computeAccessLogStats(context.TODO(), conf.KubernetesConfig.ClusterName, "Namespace", pods, opts, 10)
+	require.NoError(err)
+
+	assert.Equal(3, stats.Entries)
+	assert.Equal(sinceTime.UnixMilli(), stats.StartTime)
+	assert.Equal(map[string]int{"200": 2, "503": 1}, stats.StatusCodes)
+	require.Equal(1, len(stats.ResponseFlags))
+	assert.Equal(models.AccessLogCount{Name: "UH", Count: 1}, stats.ResponseFlags[0])
+
+	require.Equal(1, len(stats.SlowestUpstreams))
+	assert.Equal("10.129.0.72:8000", stats.SlowestUpstreams[0].Name)
+	assert.Equal(2, stats.SlowestUpstreams[0].Count)
+	assert.Equal(27.0, stats.SlowestUpstreams[0].ResponseTimes[0].Value)
+
+	require.Equal(3, len(stats.Routes))
+	assert.Equal("GET /hotels/Paris", stats.Routes[0].Name)
+	assert.Equal(40.0, stats.Routes[0].ResponseTimes[2].Value)
+	assert.Equal("GET /cars/Paris", stats.Routes[2].Name)
+	assert.Equal(1, stats.Routes[2].ErrorCount)
+
+	opts.Filter = AccessLogFilter{StatusCodes: []string{"5xx"}}
+	stats, err = svc.computeAccessLogStats(context.TODO(), conf.KubernetesConfig.ClusterName, "Namespace", pods, opts, 10)
+	require.NoError(err)
+	assert.Equal(1, stats.Entries)
+	assert.Equal(map[string]int{"503": 1}, stats.StatusCodes)
+}
@@ -2,6 +2,7 @@ package business
 
 import (
 	"context"
+	"net/http"
 	"sort"
 	"strings"
 	"sync"
@@ -284,6 +285,25 @@ func (in *AppService) GetAppDetails(ctx context.Context, criteria AppCriteria) (
 	return *appInstance, nil
 }
 
+// StreamAppLogs streams the logs of all the pods of an app to an HTTP Response, merged in timestamp order
+func (in *AppService) StreamAppLogs(ctx context.Context, cluster, namespace, app string, opts *LogOptions, w http.ResponseWriter) error {
+	namespaceApps, err := in.fetchNamespaceApps(ctx, namespace, cluster, app)
+	if err != nil {
+		return err
+	}
+
+	appDetails, ok := namespaceApps[app]
+	if !ok {
+		return kubernetes.NewNotFound(app, "Kiali", "App")
+	}
+
+	pods := models.Pods{}
+	for _, workload := range appDetails.Workloads {
+		pods = append(pods, workload.Pods...)
+	}
+	return in.businessLayer.Workload.streamMergedLogs(ctx, cluster, namespace, pods, opts, w)
+}
+
 // AppDetails holds Services and Workloads having the same "app" label
 type appDetails struct {
 	app       string
@@ -0,0 +1,329 @@
+package authentication
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	auth_v1 "k8s.io/api/authentication/v1"
+	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/clientcmd/api"
+
+	"github.com/kiali/kiali/config"
+	"github.com/kiali/kiali/kubernetes"
+	"github.com/kiali/kiali/log"
+	"github.com/kiali/kiali/util"
+)
+
+// APITokenPrefix starts the API tokens issued by Kiali, which tells them apart from the cluster tokens
+const APITokenPrefix = "kiali_"
+
+// apiTokenStrategy is the strategy of the API tokens kept in the SessionStore
+const apiTokenStrategy = "apitoken"
+
+// APIToken describes an API token, without the token itself
+// swagger:model APIToken
+type APIToken struct {
+	// The ID to use to revoke the token
+	ID string `json:"id"`
+	// A name reminding what the token is used for
+	Name string `json:"name"`
+	// The user that created the token
+	Owner string `json:"owner"`
+	// The namespaces that the token can access. Empty when the token isn't restricted to namespaces.
+	Namespaces []string `json:"namespaces,omitempty"`
+	// The Kiali role of the token, which restricts what it can change
+	Role      string    `json:"role"`
+	CreatedOn time.Time `json:"createdOn"`
+	ExpiresOn time.Time `json:"expiresOn"`
+}
+
+// AllowsNamespaces returns true if the token can access all the namespaces
+func (t APIToken) AllowsNamespaces(namespaces ...string) bool {
+	if len(t.Namespaces) == 0 {
+		return true
+	}
+	allowed := make(map[string]bool, len(t.Namespaces))
+	for _, namespace := range t.Namespaces {
+		allowed[namespace] = true
+	}
+	for _, namespace := range namespaces {
+		if !allowed[namespace] {
+			return false
+		}
+	}
+	return true
+}
+
+// APITokenRequest is the request to create an API token
+// swagger:model APITokenRequest
+type APITokenRequest struct {
+	// A name reminding what the token is used for
+	// required: true
+	Name string `json:"name"`
+	// The namespaces that the token can access. All the namespaces of the user when empty.
+	Namespaces []string `json:"namespaces,omitempty"`
+	// The Kiali role of the token, which can't be more privileged than the role of the user. Defaults to viewer.
+	Role string `json:"role,omitempty"`
+	// The lifetime of the token, in seconds. Defaults to the maximum lifetime.
+	ExpiresIn int `json:"expiresIn,omitempty"`
+}
+
+// apiTokenData is the data of a token kept in the store, as the data of a StoredSession
+type apiTokenData struct {
+	Name       string   `json:"name"`
+	Namespaces []string `json:"namespaces,omitempty"`
+	Role       string   `json:"role"`
+	// The Kubernetes identity impersonated to call the cluster API with the token
+	KubeUser   string   `json:"kubeUser"`
+	KubeGroups []string `json:"kubeGroups,omitempty"`
+}
+
+// APITokenSession is the session of a request authenticated with an API token
+type APITokenSession struct {
+	UserSessionData
+	Token APIToken
+}
+
+// APITokenManager issues and validates the API tokens. The tokens are kept hashed in the store: the store
+// can't be used to call Kiali.
+type APITokenManager struct {
+	Store SessionStore
+
+	// reviewToken returns the Kubernetes user and groups of a cluster token
+	reviewToken func(token string) (string, []string, error)
+}
+
+var apiTokenManager *APITokenManager
+
+// GetAPITokenManager returns the manager of the API tokens, or nil if the API tokens are disabled
+func GetAPITokenManager() *APITokenManager {
+	return apiTokenManager
+}
+
+// InitializeAPITokens prepares the manager of the API tokens, when they are enabled. The tokens are kept in a
+// Secret of the Kiali namespace. This should be called during Kiali startup.
+func InitializeAPITokens() {
+	cfg := config.Get()
+	apiTokenManager = nil
+	if !cfg.Auth.APITokens.Enabled {
+		return
+	}
+	clientFactory, err := kubernetes.GetClientFactory()
+	if err != nil {
+		log.Errorf("The API tokens are disabled: cannot create the client of the token store: %v", err)
+		return
+	}
+	client := clientFactory.GetSAHomeClusterClient().Kube()
+	refresh := time.Duration(cfg.Auth.APITokens.RefreshInterval) * time.Second
+	store := NewKubeSessionStore(client, cfg.Deployment.Namespace, cfg.Auth.APITokens.SecretName, true, refresh, 0)
+
+	apiTokenManager = NewAPITokenManager(store, func(token string) (string, []string, error) {
+		review := &auth_v1.TokenReview{Spec: auth_v1.TokenReviewSpec{Token: token}}
+		result, err := client.AuthenticationV1().TokenReviews().Create(context.TODO(), review, meta_v1.CreateOptions{})
+		if err != nil {
+			return "", nil, err
+		}
+		if !result.Status.Authenticated {
+			return "", nil, fmt.Errorf("the token is not authenticated: %s", result.Status.Error)
+		}
+		return result.Status.User.Username, result.Status.User.Groups, nil
+	})
+}
+
+// NewAPITokenManager returns a manager keeping the tokens in the store. The reviewer returns the Kubernetes
+// identity of the cluster tokens of the users creating the tokens.
+func NewAPITokenManager(store SessionStore, reviewer func(token string) (string, []string, error)) *APITokenManager {
+	return &APITokenManager{Store: store, reviewToken: reviewer}
+}
+
+// HasAPIToken returns true if the request is authenticated with an API token, rather than with a session
+func HasAPIToken(r *http.Request) bool {
+	return strings.HasPrefix(bearerToken(r), APITokenPrefix)
+}
+
+func bearerToken(r *http.Request) string {
+	header := r.Header.Get("Authorization")
+	if len(header) > len("Bearer ") && strings.EqualFold(header[:len("Bearer ")], "Bearer ") {
+		return strings.TrimSpace(header[len("Bearer "):])
+	}
+	return ""
+}
+
+// CreateToken issues a token for the user of the session, on behalf of the Kubernetes identity of the session. The
+// token is returned once: only its hash is kept.
+func (m *APITokenManager) CreateToken(owner string, authInfo *api.AuthInfo, request APITokenRequest) (string, *APIToken, error) {
+	maxExpiration := time.Duration(config.Get().Auth.APITokens.MaxExpiration) * time.Second
+	lifetime := time.Duration(request.ExpiresIn) * time.Second
+	if request.ExpiresIn == 0 {
+		lifetime = maxExpiration
+	} else if lifetime < 0 || lifetime > maxExpiration {
+		return "", nil, fmt.Errorf("the token lifetime must be between 1 and %d seconds", int(maxExpiration.Seconds()))
+	}
+
+	data := apiTokenData{Name: request.Name, Namespaces: request.Namespaces, Role: request.Role}
+	if authInfo.Impersonate != "" {
+		// Kiali already impersonates the user
+		data.KubeUser = authInfo.Impersonate
+		data.KubeGroups = authInfo.ImpersonateGroups
+	} else {
+		user, groups, err := m.reviewToken(authInfo.Token)
+		if err != nil {
+			return "", nil, fmt.Errorf("cannot find the Kubernetes identity of the user: %w", err)
+		}
+		data.KubeUser = user
+		data.KubeGroups = groups
+	}
+	if err := checkImpersonation(data.KubeUser, data.KubeGroups); err != nil {
+		return "", nil, err
+	}
+
+	rawToken, err := util.CryptoRandomBytes(32)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to generate the token: %w", err)
+	}
+	token := APITokenPrefix + base64.RawURLEncoding.EncodeToString(rawToken)
+
+	now := util.Clock.Now()
+	encrypted, err := encryptAPITokenData(data, now.Add(lifetime))
+	if err != nil {
+		return "", nil, err
+	}
+	stored := StoredSession{
+		ID:        hashSessionID(token),
+		Strategy:  apiTokenStrategy,
+		Username:  owner,
+		CreatedOn: now,
+		ExpiresOn: now.Add(lifetime),
+		Data:      encrypted,
+	}
+	if err := m.Store.Put(stored); err != nil {
+		return "", nil, fmt.Errorf("failed to store the token: %w", err)
+	}
+	created := apiTokenOf(stored, data)
+	return token, &created, nil
+}
+
+// ValidateToken returns the session of the request authenticated with an API token, or nil if the token is
+// unknown, revoked or expired
+func (m *APITokenManager) ValidateToken(r *http.Request) (*APITokenSession, error) {
+	token := bearerToken(r)
+	if !strings.HasPrefix(token, APITokenPrefix) {
+		return nil, nil
+	}
+	stored, err := m.Store.Get(hashSessionID(token))
+	if err != nil {
+		return nil, err
+	}
+	if stored == nil || stored.Strategy != apiTokenStrategy || !util.Clock.Now().Before(stored.ExpiresOn) {
+		return nil, nil
+	}
+	data, err := decryptAPITokenData(*stored)
+	if err != nil {
+		log.Warningf("Ignoring the invalid API token %s: %v", stored.ID, err)
+		return nil, nil
+	}
+	if err := checkImpersonation(data.KubeUser, data.KubeGroups); err != nil {
+		log.Warningf("Ignoring the API token %s: %v", stored.ID, err)
+		return nil, nil
+	}
+
+	kialiToken, err := kubernetes.GetKialiTokenForHomeCluster()
+	if err != nil {
+		return nil, fmt.Errorf("error reading the Kiali ServiceAccount token: %w", err)
+	}
+	return &APITokenSession{
+		UserSessionData: UserSessionData{
+			ExpiresOn: stored.ExpiresOn,
+			Username:  stored.Username,
+			AuthInfo:  &api.AuthInfo{Token: kialiToken, Impersonate: data.KubeUser, ImpersonateGroups: data.KubeGroups},
+		},
+		Token: apiTokenOf(*stored, data),
+	}, nil
+}
+
+// ListTokens returns the tokens of the user, or all the tokens if no user is given, the newest first
+func (m *APITokenManager) ListTokens(owner string) ([]APIToken, error) {
+	stored, err := m.Store.List()
+	if err != nil {
+		return nil, err
+	}
+	now := util.Clock.Now()
+	tokens := []APIToken{}
+	for _, s := range stored {
+		if s.Strategy != apiTokenStrategy || !now.Before(s.ExpiresOn) || (owner != "" && s.Username != owner) {
+			continue
+		}
+		data, err := decryptAPITokenData(s)
+		if err != nil {
+			continue
+		}
+		tokens = append(tokens, apiTokenOf(s, data))
+	}
+	sort.Slice(tokens, func(i, j int) bool { return tokens[i].CreatedOn.After(tokens[j].CreatedOn) })
+	return tokens, nil
+}
+
+// GetToken returns the token with the ID, or nil if there is no such token
+func (m *APITokenManager) GetToken(id string) (*APIToken, error) {
+	stored, err := m.Store.Get(id)
+	if err != nil || stored == nil || stored.Strategy != apiTokenStrategy {
+		return nil, err
+	}
+	data, err := decryptAPITokenData(*stored)
+	if err != nil {
+		return nil, err
+	}
+	token := apiTokenOf(*stored, data)
+	return &token, nil
+}
+
+// RevokeToken removes the token with the ID. The revoked token may still be accepted by the other Kiali replicas
+// until they reload the tokens.
+func (m *APITokenManager) RevokeToken(id string) error {
+	return m.Store.Delete(id)
+}
+
+// encryptAPITokenData encrypts the data of a token like the session data, so that whoever can write the store
+// can't forge the identity impersonated by a token
+func encryptAPITokenData(data apiTokenData, expiresOn time.Time) (string, error) {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return "", err
+	}
+	return encryptSessionData(&sessionData{Strategy: apiTokenStrategy, ExpiresOn: expiresOn, Payload: string(payload)})
+}
+
+// decryptAPITokenData reverses encryptAPITokenData, checking that the data was encrypted for a token expiring
+// when the stored token does
+func decryptAPITokenData(stored StoredSession) (apiTokenData, error) {
+	var data apiTokenData
+	sData, err := decryptSessionData(stored.Data)
+	if err != nil {
+		return data, err
+	}
+	if sData.Strategy != apiTokenStrategy || !sData.ExpiresOn.Equal(stored.ExpiresOn) {
+		return data, fmt.Errorf("the data of the token %s doesn't match the token", stored.ID)
+	}
+	if err := json.Unmarshal([]byte(sData.Payload), &data); err != nil {
+		return data, err
+	}
+	return data, nil
+}
+
+func apiTokenOf(stored StoredSession, data apiTokenData) APIToken {
+	return APIToken{
+		ID:         stored.ID,
+		Name:       data.Name,
+		Owner:      stored.Username,
+		Namespaces: data.Namespaces,
+		Role:       data.Role,
+		CreatedOn:  stored.CreatedOn,
+		ExpiresOn:  stored.ExpiresOn,
+	}
+}
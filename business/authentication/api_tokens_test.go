@@ -0,0 +1,145 @@
+package authentication
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/client-go/tools/clientcmd/api"
+
+	"github.com/kiali/kiali/config"
+	"github.com/kiali/kiali/util"
+)
+
+func setupAPITokenManager(t *testing.T) *APITokenManager {
+	cfg := config.NewConfig()
+	cfg.Auth.APITokens.Enabled = true
+	cfg.Auth.APITokens.MaxExpiration = 3600
+	cfg.LoginToken.SigningKey = "kiali67890123456"
+	config.Set(cfg)
+	util.Clock = util.ClockMock{Time: time.Date(2021, 12, 1, 0, 0, 0, 0, time.UTC)}
+	setupKialiToken(t, "kiali-sa-token")
+	t.Cleanup(func() {
+		config.Set(config.NewConfig())
+	})
+
+	return NewAPITokenManager(NewMemorySessionStore(), func(token string) (string, []string, error) {
+		return "ci:" + token, []string{"ci-jobs"}, nil
+	})
+}
+
+func requestWithAPIToken(token string) *http.Request {
+	request := httptest.NewRequest(http.MethodGet, "/api/istio/validations?namespaces=bookinfo", nil)
+	request.Header.Set("Authorization", "Bearer "+token)
+	return request
+}
+
+func TestAPITokenImpersonatesTheIdentityOfTheOwner(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+	manager := setupAPITokenManager(t)
+
+	token, created, err := manager.CreateToken("jdoe", &api.AuthInfo{Token: "scripts"}, APITokenRequest{
+		Name:       "health scraper",
+		Namespaces: []string{"bookinfo"},
+		Role:       config.RoleViewer,
+	})
+	require.NoError(err)
+	assert.Equal(util.Clock.Now().Add(time.Hour), created.ExpiresOn)
+	assert.NotContains(created.ID, token)
+
+	request := requestWithAPIToken(token)
+	assert.True(HasAPIToken(request))
+	session, err := manager.ValidateToken(request)
+	require.NoError(err)
+	require.NotNil(session)
+	assert.Equal("jdoe", session.Username)
+	assert.Equal("kiali-sa-token", session.AuthInfo.Token)
+	assert.Equal("ci:scripts", session.AuthInfo.Impersonate)
+	assert.Equal([]string{"ci-jobs"}, session.AuthInfo.ImpersonateGroups)
+	assert.Equal(*created, session.Token)
+
+	// The identity of an impersonated user is kept as is
+	token, _, err = manager.CreateToken("jdoe", &api.AuthInfo{Token: "kiali-sa-token", Impersonate: "oidc:jdoe", ImpersonateGroups: []string{"oidc:devs"}}, APITokenRequest{Name: "other"})
+	require.NoError(err)
+	session, err = manager.ValidateToken(requestWithAPIToken(token))
+	require.NoError(err)
+	require.NotNil(session)
+	assert.Equal("oidc:jdoe", session.AuthInfo.Impersonate)
+	assert.Equal([]string{"oidc:devs"}, session.AuthInfo.ImpersonateGroups)
+}
+
+func TestAPITokenDoesNotImpersonateSystemUsers(t *testing.T) {
+	manager := setupAPITokenManager(t)
+
+	_, _, err := manager.CreateToken("jdoe", &api.AuthInfo{Token: "kiali-sa-token", Impersonate: "system:admin"}, APITokenRequest{Name: "admin"})
+	assert.Error(t, err)
+	_, _, err = manager.CreateToken("jdoe", &api.AuthInfo{Token: "kiali-sa-token", Impersonate: "jdoe", ImpersonateGroups: []string{"system:masters"}}, APITokenRequest{Name: "masters"})
+	assert.Error(t, err)
+}
+
+func TestAPITokenIgnoresForgedData(t *testing.T) {
+	require := require.New(t)
+	manager := setupAPITokenManager(t)
+
+	token, created, err := manager.CreateToken("jdoe", &api.AuthInfo{Token: "scripts"}, APITokenRequest{Name: "forged"})
+	require.NoError(err)
+	stored, err := manager.Store.Get(created.ID)
+	require.NoError(err)
+
+	// Whoever can write the store can't change the identity impersonated by the token
+	stored.Data = `{"name":"forged","role":"admin","kubeUser":"system:admin"}`
+	require.NoError(manager.Store.Put(*stored))
+	session, err := manager.ValidateToken(requestWithAPIToken(token))
+	require.NoError(err)
+	require.Nil(session)
+}
+
+func TestAPITokenExpiresAndCanBeRevoked(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+	manager := setupAPITokenManager(t)
+
+	_, _, err := manager.CreateToken("jdoe", &api.AuthInfo{Token: "scripts"}, APITokenRequest{Name: "too long", ExpiresIn: 7200})
+	assert.Error(err)
+
+	expiring, _, err := manager.CreateToken("jdoe", &api.AuthInfo{Token: "scripts"}, APITokenRequest{Name: "short", ExpiresIn: 60})
+	require.NoError(err)
+	revoked, created, err := manager.CreateToken("jdoe", &api.AuthInfo{Token: "scripts"}, APITokenRequest{Name: "revoked"})
+	require.NoError(err)
+	_, _, err = manager.CreateToken("alice", &api.AuthInfo{Token: "scripts"}, APITokenRequest{Name: "other user"})
+	require.NoError(err)
+
+	tokens, err := manager.ListTokens("jdoe")
+	require.NoError(err)
+	assert.Len(tokens, 2)
+	tokens, err = manager.ListTokens("")
+	require.NoError(err)
+	assert.Len(tokens, 3)
+
+	require.NoError(manager.RevokeToken(created.ID))
+	session, err := manager.ValidateToken(requestWithAPIToken(revoked))
+	assert.NoError(err)
+	assert.Nil(session)
+
+	util.Clock = util.ClockMock{Time: util.Clock.Now().Add(2 * time.Minute)}
+	session, err = manager.ValidateToken(requestWithAPIToken(expiring))
+	assert.NoError(err)
+	assert.Nil(session)
+
+	session, err = manager.ValidateToken(requestWithAPIToken(APITokenPrefix + "unknown"))
+	assert.NoError(err)
+	assert.Nil(session)
+}
+
+func TestAPITokenNamespaces(t *testing.T) {
+	unrestricted := APIToken{}
+	assert.True(t, unrestricted.AllowsNamespaces("bookinfo", "istio-system"))
+
+	restricted := APIToken{Namespaces: []string{"bookinfo", "travels"}}
+	assert.True(t, restricted.AllowsNamespaces("bookinfo", "travels"))
+	assert.False(t, restricted.AllowsNamespaces("bookinfo", "istio-system"))
+}
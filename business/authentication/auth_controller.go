@@ -3,11 +3,13 @@ package authentication
 import (
 	"fmt"
 	"net/http"
+	"strings"
 	"time"
 
 	"k8s.io/client-go/tools/clientcmd/api"
 
 	"github.com/kiali/kiali/config"
+	"github.com/kiali/kiali/kubernetes"
 )
 
 // TerminateSessionError is a helper type implementing the error interface.
@@ -71,6 +73,10 @@ type UserSessionData struct {
 	//
 	// required: true
 	AuthInfo *api.AuthInfo `json:"-"`
+
+	// The groups of the user, when they are known: read from the id_token or from the groups header.
+	// They map the user to a Kiali role.
+	Groups []string `json:"-"`
 }
 
 // AuthenticationFailureError is a helper Error to assist callers of the TokenAuthController.Authenticate
@@ -96,7 +102,10 @@ func (e *AuthenticationFailureError) Error() string {
 	return e.Reason
 }
 
-var authController AuthController
+var (
+	authController AuthController
+	sessionManager SessionManager
+)
 
 // GetAuthController gets the authentication controller that is currently configured and handling
 // user sessions and any authentication related requests.
@@ -104,19 +113,121 @@ func GetAuthController() AuthController {
 	return authController
 }
 
+// GetSessionManager returns the manager of the user sessions, or nil if the sessions are kept in the browser
+// cookies and can't be managed
+func GetSessionManager() SessionManager {
+	return sessionManager
+}
+
+// newSessionPersistor returns the persistor of the configured session store
+func newSessionPersistor() (SessionPersistor, error) {
+	cfg := config.Get()
+	storeConfig := cfg.Auth.SessionStore
+	switch storeConfig.Type {
+	case config.SessionStoreMemory:
+		return ServerSessionPersistor{Store: NewMemorySessionStore()}, nil
+	case config.SessionStoreSecret, config.SessionStoreConfigMap:
+		clientFactory, err := kubernetes.GetClientFactory()
+		if err != nil {
+			return nil, fmt.Errorf("cannot create the client of the session store: %w", err)
+		}
+		refresh := time.Duration(storeConfig.RefreshInterval) * time.Second
+		store := NewKubeSessionStore(clientFactory.GetSAHomeClusterClient().Kube(), cfg.Deployment.Namespace, storeConfig.Name,
+			storeConfig.Type == config.SessionStoreSecret, refresh, storeConfig.MaxSessions)
+		return ServerSessionPersistor{Store: store}, nil
+	default:
+		return CookieSessionPersistor{}, nil
+	}
+}
+
 // InitializeAuthenticationController initializes the authentication controller associated to the
-// given strategy and prepares it to control user sessions and handle authentication requests.
-// This should be called during Kiali startup, before starting to listen to HTTP requests.
-func InitializeAuthenticationController(strategy string) {
-	persistor := CookieSessionPersistor{}
-
-	if strategy == config.AuthStrategyToken {
-		authController = NewTokenAuthController(persistor, nil)
-	} else if strategy == config.AuthStrategyOpenId {
-		authController = NewOpenIdAuthController(persistor, nil)
-	} else if strategy == config.AuthStrategyOpenshift {
-		authController = NewOpenshiftAuthController(persistor, nil)
-	} else if strategy == config.AuthStrategyHeader {
-		authController = NewHeaderAuthController(persistor, nil)
+// given strategies and prepares it to control user sessions and handle authentication requests.
+// When several strategies are given, in order of preference, the controller dispatches to their
+// controllers. This should be called during Kiali startup, before starting to listen to HTTP requests.
+// An error is returned when the configured session store can't be created.
+func InitializeAuthenticationController(strategies ...string) error {
+	persistor, err := newSessionPersistor()
+	if err != nil {
+		return err
+	}
+	sessionManager, _ = persistor.(SessionManager)
+
+	controllers := make([]StrategyAuthController, 0, len(strategies))
+	for _, strategy := range strategies {
+		if controller := newStrategyAuthController(strategy, persistor); controller != nil {
+			controllers = append(controllers, StrategyAuthController{Strategy: strategy, Controller: controller})
+		}
+	}
+
+	switch len(controllers) {
+	case 0:
+		authController = nil
+	case 1:
+		authController = controllers[0].Controller
+	default:
+		authController = NewMultiAuthController(persistor, controllers)
+	}
+	return nil
+}
+
+// newStrategyAuthController returns the controller of the strategy, or nil if the strategy has no controller
+func newStrategyAuthController(strategy string, persistor SessionPersistor) AuthController {
+	switch strategy {
+	case config.AuthStrategyToken:
+		return NewTokenAuthController(persistor, nil)
+	case config.AuthStrategyOpenId:
+		return NewOpenIdAuthController(persistor, nil)
+	case config.AuthStrategyOpenshift:
+		return NewOpenshiftAuthController(persistor, nil)
+	case config.AuthStrategyHeader:
+		return NewHeaderAuthController(persistor, nil)
+	default:
+		return nil
+	}
+}
+
+// GetStrategyAuthController returns the controller of the strategy, or nil if the strategy isn't enabled
+func GetStrategyAuthController(strategy string) AuthController {
+	if multi, ok := authController.(*MultiAuthController); ok {
+		return multi.Controller(strategy)
+	}
+	if config.Get().HasAuthStrategy(strategy) {
+		return authController
+	}
+	return nil
+}
+
+// impersonatedAuthInfo returns the credentials to call the cluster API with the Kiali service account,
+// impersonating the given user and groups. The configured prefixes are prepended to their names. The users
+// and groups reserved by Kubernetes ("system:") are never impersonated.
+func impersonatedAuthInfo(username string, groups []string) (*api.AuthInfo, error) {
+	conf := config.Get().Auth.Impersonation
+	authInfo := &api.AuthInfo{Impersonate: conf.UsernamePrefix + username}
+	for _, group := range groups {
+		authInfo.ImpersonateGroups = append(authInfo.ImpersonateGroups, conf.GroupsPrefix+group)
+	}
+	if err := checkImpersonation(authInfo.Impersonate, authInfo.ImpersonateGroups); err != nil {
+		return nil, err
+	}
+
+	kialiToken, err := kubernetes.GetKialiTokenForHomeCluster()
+	if err != nil {
+		return nil, fmt.Errorf("error reading the Kiali ServiceAccount token: %w", err)
+	}
+	authInfo.Token = kialiToken
+	return authInfo, nil
+}
+
+// checkImpersonation returns an error if the user or one of the groups is reserved by Kubernetes ("system:"),
+// as Kiali never impersonates them
+func checkImpersonation(user string, groups []string) error {
+	for _, name := range append([]string{user}, groups...) {
+		if strings.HasPrefix(name, "system:") {
+			return &AuthenticationFailureError{
+				HttpStatus: http.StatusForbidden,
+				Reason:     fmt.Sprintf("the reserved user or group [%s] can't be impersonated", name),
+			}
+		}
 	}
+	return nil
 }
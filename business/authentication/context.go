@@ -15,3 +15,17 @@ func SetAuthInfoContext(ctx context.Context, value interface{}) context.Context
 func GetAuthInfoContext(ctx context.Context) interface{} {
 	return ctx.Value(ContextKeyAuthInfo)
 }
+
+var ContextKeyAPIToken contextKey = "apiToken"
+
+// SetAPITokenContext returns a copy of the context holding the API token authenticating the request
+func SetAPITokenContext(ctx context.Context, token *APIToken) context.Context {
+	return context.WithValue(ctx, ContextKeyAPIToken, token)
+}
+
+// GetAPITokenContext returns the API token authenticating the request, or nil if the request is authenticated
+// with a session
+func GetAPITokenContext(ctx context.Context) *APIToken {
+	token, _ := ctx.Value(ContextKeyAPIToken).(*APIToken)
+	return token
+}
@@ -1,6 +1,7 @@
 package authentication
 
 import (
+	"crypto/subtle"
 	"net/http"
 	"strings"
 	"time"
@@ -12,6 +13,7 @@ import (
 	"github.com/kiali/kiali/kubernetes"
 	"github.com/kiali/kiali/log"
 	"github.com/kiali/kiali/util"
+	"github.com/kiali/kiali/util/httputil"
 )
 
 // headerAuthController contains the backing logic to implement
@@ -61,7 +63,14 @@ func NewHeaderAuthController(persistor SessionPersistor, businessInstantiator fu
 // is done by trying to fetch the account/user name from the cluster. If account/user name information
 // cannot be fetched, authentication is rejected.
 // An error is returned if the authentication failed.
+//
+// If Kiali impersonates the users, the name of the user is read from the trusted user header and no
+// token is expected.
 func (c headerAuthController) Authenticate(r *http.Request, w http.ResponseWriter) (*UserSessionData, error) {
+	if config.Get().IsImpersonating(config.AuthStrategyHeader) {
+		return c.authenticateImpersonatedUser(r, w)
+	}
+
 	authInfo := c.getTokenStringFromHeader(r)
 
 	if authInfo == nil || authInfo.Token == "" {
@@ -114,6 +123,7 @@ func (c headerAuthController) Authenticate(r *http.Request, w http.ResponseWrite
 		ExpiresOn: timeExpire,
 		Username:  tokenSubject,
 		AuthInfo:  authInfo,
+		Groups:    getGroupsFromHeader(r),
 	}, nil
 }
 
@@ -123,12 +133,34 @@ func (c headerAuthController) ValidateSession(r *http.Request, w http.ResponseWr
 	log.Tracef("Using header for authentication, Url: [%s]", r.URL.String())
 
 	sPayload := headerSessionPayload{}
-	sData, err := c.SessionStore.ReadSession(r, w, &sPayload)
+	sData, err := c.SessionStore.ReadSession(r, w, config.AuthStrategyHeader, &sPayload)
 	if err != nil {
 		log.Warningf("Could not read the session: %v", err)
 		return nil, err
 	}
 
+	if config.Get().IsImpersonating(config.AuthStrategyHeader) {
+		// The session is only valid for the user authenticated by the trusted proxy in the request
+		if sData == nil || !isFromTrustedProxy(r) {
+			return nil, nil
+		}
+		authInfo, username, err := getImpersonatedUserFromHeader(r)
+		if err != nil || authInfo == nil {
+			return nil, err
+		}
+		if username != sPayload.Subject {
+			log.Debugf("The session of [%s] is rejected for the user [%s] of the proxy", sPayload.Subject, username)
+			return nil, nil
+		}
+
+		return &UserSessionData{
+			ExpiresOn: sData.ExpiresOn,
+			Username:  username,
+			AuthInfo:  authInfo,
+			Groups:    getGroupsFromHeader(r),
+		}, nil
+	}
+
 	authInfo := c.getTokenStringFromHeader(r)
 	if authInfo == nil || authInfo.Token == "" {
 		// No token in HTTP headers, means no session.
@@ -153,6 +185,7 @@ func (c headerAuthController) ValidateSession(r *http.Request, w http.ResponseWr
 		ExpiresOn: expiration,
 		Username:  subject,
 		AuthInfo:  authInfo,
+		Groups:    getGroupsFromHeader(r),
 	}, nil
 }
 
@@ -162,6 +195,98 @@ func (c headerAuthController) TerminateSession(r *http.Request, w http.ResponseW
 	return nil
 }
 
+// authenticateImpersonatedUser handles an HTTP request of a user authenticated by the reverse proxy in
+// front of Kiali, which passes the name and the groups of the user in the trusted headers. The session
+// is created without further checks: the cluster API checks the privileges of the impersonated user.
+func (c headerAuthController) authenticateImpersonatedUser(r *http.Request, w http.ResponseWriter) (*UserSessionData, error) {
+	if !isFromTrustedProxy(r) {
+		c.SessionStore.TerminateSession(r, w)
+		return nil, &AuthenticationFailureError{
+			HttpStatus: http.StatusUnauthorized,
+			Reason:     "The request is not from a trusted proxy",
+		}
+	}
+
+	authInfo, username, err := getImpersonatedUserFromHeader(r)
+	if err != nil {
+		return nil, err
+	}
+
+	if authInfo == nil {
+		c.SessionStore.TerminateSession(r, w)
+		return nil, &AuthenticationFailureError{
+			HttpStatus: http.StatusUnauthorized,
+			Reason:     "User header is missing",
+		}
+	}
+
+	timeExpire := util.Clock.Now().Add(time.Second * time.Duration(config.Get().LoginToken.ExpirationSeconds))
+	err = c.SessionStore.CreateSession(r, w, config.AuthStrategyHeader, timeExpire, headerSessionPayload{Subject: username})
+	if err != nil {
+		return nil, err
+	}
+
+	return &UserSessionData{
+		ExpiresOn: timeExpire,
+		Username:  username,
+		AuthInfo:  authInfo,
+		Groups:    getGroupsFromHeader(r),
+	}, nil
+}
+
+// isFromTrustedProxy returns true if the request holds the secret of the reverse proxy, or was sent with
+// the client certificate of a trusted proxy. Only then are the user and groups headers set by the proxy.
+func isFromTrustedProxy(r *http.Request) bool {
+	conf := config.Get().Auth.Impersonation
+
+	if conf.ProxySecret != "" {
+		secret := r.Header.Get(conf.ProxySecretHeader)
+		if subtle.ConstantTimeCompare([]byte(secret), []byte(conf.ProxySecret)) == 1 {
+			return true
+		}
+	}
+
+	return httputil.HasClientCertificate(r, conf.TrustedProxies)
+}
+
+// getImpersonatedUserFromHeader reads the name and the groups of the user from the headers set by the
+// trusted reverse proxy, and builds the credentials to impersonate this user with the Kiali service
+// account. Nil is returned if the user header is missing. The caller checks that the proxy is trusted.
+func getImpersonatedUserFromHeader(r *http.Request) (*api.AuthInfo, string, error) {
+	conf := config.Get().Auth.Impersonation
+
+	username := strings.TrimSpace(r.Header.Get(conf.UserHeader))
+	if username == "" {
+		return nil, "", nil
+	}
+
+	authInfo, err := impersonatedAuthInfo(username, getGroupsFromHeader(r))
+	if err != nil {
+		return nil, "", err
+	}
+	return authInfo, username, nil
+}
+
+// getGroupsFromHeader reads the groups of the user from the header set by the reverse proxy. The header
+// holds comma separated groups, and may be repeated. It is ignored unless the proxy is trusted: the groups
+// choose the Kiali role of the user.
+func getGroupsFromHeader(r *http.Request) []string {
+	groupsHeader := config.Get().Auth.Impersonation.GroupsHeader
+	if groupsHeader == "" || !isFromTrustedProxy(r) {
+		return nil
+	}
+
+	var groups []string
+	for _, headerValue := range r.Header.Values(groupsHeader) {
+		for _, group := range strings.Split(headerValue, ",") {
+			if group = strings.TrimSpace(group); group != "" {
+				groups = append(groups, group)
+			}
+		}
+	}
+	return groups
+}
+
 // getTokenStringFromHeader builds a Kubernetes api.AuthInfo object that contains user credentials
 // and any other credential attributes received through HTTP headers. Minimally, the standard HTTP
 // Authorization header is required to be present in the request containing a Bearer token that
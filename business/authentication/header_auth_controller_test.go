@@ -0,0 +1,216 @@
+package authentication
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/client-go/tools/clientcmd/api"
+
+	"github.com/kiali/kiali/business"
+	"github.com/kiali/kiali/config"
+	"github.com/kiali/kiali/kubernetes"
+	"github.com/kiali/kiali/util"
+)
+
+// setupKialiToken makes the given token the Kiali ServiceAccount token
+func setupKialiToken(t *testing.T, token string) {
+	tokenFile := filepath.Join(t.TempDir(), "token")
+	require.NoError(t, os.WriteFile(tokenFile, []byte(token), 0o600))
+
+	defaultPath := kubernetes.DefaultServiceAccountPath
+	kubernetes.DefaultServiceAccountPath = tokenFile
+	kubernetes.KialiTokenForHomeCluster = ""
+	t.Cleanup(func() {
+		kubernetes.DefaultServiceAccountPath = defaultPath
+		kubernetes.KialiTokenForHomeCluster = ""
+	})
+}
+
+func TestHeaderAuthImpersonatesUser(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	cfg := config.NewConfig()
+	cfg.LoginToken.SigningKey = "kiali67890123456"
+	cfg.Auth.Strategy = config.AuthStrategyHeader
+	cfg.Auth.Impersonation.Enabled = true
+	cfg.Auth.Impersonation.GroupsPrefix = "proxy:"
+	cfg.Auth.Impersonation.ProxySecret = "proxy-secret"
+	config.Set(cfg)
+
+	clockTime := time.Date(2021, 12, 1, 0, 0, 0, 0, time.UTC)
+	util.Clock = util.ClockMock{Time: clockTime}
+	setupKialiToken(t, "kiali-token")
+
+	controller := NewHeaderAuthController(CookieSessionPersistor{}, func(authInfo *api.AuthInfo) (*business.Layer, error) {
+		assert.Fail("Business layer should not be instantiated")
+		return nil, nil
+	})
+
+	request := httptest.NewRequest(http.MethodPost, "/api/authenticate", nil)
+	request.Header.Set("X-Kiali-Proxy-Secret", "proxy-secret")
+	request.Header.Set("X-Forwarded-User", "jdoe")
+	request.Header.Add("X-Forwarded-Groups", "admins, devs")
+	request.Header.Add("X-Forwarded-Groups", "ops")
+	// The user token isn't used
+	request.Header.Set("Authorization", "Bearer user-token")
+
+	rr := httptest.NewRecorder()
+	sData, err := controller.Authenticate(request, rr)
+	require.NoError(err)
+	require.NotNil(sData)
+	assert.Equal("jdoe", sData.Username)
+	assert.Equal(&api.AuthInfo{
+		Token:             "kiali-token",
+		Impersonate:       "jdoe",
+		ImpersonateGroups: []string{"proxy:admins", "proxy:devs", "proxy:ops"},
+	}, sData.AuthInfo)
+	assert.Equal(clockTime.Add(time.Second*time.Duration(cfg.LoginToken.ExpirationSeconds)), sData.ExpiresOn)
+	assert.Len(rr.Result().Cookies(), 1)
+
+	// Later requests are identified by the user header
+	request.AddCookie(rr.Result().Cookies()[0])
+	sData, err = controller.ValidateSession(request, httptest.NewRecorder())
+	require.NoError(err)
+	require.NotNil(sData)
+	assert.Equal("jdoe", sData.Username)
+	assert.Equal("jdoe", sData.AuthInfo.Impersonate)
+	assert.Equal("kiali-token", sData.AuthInfo.Token)
+
+	// The session is only valid for the user authenticated by the proxy
+	request.Header.Set("X-Forwarded-User", "mallory")
+	sData, err = controller.ValidateSession(request, httptest.NewRecorder())
+	assert.NoError(err)
+	assert.Nil(sData)
+
+	// The user header isn't a session by itself
+	request = httptest.NewRequest(http.MethodGet, "/api/status", nil)
+	request.Header.Set("X-Kiali-Proxy-Secret", "proxy-secret")
+	request.Header.Set("X-Forwarded-User", "jdoe")
+	sData, err = controller.ValidateSession(request, httptest.NewRecorder())
+	assert.NoError(err)
+	assert.Nil(sData)
+}
+
+func TestHeaderAuthImpersonationRequiresTrustedProxy(t *testing.T) {
+	cfg := config.NewConfig()
+	cfg.LoginToken.SigningKey = "kiali67890123456"
+	cfg.Auth.Strategy = config.AuthStrategyHeader
+	cfg.Auth.Impersonation.Enabled = true
+	cfg.Auth.Impersonation.ProxySecret = "proxy-secret"
+	cfg.Auth.Impersonation.TrustedProxies = []string{"oauth-proxy"}
+	config.Set(cfg)
+	setupKialiToken(t, "kiali-token")
+	controller := NewHeaderAuthController(CookieSessionPersistor{}, nil)
+
+	for name, secret := range map[string]string{"missing": "", "wrong": "guess"} {
+		request := httptest.NewRequest(http.MethodPost, "/api/authenticate", nil)
+		request.Header.Set("X-Forwarded-User", "jdoe")
+		if secret != "" {
+			request.Header.Set("X-Kiali-Proxy-Secret", secret)
+		}
+		sData, err := controller.Authenticate(request, httptest.NewRecorder())
+		assert.Nil(t, sData, name)
+		failure, ok := err.(*AuthenticationFailureError)
+		require.True(t, ok, name)
+		assert.Equal(t, http.StatusUnauthorized, failure.HttpStatus, name)
+	}
+
+	// The proxy can present its client certificate instead of the secret
+	request := httptest.NewRequest(http.MethodPost, "/api/authenticate", nil)
+	request.Header.Set("X-Forwarded-User", "jdoe")
+	request.TLS = &tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{{Subject: pkix.Name{CommonName: "oauth-proxy"}}}}}
+	sData, err := controller.Authenticate(request, httptest.NewRecorder())
+	require.NoError(t, err)
+	assert.Equal(t, "jdoe", sData.Username)
+
+	request.TLS = &tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{{Subject: pkix.Name{CommonName: "browser"}}}}}
+	_, err = controller.Authenticate(request, httptest.NewRecorder())
+	assert.Error(t, err)
+}
+
+func TestHeaderAuthDoesNotImpersonateSystemUsers(t *testing.T) {
+	cfg := config.NewConfig()
+	cfg.LoginToken.SigningKey = "kiali67890123456"
+	cfg.Auth.Strategy = config.AuthStrategyHeader
+	cfg.Auth.Impersonation.Enabled = true
+	cfg.Auth.Impersonation.ProxySecret = "proxy-secret"
+	config.Set(cfg)
+	setupKialiToken(t, "kiali-token")
+	controller := NewHeaderAuthController(CookieSessionPersistor{}, nil)
+
+	for _, header := range []string{"X-Forwarded-User", "X-Forwarded-Groups"} {
+		request := httptest.NewRequest(http.MethodPost, "/api/authenticate", nil)
+		request.Header.Set("X-Kiali-Proxy-Secret", "proxy-secret")
+		request.Header.Set("X-Forwarded-User", "jdoe")
+		request.Header.Set(header, "system:masters")
+		sData, err := controller.Authenticate(request, httptest.NewRecorder())
+		assert.Nil(t, sData, header)
+		failure, ok := err.(*AuthenticationFailureError)
+		require.True(t, ok, header)
+		assert.Equal(t, http.StatusForbidden, failure.HttpStatus, header)
+	}
+}
+
+func TestHeaderAuthImpersonationRequiresUserHeader(t *testing.T) {
+	cfg := config.NewConfig()
+	cfg.LoginToken.SigningKey = "kiali67890123456"
+	cfg.Auth.Strategy = config.AuthStrategyHeader
+	cfg.Auth.Impersonation.Enabled = true
+	cfg.Auth.Impersonation.ProxySecret = "proxy-secret"
+	config.Set(cfg)
+	setupKialiToken(t, "kiali-token")
+
+	controller := NewHeaderAuthController(CookieSessionPersistor{}, nil)
+	request := httptest.NewRequest(http.MethodPost, "/api/authenticate", nil)
+	request.Header.Set("X-Kiali-Proxy-Secret", "proxy-secret")
+	request.Header.Set("Authorization", "Bearer user-token")
+
+	sData, err := controller.Authenticate(request, httptest.NewRecorder())
+	assert.Nil(t, sData)
+	failure, ok := err.(*AuthenticationFailureError)
+	require.True(t, ok)
+	assert.Equal(t, http.StatusUnauthorized, failure.HttpStatus)
+
+	sData, err = controller.ValidateSession(request, httptest.NewRecorder())
+	assert.NoError(t, err)
+	assert.Nil(t, sData)
+}
+
+func TestHeaderAuthReadsGroupsOfUser(t *testing.T) {
+	cfg := config.NewConfig()
+	cfg.LoginToken.SigningKey = "kiali67890123456"
+	cfg.Auth.Strategy = config.AuthStrategyHeader
+	cfg.Auth.Roles.Enabled = true
+	cfg.Auth.Impersonation.ProxySecret = "proxy-secret"
+	config.Set(cfg)
+	util.Clock = util.ClockMock{Time: time.Date(2021, 12, 1, 0, 0, 0, 0, time.UTC)}
+
+	controller := NewHeaderAuthController(CookieSessionPersistor{}, nil)
+	request := httptest.NewRequest(http.MethodGet, "/api/config", nil)
+	request.Header.Set("Authorization", "Bearer user-token")
+	request.Header.Set("X-Kiali-Proxy-Secret", "proxy-secret")
+	request.Header.Set("X-Forwarded-Groups", "sre, devs")
+
+	sData, err := controller.ValidateSession(request, httptest.NewRecorder())
+	require.NoError(t, err)
+	require.NotNil(t, sData)
+	assert.Equal(t, "user-token", sData.AuthInfo.Token)
+	assert.Equal(t, []string{"sre", "devs"}, sData.Groups)
+
+	// The groups sent by a client, not by the proxy, are ignored
+	request.Header.Del("X-Kiali-Proxy-Secret")
+	sData, err = controller.ValidateSession(request, httptest.NewRecorder())
+	require.NoError(t, err)
+	require.NotNil(t, sData)
+	assert.Empty(t, sData.Groups)
+}
@@ -0,0 +1,316 @@
+package authentication
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	core_v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kube "k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/util/retry"
+
+	"github.com/kiali/kiali/log"
+	"github.com/kiali/kiali/util"
+)
+
+// kubeSessionStore keeps the sessions in a Secret or a ConfigMap, one entry per session, so that they are shared by
+// the Kiali replicas. The sessions are read from a local copy, reloaded at most once per refresh interval: the
+// unknown sessions, e.g. forged cookies, are answered from the copy too. Thus, a session created or revoked by a
+// replica may be unknown to or still accepted by the other replicas during the refresh interval.
+// A Secret or a ConfigMap is limited to 1 MiB. When the store is full, a new session evicts the oldest session of its
+// user, or else the oldest session: a client logging in repeatedly only evicts its own sessions. The API tokens are
+// never evicted: a new token is rejected when the store is full, until tokens expire or are revoked.
+type kubeSessionStore struct {
+	client      kube.Interface
+	namespace   string
+	name        string
+	useSecret   bool
+	refresh     time.Duration
+	maxSessions int
+
+	mutex    sync.RWMutex
+	sessions map[string]StoredSession
+	loadedAt time.Time
+
+	// reloadMutex lets a single reader reload the expired local copy
+	reloadMutex sync.Mutex
+}
+
+// maxKubeStoreSize is the size of the sessions kept in a Secret or ConfigMap, below the 1 MiB limit of the resource
+// to leave room for its metadata
+const maxKubeStoreSize = 1000 * 1024
+
+// NewKubeSessionStore returns a store keeping the sessions in the named Secret (or ConfigMap) of the namespace.
+// The resource is created when the first session is stored. At most maxSessions sessions are kept, unless it is 0.
+func NewKubeSessionStore(client kube.Interface, namespace, name string, useSecret bool, refresh time.Duration, maxSessions int) SessionStore {
+	return &kubeSessionStore{client: client, namespace: namespace, name: name, useSecret: useSecret, refresh: refresh, maxSessions: maxSessions}
+}
+
+func (s *kubeSessionStore) Get(id string) (*StoredSession, error) {
+	sessions, err := s.loaded()
+	if err != nil {
+		return nil, err
+	}
+	if session, ok := sessions[id]; ok {
+		return &session, nil
+	}
+	return nil, nil
+}
+
+func (s *kubeSessionStore) List() ([]StoredSession, error) {
+	loaded, err := s.reload()
+	if err != nil {
+		return nil, err
+	}
+	sessions := make([]StoredSession, 0, len(loaded))
+	for _, session := range loaded {
+		sessions = append(sessions, session)
+	}
+	return sessions, nil
+}
+
+func (s *kubeSessionStore) Put(session StoredSession) error {
+	change := func(sessions map[string]StoredSession) (bool, error) {
+		if _, ok := sessions[session.ID]; !ok && s.maxSessions > 0 {
+			for len(sessions) >= s.maxSessions {
+				if !evictSession(sessions, session) {
+					return false, fmt.Errorf("the session store %s is full: it holds the maximum of %d sessions", s.name, s.maxSessions)
+				}
+			}
+		}
+		sessions[session.ID] = session
+		return true, nil
+	}
+	return s.update(change, func(sessions map[string]StoredSession) bool { return evictSession(sessions, session) })
+}
+
+func (s *kubeSessionStore) Delete(ids ...string) error {
+	return s.update(func(sessions map[string]StoredSession) (bool, error) {
+		changed := false
+		for _, id := range ids {
+			if _, ok := sessions[id]; ok {
+				delete(sessions, id)
+				changed = true
+			}
+		}
+		return changed, nil
+	}, nil)
+}
+
+// evictSession removes the oldest session of the user of the new session, or else the oldest session, to make room
+// for the new session. The API tokens are never evicted, nor do they evict sessions. Returns false if no session
+// could be evicted.
+func evictSession(sessions map[string]StoredSession, added StoredSession) bool {
+	if added.Strategy == apiTokenStrategy {
+		return false
+	}
+	var oldest, oldestOfUser *StoredSession
+	for id := range sessions {
+		session := sessions[id]
+		if session.ID == added.ID || session.Strategy == apiTokenStrategy {
+			continue
+		}
+		if oldest == nil || isOlderSession(session, *oldest) {
+			oldest = &session
+		}
+		if session.Username == added.Username && (oldestOfUser == nil || isOlderSession(session, *oldestOfUser)) {
+			oldestOfUser = &session
+		}
+	}
+	if oldestOfUser != nil {
+		oldest = oldestOfUser
+	}
+	if oldest == nil {
+		return false
+	}
+	log.Debugf("Evicting the session %s of user [%s] from the full session store", oldest.ID, oldest.Username)
+	delete(sessions, oldest.ID)
+	return true
+}
+
+func isOlderSession(a, b StoredSession) bool {
+	if !a.CreatedOn.Equal(b.CreatedOn) {
+		return a.CreatedOn.Before(b.CreatedOn)
+	}
+	return a.ID < b.ID
+}
+
+// loaded returns the local copy of the sessions, reloaded when it is older than the refresh interval. The copy is
+// never modified, only replaced.
+func (s *kubeSessionStore) loaded() (map[string]StoredSession, error) {
+	if sessions := s.freshSessions(); sessions != nil {
+		return sessions, nil
+	}
+
+	s.reloadMutex.Lock()
+	defer s.reloadMutex.Unlock()
+	// The copy may have been reloaded by a concurrent reader
+	if sessions := s.freshSessions(); sessions != nil {
+		return sessions, nil
+	}
+	return s.reload()
+}
+
+// freshSessions returns the local copy of the sessions, or nil if it is older than the refresh interval
+func (s *kubeSessionStore) freshSessions() map[string]StoredSession {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	if s.sessions == nil || util.Clock.Now().Sub(s.loadedAt) >= s.refresh {
+		return nil
+	}
+	return s.sessions
+}
+
+// reload reads the sessions from the cluster and refreshes the local copy
+func (s *kubeSessionStore) reload() (map[string]StoredSession, error) {
+	data, _, err := s.read(context.TODO())
+	if err != nil {
+		return nil, err
+	}
+	sessions := decodeSessions(data)
+	s.setSessions(sessions)
+	return sessions, nil
+}
+
+func (s *kubeSessionStore) setSessions(sessions map[string]StoredSession) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.sessions = sessions
+	s.loadedAt = util.Clock.Now()
+}
+
+// update applies the change to the stored sessions, retrying when another replica updated them concurrently.
+// The expired sessions are pruned first. When the sessions don't fit in the resource anymore, sessions are evicted
+// until they do; the change fails if they still don't.
+func (s *kubeSessionStore) update(change func(sessions map[string]StoredSession) (bool, error), evict func(sessions map[string]StoredSession) bool) error {
+	ctx := context.TODO()
+	retriable := func(err error) bool { return errors.IsConflict(err) || errors.IsAlreadyExists(err) }
+	return retry.OnError(retry.DefaultRetry, retriable, func() error {
+		data, existing, err := s.read(ctx)
+		if err != nil {
+			return err
+		}
+		sessions := decodeSessions(data)
+		before := len(sessions)
+		pruneExpiredSessions(sessions)
+		changed, err := change(sessions)
+		if err != nil {
+			return err
+		}
+		if !changed && len(sessions) == before {
+			s.setSessions(sessions)
+			return nil
+		}
+		encoded := encodeSessions(sessions)
+		grew := changed && len(sessions) >= before
+		for grew && encodedSize(encoded) > maxKubeStoreSize {
+			if evict == nil || !evict(sessions) {
+				return fmt.Errorf("the session store %s is full: its sessions would exceed the size limit of %d bytes", s.name, maxKubeStoreSize)
+			}
+			encoded = encodeSessions(sessions)
+		}
+		if err := s.write(ctx, encoded, existing); err != nil {
+			return err
+		}
+		s.setSessions(sessions)
+		return nil
+	})
+}
+
+// read returns the entries of the Secret or ConfigMap, and its metadata (nil when it doesn't exist)
+func (s *kubeSessionStore) read(ctx context.Context) (map[string]string, *meta_v1.ObjectMeta, error) {
+	data := map[string]string{}
+	if s.useSecret {
+		secret, err := s.client.CoreV1().Secrets(s.namespace).Get(ctx, s.name, meta_v1.GetOptions{})
+		if errors.IsNotFound(err) {
+			return data, nil, nil
+		} else if err != nil {
+			return nil, nil, err
+		}
+		for key, value := range secret.Data {
+			data[key] = string(value)
+		}
+		return data, &secret.ObjectMeta, nil
+	}
+	configMap, err := s.client.CoreV1().ConfigMaps(s.namespace).Get(ctx, s.name, meta_v1.GetOptions{})
+	if errors.IsNotFound(err) {
+		return data, nil, nil
+	} else if err != nil {
+		return nil, nil, err
+	}
+	for key, value := range configMap.Data {
+		data[key] = value
+	}
+	return data, &configMap.ObjectMeta, nil
+}
+
+// write replaces the entries of the Secret or ConfigMap, creating it when it doesn't exist. The update fails with
+// a conflict if the resource changed since it was read.
+func (s *kubeSessionStore) write(ctx context.Context, data map[string]string, existing *meta_v1.ObjectMeta) error {
+	meta := meta_v1.ObjectMeta{
+		Name:      s.name,
+		Namespace: s.namespace,
+		Labels:    map[string]string{"app.kubernetes.io/part-of": "kiali"},
+	}
+	if existing != nil {
+		meta = *existing
+	}
+	var err error
+	if s.useSecret {
+		secret := &core_v1.Secret{ObjectMeta: meta, Data: map[string][]byte{}}
+		for key, value := range data {
+			secret.Data[key] = []byte(value)
+		}
+		if existing == nil {
+			_, err = s.client.CoreV1().Secrets(s.namespace).Create(ctx, secret, meta_v1.CreateOptions{})
+		} else {
+			_, err = s.client.CoreV1().Secrets(s.namespace).Update(ctx, secret, meta_v1.UpdateOptions{})
+		}
+		return err
+	}
+	configMap := &core_v1.ConfigMap{ObjectMeta: meta, Data: data}
+	if existing == nil {
+		_, err = s.client.CoreV1().ConfigMaps(s.namespace).Create(ctx, configMap, meta_v1.CreateOptions{})
+	} else {
+		_, err = s.client.CoreV1().ConfigMaps(s.namespace).Update(ctx, configMap, meta_v1.UpdateOptions{})
+	}
+	return err
+}
+
+func encodedSize(encoded map[string]string) int {
+	size := 0
+	for id, session := range encoded {
+		size += len(id) + len(session)
+	}
+	return size
+}
+
+func decodeSessions(data map[string]string) map[string]StoredSession {
+	sessions := make(map[string]StoredSession, len(data))
+	for key, value := range data {
+		var session StoredSession
+		if err := json.Unmarshal([]byte(value), &session); err != nil {
+			log.Warningf("Ignoring the invalid stored session %s: %v", key, err)
+			continue
+		}
+		sessions[key] = session
+	}
+	return sessions
+}
+
+func encodeSessions(sessions map[string]StoredSession) map[string]string {
+	data := make(map[string]string, len(sessions))
+	for id, session := range sessions {
+		encoded, err := json.Marshal(session)
+		if err != nil {
+			log.Errorf("Could not encode the session %s: %v", id, err)
+			continue
+		}
+		data[id] = string(encoded)
+	}
+	return data
+}
@@ -0,0 +1,91 @@
+package authentication
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// ProviderQueryParam is the query parameter of the login requests choosing the authentication strategy, when
+// several strategies are enabled
+const ProviderQueryParam = "provider"
+
+// StrategyAuthController is the controller of one of the enabled authentication strategies
+type StrategyAuthController struct {
+	Strategy   string
+	Controller AuthController
+}
+
+// MultiAuthController dispatches the authentication requests to the controllers of the enabled strategies.
+// The login requests are dispatched according to their provider query parameter, or to the preferred strategy
+// without this parameter. The sessions are validated by the controller of the strategy that created them.
+type MultiAuthController struct {
+	// Controllers of the enabled strategies, in order of preference
+	Controllers []StrategyAuthController
+
+	// SessionStore persists the sessions of all the strategies
+	SessionStore SessionPersistor
+}
+
+// NewMultiAuthController initializes a new controller dispatching to the given controllers, in order of preference.
+// All the controllers must share the session persistor.
+func NewMultiAuthController(persistor SessionPersistor, controllers []StrategyAuthController) *MultiAuthController {
+	return &MultiAuthController{
+		Controllers:  controllers,
+		SessionStore: persistor,
+	}
+}
+
+// Controller returns the controller of the strategy, or nil if the strategy isn't enabled
+func (c *MultiAuthController) Controller(strategy string) AuthController {
+	for _, sc := range c.Controllers {
+		if sc.Strategy == strategy {
+			return sc.Controller
+		}
+	}
+	return nil
+}
+
+// Authenticate dispatches the login request to the controller of the requested provider.
+func (c *MultiAuthController) Authenticate(r *http.Request, w http.ResponseWriter) (*UserSessionData, error) {
+	provider := r.URL.Query().Get(ProviderQueryParam)
+	if provider == "" {
+		return c.Controllers[0].Controller.Authenticate(r, w)
+	}
+
+	controller := c.Controller(provider)
+	if controller == nil {
+		return nil, &AuthenticationFailureError{
+			HttpStatus: http.StatusBadRequest,
+			Reason:     fmt.Sprintf("the authentication provider [%s] is not enabled", provider),
+		}
+	}
+	return controller.Authenticate(r, w)
+}
+
+// ValidateSession returns the session validated by the controller of the strategy that created it, or nil if there
+// is none. A controller never validates the sessions of the other strategies, nor the requests without a session,
+// e.g. with the bearer token accepted by the header strategy alone.
+func (c *MultiAuthController) ValidateSession(r *http.Request, w http.ResponseWriter) (*UserSessionData, error) {
+	for _, sc := range c.Controllers {
+		sData, err := c.SessionStore.ReadSession(r, w, sc.Strategy, nil)
+		if err != nil {
+			return nil, err
+		}
+		if sData != nil {
+			return sc.Controller.ValidateSession(r, w)
+		}
+	}
+	return nil, nil
+}
+
+// TerminateSession terminates the session with the controller of the strategy that created it. A session that no
+// controller recognizes, or that can't be read, is cleared.
+func (c *MultiAuthController) TerminateSession(r *http.Request, w http.ResponseWriter) error {
+	for _, sc := range c.Controllers {
+		if sData, err := c.SessionStore.ReadSession(r, w, sc.Strategy, nil); err == nil && sData != nil {
+			return sc.Controller.TerminateSession(r, w)
+		}
+	}
+	c.SessionStore.TerminateSession(r, w)
+	return nil
+}
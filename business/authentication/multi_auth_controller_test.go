@@ -0,0 +1,162 @@
+package authentication
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kiali/kiali/config"
+	"github.com/kiali/kiali/util"
+)
+
+// fakeAuthController creates the sessions of its strategy, for the user named after the strategy. Like the header
+// strategy accepting bearer tokens, it can accept the requests without its session.
+type fakeAuthController struct {
+	strategy       string
+	store          SessionPersistor
+	terminations   int
+	withoutSession bool
+}
+
+func (c *fakeAuthController) Authenticate(r *http.Request, w http.ResponseWriter) (*UserSessionData, error) {
+	expiresOn := util.Clock.Now().Add(time.Hour)
+	if err := c.store.CreateSession(r, w, c.strategy, expiresOn, testSessionPayload{FirstField: c.strategy}); err != nil {
+		return nil, err
+	}
+	return &UserSessionData{ExpiresOn: expiresOn, Username: c.strategy}, nil
+}
+
+func (c *fakeAuthController) ValidateSession(r *http.Request, w http.ResponseWriter) (*UserSessionData, error) {
+	payload := testSessionPayload{}
+	sData, err := c.store.ReadSession(r, w, c.strategy, &payload)
+	if err != nil {
+		return nil, err
+	}
+	if sData == nil {
+		if c.withoutSession {
+			return &UserSessionData{ExpiresOn: util.Clock.Now(), Username: c.strategy}, nil
+		}
+		return nil, nil
+	}
+	return &UserSessionData{ExpiresOn: sData.ExpiresOn, Username: payload.FirstField}, nil
+}
+
+func (c *fakeAuthController) TerminateSession(r *http.Request, w http.ResponseWriter) error {
+	c.terminations++
+	c.store.TerminateSession(r, w)
+	return nil
+}
+
+func setupMultiAuthController(t *testing.T) (*MultiAuthController, *fakeAuthController, *fakeAuthController) {
+	cfg := config.NewConfig()
+	cfg.LoginToken.SigningKey = "kiali67890123456"
+	cfg.Auth.Strategy = config.AuthStrategyOpenId
+	cfg.Auth.Strategies = []string{config.AuthStrategyOpenId, config.AuthStrategyToken}
+	config.Set(cfg)
+	util.Clock = util.ClockMock{Time: time.Date(2021, 12, 1, 0, 0, 0, 0, time.UTC)}
+	t.Cleanup(func() {
+		config.Set(config.NewConfig())
+	})
+
+	persistor := CookieSessionPersistor{}
+	openId := &fakeAuthController{strategy: config.AuthStrategyOpenId, store: persistor}
+	token := &fakeAuthController{strategy: config.AuthStrategyToken, store: persistor}
+	controller := NewMultiAuthController(persistor, []StrategyAuthController{
+		{Strategy: config.AuthStrategyOpenId, Controller: openId},
+		{Strategy: config.AuthStrategyToken, Controller: token},
+	})
+	return controller, openId, token
+}
+
+// authenticateWith logs in with the provider, and returns a request carrying the session cookies
+func authenticateWith(t *testing.T, controller AuthController, provider string) *http.Request {
+	target := "/api/authenticate"
+	if provider != "" {
+		target += "?provider=" + provider
+	}
+	rr := httptest.NewRecorder()
+	_, err := controller.Authenticate(httptest.NewRequest(http.MethodPost, target, nil), rr)
+	require.NoError(t, err)
+
+	request := httptest.NewRequest(http.MethodGet, "/api/config", nil)
+	for _, cookie := range rr.Result().Cookies() {
+		request.AddCookie(cookie)
+	}
+	return request
+}
+
+func TestMultiAuthControllerDispatchesByProvider(t *testing.T) {
+	assert := assert.New(t)
+	controller, _, _ := setupMultiAuthController(t)
+
+	sData, err := controller.ValidateSession(authenticateWith(t, controller, config.AuthStrategyToken), httptest.NewRecorder())
+	require.NoError(t, err)
+	require.NotNil(t, sData)
+	assert.Equal(config.AuthStrategyToken, sData.Username)
+
+	// The preferred strategy is used without provider
+	sData, err = controller.ValidateSession(authenticateWith(t, controller, ""), httptest.NewRecorder())
+	require.NoError(t, err)
+	require.NotNil(t, sData)
+	assert.Equal(config.AuthStrategyOpenId, sData.Username)
+
+	sData, err = controller.Authenticate(httptest.NewRequest(http.MethodPost, "/api/authenticate?provider=openshift", nil), httptest.NewRecorder())
+	assert.Nil(sData)
+	failure, ok := err.(*AuthenticationFailureError)
+	require.True(t, ok)
+	assert.Equal(http.StatusBadRequest, failure.HttpStatus)
+
+	assert.Nil(controller.Controller(config.AuthStrategyOpenshift))
+}
+
+func TestMultiAuthControllerValidatesWithSessionStrategy(t *testing.T) {
+	controller, openId, _ := setupMultiAuthController(t)
+	openId.withoutSession = true
+
+	sData, err := controller.ValidateSession(authenticateWith(t, controller, config.AuthStrategyToken), httptest.NewRecorder())
+	require.NoError(t, err)
+	require.NotNil(t, sData)
+	assert.Equal(t, config.AuthStrategyToken, sData.Username)
+
+	// The requests without a session are rejected
+	sData, err = controller.ValidateSession(httptest.NewRequest(http.MethodGet, "/api/config", nil), httptest.NewRecorder())
+	assert.NoError(t, err)
+	assert.Nil(t, sData)
+}
+
+func TestMultiAuthControllerTerminatesWithSessionStrategy(t *testing.T) {
+	controller, openId, token := setupMultiAuthController(t)
+
+	request := authenticateWith(t, controller, config.AuthStrategyToken)
+	rr := httptest.NewRecorder()
+	require.NoError(t, controller.TerminateSession(request, rr))
+	assert.Equal(t, 0, openId.terminations)
+	assert.Equal(t, 1, token.terminations)
+	assert.NotEmpty(t, rr.Result().Cookies())
+}
+
+func TestSessionOfDisabledStrategyIsTerminated(t *testing.T) {
+	controller, _, _ := setupMultiAuthController(t)
+	request := authenticateWith(t, controller, config.AuthStrategyToken)
+
+	// The openid controller ignores the session of the token strategy, which stays
+	rr := httptest.NewRecorder()
+	sData, err := CookieSessionPersistor{}.ReadSession(request, rr, config.AuthStrategyOpenId, nil)
+	assert.NoError(t, err)
+	assert.Nil(t, sData)
+	assert.Empty(t, rr.Result().Cookies())
+
+	// The session is killed once the token strategy is disabled
+	cfg := config.Get()
+	cfg.Auth.Strategies = []string{config.AuthStrategyOpenId}
+	config.Set(cfg)
+	rr = httptest.NewRecorder()
+	sData, err = CookieSessionPersistor{}.ReadSession(request, rr, config.AuthStrategyOpenId, nil)
+	assert.NoError(t, err)
+	assert.Nil(t, sData)
+	assert.NotEmpty(t, rr.Result().Cookies())
+}
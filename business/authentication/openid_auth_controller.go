@@ -13,6 +13,7 @@ import (
 	"net/url"
 	"os"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/gorilla/mux"
@@ -39,6 +40,10 @@ const (
 	// This is for cases when the authentication server is using TLS with a self-signed
 	// certificate.
 	OpenIdServerCAFile = "/kiali-cabundle/openid-server-ca.crt"
+
+	// refreshedOpenIdTokensRetention is how long the tokens obtained by renewing a session are
+	// kept for the concurrent requests still sending the replaced session.
+	refreshedOpenIdTokensRetention = time.Minute
 )
 
 // cachedOpenIdKeySet stores the metadata obtained from the /.well-known/openid-configuration
@@ -58,6 +63,21 @@ var cachedOpenIdKeySet *jose.JSONWebKeySet
 // prevents fetching the same data twice at the same time.
 var openIdFlightGroup singleflight.Group
 
+// refreshedOpenIdTokens stores the recent responses of the OpenId server to the token refresh
+// requests, by hash of the refresh token that was used. The browser may still send the replaced
+// session on requests started before the renewal completed. If the OpenId server rotates the
+// refresh tokens, using the replaced refresh token again would fail (and may even revoke
+// the session), so these requests get the tokens that were already obtained.
+var refreshedOpenIdTokens = map[string]refreshedOpenIdTokensEntry{}
+
+// refreshedOpenIdTokensMutex synchronizes the access to refreshedOpenIdTokens.
+var refreshedOpenIdTokensMutex sync.Mutex
+
+type refreshedOpenIdTokensEntry struct {
+	Tokens      *openIdTokenResponse
+	RefreshedOn time.Time
+}
+
 // openIdMetadata is a helper struct to parse the response from the metadata
 // endpoint /.well-known/openid-configuration of the OpenID server.
 // This was borrowed from https://github.com/coreos/go-oidc/blob/8d771559cf6e5111c9b9159810d0e4538e7cdc82/oidc.go
@@ -70,6 +90,9 @@ type openIdMetadata struct {
 	UserInfoURL string   `json:"userinfo_endpoint"`
 	Algorithms  []string `json:"id_token_signing_alg_values_supported"`
 
+	// RevocationURL is the token revocation endpoint (RFC 7009), if the OpenId server supports it.
+	RevocationURL string `json:"revocation_endpoint,omitempty"`
+
 	// Some extra fields
 	ScopesSupported        []string `json:"scopes_supported"`
 	ResponseTypesSupported []string `json:"response_types_supported"`
@@ -85,6 +108,35 @@ type oidcSessionPayload struct {
 	// the access_token, depending on the Kiali configuration. If RBAC is enabled,
 	// this is the token that can be used against the Kubernetes API.
 	Token string `json:"token,omitempty"`
+
+	// RefreshToken is the refresh token provided by the OpenId server, if any. It is
+	// used to renew the session before the Token expires.
+	RefreshToken string `json:"refreshToken,omitempty"`
+
+	// Groups are the groups of the user, read from the id_token. They are only stored
+	// when Kiali impersonates the users, or maps them to roles.
+	Groups []string `json:"groups,omitempty"`
+}
+
+// openIdTokenResponse is a helper type to parse the responses of the token endpoint of the OpenId server.
+type openIdTokenResponse struct {
+	IdToken      string `json:"id_token"`
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+}
+
+// openIdTokenError is the error returned when the token endpoint of the OpenId server rejects a request.
+type openIdTokenError struct {
+	// Status is the HTTP status of the response.
+	Status string
+
+	// Code is the OAuth error code of the response (e.g. "invalid_grant"), if present.
+	Code string
+}
+
+// Error returns the text representation of an openIdTokenError.
+func (e *openIdTokenError) Error() string {
+	return fmt.Sprintf("request failed (HTTP response status = %s)", e.Status)
 }
 
 // badOidcRequest is a helper type implementing Go's error interface. It's used to assist in
@@ -181,11 +233,12 @@ func (c OpenIdAuthController) PostRoutes(router *mux.Router) {
 // ValidateSession restores a session previously created by the Authenticate function. A sanity check of
 // the id_token is performed if Kiali is not configured to use the access_token. Also, if RBAC is enabled,
 // a privilege check is performed to verify that the user still has privileges to use Kiali.
+// If the session is about to expire and the OpenId server issued a refresh token, the session is renewed.
 // If the session is still valid, a populated UserSessionData is returned. Otherwise, nil is returned.
 func (c OpenIdAuthController) ValidateSession(r *http.Request, w http.ResponseWriter) (*UserSessionData, error) {
 	// Restore a previously started session.
 	sPayload := oidcSessionPayload{}
-	sData, err := c.SessionStore.ReadSession(r, w, &sPayload)
+	sData, err := c.SessionStore.ReadSession(r, w, config.AuthStrategyOpenId, &sPayload)
 	if err != nil {
 		log.Warningf("Could not read the session: %v", err)
 		return nil, nil
@@ -202,6 +255,25 @@ func (c OpenIdAuthController) ValidateSession(r *http.Request, w http.ResponseWr
 
 	conf := config.Get()
 
+	// Renew the session if the tokens are about to expire
+	renewalWindow := time.Duration(conf.Auth.OpenId.TokenRenewalWindow) * time.Second
+	if len(sPayload.RefreshToken) != 0 && renewalWindow > 0 && !util.Clock.Now().Add(renewalWindow).Before(sData.ExpiresOn) {
+		expiresOn, renewedPayload, err := c.renewSession(r, w, sPayload)
+		if err != nil {
+			var tokenErr *openIdTokenError
+			if errors.As(err, &tokenErr) && tokenErr.Code == "invalid_grant" {
+				// The refresh token expired or was revoked by the OpenId server
+				log.Infof("Session of [%s] terminated: the OpenId server rejected the refresh token", sPayload.Subject)
+				c.SessionStore.TerminateSession(r, w)
+				return nil, nil
+			}
+			log.Warningf("Could not renew the session of [%s], it will end on expiration: %v", sPayload.Subject, err)
+		} else {
+			sData.ExpiresOn = expiresOn
+			sPayload = *renewedPayload
+		}
+	}
+
 	// If the id_token is being used to make calls to the cluster API, it's known that
 	// this token is a JWT and some of its structure; so, it's possible to do some sanity
 	// checks on the token. However, if the access_token is being used, this token is opaque
@@ -227,10 +299,21 @@ func (c OpenIdAuthController) ValidateSession(r *http.Request, w http.ResponseWr
 		}
 	}
 
-	var token string
-	if !conf.Auth.OpenId.DisableRBAC {
-		// If RBAC is ENABLED, check that the user has privileges on the cluster.
-		bs, err := business.Get(&api.AuthInfo{Token: sPayload.Token})
+	var authInfo *api.AuthInfo
+	if conf.IsImpersonating(config.AuthStrategyOpenId) || !conf.Auth.OpenId.DisableRBAC {
+		// If impersonating, the Kiali ServiceAccount is used with the identity of the user. Else, if
+		// RBAC is ENABLED, the OpenId token is used.
+		if conf.IsImpersonating(config.AuthStrategyOpenId) {
+			authInfo, err = impersonatedAuthInfo(sPayload.Subject, sPayload.Groups)
+			if err != nil {
+				return nil, err
+			}
+		} else {
+			authInfo = &api.AuthInfo{Token: sPayload.Token}
+		}
+
+		// Check that the user has privileges on the cluster.
+		bs, err := business.Get(authInfo)
 		if err != nil {
 			log.Warningf("Could not get the business layer!!: %v", err)
 			return nil, fmt.Errorf("could not get the business layer: %w", err)
@@ -241,16 +324,15 @@ func (c OpenIdAuthController) ValidateSession(r *http.Request, w http.ResponseWr
 			log.Warningf("Token error!: %v", err)
 			return nil, nil
 		}
-
-		token = sPayload.Token
 	} else {
 		// If RBAC is off, it's assumed that the kubernetes cluster will reject the OpenId token.
 		// Instead, we use the Kiali token and this has the side effect that all users will share the
 		// same privileges.
-		token, err = kubernetes.GetKialiTokenForHomeCluster()
+		token, err := kubernetes.GetKialiTokenForHomeCluster()
 		if err != nil {
 			return nil, fmt.Errorf("error reading the Kiali ServiceAccount token: %w", err)
 		}
+		authInfo = &api.AuthInfo{Token: token}
 	}
 
 	// Internal header used to propagate the subject of the request for audit purposes
@@ -259,16 +341,64 @@ func (c OpenIdAuthController) ValidateSession(r *http.Request, w http.ResponseWr
 	return &UserSessionData{
 		ExpiresOn: sData.ExpiresOn,
 		Username:  sPayload.Subject,
-		AuthInfo:  &api.AuthInfo{Token: token},
+		AuthInfo:  authInfo,
+		Groups:    sPayload.Groups,
 	}, nil
 }
 
-// TerminateSession unconditionally terminates any existing session without any validation.
+// TerminateSession unconditionally terminates any existing session without any validation. If the
+// session holds a refresh token, it is revoked when the OpenId server supports token revocation.
 func (c OpenIdAuthController) TerminateSession(r *http.Request, w http.ResponseWriter) error {
+	sPayload := oidcSessionPayload{}
+	if sData, err := c.SessionStore.ReadSession(r, w, config.AuthStrategyOpenId, &sPayload); err == nil && sData != nil && len(sPayload.RefreshToken) != 0 {
+		if err := revokeOpenIdRefreshToken(sPayload.RefreshToken); err != nil {
+			log.Warningf("Could not revoke the refresh token of the session of [%s]: %v", sPayload.Subject, err)
+		}
+	}
+
 	c.SessionStore.TerminateSession(r, w)
 	return nil
 }
 
+// renewSession exchanges the refresh token of the session for new tokens, and replaces the session
+// with one holding the new tokens. The refresh token is kept, unless the OpenId server rotated it. The
+// new id_token goes through the same checks as on login, and must be for the same user.
+func (c OpenIdAuthController) renewSession(r *http.Request, w http.ResponseWriter, sPayload oidcSessionPayload) (time.Time, *oidcSessionPayload, error) {
+	tokens, err := refreshOpenIdTokens(sPayload.RefreshToken)
+	if err != nil {
+		return time.Time{}, nil, err
+	}
+
+	if len(tokens.IdToken) == 0 {
+		return time.Time{}, nil, errors.New("the IdP did not provide an id_token")
+	}
+
+	flow := openidFlowHelper{
+		AccessToken:          tokens.AccessToken,
+		IdToken:              tokens.IdToken,
+		RefreshToken:         tokens.RefreshToken,
+		businessInstantiator: c.businessInstantiator,
+	}
+	if len(flow.RefreshToken) == 0 {
+		flow.RefreshToken = sPayload.RefreshToken
+	}
+
+	flow.parseOpenIdToken()
+	if flow.Error == nil && flow.Subject != sPayload.Subject {
+		flow.Error = fmt.Errorf("the renewed id_token is for another user [%s]", flow.Subject)
+	}
+	flow.
+		checkAllowedDomains().
+		checkUserPrivileges()
+	renewedPayload := flow.createSession(r, w, c.SessionStore)
+	if flow.Error != nil {
+		return time.Time{}, nil, flow.Error
+	}
+
+	log.Debugf("Session of [%s] renewed until %s", flow.Subject, flow.ExpiresOn.Format(time.RFC822))
+	return flow.ExpiresOn, renewedPayload, nil
+}
+
 // authenticateWithAuthorizationCodeFlow is the entry point to handle OpenId authentication using the authorization
 // code flow. The HTTP request should contain "code" and "state" as URL parameters. Kiali will exchange the code
 // for a token by contacting the OpenId server. If RBAC is enabled, the id_token should be valid to be used in the
@@ -326,7 +456,7 @@ func (c OpenIdAuthController) redirectToAuthServerHandler(w http.ResponseWriter,
 	conf := config.Get()
 
 	// This endpoint should be available only if OpenId strategy is configured
-	if conf.Auth.Strategy != config.AuthStrategyOpenId {
+	if !conf.HasAuthStrategy(config.AuthStrategyOpenId) {
 		w.Header().Set("Content-Type", "text/plain")
 		w.WriteHeader(http.StatusNotFound)
 		_, _ = w.Write([]byte("OpenId strategy is not enabled"))
@@ -443,9 +573,16 @@ type openidFlowHelper struct {
 	// of the implicit flow, or on the request to exchange the authorization code.
 	IdToken string
 
+	// Groups are the groups of the user, read from the id_token when Kiali impersonates the users,
+	// or maps them to roles.
+	Groups []string
+
 	// Nonce is the code used to mitigate replay attacks. It's read from an HTTP Cookie.
 	Nonce string
 
+	// RefreshToken stores the refresh_token returned by the OpenId server, if any.
+	RefreshToken string
+
 	// NonceHash is the sha256 hash of the nonce code. It is calculated after reading the nonce from its http cookie.
 	NonceHash []byte
 
@@ -595,6 +732,9 @@ func (p *openidFlowHelper) checkAllowedDomains() *openidFlowHelper {
 // to log in to Kiali.
 //
 // If RBAC is disabled, then only validity of the id_token is verified (see validateOpenIdTokenInHouse).
+//
+// If Kiali impersonates the users, the validity of the id_token is verified, and the privileges of the
+// impersonated user are tested against the cluster API.
 func (p *openidFlowHelper) checkUserPrivileges() *openidFlowHelper {
 	// Do nothing if there was an error in previous flow steps.
 	if p.Error != nil {
@@ -603,7 +743,33 @@ func (p *openidFlowHelper) checkUserPrivileges() *openidFlowHelper {
 
 	conf := config.Get()
 	p.UseAccessToken = false
-	if conf.Auth.OpenId.DisableRBAC {
+	if conf.IsImpersonating(config.AuthStrategyOpenId) {
+		// The OpenId token isn't passed to the cluster API server, so it must be fully validated.
+		err := validateOpenIdTokenInHouse(p)
+		if err != nil {
+			p.Error = &AuthenticationFailureError{
+				HttpStatus: http.StatusForbidden,
+				Reason:     "the OpenID token was rejected",
+				Detail:     err,
+			}
+			return p
+		}
+
+		authInfo, err := impersonatedAuthInfo(p.Subject, p.Groups)
+		if err != nil {
+			p.Error = err
+			return p
+		}
+		httpStatus, errMsg, detailedError := verifyOpenIdUserAccess(authInfo, p.businessInstantiator)
+		if httpStatus != http.StatusOK {
+			p.Error = &AuthenticationFailureError{
+				HttpStatus: httpStatus,
+				Reason:     errMsg,
+				Detail:     detailedError,
+			}
+			return p
+		}
+	} else if conf.Auth.OpenId.DisableRBAC {
 		// When RBAC is on, we delegate some validations to the Kubernetes cluster. However, if RBAC is off
 		// the token must be fully validated, as we no longer pass the OpenId token to the cluster API server.
 		// Since the configuration indicates RBAC is off, we do the validations:
@@ -625,7 +791,7 @@ func (p *openidFlowHelper) checkUserPrivileges() *openidFlowHelper {
 			apiToken = p.AccessToken
 			p.UseAccessToken = true
 		}
-		httpStatus, errMsg, detailedError := verifyOpenIdUserAccess(apiToken, p.businessInstantiator)
+		httpStatus, errMsg, detailedError := verifyOpenIdUserAccess(&api.AuthInfo{Token: apiToken}, p.businessInstantiator)
 		if httpStatus != http.StatusOK {
 			p.Error = &AuthenticationFailureError{
 				HttpStatus: httpStatus,
@@ -710,14 +876,44 @@ func (p *openidFlowHelper) parseOpenIdToken() *openidFlowHelper {
 	}
 
 	// Extract the name of the user from the id_token. The "subject" is passed to the front-end to be displayed.
+	conf := config.Get()
 	p.Subject = "OpenId User" // Set a default value
-	if userClaim, ok := claims[config.Get().Auth.OpenId.UsernameClaim]; ok && len(userClaim.(string)) > 0 {
+	if userClaim, ok := claims[conf.Auth.OpenId.UsernameClaim]; ok && len(userClaim.(string)) > 0 {
 		p.Subject = userClaim.(string)
+	} else if conf.IsImpersonating(config.AuthStrategyOpenId) {
+		p.Error = &AuthenticationFailureError{
+			Reason: fmt.Sprintf("the received id_token has no '%s' claim to identify the user to impersonate", conf.Auth.OpenId.UsernameClaim),
+		}
+		p.ShouldTerminateSession = true
+		return p
+	}
+
+	// Extract the groups of the user to impersonate them, or to map them to roles
+	if conf.IsImpersonating(config.AuthStrategyOpenId) || conf.Auth.Roles.Enabled {
+		p.Groups = parseGroupsClaim(claims[conf.Auth.Impersonation.GroupsClaim])
 	}
 
 	return p
 }
 
+// parseGroupsClaim parses the groups claim of an id_token, which can be either a list or a single group.
+func parseGroupsClaim(claimValue interface{}) []string {
+	switch groups := claimValue.(type) {
+	case string:
+		return []string{groups}
+	case []interface{}:
+		parsed := make([]string, 0, len(groups))
+		for _, group := range groups {
+			if name, ok := group.(string); ok && name != "" {
+				parsed = append(parsed, name)
+			}
+		}
+		return parsed
+	default:
+		return nil
+	}
+}
+
 // validateOpenIdNonceCode checks that the nonce hash that is present in the id_token is the right
 // hash, given the nonce code present in the http cookie.
 //
@@ -780,33 +976,52 @@ func (p *openidFlowHelper) requestOpenIdToken(redirect_uri string) *openidFlowHe
 		return p
 	}
 
-	oidcMeta, err := getOpenIdMetadata()
+	// Exchange authorization code for a token
+	requestParams := url.Values{}
+	requestParams.Set("code", p.Code)
+	requestParams.Set("grant_type", "authorization_code")
+	requestParams.Set("redirect_uri", redirect_uri)
+
+	tokenResponse, err := postOpenIdTokenRequest(requestParams)
 	if err != nil {
 		p.Error = err
 		return p
 	}
 
+	if len(tokenResponse.IdToken) == 0 {
+		p.Error = errors.New("the IdP did not provide an id_token")
+		return p
+	}
+
+	p.IdToken = tokenResponse.IdToken
+	p.AccessToken = tokenResponse.AccessToken
+	p.RefreshToken = tokenResponse.RefreshToken
+	return p
+}
+
+// postOpenIdTokenRequest makes a request with the given parameters to the token endpoint of the OpenId server,
+// authenticating Kiali with the configured client credentials. An openIdTokenError is returned if the OpenId
+// server rejects the request.
+func postOpenIdTokenRequest(requestParams url.Values) (*openIdTokenResponse, error) {
+	oidcMeta, err := getOpenIdMetadata()
+	if err != nil {
+		return nil, err
+	}
+
 	cfg := config.Get().Auth.OpenId
 
 	httpClient, err := createHttpClient(oidcMeta.TokenURL)
 	if err != nil {
-		p.Error = fmt.Errorf("failure when creating http client to request open id token: %w", err)
-		return p
+		return nil, fmt.Errorf("failure when creating http client to request open id token: %w", err)
 	}
 
-	// Exchange authorization code for a token
-	requestParams := url.Values{}
-	requestParams.Set("code", p.Code)
-	requestParams.Set("grant_type", "authorization_code")
-	requestParams.Set("redirect_uri", redirect_uri)
 	if len(cfg.ClientSecret) == 0 {
 		requestParams.Set("client_id", cfg.ClientId)
 	}
 
 	tokenRequest, err := http.NewRequest(http.MethodPost, oidcMeta.TokenURL, strings.NewReader(requestParams.Encode()))
 	if err != nil {
-		p.Error = fmt.Errorf("failure when creating the token request: %w", err)
-		return p
+		return nil, fmt.Errorf("failure when creating the token request: %w", err)
 	}
 
 	if len(cfg.ClientSecret) > 0 {
@@ -816,43 +1031,130 @@ func (p *openidFlowHelper) requestOpenIdToken(redirect_uri string) *openidFlowHe
 	tokenRequest.Header.Add("Content-Type", "application/x-www-form-urlencoded")
 	response, err := httpClient.Do(tokenRequest)
 	if err != nil {
-		p.Error = fmt.Errorf("failure when requesting token from IdP: %w", err)
-		return p
+		return nil, fmt.Errorf("failure when requesting token from IdP: %w", err)
 	}
 
 	defer response.Body.Close()
 	rawTokenResponse, err := io.ReadAll(response.Body)
 	if err != nil {
-		p.Error = fmt.Errorf("failed to read token response from IdP: %w", err)
-		return p
+		return nil, fmt.Errorf("failed to read token response from IdP: %w", err)
 	}
 
 	if response.StatusCode != 200 {
 		log.Debugf("OpenId token request failed with response: %s", string(rawTokenResponse))
-		p.Error = fmt.Errorf("request failed (HTTP response status = %s)", response.Status)
-		return p
+		var errorResponse struct {
+			Error string `json:"error"`
+		}
+		_ = json.Unmarshal(rawTokenResponse, &errorResponse)
+		return nil, &openIdTokenError{Status: response.Status, Code: errorResponse.Error}
 	}
 
 	// Parse token response
-	var tokenResponse struct {
-		IdToken     string `json:"id_token"`
-		AccessToken string `json:"access_token"`
+	var tokenResponse openIdTokenResponse
+	err = json.Unmarshal(rawTokenResponse, &tokenResponse)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse OpenId token response: %w", err)
 	}
 
-	err = json.Unmarshal(rawTokenResponse, &tokenResponse)
+	return &tokenResponse, nil
+}
+
+// refreshOpenIdTokens exchanges a refresh token for new tokens. Concurrent refreshes with the same refresh
+// token are done only once, and the obtained tokens are kept for a short while for the requests that still
+// send the replaced session (see refreshedOpenIdTokens).
+func refreshOpenIdTokens(refreshToken string) (*openIdTokenResponse, error) {
+	hash := sha256.Sum256([]byte(refreshToken))
+	key := fmt.Sprintf("%x", hash)
+
+	getRefreshed := func() *openIdTokenResponse {
+		refreshedOpenIdTokensMutex.Lock()
+		defer refreshedOpenIdTokensMutex.Unlock()
+		if entry, ok := refreshedOpenIdTokens[key]; ok && util.Clock.Now().Sub(entry.RefreshedOn) < refreshedOpenIdTokensRetention {
+			return entry.Tokens
+		}
+		return nil
+	}
+
+	if tokens := getRefreshed(); tokens != nil {
+		return tokens, nil
+	}
+
+	refreshed, err, _ := openIdFlightGroup.Do("refresh-"+key, func() (interface{}, error) {
+		// The refresh may have completed since the first check
+		if tokens := getRefreshed(); tokens != nil {
+			return tokens, nil
+		}
+
+		requestParams := url.Values{}
+		requestParams.Set("grant_type", "refresh_token")
+		requestParams.Set("refresh_token", refreshToken)
+		tokens, err := postOpenIdTokenRequest(requestParams)
+		if err != nil {
+			return nil, err
+		}
+
+		refreshedOpenIdTokensMutex.Lock()
+		defer refreshedOpenIdTokensMutex.Unlock()
+		now := util.Clock.Now()
+		for k, entry := range refreshedOpenIdTokens {
+			if now.Sub(entry.RefreshedOn) >= refreshedOpenIdTokensRetention {
+				delete(refreshedOpenIdTokens, k)
+			}
+		}
+		refreshedOpenIdTokens[key] = refreshedOpenIdTokensEntry{Tokens: tokens, RefreshedOn: now}
+		return tokens, nil
+	})
 	if err != nil {
-		p.Error = fmt.Errorf("cannot parse OpenId token response: %w", err)
-		return p
+		return nil, err
 	}
 
-	if len(tokenResponse.IdToken) == 0 {
-		p.Error = errors.New("the IdP did not provide an id_token")
-		return p
+	return refreshed.(*openIdTokenResponse), nil
+}
+
+// revokeOpenIdRefreshToken asks the OpenId server to revoke a refresh token, if the server exposes
+// a revocation endpoint (RFC 7009). Otherwise, nothing is done.
+func revokeOpenIdRefreshToken(refreshToken string) error {
+	oidcMeta, err := getOpenIdMetadata()
+	if err != nil {
+		return err
+	}
+	if len(oidcMeta.RevocationURL) == 0 {
+		return nil
 	}
 
-	p.IdToken = tokenResponse.IdToken
-	p.AccessToken = tokenResponse.AccessToken
-	return p
+	cfg := config.Get().Auth.OpenId
+
+	httpClient, err := createHttpClient(oidcMeta.RevocationURL)
+	if err != nil {
+		return fmt.Errorf("failure when creating http client to revoke the refresh token: %w", err)
+	}
+
+	requestParams := url.Values{}
+	requestParams.Set("token", refreshToken)
+	requestParams.Set("token_type_hint", "refresh_token")
+	if len(cfg.ClientSecret) == 0 {
+		requestParams.Set("client_id", cfg.ClientId)
+	}
+
+	revocationRequest, err := http.NewRequest(http.MethodPost, oidcMeta.RevocationURL, strings.NewReader(requestParams.Encode()))
+	if err != nil {
+		return fmt.Errorf("failure when creating the revocation request: %w", err)
+	}
+	if len(cfg.ClientSecret) > 0 {
+		revocationRequest.SetBasicAuth(url.QueryEscape(cfg.ClientId), url.QueryEscape(cfg.ClientSecret))
+	}
+	revocationRequest.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+
+	response, err := httpClient.Do(revocationRequest)
+	if err != nil {
+		return fmt.Errorf("failure when revoking the refresh token: %w", err)
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != 200 {
+		return fmt.Errorf("revocation request failed (HTTP response status = %s)", response.Status)
+	}
+	return nil
 }
 
 // buildSessionPayload returns a struct that should be used as a payload for a call to SessionPersistor.CreateSession.
@@ -863,10 +1165,22 @@ func buildSessionPayload(openIdParams *openidFlowHelper) *oidcSessionPayload {
 		token = openIdParams.AccessToken
 	}
 
-	return &oidcSessionPayload{
+	payload := &oidcSessionPayload{
 		Token:   token,
 		Subject: openIdParams.Subject,
 	}
+
+	// The refresh token is only needed to renew the session
+	conf := config.Get()
+	if conf.Auth.OpenId.TokenRenewalWindow > 0 {
+		payload.RefreshToken = openIdParams.RefreshToken
+	}
+
+	if conf.IsImpersonating(config.AuthStrategyOpenId) || conf.Auth.Roles.Enabled {
+		payload.Groups = openIdParams.Groups
+	}
+
+	return payload
 }
 
 // checkDomain verifies that the "hd" or the "email" claims in tokenClaims contain a domain
@@ -1350,11 +1664,11 @@ func validateOpenIdTokenInHouse(openIdParams *openidFlowHelper) error {
 	return nil
 }
 
-// verifyOpenIdUserAccess checks that the provided token has enough privileges on the cluster to
+// verifyOpenIdUserAccess checks that the provided credentials have enough privileges on the cluster to
 // allow a login to Kiali.
-func verifyOpenIdUserAccess(token string, businessInstantiator func(authInfo *api.AuthInfo) (*business.Layer, error)) (int, string, error) {
+func verifyOpenIdUserAccess(authInfo *api.AuthInfo, businessInstantiator func(authInfo *api.AuthInfo) (*business.Layer, error)) (int, string, error) {
 	// Create business layer using the id_token
-	bsLayer, err := businessInstantiator(&api.AuthInfo{Token: token})
+	bsLayer, err := businessInstantiator(authInfo)
 	if err != nil {
 		return http.StatusInternalServerError, "Error instantiating the business layer", err
 	}
@@ -14,6 +14,8 @@ import (
 
 	osproject_v1 "github.com/openshift/api/project/v1"
 	"github.com/stretchr/testify/assert"
+	"gopkg.in/square/go-jose.v2"
+	"gopkg.in/square/go-jose.v2/jwt"
 	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/tools/clientcmd/api"
 
@@ -863,3 +865,261 @@ func TestOpenIdCodeFlowShouldRejectMissingNonceInToken(t *testing.T) {
 	assert.Equal(t, "/kiali-test/?"+q.Encode(), response.Header.Get("Location"))
 	assert.Equal(t, http.StatusFound, response.StatusCode)
 }
+
+/*** Session renewal tests ***/
+
+// setupOpenIdSessionRenewal starts an OpenId server whose token endpoint is served by tokenHandler, and returns
+// a request sending a session, expiring in one second, that holds the openIdTestToken and the refresh token "refresh-1".
+func setupOpenIdSessionRenewal(t *testing.T, tokenHandler http.HandlerFunc) (*OpenIdAuthController, *http.Request) {
+	cachedOpenIdMetadata = nil
+	refreshedOpenIdTokens = map[string]refreshedOpenIdTokensEntry{}
+	var oidcMetadata []byte
+	testServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/.well-known/openid-configuration" {
+			w.WriteHeader(200)
+			_, _ = w.Write(oidcMetadata)
+		}
+		if r.URL.Path == "/token" {
+			tokenHandler(w, r)
+		}
+	}))
+	t.Cleanup(testServer.Close)
+
+	oidcMeta := openIdMetadata{
+		Issuer:                 testServer.URL,
+		AuthURL:                testServer.URL + "/auth",
+		TokenURL:               testServer.URL + "/token",
+		JWKSURL:                testServer.URL + "/jwks",
+		ScopesSupported:        []string{"openid"},
+		ResponseTypesSupported: []string{"code"},
+	}
+	oidcMetadata, err := json.Marshal(oidcMeta)
+	assert.Nil(t, err)
+
+	clockTime := time.Date(2021, 12, 1, 0, 0, 0, 0, time.UTC)
+	util.Clock = util.ClockMock{Time: clockTime}
+
+	cfg := config.NewConfig()
+	cfg.Server.WebRoot = "/kiali-test"
+	cfg.LoginToken.SigningKey = "kiali67890123456"
+	cfg.Auth.Strategy = config.AuthStrategyOpenId
+	cfg.Auth.OpenId.IssuerUri = testServer.URL
+	cfg.Auth.OpenId.ClientId = "kiali-client"
+	config.Set(cfg)
+
+	k8s := kubetest.NewK8SClientMock()
+	business.SetWithBackends(kubetest.NewK8SClientFactoryMock(k8s), nil)
+	k8s.On("GetProjects", "").Return([]osproject_v1.Project{
+		{ObjectMeta: meta_v1.ObjectMeta{Name: "Foo"}},
+	}, nil)
+
+	controller := NewOpenIdAuthController(CookieSessionPersistor{}, func(authInfo *api.AuthInfo) (*business.Layer, error) {
+		k8sclients := make(map[string]kubernetes.ClientInterface)
+		k8sclients[kubernetes.HomeClusterName] = k8s
+		return business.NewWithBackends(k8sclients, k8sclients, nil, nil), nil
+	})
+
+	rr := httptest.NewRecorder()
+	err = controller.SessionStore.CreateSession(nil, rr, config.AuthStrategyOpenId, clockTime.Add(time.Second), oidcSessionPayload{
+		Subject:      "jdoe@domain.com",
+		Token:        openIdTestToken,
+		RefreshToken: "refresh-1",
+	})
+	assert.Nil(t, err)
+
+	request := httptest.NewRequest(http.MethodGet, "/api/namespaces", nil)
+	for _, cookie := range rr.Result().Cookies() {
+		request.AddCookie(cookie)
+	}
+	return controller, request
+}
+
+// buildOpenIdTestToken returns an id_token for the subject, expiring on the given time
+func buildOpenIdTestToken(t *testing.T, subject string, expiresOn time.Time) string {
+	signer, err := jose.NewSigner(jose.SigningKey{Algorithm: jose.HS256, Key: []byte("kiali67890123456")}, nil)
+	assert.Nil(t, err)
+	token, err := jwt.Signed(signer).Claims(map[string]interface{}{
+		"sub": subject,
+		"iat": util.Clock.Now().Unix(),
+		"exp": expiresOn.Unix(),
+	}).CompactSerialize()
+	assert.Nil(t, err)
+	return token
+}
+
+func TestOpenIdSessionIsRenewedWithRefreshToken(t *testing.T) {
+	var renewedToken string
+	tokenRequests := 0
+	controller, request := setupOpenIdSessionRenewal(t, func(w http.ResponseWriter, r *http.Request) {
+		tokenRequests++
+		_ = r.ParseForm()
+		assert.Equal(t, "refresh_token", r.Form.Get("grant_type"))
+		assert.Equal(t, "refresh-1", r.Form.Get("refresh_token"))
+		assert.Equal(t, "kiali-client", r.Form.Get("client_id"))
+
+		w.WriteHeader(200)
+		_, _ = w.Write([]byte(fmt.Sprintf(`{ "id_token": "%s", "refresh_token": "refresh-2" }`, renewedToken)))
+	})
+	renewedExpiration := time.Date(2021, 12, 1, 1, 0, 0, 0, time.UTC)
+	renewedToken = buildOpenIdTestToken(t, "jdoe@domain.com", renewedExpiration)
+
+	rr := httptest.NewRecorder()
+	sData, err := controller.ValidateSession(request, rr)
+	assert.Nil(t, err)
+	assert.NotNil(t, sData)
+	assert.Equal(t, renewedExpiration, sData.ExpiresOn.UTC())
+	assert.Equal(t, renewedToken, sData.AuthInfo.Token)
+	assert.Equal(t, "jdoe@domain.com", sData.Username)
+
+	// The new session holds the rotated refresh token
+	renewedRequest := httptest.NewRequest(http.MethodGet, "/api/namespaces", nil)
+	for _, cookie := range rr.Result().Cookies() {
+		assert.Equal(t, renewedExpiration, cookie.Expires)
+		renewedRequest.AddCookie(cookie)
+	}
+	sPayload := oidcSessionPayload{}
+	_, err = controller.SessionStore.ReadSession(renewedRequest, httptest.NewRecorder(), config.AuthStrategyOpenId, &sPayload)
+	assert.Nil(t, err)
+	assert.Equal(t, "refresh-2", sPayload.RefreshToken)
+	assert.Equal(t, renewedToken, sPayload.Token)
+
+	// A concurrent request still sending the replaced session gets the same tokens,
+	// without using the rotated refresh token again
+	sData, err = controller.ValidateSession(request, httptest.NewRecorder())
+	assert.Nil(t, err)
+	assert.Equal(t, renewedToken, sData.AuthInfo.Token)
+	assert.Equal(t, 1, tokenRequests)
+}
+
+func TestOpenIdSessionIsTerminatedWhenRefreshTokenIsRevoked(t *testing.T) {
+	controller, request := setupOpenIdSessionRenewal(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(400)
+		_, _ = w.Write([]byte(`{ "error": "invalid_grant", "error_description": "Token is not active" }`))
+	})
+
+	rr := httptest.NewRecorder()
+	sData, err := controller.ValidateSession(request, rr)
+	assert.Nil(t, err)
+	assert.Nil(t, sData)
+
+	// Session cookie is dropped
+	response := rr.Result()
+	assert.NotEmpty(t, response.Cookies())
+	for _, cookie := range response.Cookies() {
+		assert.Equal(t, -1, cookie.MaxAge)
+	}
+}
+
+func TestOpenIdSessionIsKeptWhenRenewalFails(t *testing.T) {
+	controller, request := setupOpenIdSessionRenewal(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(503)
+	})
+
+	rr := httptest.NewRecorder()
+	sData, err := controller.ValidateSession(request, rr)
+	assert.Nil(t, err)
+	assert.NotNil(t, sData)
+	assert.Equal(t, time.Date(2021, 12, 1, 0, 0, 1, 0, time.UTC), sData.ExpiresOn)
+	assert.Equal(t, openIdTestToken, sData.AuthInfo.Token)
+	assert.Empty(t, rr.Result().Cookies())
+}
+
+func TestOpenIdSessionRenewalRejectsAnotherUser(t *testing.T) {
+	var renewedToken string
+	controller, request := setupOpenIdSessionRenewal(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(200)
+		_, _ = w.Write([]byte(fmt.Sprintf(`{ "id_token": "%s" }`, renewedToken)))
+	})
+	renewedToken = buildOpenIdTestToken(t, "mallory@domain.com", time.Date(2021, 12, 1, 1, 0, 0, 0, time.UTC))
+
+	sData, err := controller.ValidateSession(request, httptest.NewRecorder())
+	assert.Nil(t, err)
+	assert.NotNil(t, sData)
+	assert.Equal(t, "jdoe@domain.com", sData.Username)
+	assert.Equal(t, openIdTestToken, sData.AuthInfo.Token)
+}
+
+/*** Impersonation tests ***/
+
+func TestOpenIdSessionImpersonatesUser(t *testing.T) {
+	clockTime := time.Date(2021, 12, 1, 0, 0, 0, 0, time.UTC)
+	util.Clock = util.ClockMock{Time: clockTime}
+
+	cfg := config.NewConfig()
+	cfg.LoginToken.SigningKey = "kiali67890123456"
+	cfg.Auth.Strategy = config.AuthStrategyOpenId
+	cfg.Auth.Impersonation.Enabled = true
+	cfg.Auth.Impersonation.UsernamePrefix = "oidc:"
+	cfg.Auth.Impersonation.GroupsPrefix = "oidc:"
+	config.Set(cfg)
+	setupKialiToken(t, "kiali-token")
+
+	k8s := kubetest.NewK8SClientMock()
+	business.SetWithBackends(kubetest.NewK8SClientFactoryMock(k8s), nil)
+	k8s.On("GetProjects", "").Return([]osproject_v1.Project{
+		{ObjectMeta: meta_v1.ObjectMeta{Name: "Foo"}},
+	}, nil)
+
+	controller := NewOpenIdAuthController(CookieSessionPersistor{}, nil)
+	rr := httptest.NewRecorder()
+	err := controller.SessionStore.CreateSession(nil, rr, config.AuthStrategyOpenId, clockTime.Add(time.Second), oidcSessionPayload{
+		Subject: "jdoe@domain.com",
+		Token:   openIdTestToken,
+		Groups:  []string{"admins"},
+	})
+	assert.Nil(t, err)
+
+	request := httptest.NewRequest(http.MethodGet, "/api/namespaces", nil)
+	for _, cookie := range rr.Result().Cookies() {
+		request.AddCookie(cookie)
+	}
+
+	sData, err := controller.ValidateSession(request, httptest.NewRecorder())
+	assert.Nil(t, err)
+	assert.NotNil(t, sData)
+	assert.Equal(t, "jdoe@domain.com", sData.Username)
+	assert.Equal(t, &api.AuthInfo{
+		Token:             "kiali-token",
+		Impersonate:       "oidc:jdoe@domain.com",
+		ImpersonateGroups: []string{"oidc:admins"},
+	}, sData.AuthInfo)
+}
+
+func TestOpenIdGroupsClaimIsParsed(t *testing.T) {
+	cfg := config.NewConfig()
+	cfg.Auth.Strategy = config.AuthStrategyOpenId
+	cfg.Auth.Impersonation.Enabled = true
+	config.Set(cfg)
+
+	flow := openidFlowHelper{IdToken: openIdTestToken}
+	flow.parseOpenIdToken()
+	assert.Nil(t, flow.Error)
+	assert.Equal(t, "jdoe@domain.com", flow.Subject)
+	assert.Empty(t, flow.Groups)
+
+	assert.Equal(t, []string{"admins", "devs"}, parseGroupsClaim([]interface{}{"admins", "", "devs"}))
+	assert.Equal(t, []string{"admins"}, parseGroupsClaim("admins"))
+
+	// The user to impersonate must be known
+	cfg.Auth.OpenId.UsernameClaim = "preferred_username"
+	config.Set(cfg)
+	flow = openidFlowHelper{IdToken: openIdTestToken}
+	flow.parseOpenIdToken()
+	assert.NotNil(t, flow.Error)
+}
+
+func TestOpenIdGroupsAreStoredForRoles(t *testing.T) {
+	cfg := config.NewConfig()
+	cfg.Auth.Strategy = config.AuthStrategyOpenId
+	cfg.Auth.Roles.Enabled = true
+	config.Set(cfg)
+
+	flow := openidFlowHelper{Subject: "jdoe", Groups: []string{"sre"}}
+	payload := buildSessionPayload(&flow)
+	assert.Equal(t, []string{"sre"}, payload.Groups)
+
+	cfg.Auth.Roles.Enabled = false
+	config.Set(cfg)
+	payload = buildSessionPayload(&flow)
+	assert.Empty(t, payload.Groups)
+}
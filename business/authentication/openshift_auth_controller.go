@@ -19,6 +19,9 @@ import (
 // openshiftSessionPayload holds the data that will be persisted in the SessionStore
 // in order to be able to maintain the session of the user across requests.
 type openshiftSessionPayload struct {
+	// Subject is the name of the user of the token
+	Subject string `json:"subject,omitempty"`
+
 	// Token is the access_token that was provided by the OpenShift OAuth server.
 	// It can be used against the cluster API.
 	Token string `json:"token,omitempty"`
@@ -98,7 +101,7 @@ func (o openshiftAuthController) Authenticate(r *http.Request, w http.ResponseWr
 		}
 	}
 
-	err = o.SessionStore.CreateSession(r, w, config.AuthStrategyOpenshift, expiresOn, openshiftSessionPayload{Token: token})
+	err = o.SessionStore.CreateSession(r, w, config.AuthStrategyOpenshift, expiresOn, openshiftSessionPayload{Subject: user.Metadata.Name, Token: token})
 	if err != nil {
 		return nil, err
 	}
@@ -129,7 +132,7 @@ func (o openshiftAuthController) ValidateSession(r *http.Request, w http.Respons
 		expires = util.Clock.Now().Add(time.Second * time.Duration(config.Get().LoginToken.ExpirationSeconds))
 	} else {
 		sPayload := openshiftSessionPayload{}
-		sData, err := o.SessionStore.ReadSession(r, w, &sPayload)
+		sData, err := o.SessionStore.ReadSession(r, w, config.AuthStrategyOpenshift, &sPayload)
 		if err != nil {
 			log.Warningf("Could not read the openshift session: %v", err)
 			return nil, nil
@@ -176,7 +179,7 @@ func (o openshiftAuthController) ValidateSession(r *http.Request, w http.Respons
 // The cleanup is done assuming the access_token was issued to be used only in Kiali.
 func (o openshiftAuthController) TerminateSession(r *http.Request, w http.ResponseWriter) error {
 	sPayload := openshiftSessionPayload{}
-	sData, err := o.SessionStore.ReadSession(r, w, &sPayload)
+	sData, err := o.SessionStore.ReadSession(r, w, config.AuthStrategyOpenshift, &sPayload)
 	if err != nil {
 		return TerminateSessionError{
 			Message:    fmt.Sprintf("There is no active openshift session: %v", err),
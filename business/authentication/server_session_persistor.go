@@ -0,0 +1,338 @@
+package authentication
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/kiali/kiali/config"
+	"github.com/kiali/kiali/log"
+	"github.com/kiali/kiali/util"
+)
+
+const (
+	// SessionIDCookieName is the name of the cookie holding the ID of a server-side session
+	SessionIDCookieName = config.TokenCookieName + "-session"
+
+	// replacedSessionGracePeriod is how long a replaced session is still accepted, for the concurrent
+	// requests started before the browser got the new session cookie.
+	replacedSessionGracePeriod = time.Minute
+)
+
+// StoredSession is a session kept by a SessionStore. Data is the encrypted session data, and ID is the hash of the
+// session ID sent in the cookie: the stored sessions can't be used to impersonate their users.
+type StoredSession struct {
+	ID        string    `json:"id"`
+	Strategy  string    `json:"strategy"`
+	Username  string    `json:"username,omitempty"`
+	CreatedOn time.Time `json:"createdOn"`
+	ExpiresOn time.Time `json:"expiresOn"`
+	Data      string    `json:"data"`
+}
+
+// SessionStore keeps the sessions of the ServerSessionPersistor
+type SessionStore interface {
+	// Get returns the session with the given (hashed) ID, or nil if there is no such session
+	Get(id string) (*StoredSession, error)
+	// List returns all the sessions
+	List() ([]StoredSession, error)
+	// Put adds a session
+	Put(session StoredSession) error
+	// Delete removes the sessions with the given (hashed) IDs. Unknown IDs are ignored.
+	Delete(ids ...string) error
+}
+
+// SessionInfo describes an active session, without its data
+// swagger:model SessionInfo
+type SessionInfo struct {
+	// The ID to use to revoke the session
+	ID string `json:"id"`
+	// The strategy used to authenticate the user
+	Strategy string `json:"strategy"`
+	// The user of the session, when known
+	Username  string    `json:"username,omitempty"`
+	CreatedOn time.Time `json:"createdOn"`
+	ExpiresOn time.Time `json:"expiresOn"`
+}
+
+// SessionManager is implemented by the persistors able to list and revoke the sessions
+type SessionManager interface {
+	ListSessions() ([]SessionInfo, error)
+	// RevokeSession terminates the session with the given ID. Returns false if there is no such session.
+	RevokeSession(id string) (bool, error)
+	// RevokeUserSessions terminates all the sessions of a user, and returns how many were terminated
+	RevokeUserSessions(username string) (int, error)
+}
+
+// ServerSessionPersistor is a session storage keeping the sessions on the server side: the browser cookie only
+// holds an opaque session ID, and the encrypted session data is kept in a SessionStore. Unlike with the
+// CookieSessionPersistor, the sessions can be listed and revoked.
+type ServerSessionPersistor struct {
+	Store SessionStore
+}
+
+// CreateSession starts a user session, keeping its encrypted data in the store. An existing session of the
+// request is replaced: it expires after a short grace period.
+func (p ServerSessionPersistor) CreateSession(r *http.Request, w http.ResponseWriter, strategy string, expiresOn time.Time, payload interface{}) error {
+	sData, err := newSessionData(strategy, expiresOn, payload)
+	if err != nil {
+		return err
+	}
+	encrypted, err := encryptSessionData(sData)
+	if err != nil {
+		return err
+	}
+
+	rawID, err := util.CryptoRandomBytes(32)
+	if err != nil {
+		return fmt.Errorf("error when creating the session - failed to generate the session ID: %w", err)
+	}
+	sessionID := base64.RawURLEncoding.EncodeToString(rawID)
+
+	err = p.Store.Put(StoredSession{
+		ID:        hashSessionID(sessionID),
+		Strategy:  strategy,
+		Username:  sessionSubject(sData.Payload),
+		CreatedOn: util.Clock.Now(),
+		ExpiresOn: expiresOn,
+		Data:      encrypted,
+	})
+	if err != nil {
+		return fmt.Errorf("error when creating the session - failed to store the session: %w", err)
+	}
+
+	if r != nil {
+		if cookie, err := r.Cookie(SessionIDCookieName); err == nil {
+			if err := p.expireReplacedSession(hashSessionID(cookie.Value)); err != nil {
+				log.Warningf("Could not expire the replaced session: %v", err)
+			}
+		}
+	}
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     SessionIDCookieName,
+		Value:    sessionID,
+		Expires:  expiresOn,
+		HttpOnly: true,
+		Path:     config.Get().Server.WebRoot,
+		SameSite: http.SameSiteStrictMode,
+	})
+	return nil
+}
+
+// ReadSession restores the session of the request from the store. A revoked or expired session, or a session
+// created with a strategy that is no longer enabled, is terminated and no data is returned. No data is returned
+// either for the sessions of the other enabled strategies. If a payload is provided, the original data is parsed
+// and stored in the payload argument.
+func (p ServerSessionPersistor) ReadSession(r *http.Request, w http.ResponseWriter, strategy string, payload interface{}) (*sessionData, error) {
+	cookie, err := r.Cookie(SessionIDCookieName)
+	if err != nil {
+		if err == http.ErrNoCookie {
+			log.Tracef("The session cookie is missing.")
+			return nil, nil
+		}
+		return nil, fmt.Errorf("unable to read the session cookie: %w", err)
+	}
+
+	stored, err := p.Store.Get(hashSessionID(cookie.Value))
+	if err != nil {
+		return nil, fmt.Errorf("error when restoring the session - failed to read the store: %w", err)
+	}
+	if stored == nil {
+		log.Tracef("Session is invalid because it is unknown or it was revoked")
+		p.TerminateSession(r, w)
+		return nil, nil
+	}
+
+	if !util.Clock.Now().Before(stored.ExpiresOn) {
+		log.Tracef("Session is invalid because it was replaced or it expired on %s", stored.ExpiresOn.Format(time.RFC822))
+		p.TerminateSession(r, w)
+		return nil, nil
+	}
+
+	sData, err := decryptSessionData(stored.Data)
+	if err != nil {
+		return nil, err
+	}
+
+	if !config.Get().HasAuthStrategy(sData.Strategy) {
+		log.Tracef("Session is invalid because it was created with authentication strategy %s, which is not enabled", sData.Strategy)
+		p.TerminateSession(r, w)
+		return nil, nil
+	}
+
+	if !util.Clock.Now().Before(sData.ExpiresOn) {
+		log.Tracef("Session is invalid because it expired on %s", sData.ExpiresOn.Format(time.RFC822))
+		p.TerminateSession(r, w)
+		return nil, nil
+	}
+
+	if sData.Strategy != strategy {
+		return nil, nil
+	}
+
+	if payload != nil {
+		if err := json.Unmarshal([]byte(sData.Payload), payload); err != nil {
+			return nil, fmt.Errorf("error when restoring the session - failed to parse the session payload: %w", err)
+		}
+	}
+	return sData, nil
+}
+
+// TerminateSession removes the session of the request from the store, and drops the session cookie
+func (p ServerSessionPersistor) TerminateSession(r *http.Request, w http.ResponseWriter) {
+	cookie, err := r.Cookie(SessionIDCookieName)
+	if err != nil {
+		return
+	}
+	if err := p.Store.Delete(hashSessionID(cookie.Value)); err != nil {
+		log.Errorf("Could not delete the session from the store: %v", err)
+	}
+	http.SetCookie(w, &http.Cookie{
+		Name:     SessionIDCookieName,
+		Value:    "",
+		Expires:  time.Unix(0, 0),
+		HttpOnly: true,
+		MaxAge:   -1,
+		Path:     config.Get().Server.WebRoot,
+		SameSite: http.SameSiteStrictMode,
+	})
+}
+
+// ListSessions returns the active sessions, sorted by creation time
+func (p ServerSessionPersistor) ListSessions() ([]SessionInfo, error) {
+	stored, err := p.Store.List()
+	if err != nil {
+		return nil, err
+	}
+	now := util.Clock.Now()
+	sessions := []SessionInfo{}
+	for _, s := range stored {
+		if !now.Before(s.ExpiresOn) {
+			continue
+		}
+		sessions = append(sessions, SessionInfo{ID: s.ID, Strategy: s.Strategy, Username: s.Username, CreatedOn: s.CreatedOn, ExpiresOn: s.ExpiresOn})
+	}
+	sort.Slice(sessions, func(i, j int) bool { return sessions[i].CreatedOn.Before(sessions[j].CreatedOn) })
+	return sessions, nil
+}
+
+// RevokeSession terminates the session with the given ID, as listed by ListSessions
+func (p ServerSessionPersistor) RevokeSession(id string) (bool, error) {
+	stored, err := p.Store.Get(id)
+	if err != nil || stored == nil {
+		return false, err
+	}
+	return true, p.Store.Delete(id)
+}
+
+// RevokeUserSessions terminates all the sessions of the user
+func (p ServerSessionPersistor) RevokeUserSessions(username string) (int, error) {
+	stored, err := p.Store.List()
+	if err != nil {
+		return 0, err
+	}
+	ids := []string{}
+	for _, s := range stored {
+		if s.Username == username {
+			ids = append(ids, s.ID)
+		}
+	}
+	if len(ids) == 0 {
+		return 0, nil
+	}
+	return len(ids), p.Store.Delete(ids...)
+}
+
+// expireReplacedSession shortens the lifetime of a replaced session to the grace period
+func (p ServerSessionPersistor) expireReplacedSession(id string) error {
+	stored, err := p.Store.Get(id)
+	if err != nil || stored == nil {
+		return err
+	}
+	graceEnd := util.Clock.Now().Add(replacedSessionGracePeriod)
+	if !graceEnd.Before(stored.ExpiresOn) {
+		return nil
+	}
+	stored.ExpiresOn = graceEnd
+	return p.Store.Put(*stored)
+}
+
+func hashSessionID(sessionID string) string {
+	hash := sha256.Sum256([]byte(sessionID))
+	return hex.EncodeToString(hash[:])
+}
+
+// sessionSubject returns the user of a session payload, when the payload records it
+func sessionSubject(payload string) string {
+	var subject struct {
+		Subject string `json:"subject"`
+	}
+	if err := json.Unmarshal([]byte(payload), &subject); err != nil {
+		return ""
+	}
+	return subject.Subject
+}
+
+// memorySessionStore keeps the sessions in memory. They are lost when Kiali restarts, and they aren't shared
+// by the replicas.
+type memorySessionStore struct {
+	mutex    sync.RWMutex
+	sessions map[string]StoredSession
+}
+
+// NewMemorySessionStore returns a store keeping the sessions in memory
+func NewMemorySessionStore() SessionStore {
+	return &memorySessionStore{sessions: map[string]StoredSession{}}
+}
+
+func (s *memorySessionStore) Get(id string) (*StoredSession, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	if session, ok := s.sessions[id]; ok {
+		return &session, nil
+	}
+	return nil, nil
+}
+
+func (s *memorySessionStore) List() ([]StoredSession, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	sessions := make([]StoredSession, 0, len(s.sessions))
+	for _, session := range s.sessions {
+		sessions = append(sessions, session)
+	}
+	return sessions, nil
+}
+
+func (s *memorySessionStore) Put(session StoredSession) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	pruneExpiredSessions(s.sessions)
+	s.sessions[session.ID] = session
+	return nil
+}
+
+func (s *memorySessionStore) Delete(ids ...string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	for _, id := range ids {
+		delete(s.sessions, id)
+	}
+	return nil
+}
+
+func pruneExpiredSessions(sessions map[string]StoredSession) {
+	now := util.Clock.Now()
+	for id, session := range sessions {
+		if !now.Before(session.ExpiresOn) {
+			delete(sessions, id)
+		}
+	}
+}
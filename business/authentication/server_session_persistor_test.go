@@ -0,0 +1,293 @@
+package authentication
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/api/errors"
+	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+
+	"github.com/kiali/kiali/config"
+	"github.com/kiali/kiali/util"
+)
+
+type testSubjectPayload struct {
+	Subject string `json:"subject,omitempty"`
+	Token   string `json:"token,omitempty"`
+}
+
+func setupServerSessions(t *testing.T) time.Time {
+	cfg := config.NewConfig()
+	cfg.Server.WebRoot = "/kiali-app"
+	cfg.LoginToken.SigningKey = "kiali67890123456"
+	cfg.Auth.Strategy = "test"
+	config.Set(cfg)
+
+	clockTime := time.Date(2021, 12, 1, 0, 0, 0, 0, time.UTC)
+	util.Clock = util.ClockMock{Time: clockTime}
+	t.Cleanup(func() { util.Clock = util.RealClock{} })
+	return clockTime
+}
+
+// createServerSession creates a session and returns a request sending its cookie
+func createServerSession(t *testing.T, persistor ServerSessionPersistor, subject string, expiresOn time.Time) *http.Request {
+	rr := httptest.NewRecorder()
+	err := persistor.CreateSession(nil, rr, "test", expiresOn, testSubjectPayload{Subject: subject, Token: "secret-" + subject})
+	require.NoError(t, err)
+	cookies := rr.Result().Cookies()
+	require.Len(t, cookies, 1)
+
+	request := httptest.NewRequest(http.MethodGet, "/api/status", nil)
+	request.AddCookie(cookies[0])
+	return request
+}
+
+func TestServerSessionLifecycle(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+	clockTime := setupServerSessions(t)
+
+	store := NewMemorySessionStore()
+	persistor := ServerSessionPersistor{Store: store}
+	expiresOn := clockTime.Add(time.Hour)
+
+	rr := httptest.NewRecorder()
+	require.NoError(persistor.CreateSession(nil, rr, "test", expiresOn, testSubjectPayload{Subject: "alice", Token: "secret-alice"}))
+	cookie := rr.Result().Cookies()[0]
+	assert.Equal(SessionIDCookieName, cookie.Name)
+	assert.Equal("/kiali-app", cookie.Path)
+	assert.True(cookie.HttpOnly)
+	assert.Equal(expiresOn, cookie.Expires)
+
+	// Only the hash of the session ID and the encrypted data are stored
+	stored, err := store.List()
+	require.NoError(err)
+	require.Len(stored, 1)
+	assert.Equal("alice", stored[0].Username)
+	assert.NotEqual(cookie.Value, stored[0].ID)
+	assert.NotContains(stored[0].Data, "secret-alice")
+
+	request := httptest.NewRequest(http.MethodGet, "/api/status", nil)
+	request.AddCookie(cookie)
+	payload := testSubjectPayload{}
+	sData, err := persistor.ReadSession(request, httptest.NewRecorder(), "test", &payload)
+	require.NoError(err)
+	require.NotNil(sData)
+	assert.Equal(expiresOn, sData.ExpiresOn)
+	assert.Equal("secret-alice", payload.Token)
+
+	// Terminating the session removes it from the store
+	rr = httptest.NewRecorder()
+	persistor.TerminateSession(request, rr)
+	assert.Equal(-1, rr.Result().Cookies()[0].MaxAge)
+	sData, err = persistor.ReadSession(request, httptest.NewRecorder(), "test", nil)
+	assert.NoError(err)
+	assert.Nil(sData)
+}
+
+func TestServerSessionRejected(t *testing.T) {
+	assert := assert.New(t)
+	clockTime := setupServerSessions(t)
+	persistor := ServerSessionPersistor{Store: NewMemorySessionStore()}
+
+	// Unknown session
+	request := httptest.NewRequest(http.MethodGet, "/api/status", nil)
+	request.AddCookie(&http.Cookie{Name: SessionIDCookieName, Value: "forged"})
+	rr := httptest.NewRecorder()
+	sData, err := persistor.ReadSession(request, rr, "test", nil)
+	assert.NoError(err)
+	assert.Nil(sData)
+	assert.Equal(-1, rr.Result().Cookies()[0].MaxAge)
+
+	// Expired session
+	request = createServerSession(t, persistor, "alice", clockTime.Add(time.Minute))
+	util.Clock = util.ClockMock{Time: clockTime.Add(2 * time.Minute)}
+	sData, err = persistor.ReadSession(request, httptest.NewRecorder(), "test", nil)
+	assert.NoError(err)
+	assert.Nil(sData)
+
+	// Session of another strategy
+	util.Clock = util.ClockMock{Time: clockTime}
+	request = createServerSession(t, persistor, "alice", clockTime.Add(time.Hour))
+	cfg := config.Get()
+	cfg.Auth.Strategy = config.AuthStrategyToken
+	config.Set(cfg)
+	sData, err = persistor.ReadSession(request, httptest.NewRecorder(), "test", nil)
+	assert.NoError(err)
+	assert.Nil(sData)
+}
+
+func TestServerSessionRevocation(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+	clockTime := setupServerSessions(t)
+	persistor := ServerSessionPersistor{Store: NewMemorySessionStore()}
+
+	alice1 := createServerSession(t, persistor, "alice", clockTime.Add(time.Hour))
+	util.Clock = util.ClockMock{Time: clockTime.Add(time.Second)}
+	alice2 := createServerSession(t, persistor, "alice", clockTime.Add(time.Hour))
+	util.Clock = util.ClockMock{Time: clockTime.Add(2 * time.Second)}
+	bob := createServerSession(t, persistor, "bob", clockTime.Add(time.Hour))
+
+	sessions, err := persistor.ListSessions()
+	require.NoError(err)
+	require.Len(sessions, 3)
+	assert.Equal([]string{"alice", "alice", "bob"}, []string{sessions[0].Username, sessions[1].Username, sessions[2].Username})
+
+	found, err := persistor.RevokeSession(sessions[2].ID)
+	assert.True(found)
+	assert.NoError(err)
+	found, err = persistor.RevokeSession(sessions[2].ID)
+	assert.False(found)
+	assert.NoError(err)
+	sData, _ := persistor.ReadSession(bob, httptest.NewRecorder(), "test", nil)
+	assert.Nil(sData)
+
+	revoked, err := persistor.RevokeUserSessions("alice")
+	assert.NoError(err)
+	assert.Equal(2, revoked)
+	for _, request := range []*http.Request{alice1, alice2} {
+		sData, _ = persistor.ReadSession(request, httptest.NewRecorder(), "test", nil)
+		assert.Nil(sData)
+	}
+	sessions, _ = persistor.ListSessions()
+	assert.Empty(sessions)
+}
+
+func TestKubeSessionStore(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+	clockTime := setupServerSessions(t)
+
+	for _, useSecret := range []bool{true, false} {
+		client := kubefake.NewSimpleClientset()
+		// Two replicas sharing the sessions
+		replica1 := ServerSessionPersistor{Store: NewKubeSessionStore(client, "istio-system", "kiali-sessions", useSecret, time.Minute, 0)}
+		replica2 := ServerSessionPersistor{Store: NewKubeSessionStore(client, "istio-system", "kiali-sessions", useSecret, time.Minute, 0)}
+
+		request := createServerSession(t, replica1, "alice", clockTime.Add(time.Hour))
+		if useSecret {
+			secret, err := client.CoreV1().Secrets("istio-system").Get(context.TODO(), "kiali-sessions", meta_v1.GetOptions{})
+			require.NoError(err)
+			assert.Len(secret.Data, 1)
+		} else {
+			configMap, err := client.CoreV1().ConfigMaps("istio-system").Get(context.TODO(), "kiali-sessions", meta_v1.GetOptions{})
+			require.NoError(err)
+			assert.Len(configMap.Data, 1)
+			for _, value := range configMap.Data {
+				assert.False(strings.Contains(value, "secret-alice"))
+			}
+		}
+
+		// The session created by a replica is accepted by the other one
+		payload := testSubjectPayload{}
+		sData, err := replica2.ReadSession(request, httptest.NewRecorder(), "test", &payload)
+		require.NoError(err)
+		require.NotNil(sData)
+		assert.Equal("secret-alice", payload.Token)
+
+		// Expired sessions are pruned on writes
+		util.Clock = util.ClockMock{Time: clockTime.Add(2 * time.Hour)}
+		createServerSession(t, replica2, "bob", clockTime.Add(3*time.Hour))
+		sessions, err := replica1.ListSessions()
+		require.NoError(err)
+		require.Len(sessions, 1)
+		assert.Equal("bob", sessions[0].Username)
+
+		revoked, err := replica1.RevokeUserSessions("bob")
+		assert.NoError(err)
+		assert.Equal(1, revoked)
+		sessions, _ = replica2.ListSessions()
+		assert.Empty(sessions)
+		util.Clock = util.ClockMock{Time: clockTime}
+	}
+
+	// Missing resource
+	_, err := kubefake.NewSimpleClientset().CoreV1().Secrets("istio-system").Get(context.TODO(), "kiali-sessions", meta_v1.GetOptions{})
+	assert.True(errors.IsNotFound(err))
+	sessions, err := ServerSessionPersistor{Store: NewKubeSessionStore(kubefake.NewSimpleClientset(), "istio-system", "kiali-sessions", true, time.Minute, 0)}.ListSessions()
+	assert.NoError(err)
+	assert.Empty(sessions)
+}
+
+// storedUsers returns the users of the stored sessions, sorted
+func storedUsers(t *testing.T, store SessionStore) []string {
+	sessions, err := store.List()
+	require.NoError(t, err)
+	users := []string{}
+	for _, session := range sessions {
+		users = append(users, session.Username)
+	}
+	sort.Strings(users)
+	return users
+}
+
+func TestKubeSessionStoreLimit(t *testing.T) {
+	assert := assert.New(t)
+	clockTime := setupServerSessions(t)
+	store := NewKubeSessionStore(kubefake.NewSimpleClientset(), "istio-system", "kiali-sessions", true, time.Minute, 2)
+	persistor := ServerSessionPersistor{Store: store}
+
+	createServerSession(t, persistor, "alice", clockTime.Add(time.Hour))
+	util.Clock = util.ClockMock{Time: clockTime.Add(time.Minute)}
+	createServerSession(t, persistor, "bob", clockTime.Add(2*time.Hour))
+
+	// A login evicts the oldest session when the store is full
+	util.Clock = util.ClockMock{Time: clockTime.Add(2 * time.Minute)}
+	createServerSession(t, persistor, "carol", clockTime.Add(time.Hour))
+	assert.Equal([]string{"bob", "carol"}, storedUsers(t, store))
+
+	// A user logging in again only evicts their own sessions
+	util.Clock = util.ClockMock{Time: clockTime.Add(3 * time.Minute)}
+	createServerSession(t, persistor, "carol", clockTime.Add(time.Hour))
+	util.Clock = util.ClockMock{Time: clockTime.Add(4 * time.Minute)}
+	createServerSession(t, persistor, "carol", clockTime.Add(time.Hour))
+	assert.Equal([]string{"bob", "carol"}, storedUsers(t, store))
+
+	// The API tokens are never evicted
+	tokens := NewKubeSessionStore(kubefake.NewSimpleClientset(), "istio-system", "kiali-tokens", true, time.Minute, 1)
+	assert.NoError(tokens.Put(StoredSession{ID: "a", Strategy: apiTokenStrategy, Username: "alice", ExpiresOn: clockTime.Add(time.Hour)}))
+	err := tokens.Put(StoredSession{ID: "b", Strategy: apiTokenStrategy, Username: "alice", ExpiresOn: clockTime.Add(time.Hour)})
+	assert.ErrorContains(err, "the session store kiali-tokens is full")
+
+	// The sessions must fit in the Secret
+	err = persistor.CreateSession(nil, httptest.NewRecorder(), "test", clockTime.Add(time.Hour), testSubjectPayload{Subject: "dave", Token: strings.Repeat("x", maxKubeStoreSize)})
+	assert.ErrorContains(err, "the session store kiali-sessions is full")
+	assert.Equal([]string{"bob", "carol"}, storedUsers(t, store))
+}
+
+func TestKubeSessionStoreReloadsOncePerInterval(t *testing.T) {
+	assert := assert.New(t)
+	clockTime := setupServerSessions(t)
+
+	client := kubefake.NewSimpleClientset()
+	reads := 0
+	client.PrependReactor("get", "secrets", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		reads++
+		return false, nil, nil
+	})
+	store := NewKubeSessionStore(client, "istio-system", "kiali-sessions", true, time.Minute, 0)
+
+	// The unknown sessions are answered from the local copy
+	for i := 0; i < 3; i++ {
+		session, err := store.Get("forged")
+		assert.NoError(err)
+		assert.Nil(session)
+	}
+	assert.Equal(1, reads)
+
+	util.Clock = util.ClockMock{Time: clockTime.Add(2 * time.Minute)}
+	_, err := store.Get("forged")
+	assert.NoError(err)
+	assert.Equal(2, reads)
+}
@@ -25,7 +25,7 @@ const SessionCookieMaxSize = 3584
 
 type SessionPersistor interface {
 	CreateSession(r *http.Request, w http.ResponseWriter, strategy string, expiresOn time.Time, payload interface{}) error
-	ReadSession(r *http.Request, w http.ResponseWriter, payload interface{}) (sData *sessionData, err error)
+	ReadSession(r *http.Request, w http.ResponseWriter, strategy string, payload interface{}) (sData *sessionData, err error)
 	TerminateSession(r *http.Request, w http.ResponseWriter)
 }
 
@@ -54,62 +54,15 @@ type sessionData struct {
 // the encrypted data is what is sent in cookies. The strategy, expiresOn and payload arguments
 // are all required.
 func (p CookieSessionPersistor) CreateSession(_ *http.Request, w http.ResponseWriter, strategy string, expiresOn time.Time, payload interface{}) error {
-	// Validate that there is a payload and a strategy. The strategy is required just in case Kiali is reconfigured with a
-	// different strategy and drop any stale session. The payload is required because it does not make sense to start a session
-	// if there is no data to persist.
-	if payload == nil || len(strategy) == 0 {
-		return errors.New("a session cannot be created without strategy, or with a nil payload")
-	}
-
-	// Reject expiration time that is already in the past.
-	if !util.Clock.Now().Before(expiresOn) {
-		return errors.New("the expiration time of a session cannot be in the past")
-	}
-
-	// Serialize the payload. The resulting string will be re-serialized along some metadata.
-	// It may not sound very efficient to serialize twice (the sessionData struct may declare
-	//  its Payload field as interface{}). However, this allows de-serialization
-	// to the original type in the ReadSession function, rather than manually parsing a generic map[string]interface{}.
-	// Read more in the ReadSession function.
-	payloadMarshalled, err := json.Marshal(payload)
-	if err != nil {
-		return fmt.Errorf("error when creating the session - failed to marshal payload: %w", err)
-	}
-
-	// Add some metadata to the session and serialize this structure. The resulting string
-	// is what will be encrypted and stored in cookies.
-	sData := sessionData{
-		Strategy:  strategy,
-		ExpiresOn: expiresOn,
-		Payload:   string(payloadMarshalled),
-	}
-
-	sDataJson, err := json.Marshal(sData)
+	sData, err := newSessionData(strategy, expiresOn, payload)
 	if err != nil {
-		return fmt.Errorf("error when creating the session - failed to marshal JSON: %w", err)
+		return err
 	}
-
-	// The sDataJson string holds the session data that we want to persist.
-	// It's time to encrypt this data which will result in an illegible sequence of bytes which are then
-	// encoded to base64 get a string that is suitable to store in browser cookies.
-	block, err := aes.NewCipher([]byte(config.GetSigningKey()))
+	base64SessionData, err := encryptSessionData(sData)
 	if err != nil {
-		return fmt.Errorf("error when creating the session - failed to create cipher: %w", err)
+		return err
 	}
 
-	aesGcm, err := cipher.NewGCM(block)
-	if err != nil {
-		return fmt.Errorf("error when creating credentials - failed to create gcm: %w", err)
-	}
-
-	aesGcmNonce, err := util.CryptoRandomBytes(aesGcm.NonceSize())
-	if err != nil {
-		return fmt.Errorf("error when creating credentials - failed to generate random bytes: %w", err)
-	}
-
-	cipherSessionData := aesGcm.Seal(aesGcmNonce, aesGcmNonce, sDataJson, nil)
-	base64SessionData := base64.StdEncoding.EncodeToString(cipherSessionData)
-
 	// The base64SessionData holds what we want to store in browser cookies.
 	// It's time to set/send the browser cookies to persist the session.
 
@@ -164,9 +117,9 @@ func (p CookieSessionPersistor) CreateSession(_ *http.Request, w http.ResponseWr
 // ReadSession restores (decrypts) and returns the data that was persisted when using the CreateSession function.
 // If a payload is provided, the original data is parsed and stored in the payload argument. As part of restoring
 // the session, validation of expiration time is performed and no data is returned assuming the session is stale.
-// Also, it is verified that the currently configured authentication strategy is the same as when the session was
-// created.
-func (p CookieSessionPersistor) ReadSession(r *http.Request, w http.ResponseWriter, payload interface{}) (*sessionData, error) {
+// Also, it is verified that the authentication strategy of the session is still enabled, and that it is the given
+// strategy: no data is returned for the sessions of the other enabled strategies.
+func (p CookieSessionPersistor) ReadSession(r *http.Request, w http.ResponseWriter, strategy string, payload interface{}) (*sessionData, error) {
 	// This CookieSessionPersistor only deals with sessions using cookies holding encrypted data.
 	// Thus, presence for a cookie with the "-aes" suffix is checked and it's assumed no active session
 	// if such cookie is not found in the request.
@@ -223,45 +176,15 @@ func (p CookieSessionPersistor) ReadSession(r *http.Request, w http.ResponseWrit
 
 	// Persisted data has been read, but it's base64 encoded and it's also encrypted (per
 	// the process in CreateSession function). Reverse the encoding and, then, decrypt the data.
-	cipherSessionData, err := base64.StdEncoding.DecodeString(base64SessionData)
+	sData, err := decryptSessionData(base64SessionData)
 	if err != nil {
-		// Older cookie specs don't allow "=", so it may get trimmed out.  If the std encoding
-		// doesn't work, try raw encoding (with no padding).  If it still fails, error out
-		cipherSessionData, err = base64.RawStdEncoding.DecodeString(base64SessionData)
-		if err != nil {
-			return nil, fmt.Errorf("unable to decode session data: %w", err)
-		}
-	}
-
-	block, err := aes.NewCipher([]byte(config.GetSigningKey()))
-	if err != nil {
-		return nil, fmt.Errorf("error when restoring the session - failed to create the cipher: %w", err)
+		return nil, err
 	}
 
-	aesGCM, err := cipher.NewGCM(block)
-	if err != nil {
-		return nil, fmt.Errorf("error when restoring the session - failed to create gcm: %w", err)
-	}
-
-	nonceSize := aesGCM.NonceSize()
-	nonce, cipherSessionData := cipherSessionData[:nonceSize], cipherSessionData[nonceSize:]
-
-	sessionDataJson, err := aesGCM.Open(nil, nonce, cipherSessionData, nil)
-	if err != nil {
-		return nil, fmt.Errorf("error when restoring the session - failed to decrypt: %w", err)
-	}
-
-	// sessionDataJson is holding the decrypted data as a string. This should be a JSON document. Let's parse it.
-	var sData sessionData
-	err = json.Unmarshal(sessionDataJson, &sData)
-	if err != nil {
-		return nil, fmt.Errorf("error when restoring the session - failed to parse the session data: %w", err)
-	}
-
-	// Check that the currently configured strategy matches the strategy set in the session.
+	// Check that the strategy set in the session is still enabled.
 	// This is to prevent taking a session as valid if somebody re-configured Kiali with a different auth strategy.
-	if sData.Strategy != config.Get().Auth.Strategy {
-		log.Tracef("Session is invalid because it was created with authentication strategy %s, but current authentication strategy is %s", sData.Strategy, config.Get().Auth.Strategy)
+	if !config.Get().HasAuthStrategy(sData.Strategy) {
+		log.Tracef("Session is invalid because it was created with authentication strategy %s, which is not enabled", sData.Strategy)
 		p.TerminateSession(r, w) // Kill the spurious session
 
 		return nil, nil
@@ -277,6 +200,11 @@ func (p CookieSessionPersistor) ReadSession(r *http.Request, w http.ResponseWrit
 		return nil, nil
 	}
 
+	// The session may belong to another of the enabled strategies
+	if sData.Strategy != strategy {
+		return nil, nil
+	}
+
 	// The Payload field of the parsed JSON contains yet another JSON document. This is where we see the advantage
 	// of the double serialization of the payload. Here in ReadSession we are receiving a payload argument. If the caller
 	// passes an object with the original type of the payload that was passed to CreateSession, we can let the json
@@ -289,7 +217,7 @@ func (p CookieSessionPersistor) ReadSession(r *http.Request, w http.ResponseWrit
 		}
 	}
 
-	return &sData, nil
+	return sData, nil
 }
 
 // TerminateSession destroys any persisted data of a session created by the CreateSession function.
@@ -355,3 +283,110 @@ func chunkString(s string, chunkSize int) []string {
 	}
 	return chunks
 }
+
+// newSessionData validates the arguments of a new session, and returns the session data to persist
+func newSessionData(strategy string, expiresOn time.Time, payload interface{}) (*sessionData, error) {
+	// Validate that there is a payload and a strategy. The strategy is required just in case Kiali is reconfigured with a
+	// different strategy and drop any stale session. The payload is required because it does not make sense to start a session
+	// if there is no data to persist.
+	if payload == nil || len(strategy) == 0 {
+		return nil, errors.New("a session cannot be created without strategy, or with a nil payload")
+	}
+
+	// Reject expiration time that is already in the past.
+	if !util.Clock.Now().Before(expiresOn) {
+		return nil, errors.New("the expiration time of a session cannot be in the past")
+	}
+
+	// Serialize the payload. The resulting string will be re-serialized along some metadata.
+	// It may not sound very efficient to serialize twice (the sessionData struct may declare
+	//  its Payload field as interface{}). However, this allows de-serialization
+	// to the original type in the ReadSession function, rather than manually parsing a generic map[string]interface{}.
+	// Read more in the ReadSession function.
+	payloadMarshalled, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("error when creating the session - failed to marshal payload: %w", err)
+	}
+
+	// Add some metadata to the session. This structure is what will be encrypted and persisted.
+	sData := sessionData{
+		Strategy:  strategy,
+		ExpiresOn: expiresOn,
+		Payload:   string(payloadMarshalled),
+	}
+
+	return &sData, nil
+}
+
+// encryptSessionData encrypts the session data using the AES-GCM algorithm. The encrypted data is encoded to base64,
+// to get a string that is suitable to store in browser cookies.
+func encryptSessionData(sData *sessionData) (string, error) {
+	sDataJson, err := json.Marshal(sData)
+	if err != nil {
+		return "", fmt.Errorf("error when creating the session - failed to marshal JSON: %w", err)
+	}
+
+	// The sDataJson string holds the session data that we want to persist.
+	// It's time to encrypt this data which will result in an illegible sequence of bytes which are then
+	// encoded to base64 get a string that is suitable to store in browser cookies.
+	block, err := aes.NewCipher([]byte(config.GetSigningKey()))
+	if err != nil {
+		return "", fmt.Errorf("error when creating the session - failed to create cipher: %w", err)
+	}
+
+	aesGcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return "", fmt.Errorf("error when creating credentials - failed to create gcm: %w", err)
+	}
+
+	aesGcmNonce, err := util.CryptoRandomBytes(aesGcm.NonceSize())
+	if err != nil {
+		return "", fmt.Errorf("error when creating credentials - failed to generate random bytes: %w", err)
+	}
+
+	cipherSessionData := aesGcm.Seal(aesGcmNonce, aesGcmNonce, sDataJson, nil)
+	return base64.StdEncoding.EncodeToString(cipherSessionData), nil
+}
+
+// decryptSessionData reverses the encoding and the encryption of encryptSessionData
+func decryptSessionData(base64SessionData string) (*sessionData, error) {
+	cipherSessionData, err := base64.StdEncoding.DecodeString(base64SessionData)
+	if err != nil {
+		// Older cookie specs don't allow "=", so it may get trimmed out.  If the std encoding
+		// doesn't work, try raw encoding (with no padding).  If it still fails, error out
+		cipherSessionData, err = base64.RawStdEncoding.DecodeString(base64SessionData)
+		if err != nil {
+			return nil, fmt.Errorf("unable to decode session data: %w", err)
+		}
+	}
+
+	block, err := aes.NewCipher([]byte(config.GetSigningKey()))
+	if err != nil {
+		return nil, fmt.Errorf("error when restoring the session - failed to create the cipher: %w", err)
+	}
+
+	aesGCM, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("error when restoring the session - failed to create gcm: %w", err)
+	}
+
+	nonceSize := aesGCM.NonceSize()
+	if len(cipherSessionData) < nonceSize {
+		return nil, errors.New("error when restoring the session - the session data is too short")
+	}
+	nonce, cipherSessionData := cipherSessionData[:nonceSize], cipherSessionData[nonceSize:]
+
+	sessionDataJson, err := aesGCM.Open(nil, nonce, cipherSessionData, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error when restoring the session - failed to decrypt: %w", err)
+	}
+
+	// sessionDataJson is holding the decrypted data as a string. This should be a JSON document. Let's parse it.
+	var sData sessionData
+	err = json.Unmarshal(sessionDataJson, &sData)
+	if err != nil {
+		return nil, fmt.Errorf("error when restoring the session - failed to parse the session data: %w", err)
+	}
+
+	return &sData, nil
+}
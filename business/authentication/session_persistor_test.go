@@ -164,7 +164,7 @@ func TestReadSessionWithNoActiveSession(t *testing.T) {
 	var payload testSessionPayload
 	rr := httptest.NewRecorder()
 	persistor := CookieSessionPersistor{}
-	sData, err := persistor.ReadSession(request, rr, payload)
+	sData, err := persistor.ReadSession(request, rr, "test", payload)
 
 	assert.Nil(t, sData)
 	assert.Nil(t, err)
@@ -203,7 +203,7 @@ func TestReadSessionWithSingleCookie(t *testing.T) {
 	// Read/restore the session.
 	rr = httptest.NewRecorder()
 	restoredPayload := testSessionPayload{}
-	sData, err := persistor.ReadSession(request, rr, &restoredPayload)
+	sData, err := persistor.ReadSession(request, rr, "test", &restoredPayload)
 
 	assert.Nil(t, err)
 	assert.NotNil(t, sData)
@@ -246,7 +246,7 @@ func TestReadSessionWithTwoCookies(t *testing.T) {
 	// Read/restore the session.
 	rr = httptest.NewRecorder()
 	restoredPayload := testSessionPayload{}
-	sData, err := persistor.ReadSession(request, rr, &restoredPayload)
+	sData, err := persistor.ReadSession(request, rr, "test", &restoredPayload)
 
 	assert.Nil(t, err)
 	assert.NotNil(t, sData)
@@ -290,7 +290,7 @@ func TestReadSessionRejectsExpired(t *testing.T) {
 	// Read/restore the session.
 	rr = httptest.NewRecorder()
 	restoredPayload := testSessionPayload{}
-	sData, err := persistor.ReadSession(request, rr, &restoredPayload)
+	sData, err := persistor.ReadSession(request, rr, "test", &restoredPayload)
 
 	assert.Nil(t, err)
 	assert.Nil(t, sData)
@@ -328,7 +328,7 @@ func TestReadSessionRejectsDifferentStrategy(t *testing.T) {
 	// Read/restore the session.
 	rr = httptest.NewRecorder()
 	restoredPayload := testSessionPayload{}
-	sData, err := persistor.ReadSession(request, rr, &restoredPayload)
+	sData, err := persistor.ReadSession(request, rr, "test", &restoredPayload)
 
 	assert.Nil(t, err)
 	assert.Nil(t, sData)
@@ -371,7 +371,7 @@ func TestReadSessionRejectsDifferentSigningKey(t *testing.T) {
 	// Read/restore the session.
 	rr = httptest.NewRecorder()
 	restoredPayload := testSessionPayload{}
-	sData, err := persistor.ReadSession(request, rr, &restoredPayload)
+	sData, err := persistor.ReadSession(request, rr, "test", &restoredPayload)
 
 	assert.NotNil(t, err) // When the signing key does not match, an error is generated.
 	assert.Nil(t, sData)
@@ -33,6 +33,9 @@ type tokenAuthController struct {
 }
 
 type tokenSessionPayload struct {
+	// Subject is the user of the token, as far as it can be known from the token
+	Subject string `json:"subject,omitempty"`
+
 	// Token is the string that the user entered in the Kiali login screen. It should be
 	// a token that can be used against the Kubernetes API
 	Token string `json:"token,omitempty"`
@@ -96,14 +99,15 @@ func (c tokenAuthController) Authenticate(r *http.Request, w http.ResponseWriter
 	// Token was valid against the Kubernetes API, and it has privileges to read some namespace.
 	// Accept the token. Create the user session.
 	timeExpire := util.Clock.Now().Add(time.Second * time.Duration(config.Get().LoginToken.ExpirationSeconds))
-	err = c.SessionStore.CreateSession(r, w, config.AuthStrategyToken, timeExpire, tokenSessionPayload{Token: token})
+	subject := extractSubjectFromK8sToken(token)
+	err = c.SessionStore.CreateSession(r, w, config.AuthStrategyToken, timeExpire, tokenSessionPayload{Subject: subject, Token: token})
 	if err != nil {
 		return nil, err
 	}
 
 	return &UserSessionData{
 		ExpiresOn: timeExpire,
-		Username:  extractSubjectFromK8sToken(token),
+		Username:  subject,
 		AuthInfo:  &api.AuthInfo{Token: token},
 	}, nil
 }
@@ -115,7 +119,7 @@ func (c tokenAuthController) Authenticate(r *http.Request, w http.ResponseWriter
 func (c tokenAuthController) ValidateSession(r *http.Request, w http.ResponseWriter) (*UserSessionData, error) {
 	// Restore a previously started session.
 	sPayload := tokenSessionPayload{}
-	sData, err := c.SessionStore.ReadSession(r, w, &sPayload)
+	sData, err := c.SessionStore.ReadSession(r, w, config.AuthStrategyToken, &sPayload)
 	if err != nil {
 		log.Warningf("Could not read the session: %v", err)
 		return nil, err
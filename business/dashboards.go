@@ -6,10 +6,14 @@ import (
 	"strings"
 	"sync"
 
+	api_errors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/util/validation"
 	"k8s.io/client-go/tools/clientcmd/api"
 
 	"github.com/kiali/kiali/config"
 	"github.com/kiali/kiali/config/dashboards"
+	"github.com/kiali/kiali/kubernetes"
+	"github.com/kiali/kiali/kubernetes/cache"
 	"github.com/kiali/kiali/log"
 	"github.com/kiali/kiali/models"
 	"github.com/kiali/kiali/prometheus"
@@ -45,6 +49,7 @@ func NewDashboardsService(namespace *models.Namespace, workload *models.Workload
 	if namespace != nil {
 		nsDashboards := dashboards.GetNamespaceMonitoringDashboards(namespace.Name, namespace.Annotations)
 		builtInDashboards = dashboards.AddMonitoringDashboards(builtInDashboards, nsDashboards)
+		builtInDashboards = dashboards.AddMonitoringDashboards(builtInDashboards, getResourceDashboards(namespace))
 	}
 	if workload != nil {
 		wkDashboards := dashboards.GetWorkloadMonitoringDashboards(namespace.Name, workload.Name, workload.DashboardAnnotations)
@@ -60,6 +65,52 @@ func NewDashboardsService(namespace *models.Namespace, workload *models.Workload
 	}
 }
 
+// getResourceDashboards returns the dashboards shipped in the namespace as MonitoringDashboard resources or as
+// labeled ConfigMaps. They are read from the cache, so that changes are picked up without restarting Kiali.
+func getResourceDashboards(namespace *models.Namespace) dashboards.MonitoringDashboardsList {
+	found := dashboards.MonitoringDashboardsList{}
+	if !IsNamespaceCached(namespace.Name) {
+		return found
+	}
+	var kubeCache cache.KubeCache = kialiCache
+	if namespace.Cluster != "" {
+		clusterCache, err := kialiCache.GetKubeCache(namespace.Cluster)
+		if err != nil {
+			log.Debugf("No cache for the dashboards of cluster %s: %v", namespace.Cluster, err)
+			return found
+		}
+		kubeCache = clusterCache
+	}
+
+	resources, err := kubeCache.GetMonitoringDashboards(namespace.Name)
+	if err != nil {
+		log.Errorf("Could not read the MonitoringDashboards of namespace %s: %v", namespace.Name, err)
+	}
+	found = append(found, resources...)
+
+	configMaps, err := kubeCache.GetConfigMaps(namespace.Name, dashboards.DashboardConfigMapLabel)
+	if err != nil {
+		log.Errorf("Could not read the dashboards ConfigMaps of namespace %s: %v", namespace.Name, err)
+	}
+	for _, cm := range configMaps {
+		// Sort the entries, for a stable override when a dashboard is defined twice
+		keys := make([]string, 0, len(cm.Data))
+		for key := range cm.Data {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			parsed, err := dashboards.ParseMonitoringDashboards(cm.Data[key])
+			if err != nil {
+				log.Errorf("Invalid dashboard in ConfigMap %s/%s, entry %s: %v", namespace.Name, cm.Name, key, err)
+				continue
+			}
+			found = append(found, parsed...)
+		}
+	}
+	return found
+}
+
 func (in *DashboardsService) prom() (prometheus.ClientInterface, error) {
 	// Lazy init
 	if in.promClient == nil {
@@ -237,6 +288,55 @@ func (in *DashboardsService) GetDashboard(authInfo *api.AuthInfo, params models.
 	}, nil
 }
 
+// grafanaOptions returns the labels used to filter the dashboards metrics, for the Grafana conversions
+func (in *DashboardsService) grafanaOptions() dashboards.GrafanaOptions {
+	labels := config.Get().IstioLabels
+	return dashboards.GrafanaOptions{
+		NamespaceLabel: in.namespaceLabel,
+		FilterLabels:   []string{labels.AppLabelName, labels.VersionLabelName},
+		Scope:          in.promConfig.QueryScope,
+	}
+}
+
+// ExportGrafanaDashboard returns the dashboard as a Grafana dashboard, with its references resolved
+func (in *DashboardsService) ExportGrafanaDashboard(template string) (*dashboards.GrafanaDashboard, error) {
+	if _, ok := in.dashboards[template]; !ok {
+		return nil, kubernetes.NewNotFound(template, "Kiali", "MonitoringDashboard")
+	}
+	dashboard, err := in.loadAndResolveDashboardResource(template, map[string]bool{})
+	if err != nil {
+		return nil, err
+	}
+	grafana := dashboards.ToGrafana(*dashboard, in.grafanaOptions())
+	return &grafana, nil
+}
+
+// ImportGrafanaDashboard converts a Grafana dashboard to a MonitoringDashboard resource of the namespace, named
+// after the dashboard title unless a name is given. The resource is not created: it is returned to be applied.
+func (in *DashboardsService) ImportGrafanaDashboard(grafana dashboards.GrafanaDashboard, namespace, name string) (*models.GrafanaDashboardImport, error) {
+	dashboard, warnings := dashboards.FromGrafana(grafana, name, in.grafanaOptions())
+	if errs := validation.IsDNS1123Subdomain(dashboard.Name); len(errs) > 0 {
+		return nil, api_errors.NewBadRequest(fmt.Sprintf("invalid dashboard name %q: %s", dashboard.Name, strings.Join(errs, ", ")))
+	}
+	if len(dashboard.Items) == 0 {
+		return nil, api_errors.NewBadRequest(fmt.Sprintf("no panel of the Grafana dashboard could be converted: %s", strings.Join(warnings, "; ")))
+	}
+	spec, err := dashboards.ToResourceSpec(*dashboard)
+	if err != nil {
+		return nil, err
+	}
+	delete(spec, "name")
+	return &models.GrafanaDashboardImport{
+		Resource: map[string]interface{}{
+			"apiVersion": kubernetes.MonitoringDashboardGroupVersionV1Alpha1.String(),
+			"kind":       "MonitoringDashboard",
+			"metadata":   map[string]interface{}{"name": dashboard.Name, "namespace": namespace},
+			"spec":       spec,
+		},
+		Warnings: warnings,
+	}, nil
+}
+
 // SearchExplicitDashboards will check annotations of all supplied pods to extract a unique list of dashboards
 // Accepted annotations are "kiali.io/runtimes" and "kiali.io/dashboards"
 func (in *DashboardsService) SearchExplicitDashboards(pods []models.Pod) []models.Runtime {
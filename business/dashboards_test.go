@@ -4,6 +4,8 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/client-go/tools/clientcmd/api"
 
 	"github.com/kiali/kiali/config"
@@ -136,6 +138,46 @@ func TestGetComposedDashboardSingleChart(t *testing.T) {
 	assert.Equal("My chart 1_2", d.Items[2].Chart.Name)
 }
 
+func TestExportGrafanaDashboard(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	composed := fakeDashboard("2")
+	composed.Items = append(composed.Items, dashboards.MonitoringDashboardItem{Include: "dashboard1$My chart 1_2"})
+	service, _ := setupService("my-namespace", []dashboards.MonitoringDashboard{*fakeDashboard("1"), *composed})
+
+	grafana, err := service.ExportGrafanaDashboard("dashboard2")
+	require.NoError(err)
+	assert.Equal("Dashboard 2", grafana.Title)
+	require.Len(grafana.Panels, 3)
+	assert.Equal("My chart 1_2", grafana.Panels[2].Title)
+
+	_, err = service.ExportGrafanaDashboard("missing")
+	assert.True(errors.IsNotFound(err))
+}
+
+func TestImportGrafanaDashboard(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	service, _ := setupService("my-namespace", nil)
+	grafana := dashboards.ToGrafana(*fakeDashboard("1"), service.grafanaOptions())
+
+	imported, err := service.ImportGrafanaDashboard(grafana, "my-namespace", "")
+	require.NoError(err)
+	assert.Empty(imported.Warnings)
+	assert.Equal("MonitoringDashboard", imported.Resource["kind"])
+	assert.Equal(map[string]interface{}{"name": "dashboard-1", "namespace": "my-namespace"}, imported.Resource["metadata"])
+	spec := imported.Resource["spec"].(map[string]interface{})
+	assert.Equal("Dashboard 1", spec["title"])
+	assert.Nil(spec["name"])
+
+	_, err = service.ImportGrafanaDashboard(grafana, "my-namespace", "Not_A_Name")
+	assert.True(errors.IsBadRequest(err))
+	_, err = service.ImportGrafanaDashboard(dashboards.GrafanaDashboard{Title: "Empty"}, "my-namespace", "")
+	assert.True(errors.IsBadRequest(err))
+}
+
 func TestCircularDependency(t *testing.T) {
 	assert := assert.New(t)
 
@@ -990,6 +990,7 @@ func (in *IstioConfigService) DeleteIstioConfigDetail(cluster, namespace, resour
 		err = fmt.Errorf("object type not found: %v", resourceType)
 	}
 	if err != nil {
+		refreshPermissionsOnForbidden(in.userClients[cluster], err)
 		return err
 	}
 
@@ -1064,6 +1065,7 @@ func (in *IstioConfigService) UpdateIstioConfigDetail(cluster, namespace, resour
 	if kialiCache != nil && err == nil {
 		kialiCache.Refresh(namespace)
 	}
+	refreshPermissionsOnForbidden(in.userClients[cluster], err)
 	return istioConfigDetail, err
 }
 
@@ -1189,6 +1191,7 @@ func (in *IstioConfigService) CreateIstioConfigDetail(cluster, namespace, resour
 	if kialiCache != nil && err == nil {
 		kialiCache.Refresh(namespace)
 	}
+	refreshPermissionsOnForbidden(in.userClients[cluster], err)
 	return istioConfigDetail, err
 }
 
@@ -1296,7 +1299,8 @@ func (in *IstioConfigService) GetIstioConfigPermissions(ctx context.Context, nam
 		}
 		wg.Wait()
 
-		// Join networking and security permissions into a single result
+		// Join networking and security permissions into a single result, restricted by the role of the user
+		capabilities := GetCapabilitiesContext(ctx)
 		for _, ns := range namespaces {
 			allRP := make(models.ResourcesPermissions, len(newNetworkingConfigTypes)+len(newSecurityConfigTypes)+len(newK8sNetworkingConfigTypes))
 			istioConfigPermissions[ns] = &allRP
@@ -1309,6 +1313,11 @@ func (in *IstioConfigService) GetIstioConfigPermissions(ctx context.Context, nam
 			for resource, permissions := range *securityPermissions[ns] {
 				(*istioConfigPermissions[ns])[resource] = permissions
 			}
+			for resource := range *istioConfigPermissions[ns] {
+				if !capabilities.CanEditIstioConfig(resource) {
+					(*istioConfigPermissions[ns])[resource] = &models.ResourcePermissions{}
+				}
+			}
 		}
 	}
 	return istioConfigPermissions
@@ -1317,6 +1326,11 @@ func (in *IstioConfigService) GetIstioConfigPermissions(ctx context.Context, nam
 func getPermissions(ctx context.Context, k8s kubernetes.ClientInterface, cluster string, namespace, objectType string) (bool, bool, bool) {
 	var canCreate, canPatch, canDelete bool
 
+	// The role of the user may not allow changing this type of config, whatever the RBAC allows
+	if !GetCapabilitiesContext(ctx).CanEditIstioConfig(objectType) {
+		return canCreate, canPatch, canDelete
+	}
+
 	if api, ok := kubernetes.ResourceTypesToAPI[objectType]; ok {
 		resourceType := objectType
 		return getPermissionsApi(ctx, k8s, cluster, namespace, api, resourceType)
@@ -1346,24 +1360,12 @@ func getPermissionsApi(ctx context.Context, k8s kubernetes.ClientInterface, clus
 		Synced with:
 		https://github.com/kiali/kiali-operator/blob/master/roles/default/kiali-deploy/templates/kubernetes/role.yaml#L62
 	*/
-	ssars, permErr := k8s.GetSelfSubjectAccessReview(ctx, namespace, api, resourceType, []string{"create", "patch", "delete"})
-	if permErr == nil {
-		for _, ssar := range ssars {
-			if ssar.Spec.ResourceAttributes != nil {
-				switch ssar.Spec.ResourceAttributes.Verb {
-				case "create":
-					canCreate = ssar.Status.Allowed
-				case "patch":
-					canPatch = ssar.Status.Allowed
-				case "delete":
-					canDelete = ssar.Status.Allowed
-				}
-			}
-		}
-	} else {
+	allowed, permErr := canI(ctx, k8s, cluster, namespace, api, resourceType, []string{"create", "patch", "delete"})
+	if permErr != nil {
 		log.Errorf("Error getting permissions [namespace: %s, api: %s, resourceType: %s]: %v", namespace, api, "*", permErr)
+		return canCreate, canPatch, canDelete
 	}
-	return canCreate, canPatch, canDelete
+	return allowed["create"], allowed["patch"], allowed["delete"]
 }
 
 func checkType(types []string, name string) bool {
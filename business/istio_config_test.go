@@ -416,6 +416,25 @@ func (a *fakeAccessReview) GetSelfSubjectAccessReview(ctx context.Context, names
 	return fakeGetSelfSubjectAccessReview(), nil
 }
 
+func (a *fakeAccessReview) GetSelfSubjectRulesReview(ctx context.Context, namespace string) (*auth_v1.SelfSubjectRulesReview, error) {
+	return fakeGetSelfSubjectRulesReview(), nil
+}
+
+// fakeGetSelfSubjectRulesReview grants the same permissions as fakeGetSelfSubjectAccessReview, whatever the resource
+func fakeGetSelfSubjectRulesReview() *auth_v1.SelfSubjectRulesReview {
+	return &auth_v1.SelfSubjectRulesReview{
+		Status: auth_v1.SubjectRulesReviewStatus{
+			ResourceRules: []auth_v1.ResourceRule{
+				{
+					Verbs:     []string{"get", "list", "watch", "create", "patch"},
+					APIGroups: []string{"*"},
+					Resources: []string{"*"},
+				},
+			},
+		},
+	}
+}
+
 func mockGetIstioConfigDetails(t *testing.T) IstioConfigService {
 	conf := config.NewConfig()
 	config.Set(conf)
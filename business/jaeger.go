@@ -20,19 +20,25 @@ type (
 
 type JaegerService struct {
 	loader        JaegerLoader
-	loaderErr     error
-	jaeger        jaeger.ClientInterface
 	businessLayer *Layer
 }
 
-func (in *JaegerService) client() (jaeger.ClientInterface, error) {
-	if in.jaeger != nil {
-		return in.jaeger, nil
-	} else if in.loaderErr != nil {
-		return nil, in.loaderErr
+// onceJaegerLoader returns a loader calling the given loader only the first time. It is safe for concurrent use,
+// as the traces of several apps are fetched concurrently by the trace graph.
+func onceJaegerLoader(loader JaegerLoader) JaegerLoader {
+	var once sync.Once
+	var client jaeger.ClientInterface
+	var err error
+	return func() (jaeger.ClientInterface, error) {
+		once.Do(func() {
+			client, err = loader()
+		})
+		return client, err
 	}
-	in.jaeger, in.loaderErr = in.loader()
-	return in.jaeger, in.loaderErr
+}
+
+func (in *JaegerService) client() (jaeger.ClientInterface, error) {
+	return in.loader()
 }
 
 func (in *JaegerService) getFilteredSpans(ns, app string, query models.TracingQuery, filter SpanFilter) ([]jaeger.JaegerSpan, error) {
@@ -44,21 +50,6 @@ func (in *JaegerService) getFilteredSpans(ns, app string, query models.TracingQu
 	return spans, nil
 }
 
-func mergeResponses(dest *jaeger.JaegerResponse, src *jaeger.JaegerResponse) {
-	dest.JaegerServiceName = src.JaegerServiceName
-	dest.Errors = append(dest.Errors, src.Errors...)
-	traceIds := make(map[jaegerModels.TraceID]bool)
-	for _, prev := range dest.Data {
-		traceIds[prev.TraceID] = true
-	}
-	for _, trace := range src.Data {
-		if _, ok := traceIds[trace.TraceID]; !ok {
-			dest.Data = append(dest.Data, trace)
-			traceIds[trace.TraceID] = true
-		}
-	}
-}
-
 func (in *JaegerService) GetAppSpans(ns, app string, query models.TracingQuery) ([]jaeger.JaegerSpan, error) {
 	return in.getFilteredSpans(ns, app, query, nil /*no post-filtering for apps*/)
 }
@@ -120,26 +111,34 @@ func wkdSpanFilter(ns, workload string) SpanFilter {
 	}
 }
 
+// GetAppTraces returns the traces of an app. The filters that the tracing backend doesn't support are applied on the
+// returned traces. When the query limit is reached, NextEndMicros is set for fetching the next page.
 func (in *JaegerService) GetAppTraces(ns, app string, query models.TracingQuery) (*jaeger.JaegerResponse, error) {
 	client, err := in.client()
 	if err != nil {
 		return nil, err
 	}
+	if query.SpanStatus == "error" {
+		// Let the tracing backend pre-filter the traces in error
+		tags := map[string]string{"error": "true"}
+		for k, v := range query.Tags {
+			tags[k] = v
+		}
+		query.Tags = tags
+	}
 	r, err := client.GetAppTraces(ns, app, query)
 	if err != nil {
 		return nil, err
 	}
-	if len(r.Data) == query.Limit {
-		// Reached the limit, use split & join mode to spread traces over the requested interval
-		log.Trace("Limit of traces was reached, using split & join mode")
-		more, err := in.getAppTracesSlicedInterval(ns, app, query)
-		if err != nil {
-			// Log error but continue to process results (might still have some data fetched)
-			log.Errorf("Traces split & join failed: %v", err)
-		}
-		if more != nil {
-			mergeResponses(r, more)
+	paginate(r, query)
+	if query.HasPostFilters() {
+		traces := []jaegerModels.Trace{}
+		for _, trace := range r.Data {
+			if matchesTracingQuery(&trace, query) {
+				traces = append(traces, trace)
+			}
 		}
+		r.Data = traces
 	}
 	return r, nil
 }
@@ -222,53 +221,6 @@ func (in *JaegerService) GetWorkloadTraces(ctx context.Context, ns, workload str
 	return r, err
 }
 
-func (in *JaegerService) getAppTracesSlicedInterval(ns, app string, query models.TracingQuery) (*jaeger.JaegerResponse, error) {
-	client, err := in.client()
-	if err != nil {
-		return nil, err
-	}
-	// Spread queries over 10 interval slices
-	nSlices := 10
-	limit := query.Limit / nSlices
-	if limit == 0 {
-		limit = 1
-	}
-	diff := query.End.Sub(query.Start)
-	duration := diff / time.Duration(nSlices)
-
-	type tracesChanResult struct {
-		resp *jaeger.JaegerResponse
-		err  error
-	}
-	tracesChan := make(chan tracesChanResult, nSlices)
-	var wg sync.WaitGroup
-
-	for i := 0; i < nSlices; i++ {
-		q := query
-		q.Limit = limit
-		q.Start = query.Start.Add(duration * time.Duration(i))
-		q.End = q.Start.Add(duration)
-		wg.Add(1)
-		go func(q models.TracingQuery) {
-			defer wg.Done()
-			r, err := client.GetAppTraces(ns, app, q)
-			tracesChan <- tracesChanResult{resp: r, err: err}
-		}(q)
-	}
-	wg.Wait()
-	// All slices are fetched, close channel
-	close(tracesChan)
-	merged := &jaeger.JaegerResponse{}
-	for r := range tracesChan {
-		if r.err != nil {
-			err = r.err
-			continue
-		}
-		mergeResponses(merged, r.resp)
-	}
-	return merged, err
-}
-
 func (in *JaegerService) GetJaegerTraceDetail(traceID string) (trace *jaeger.JaegerSingleTrace, err error) {
 	client, err := in.client()
 	if err != nil {
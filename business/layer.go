@@ -12,6 +12,7 @@ import (
 	"github.com/kiali/kiali/kubernetes/cache"
 	"github.com/kiali/kiali/log"
 	"github.com/kiali/kiali/prometheus"
+	"github.com/kiali/kiali/tempo"
 )
 
 // Layer is a container for fast access to inner services.
@@ -43,6 +44,7 @@ type Layer struct {
 var (
 	clientFactory    kubernetes.ClientFactory
 	jaegerClient     jaeger.ClientInterface
+	jaegerClientLock sync.Mutex
 	kialiCache       cache.KialiCache
 	once             sync.Once
 	prometheusClient prometheus.ClientInterface
@@ -136,9 +138,11 @@ func Get(authInfo *api.AuthInfo) (*Layer, error) {
 
 	// Create Jaeger client
 	jaegerLoader := func() (jaeger.ClientInterface, error) {
+		jaegerClientLock.Lock()
+		defer jaegerClientLock.Unlock()
 		var err error
 		if jaegerClient == nil {
-			jaegerClient, err = jaeger.NewClient(authInfo.Token)
+			jaegerClient, err = newTracingClient(authInfo.Token)
 			if err != nil {
 				jaegerClient = nil
 			}
@@ -147,7 +151,20 @@ func Get(authInfo *api.AuthInfo) (*Layer, error) {
 	}
 
 	kialiSAClient := clientFactory.GetSAClients()
-	return NewWithBackends(userClients, kialiSAClient, prometheusClient, jaegerLoader), nil
+	// The layer collects the Prometheus warnings of its own queries, apart from the other requests
+	prom := prometheusClient
+	if client, ok := prom.(*prometheus.Client); ok {
+		prom = client.WithContext(client.GetContext())
+	}
+	return NewWithBackends(userClients, kialiSAClient, prom, jaegerLoader), nil
+}
+
+// newTracingClient creates the client of the configured tracing backend
+func newTracingClient(token string) (jaeger.ClientInterface, error) {
+	if config.Get().ExternalServices.Tracing.Provider == config.TempoProvider {
+		return tempo.NewClient(token)
+	}
+	return jaeger.NewClient(token)
 }
 
 // SetWithBackends allows for specifying the ClientFactory and Prometheus clients to be used.
@@ -169,7 +186,7 @@ func NewWithBackends(userClients map[string]kubernetes.ClientInterface, kialiSAC
 	temporaryLayer.IstioConfig = IstioConfigService{config: *config.Get(), userClients: userClients, kialiCache: kialiCache, businessLayer: temporaryLayer}
 	temporaryLayer.IstioStatus = IstioStatusService{k8s: userClients[homeClusterName], businessLayer: temporaryLayer}
 	temporaryLayer.IstioCerts = IstioCertsService{k8s: userClients[homeClusterName], businessLayer: temporaryLayer}
-	temporaryLayer.Jaeger = JaegerService{loader: jaegerClient, businessLayer: temporaryLayer}
+	temporaryLayer.Jaeger = JaegerService{loader: onceJaegerLoader(jaegerClient), businessLayer: temporaryLayer}
 	temporaryLayer.k8sClients = userClients
 	temporaryLayer.Mesh = NewMeshService(userClients[homeClusterName], temporaryLayer, nil)
 	temporaryLayer.Namespace = NewNamespaceService(userClients, kialiSAClients)
@@ -0,0 +1,313 @@
+package business
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/kiali/kiali/jaeger"
+	jaegerModels "github.com/kiali/kiali/jaeger/model/json"
+	"github.com/kiali/kiali/log"
+	"github.com/kiali/kiali/models"
+	"github.com/kiali/kiali/observability"
+)
+
+// requestIDTag is the span tag where Envoy records the x-request-id header of the traced request
+const requestIDTag = "guid:x-request-id"
+
+// traceLogsMargin widens the time window of a trace when fetching logs, to catch lines logged around the spans
+const traceLogsMargin = time.Second
+
+var (
+	// W3C trace context: version-traceid-parentid-flags
+	traceparentRegexp = regexp.MustCompile(`(?i)\b[0-9a-f]{2}-([0-9a-f]{32})-([0-9a-f]{16})-[0-9a-f]{2}\b`)
+	// B3 single header: traceid-spanid[-sampled[-parentspanid]]
+	b3Regexp        = regexp.MustCompile(`(?i)\bb3["']?\s*[=:]\s*["']?([0-9a-f]{32}|[0-9a-f]{16})-([0-9a-f]{16})\b`)
+	b3TraceIDRegexp = regexp.MustCompile(`(?i)\bx-b3-traceid["']?\s*[=:]\s*["']?([0-9a-f]{32}|[0-9a-f]{16})\b`)
+	b3SpanIDRegexp  = regexp.MustCompile(`(?i)\bx-b3-spanid["']?\s*[=:]\s*["']?([0-9a-f]{16})\b`)
+	requestIDRegexp = regexp.MustCompile(`(?i)\bx-request-id["']?\s*[=:]\s*["']?([0-9a-z-]{8,})`)
+)
+
+// TraceLogs holds the log entries of the pods involved in a trace, in the time window of the trace
+type TraceLogs struct {
+	Entries        []LogEntry `json:"entries"`
+	LinesTruncated bool       `json:"linesTruncated,omitempty"`
+	// Pods involved in the trace, as namespace/name
+	Pods     []string `json:"pods"`
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+// extractTraceContext sets the trace, span and request IDs of a log entry. They are read from the proxy access log
+// fields, from the fields of JSON application logs, or from W3C traceparent and B3 headers found in the message.
+func extractTraceContext(entry *LogEntry) {
+	if entry.AccessLog != nil && entry.AccessLog.RequestId != "-" {
+		entry.RequestID = entry.AccessLog.RequestId
+	}
+
+	if strings.HasPrefix(entry.Message, "{") {
+		fields := map[string]interface{}{}
+		if err := json.Unmarshal([]byte(entry.Message), &fields); err == nil {
+			extractJSONTraceContext(entry, fields)
+		}
+	}
+
+	if entry.TraceID == "" {
+		if match := traceparentRegexp.FindStringSubmatch(entry.Message); match != nil {
+			entry.TraceID, entry.SpanID = match[1], match[2]
+		} else if match := b3Regexp.FindStringSubmatch(entry.Message); match != nil {
+			entry.TraceID, entry.SpanID = match[1], match[2]
+		} else if match := b3TraceIDRegexp.FindStringSubmatch(entry.Message); match != nil {
+			entry.TraceID = match[1]
+			if spanMatch := b3SpanIDRegexp.FindStringSubmatch(entry.Message); spanMatch != nil {
+				entry.SpanID = spanMatch[1]
+			}
+		}
+	}
+	if entry.RequestID == "" {
+		if match := requestIDRegexp.FindStringSubmatch(entry.Message); match != nil {
+			entry.RequestID = match[1]
+		}
+	}
+
+	entry.TraceID = strings.ToLower(entry.TraceID)
+	entry.SpanID = strings.ToLower(entry.SpanID)
+}
+
+// extractJSONTraceContext reads trace identifiers from the top level fields of a JSON log line. Field names are
+// compared ignoring case and separators, so trace_id, traceId and trace.id are all the same.
+func extractJSONTraceContext(entry *LogEntry, fields map[string]interface{}) {
+	for key, value := range fields {
+		v, ok := value.(string)
+		if !ok || v == "" {
+			continue
+		}
+		switch strings.NewReplacer("-", "", "_", "", ".", "").Replace(strings.ToLower(key)) {
+		case "traceparent":
+			if match := traceparentRegexp.FindStringSubmatch(v); match != nil {
+				entry.TraceID, entry.SpanID = match[1], match[2]
+			}
+		case "b3":
+			if parts := strings.Split(v, "-"); len(parts) >= 2 {
+				entry.TraceID, entry.SpanID = parts[0], parts[1]
+			}
+		case "traceid", "xb3traceid":
+			entry.TraceID = v
+		case "spanid", "xb3spanid":
+			entry.SpanID = v
+		case "requestid", "xrequestid":
+			entry.RequestID = v
+		}
+	}
+}
+
+// sameTraceID compares trace IDs ignoring case and leading zeros, as Jaeger shortens 128 bits IDs with an empty high part
+func sameTraceID(id1, id2 string) bool {
+	return id1 != "" && strings.TrimLeft(strings.ToLower(id1), "0") == strings.TrimLeft(strings.ToLower(id2), "0")
+}
+
+// GetLogTrace returns the trace of a log entry, given the trace ID or the request ID found in the entry.
+// Finding a trace by request ID requires the app, as traces are searched by app.
+func (in *JaegerService) GetLogTrace(ns, app, traceID, requestID string, query models.TracingQuery) (*jaeger.JaegerSingleTrace, error) {
+	if traceID != "" {
+		return in.GetJaegerTraceDetail(traceID)
+	}
+	if requestID == "" || app == "" {
+		return nil, fmt.Errorf("either a trace ID, or a request ID and an app are required")
+	}
+
+	client, err := in.client()
+	if err != nil {
+		return nil, err
+	}
+	query.Limit = 1
+	if query.Tags == nil {
+		query.Tags = map[string]string{}
+	}
+	query.Tags[requestIDTag] = requestID
+	r, err := client.GetAppTraces(ns, app, query)
+	if err != nil {
+		return nil, err
+	}
+	if r == nil || len(r.Data) == 0 {
+		return nil, nil
+	}
+	return &jaeger.JaegerSingleTrace{Data: r.Data[0], Errors: r.Errors}, nil
+}
+
+// tracePod is a pod involved in a trace
+type tracePod struct {
+	namespace string
+	name      string
+}
+
+// tracePods returns the pods of the spans of a trace. Envoy spans have a node_id tag like
+// sidecar~172.17.0.20~ai-locals-6d8996bff-ztg6z.default~default.svc.cluster.local, other spans
+// are matched with the hostname of their process when they have an istio.namespace tag.
+func tracePods(trace *jaegerModels.Trace) []tracePod {
+	seen := map[tracePod]bool{}
+	pods := []tracePod{}
+	add := func(p tracePod) {
+		if p.name != "" && p.namespace != "" && !seen[p] {
+			seen[p] = true
+			pods = append(pods, p)
+		}
+	}
+
+	for _, span := range trace.Spans {
+		found := false
+		namespace := ""
+		for _, tag := range span.Tags {
+			v, ok := tag.Value.(string)
+			if !ok {
+				continue
+			}
+			switch tag.Key {
+			case "node_id":
+				parts := strings.Split(v, "~")
+				if len(parts) >= 3 {
+					if i := strings.LastIndex(parts[2], "."); i > 0 {
+						add(tracePod{name: parts[2][:i], namespace: parts[2][i+1:]})
+						found = true
+					}
+				}
+			case "istio.namespace":
+				namespace = v
+			}
+		}
+		if found || namespace == "" {
+			continue
+		}
+		if process, ok := trace.Processes[span.ProcessID]; ok {
+			for _, tag := range process.Tags {
+				if v, ok := tag.Value.(string); ok && tag.Key == "hostname" {
+					add(tracePod{name: v, namespace: namespace})
+				}
+			}
+		}
+	}
+	return pods
+}
+
+// traceRequestIDs returns the request IDs recorded in the spans of a trace
+func traceRequestIDs(trace *jaegerModels.Trace) map[string]bool {
+	ids := map[string]bool{}
+	for _, span := range trace.Spans {
+		for _, tag := range span.Tags {
+			if v, ok := tag.Value.(string); ok && tag.Key == requestIDTag {
+				ids[v] = true
+			}
+		}
+	}
+	return ids
+}
+
+// traceTimeWindow returns the time window covered by the spans of a trace
+func traceTimeWindow(trace *jaegerModels.Trace) (time.Time, time.Time) {
+	var start, end uint64
+	for _, span := range trace.Spans {
+		if start == 0 || span.StartTime < start {
+			start = span.StartTime
+		}
+		if span.StartTime+span.Duration > end {
+			end = span.StartTime + span.Duration
+		}
+	}
+	return time.UnixMicro(int64(start)), time.UnixMicro(int64(end))
+}
+
+// GetTraceLogs returns the log entries of all the pods involved in a trace, in the time window of the trace,
+// sorted by timestamp. When correlatedOnly is set, only the entries carrying the trace ID or one of the request
+// IDs of the trace are returned.
+func (in *JaegerService) GetTraceLogs(ctx context.Context, cluster, traceID string, correlatedOnly bool, maxLines int) (*TraceLogs, error) {
+	var end observability.EndFunc
+	ctx, end = observability.StartSpan(ctx, "GetTraceLogs",
+		observability.Attribute("package", "business"),
+		observability.Attribute("cluster", cluster),
+		observability.Attribute("traceID", traceID),
+	)
+	defer end()
+
+	trace, err := in.GetJaegerTraceDetail(traceID)
+	if err != nil {
+		return nil, err
+	}
+	if trace == nil {
+		return nil, nil
+	}
+	return in.businessLayer.Workload.getTraceLogs(ctx, cluster, &trace.Data, correlatedOnly, maxLines)
+}
+
+func (in *WorkloadService) getTraceLogs(ctx context.Context, cluster string, trace *jaegerModels.Trace, correlatedOnly bool, maxLines int) (*TraceLogs, error) {
+	userClient, ok := in.userClients[cluster]
+	if !ok {
+		return nil, fmt.Errorf("user client for cluster [%s] not found", cluster)
+	}
+
+	traceLogs := &TraceLogs{Entries: []LogEntry{}, Pods: []string{}}
+	streams := []containerLogStream{}
+	allContainers := &LogOptions{IsProxy: true}
+	for _, p := range tracePods(trace) {
+		pod, err := in.GetPod(cluster, p.namespace, p.name)
+		if err != nil {
+			// Pods of old traces may no longer exist
+			traceLogs.Warnings = append(traceLogs.Warnings, fmt.Sprintf("Logs of pod [%s/%s] not available: %s", p.namespace, p.name, err.Error()))
+			continue
+		}
+		traceLogs.Pods = append(traceLogs.Pods, p.namespace+"/"+p.name)
+		streams = append(streams, buildContainerLogStreams(p.namespace, models.Pods{pod}, allContainers)...)
+	}
+	if len(streams) == 0 {
+		return traceLogs, nil
+	}
+
+	start, finish := traceTimeWindow(trace)
+	duration := finish.Sub(start) + 2*traceLogsMargin
+	opts := &LogOptions{Duration: &duration}
+	opts.Timestamps = true
+	opts.SinceTime = &meta_v1.Time{Time: start.Add(-traceLogsMargin)}
+	requestIDs := traceRequestIDs(trace)
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	events := make(chan containerLogEvent, len(streams))
+	for i, stream := range streams {
+		go in.readContainerLogs(ctx, userClient, i, stream, opts, events)
+	}
+
+	for openStreams := len(streams); openStreams > 0; {
+		select {
+		case event := <-events:
+			if event.entry == nil {
+				openStreams--
+				continue
+			}
+			// k8s only filters the start time with a precision of seconds
+			if event.entry.TimestampUnix < opts.SinceTime.UnixMilli() {
+				continue
+			}
+			if correlatedOnly && !sameTraceID(event.entry.TraceID, string(trace.TraceID)) && !requestIDs[event.entry.RequestID] {
+				continue
+			}
+			traceLogs.Entries = append(traceLogs.Entries, *event.entry)
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	sort.SliceStable(traceLogs.Entries, func(i, j int) bool {
+		return traceLogs.Entries[i].TimestampUnix < traceLogs.Entries[j].TimestampUnix
+	})
+	if maxLines > 0 && len(traceLogs.Entries) > maxLines {
+		traceLogs.Entries = traceLogs.Entries[:maxLines]
+		traceLogs.LinesTruncated = true
+	}
+	log.Tracef("Found %d log entries in %d pods for trace %s", len(traceLogs.Entries), len(traceLogs.Pods), trace.TraceID)
+	return traceLogs, nil
+}
@@ -0,0 +1,135 @@
+package business
+
+import (
+	"context"
+	"testing"
+
+	"github.com/nitishm/engarde/pkg/parser"
+	osproject_v1 "github.com/openshift/api/project/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	core_v1 "k8s.io/api/core/v1"
+	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/kiali/kiali/config"
+	jaegerModels "github.com/kiali/kiali/jaeger/model/json"
+	"github.com/kiali/kiali/kubernetes/kubetest"
+)
+
+func TestExtractTraceContext(t *testing.T) {
+	assert := assert.New(t)
+	engardeParser := parser.New(parser.IstioProxyAccessLogsPattern)
+
+	entry := parseLogLine(`2021-02-01T21:34:35Z GET /hotels traceparent=00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01 done`, false, engardeParser)
+	assert.Equal("0af7651916cd43dd8448eb211c80319c", entry.TraceID)
+	assert.Equal("b7ad6b7169203331", entry.SpanID)
+
+	entry = parseLogLine(`2021-02-01T21:34:35Z {"level":"error","msg":"failed","trace_id":"0AF7651916CD43DD8448EB211C80319C","spanId":"b7ad6b7169203331","x-request-id":"7e7e2dd0-0a96-4535-950b-e303805b7e27"}`, false, engardeParser)
+	assert.Equal("0af7651916cd43dd8448eb211c80319c", entry.TraceID)
+	assert.Equal("b7ad6b7169203331", entry.SpanID)
+	assert.Equal("7e7e2dd0-0a96-4535-950b-e303805b7e27", entry.RequestID)
+
+	entry = parseLogLine(`2021-02-01T21:34:35Z headers: b3: 80f198ee56343ba864fe8b2a57d3eff7-e457b5a2e4d86bd1-1`, false, engardeParser)
+	assert.Equal("80f198ee56343ba864fe8b2a57d3eff7", entry.TraceID)
+	assert.Equal("e457b5a2e4d86bd1", entry.SpanID)
+
+	entry = parseLogLine(`2021-02-01T21:34:35Z headers: X-B3-TraceId=463ac35c9f6413ad X-B3-SpanId=a2fb4a1d1a96d312`, false, engardeParser)
+	assert.Equal("463ac35c9f6413ad", entry.TraceID)
+	assert.Equal("a2fb4a1d1a96d312", entry.SpanID)
+
+	entry = parseLogLine(FakePodLogsProxy().Logs, true, engardeParser)
+	assert.Equal("", entry.TraceID)
+	assert.Equal("7e7e2dd0-0a96-4535-950b-e303805b7e27", entry.RequestID)
+
+	entry = parseLogLine(`2021-02-01T21:34:35Z nothing to see here`, false, engardeParser)
+	assert.Equal("", entry.TraceID)
+	assert.Equal("", entry.RequestID)
+}
+
+func TestSameTraceID(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.True(sameTraceID("0000000000000000463ac35c9f6413ad", "463ac35c9f6413ad"))
+	assert.True(sameTraceID("463AC35C9F6413AD", "463ac35c9f6413ad"))
+	assert.False(sameTraceID("", ""))
+	assert.False(sameTraceID("463ac35c9f6413ad", "a2fb4a1d1a96d312"))
+}
+
+func fakeTraceWithPods() *jaegerModels.Trace {
+	return &jaegerModels.Trace{
+		TraceID: "463ac35c9f6413ad",
+		Spans: []jaegerModels.Span{
+			{
+				SpanID:    "a2fb4a1d1a96d312",
+				StartTime: 1612215275533000,
+				Duration:  14000,
+				Tags: []jaegerModels.KeyValue{
+					{Key: "node_id", Value: "sidecar~172.17.0.20~details-1.Namespace~Namespace.svc.cluster.local"},
+					{Key: requestIDTag, Value: "7e7e2dd0-0a96-4535-950b-e303805b7e27"},
+				},
+			},
+			{
+				SpanID:    "b2fb4a1d1a96d312",
+				StartTime: 1612215275540000,
+				Duration:  2000,
+				ProcessID: "p1",
+				Tags: []jaegerModels.KeyValue{
+					{Key: "istio.namespace", Value: "Namespace"},
+				},
+			},
+		},
+		Processes: map[jaegerModels.ProcessID]jaegerModels.Process{
+			"p1": {ServiceName: "reviews", Tags: []jaegerModels.KeyValue{{Key: "hostname", Value: "reviews-1"}}},
+		},
+	}
+}
+
+func TestTracePods(t *testing.T) {
+	assert := assert.New(t)
+
+	pods := tracePods(fakeTraceWithPods())
+	assert.Equal([]tracePod{{namespace: "Namespace", name: "details-1"}, {namespace: "Namespace", name: "reviews-1"}}, pods)
+}
+
+func TestGetTraceLogs(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	pod := func(name, container string) *core_v1.Pod {
+		return &core_v1.Pod{
+			ObjectMeta: meta_v1.ObjectMeta{Name: name, Namespace: "Namespace"},
+			Spec:       core_v1.PodSpec{Containers: []core_v1.Container{{Name: container}}},
+		}
+	}
+	k8s := &multiPodLogStreamer{
+		logs: map[string]string{
+			"details-1/details": `2021-02-01T21:34:30Z too early
+2021-02-01T21:34:35.535Z handling x-request-id=7e7e2dd0-0a96-4535-950b-e303805b7e27
+2021-02-01T21:34:35.538Z unrelated`,
+			"reviews-1/reviews": `2021-02-01T21:34:35.541Z {"msg":"reviews","traceId":"463ac35c9f6413ad"}
+2021-02-01T21:34:59Z too late`,
+		},
+		ClientInterface: kubetest.NewFakeK8sClient(
+			&osproject_v1.Project{ObjectMeta: meta_v1.ObjectMeta{Name: "Namespace"}},
+			pod("details-1", "details"),
+			pod("reviews-1", "reviews"),
+		),
+	}
+	conf := config.NewConfig()
+	SetupBusinessLayer(t, k8s, *conf)
+	svc := setupWorkloadService(k8s, conf)
+
+	traceLogs, err := svc.getTraceLogs(context.TODO(), conf.KubernetesConfig.ClusterName, fakeTraceWithPods(), false, 100)
+	require.NoError(err)
+	assert.Equal([]string{"Namespace/details-1", "Namespace/reviews-1"}, traceLogs.Pods)
+	require.Equal(3, len(traceLogs.Entries))
+	assert.Equal("handling x-request-id=7e7e2dd0-0a96-4535-950b-e303805b7e27", traceLogs.Entries[0].Message)
+	assert.Equal("unrelated", traceLogs.Entries[1].Message)
+	assert.Equal("reviews-1", traceLogs.Entries[2].Pod)
+
+	traceLogs, err = svc.getTraceLogs(context.TODO(), conf.KubernetesConfig.ClusterName, fakeTraceWithPods(), true, 100)
+	require.NoError(err)
+	require.Equal(2, len(traceLogs.Entries))
+	assert.Equal("7e7e2dd0-0a96-4535-950b-e303805b7e27", traceLogs.Entries[0].RequestID)
+	assert.Equal("463ac35c9f6413ad", traceLogs.Entries[1].TraceID)
+}
@@ -50,6 +50,10 @@ type Cluster struct {
 	// IsKialiHome specifies if this cluster is hosting this Kiali instance (and the observed Mesh Control Plane)
 	IsKialiHome bool `json:"isKialiHome"`
 
+	// IsFederated specifies if the data of this cluster is read from the API of its Kiali instance, instead of its
+	// Kubernetes API
+	IsFederated bool `json:"isFederated"`
+
 	// IsGatewayToNamespace specifies the PILOT_SCOPE_GATEWAY_TO_NAMESPACE environment variable in Control PLane
 	IsGatewayToNamespace bool `json:"isGatewayToNamespace"`
 
@@ -62,7 +66,8 @@ type Cluster struct {
 	// Network specifies the logical NETWORK_ID as known by the Control Plane
 	Network string `json:"network"`
 
-	// SecretName is the name of the kubernetes "remote cluster secret" that was mounted to the file system and where data of this cluster was resolved
+	// SecretName is the name of the kubernetes "remote cluster secret" that was mounted to the file system, or watched
+	// by Kiali, and where data of this cluster was resolved
 	SecretName string `json:"secretName"`
 }
 
@@ -139,9 +144,37 @@ func (in *MeshService) GetClusters(r *http.Request) (clusters []Cluster, errVal
 		clusters = append(remoteClusters, *myCluster)
 	}
 
+	clusters = append(clusters, federatedClusters(clusters)...)
+
 	return
 }
 
+// federatedClusters returns the clusters of the federated Kiali instances that aren't already known
+func federatedClusters(known []Cluster) []Cluster {
+	cfg := config.Get()
+	if !cfg.Federation.Enabled {
+		return []Cluster{}
+	}
+
+	knownNames := make(map[string]bool, len(known))
+	for _, cluster := range known {
+		knownNames[cluster.Name] = true
+	}
+
+	clusters := []Cluster{}
+	for _, peer := range cfg.Federation.Peers {
+		if knownNames[peer.Cluster] {
+			continue
+		}
+		clusters = append(clusters, Cluster{
+			IsFederated:    true,
+			KialiInstances: []KialiInstance{{Url: peer.URL}},
+			Name:           peer.Cluster,
+		})
+	}
+	return clusters
+}
+
 // IsMeshConfigured does not change and can be cached
 
 // isMeshConfiguredCached just indicates whether we have cached the value (because it may be false)
@@ -436,9 +469,13 @@ func (in *MeshService) resolveRemoteClustersFromSecrets() ([]Cluster, error) {
 	// Strictly speaking, this list may be incomplete: it's list of visible clusters for a control plane.
 	// But, for now, let's use it as the absolute "list of clusters in the mesh (excluding home cluster)".
 
-	remoteClusterInfos, err := kubernetes.GetRemoteClusterInfos()
-	if err != nil || len(remoteClusterInfos) == 0 {
-		return []Cluster{}, err
+	// The remote clusters are the ones known by the client factory, which follows the clusters registered at runtime
+	if clientFactory == nil {
+		return []Cluster{}, nil
+	}
+	remoteClusterInfos := clientFactory.GetRemoteClusterInfos()
+	if len(remoteClusterInfos) == 0 {
+		return []Cluster{}, nil
 	}
 
 	clusters := make([]Cluster, 0, len(remoteClusterInfos))
@@ -226,6 +226,13 @@ func TestGetClustersResolvesRemoteClusters(t *testing.T) {
 		return remoteClient, nil
 	}
 
+	// The client factory loads the mounted remote cluster secrets at startup
+	mockClientFactory := kubetest.NewK8SClientFactoryMock(k8s)
+	remoteClusterInfos, err := kubernetes.GetRemoteClusterInfos()
+	check.Nil(err)
+	mockClientFactory.RemoteClusterInfos = remoteClusterInfos
+	SetWithBackends(mockClientFactory, nil)
+
 	clients := make(map[string]kubernetes.ClientInterface)
 	clients[kubernetes.HomeClusterName] = k8s
 	layer := NewWithBackends(clients, clients, nil, nil)
@@ -0,0 +1,245 @@
+package business
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	api_errors "k8s.io/apimachinery/pkg/api/errors"
+
+	"github.com/kiali/kiali/config"
+	"github.com/kiali/kiali/kubernetes"
+	"github.com/kiali/kiali/models"
+)
+
+var (
+	templateVariableRegexp = regexp.MustCompile(`\$([a-zA-Z][a-zA-Z0-9_]*)`)
+	// The substituted values are restricted to Kubernetes names, so that they can't escape a PromQL string
+	templateValueRegexp = regexp.MustCompile(`^[a-zA-Z0-9]([-a-zA-Z0-9_.]*[a-zA-Z0-9])?$`)
+	rateIntervalRegexp  = regexp.MustCompile(`^[0-9]+(ms|s|m|h|d|w|y)$`)
+	templateIdentRegexp = regexp.MustCompile(`^[a-zA-Z_:][a-zA-Z0-9_:]*`)
+	// label="$namespace", the only namespace matcher accepted in a template selector
+	namespaceMatcherRegexp = regexp.MustCompile(`([a-zA-Z_][a-zA-Z0-9_]*)\s*=\s*"\$namespace"`)
+)
+
+// Variables that can be used in the metric templates
+var metricTemplateVariables = map[string]bool{
+	"app": true, "cluster": true, "namespace": true, "rateInterval": true, "service": true, "version": true, "workload": true,
+}
+
+// PromQL keywords that can be followed by something else than a parenthesis
+var promQLKeywords = map[string]bool{
+	"and": true, "or": true, "unless": true, "bool": true, "offset": true, "atan2": true, "Inf": true, "NaN": true, "inf": true, "nan": true,
+}
+
+// PromQL modifiers followed by a list of labels
+var promQLLabelLists = map[string]bool{
+	"by": true, "without": true, "on": true, "ignoring": true, "group_left": true, "group_right": true,
+}
+
+// Namespace labels used by the Istio metrics, accepted in addition to the custom dashboards namespace label
+var istioNamespaceLabels = []string{"namespace", "source_workload_namespace", "destination_workload_namespace", "destination_service_namespace"}
+
+// GetMetricTemplates returns the valid metric templates of the configuration
+func (in *DashboardsService) GetMetricTemplates() []models.MetricTemplate {
+	templates := []models.MetricTemplate{}
+	for _, t := range config.Get().ExternalServices.CustomDashboards.QueryTemplates {
+		variables, err := in.validateMetricTemplate(t)
+		if err != nil {
+			continue
+		}
+		templates = append(templates, models.MetricTemplate{Name: t.Name, Description: t.Description, Unit: t.Unit, Variables: variables})
+	}
+	sort.Slice(templates, func(i, j int) bool { return templates[i].Name < templates[j].Name })
+	return templates
+}
+
+// GetMetricTemplate runs the metric template over the query range. The caller must check that the query namespace
+// is accessible to the user: the template is confined to that namespace. Returns the warnings of the query.
+func (in *DashboardsService) GetMetricTemplate(query models.MetricTemplateQuery, name string) (*models.MetricTemplateResult, []string, error) {
+	var template *config.MetricQueryTemplate
+	for _, t := range config.Get().ExternalServices.CustomDashboards.QueryTemplates {
+		if t.Name == name {
+			t := t
+			template = &t
+			break
+		}
+	}
+	if template == nil {
+		return nil, nil, kubernetes.NewNotFound(name, "Kiali", "MetricTemplate")
+	}
+	if _, err := in.validateMetricTemplate(*template); err != nil {
+		return nil, nil, api_errors.NewInternalError(fmt.Errorf("invalid metric template %s: %v", name, err))
+	}
+
+	values := make(map[string]string, len(query.Variables)+3)
+	for k, v := range query.Variables {
+		values[k] = v
+	}
+	values["namespace"] = query.Namespace
+	values["cluster"] = query.Cluster
+	values["rateInterval"] = query.RateInterval
+	promQL, err := substituteMetricTemplate(template.Query, values)
+	if err != nil {
+		return nil, nil, api_errors.NewBadRequest(err.Error())
+	}
+
+	client, err := in.prom()
+	if err != nil {
+		return nil, nil, err
+	}
+	metric := client.FetchQueryRange(promQL, &query.RangeQuery)
+	converted, err := models.ConvertMetric(name, metric, models.ConversionParams{Scale: 1.0})
+	if err != nil {
+		return nil, metric.Warnings, err
+	}
+	return &models.MetricTemplateResult{Name: name, Unit: template.Unit, Metrics: converted}, metric.Warnings, nil
+}
+
+// namespaceLabels returns the labels accepted to confine a template selector to the namespace
+func (in *DashboardsService) namespaceLabels() map[string]bool {
+	labels := map[string]bool{in.namespaceLabel: true}
+	for _, l := range istioNamespaceLabels {
+		labels[l] = true
+	}
+	return labels
+}
+
+// validateMetricTemplate checks that the template only uses known variables, and that every selector of the
+// expression is confined to the namespace: it must match a namespace label on "$namespace", and there must be no
+// selector without labels (i.e. a bare metric name). Returns the variables used by the template.
+func (in *DashboardsService) validateMetricTemplate(t config.MetricQueryTemplate) ([]string, error) {
+	if t.Name == "" || t.Query == "" {
+		return nil, fmt.Errorf("name and query are required")
+	}
+	used := map[string]bool{}
+	for _, match := range templateVariableRegexp.FindAllStringSubmatch(t.Query, -1) {
+		if !metricTemplateVariables[match[1]] {
+			return nil, fmt.Errorf("unknown variable $%s", match[1])
+		}
+		used[match[1]] = true
+	}
+	selectors, err := templateSelectors(t.Query)
+	if err != nil {
+		return nil, err
+	}
+	if len(selectors) == 0 {
+		return nil, fmt.Errorf("no selector found")
+	}
+	namespaceLabels := in.namespaceLabels()
+	for _, selector := range selectors {
+		confined := false
+		for _, match := range namespaceMatcherRegexp.FindAllStringSubmatch(selector, -1) {
+			if namespaceLabels[match[1]] {
+				confined = true
+				break
+			}
+		}
+		if !confined {
+			return nil, fmt.Errorf("selector {%s} must match a namespace label on \"$namespace\"", selector)
+		}
+	}
+	variables := make([]string, 0, len(used))
+	for v := range used {
+		variables = append(variables, v)
+	}
+	sort.Strings(variables)
+	return variables, nil
+}
+
+// templateSelectors returns the content of the label selectors ({...}) of a PromQL expression. It fails when it
+// finds a selector without labels, e.g. "up" or "up[5m]", that can't be confined to a namespace.
+func templateSelectors(query string) ([]string, error) {
+	selectors := []string{}
+	for i := 0; i < len(query); {
+		c := query[i]
+		switch {
+		case c == '"' || c == '\'' || c == '`':
+			end := strings.IndexByte(query[i+1:], c)
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated string")
+			}
+			i += end + 2
+		case c == '[':
+			end := strings.IndexByte(query[i:], ']')
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated range")
+			}
+			i += end + 1
+		case c == '{':
+			end := strings.IndexByte(query[i:], '}')
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated selector")
+			}
+			selectors = append(selectors, query[i+1:i+end])
+			i += end + 1
+		case c >= '0' && c <= '9' || c == '.':
+			// number or duration
+			for i < len(query) && (isAlphaNum(query[i]) || query[i] == '.') {
+				i++
+			}
+		case c == '$':
+			// variable, substituted later
+			i++
+			for i < len(query) && isAlphaNum(query[i]) {
+				i++
+			}
+		default:
+			ident := templateIdentRegexp.FindString(query[i:])
+			if ident == "" {
+				i++
+				continue
+			}
+			i += len(ident)
+			next := i
+			for next < len(query) && (query[next] == ' ' || query[next] == '\t' || query[next] == '\n') {
+				next++
+			}
+			switch {
+			case promQLLabelLists[ident]:
+				// skip the labels list
+				if next < len(query) && query[next] == '(' {
+					if end := strings.IndexByte(query[next:], ')'); end >= 0 {
+						i = next + end + 1
+					}
+				}
+			case promQLKeywords[ident]:
+			case next < len(query) && (query[next] == '(' || query[next] == '{'):
+				// function, aggregation, or selector with labels
+			case promQLLabelLists[templateIdentRegexp.FindString(query[next:])]:
+				// aggregation with a leading clause, e.g. sum by (label) (...)
+			default:
+				return nil, fmt.Errorf("selector %s must have labels matching the namespace", ident)
+			}
+		}
+	}
+	return selectors, nil
+}
+
+func isAlphaNum(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '_'
+}
+
+// substituteMetricTemplate replaces the variables of the template with their values, which must be valid names
+// (or a duration for $rateInterval)
+func substituteMetricTemplate(query string, values map[string]string) (string, error) {
+	var err error
+	result := templateVariableRegexp.ReplaceAllStringFunc(query, func(variable string) string {
+		name := variable[1:]
+		value := values[name]
+		switch {
+		case value == "":
+			err = fmt.Errorf("missing value for variable $%s", name)
+		case name == "rateInterval" && !rateIntervalRegexp.MatchString(value):
+			err = fmt.Errorf("invalid value for variable $%s: %s", name, value)
+		case name != "rateInterval" && !templateValueRegexp.MatchString(value):
+			err = fmt.Errorf("invalid value for variable $%s: %s", name, value)
+		}
+		return value
+	})
+	if err != nil {
+		return "", err
+	}
+	return result, nil
+}
@@ -0,0 +1,113 @@
+package business
+
+import (
+	"testing"
+
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	api_errors "k8s.io/apimachinery/pkg/api/errors"
+
+	"github.com/kiali/kiali/config"
+	"github.com/kiali/kiali/models"
+	"github.com/kiali/kiali/prometheus"
+	pmock "github.com/kiali/kiali/prometheus/prometheustest"
+)
+
+func setupMetricTemplates(templates ...config.MetricQueryTemplate) (*DashboardsService, *pmock.PromClientMock) {
+	cfg := config.NewConfig()
+	cfg.ExternalServices.CustomDashboards.QueryTemplates = templates
+	config.Set(cfg)
+	prom := new(pmock.PromClientMock)
+	service := NewDashboardsService(&models.Namespace{Name: "bookinfo"}, nil)
+	service.promClient = prom
+	return service, prom
+}
+
+func TestValidateMetricTemplate(t *testing.T) {
+	assert := assert.New(t)
+	service, _ := setupMetricTemplates()
+
+	variables, err := service.validateMetricTemplate(config.MetricQueryTemplate{
+		Name: "saturation",
+		Query: `sum(rate(container_cpu_usage_seconds_total{namespace="$namespace",pod=~"$workload-.*"}[$rateInterval])) by (pod)
+			/ on (pod) group_left sum(kube_pod_container_resource_limits{namespace="$namespace",resource="cpu"}) by (pod) offset 1m`,
+	})
+	assert.NoError(err)
+	assert.Equal([]string{"namespace", "rateInterval", "workload"}, variables)
+
+	_, err = service.validateMetricTemplate(config.MetricQueryTemplate{
+		Name:  "istio",
+		Query: `sum by (response_code) (rate(istio_requests_total{destination_workload_namespace="$namespace"}[5m])) > 0`,
+	})
+	assert.NoError(err)
+
+	invalid := map[string]string{
+		"not confined":     `sum(rate(istio_requests_total{reporter="source"}[5m]))`,
+		"regex namespace":  `sum(queue_depth{namespace=~".*"})`,
+		"other label":      `sum(queue_depth{pod="$namespace"})`,
+		"bare selector":    `sum(queue_depth{namespace="$namespace"}) / sum(up)`,
+		"bare range":       `rate(requests_total[5m])`,
+		"unknown variable": `sum(queue_depth{namespace="$namespace",team="$team"})`,
+	}
+	for name, query := range invalid {
+		_, err = service.validateMetricTemplate(config.MetricQueryTemplate{Name: name, Query: query})
+		assert.Error(err, name)
+	}
+}
+
+func TestSubstituteMetricTemplate(t *testing.T) {
+	assert := assert.New(t)
+
+	query, err := substituteMetricTemplate(`sum(queue_depth{namespace="$namespace",app="$app"}[$rateInterval])`,
+		map[string]string{"namespace": "bookinfo", "app": "reviews", "rateInterval": "5m"})
+	assert.NoError(err)
+	assert.Equal(`sum(queue_depth{namespace="bookinfo",app="reviews"}[5m])`, query)
+
+	// Values can't escape the PromQL strings
+	_, err = substituteMetricTemplate(`sum(queue_depth{namespace="$namespace",app="$app"})`,
+		map[string]string{"namespace": "bookinfo", "app": `x"} or up{a="`})
+	assert.Error(err)
+	_, err = substituteMetricTemplate(`rate(queue_depth{namespace="$namespace"}[$rateInterval])`,
+		map[string]string{"namespace": "bookinfo", "rateInterval": "5m])"})
+	assert.Error(err)
+
+	// Used variables are required
+	_, err = substituteMetricTemplate(`sum(queue_depth{namespace="$namespace",app="$app"})`, map[string]string{"namespace": "bookinfo"})
+	assert.Error(err)
+}
+
+func TestGetMetricTemplate(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	service, prom := setupMetricTemplates(
+		config.MetricQueryTemplate{Name: "queue", Query: `max(queue_depth{namespace="$namespace",app="$app"})`, Unit: "messages"},
+		config.MetricQueryTemplate{Name: "invalid", Query: `max(queue_depth)`},
+	)
+	query := models.MetricTemplateQuery{Namespace: "bookinfo", Variables: map[string]string{"app": "reviews"}}
+	query.FillDefaults()
+	prom.On("FetchQueryRange", `max(queue_depth{namespace="bookinfo",app="reviews"})`, mock.AnythingOfType("*prometheus.RangeQuery")).Return(prometheus.Metric{
+		Matrix:   model.Matrix{{Metric: model.Metric{}, Values: []model.SamplePair{{Timestamp: 0, Value: 3}}}},
+		Warnings: []string{"partial data"},
+	})
+
+	result, warnings, err := service.GetMetricTemplate(query, "queue")
+	require.NoError(err)
+	assert.Equal("messages", result.Unit)
+	require.Len(result.Metrics, 1)
+	assert.Equal(float64(3), result.Metrics[0].Datapoints[0].Value)
+	assert.Equal([]string{"partial data"}, warnings)
+
+	_, _, err = service.GetMetricTemplate(query, "missing")
+	assert.True(api_errors.IsNotFound(err))
+	_, _, err = service.GetMetricTemplate(query, "invalid")
+	assert.True(api_errors.IsInternalError(err))
+
+	query.Variables = map[string]string{}
+	_, _, err = service.GetMetricTemplate(query, "queue")
+	assert.True(api_errors.IsBadRequest(err))
+
+	assert.Equal([]models.MetricTemplate{{Name: "queue", Unit: "messages", Variables: []string{"app", "namespace"}}}, service.GetMetricTemplates())
+}
@@ -1,11 +1,15 @@
 package business
 
 import (
+	"fmt"
 	"math"
 	"sort"
 	"strings"
 	"sync"
 
+	prom_v1 "github.com/prometheus/client_golang/api/prometheus/v1"
+
+	"github.com/kiali/kiali/log"
 	"github.com/kiali/kiali/models"
 	"github.com/kiali/kiali/prometheus"
 )
@@ -21,6 +25,13 @@ func NewMetricsService(prom prometheus.ClientInterface) *MetricsService {
 }
 
 func (in *MetricsService) GetMetrics(q models.IstioMetricsQuery, scaler func(n string) float64) (models.MetricsMap, error) {
+	metrics, _, err := in.GetMetricsWithWarnings(q, scaler)
+	return metrics, err
+}
+
+// GetMetricsWithWarnings returns the metrics and the warnings about them: the Prometheus warnings, and the metrics
+// skipped because their queries failed. It fails only when all the metrics fail.
+func (in *MetricsService) GetMetricsWithWarnings(q models.IstioMetricsQuery, scaler func(n string) float64) (models.MetricsMap, []string, error) {
 	lb := createMetricsLabelsBuilder(&q)
 	grouping := strings.Join(q.ByLabels, ",")
 	return in.fetchAllMetrics(q, lb, grouping, scaler)
@@ -65,7 +76,7 @@ func createMetricsLabelsBuilder(q *models.IstioMetricsQuery) *MetricsLabelsBuild
 	return lb
 }
 
-func (in *MetricsService) fetchAllMetrics(q models.IstioMetricsQuery, lb *MetricsLabelsBuilder, grouping string, scaler func(n string) float64) (models.MetricsMap, error) {
+func (in *MetricsService) fetchAllMetrics(q models.IstioMetricsQuery, lb *MetricsLabelsBuilder, grouping string, scaler func(n string) float64) (models.MetricsMap, []string, error) {
 	labels := lb.Build()
 	labelsError := lb.BuildForErrors()
 
@@ -82,9 +93,21 @@ func (in *MetricsService) fetchAllMetrics(q models.IstioMetricsQuery, lb *Metric
 		*histo = h
 	}
 
+	fetchExemplars := func(query string, exemplars *[]prom_v1.ExemplarQueryResult) {
+		defer wg.Done()
+		e, err := in.prom.QueryExemplars(query, q.Start, q.End)
+		if err != nil {
+			// Exemplars are optional (e.g. exemplar storage may be disabled in Prometheus): log and keep the metrics
+			log.Debugf("Could not fetch exemplars for query %s: %v", query, err)
+			return
+		}
+		*exemplars = e
+	}
+
 	type resultHolder struct {
 		metric     prometheus.Metric
 		histo      prometheus.Histogram
+		exemplars  []prom_v1.ExemplarQueryResult
 		definition istioMetric
 	}
 	maxResults := len(istioMetrics)
@@ -110,18 +133,35 @@ func (in *MetricsService) fetchAllMetrics(q models.IstioMetricsQuery, lb *Metric
 			results = append(results, &result)
 			if istioMetric.isHisto {
 				go fetchHisto(istioMetric.istioName, &result.histo)
+				if q.IncludeExemplars {
+					wg.Add(1)
+					go fetchExemplars(istioMetric.istioName+"_bucket"+labels, &result.exemplars)
+				}
 			} else {
 				labelsToUse := istioMetric.labelsToUse(labels, labelsError)
 				go fetchRate(istioMetric.istioName, &result.metric, labelsToUse)
+				if q.IncludeExemplars {
+					selectors := make([]string, len(labelsToUse))
+					for i, lbl := range labelsToUse {
+						selectors[i] = istioMetric.istioName + lbl
+					}
+					wg.Add(1)
+					go fetchExemplars(strings.Join(selectors, " or "), &result.exemplars)
+				}
 			}
 		}
 	}
 	wg.Wait()
 
-	// Return results as two maps per reporter
+	// Return results as two maps per reporter. A failed metric is skipped with a warning, so that a slow or failing
+	// query doesn't fail the other metrics.
 	metrics := make(models.MetricsMap)
+	warnings := prometheus.QueryWarnings{}
+	var firstErr error
+	fetched := 0
 	for _, result := range results {
 		if result != nil {
+			fetched++
 			conversionParams := models.ConversionParams{Scale: 1.0}
 			if scaler != nil {
 				scale := scaler(result.definition.kialiName)
@@ -132,20 +172,40 @@ func (in *MetricsService) fetchAllMetrics(q models.IstioMetricsQuery, lb *Metric
 			var converted []models.Metric
 			var err error
 			if result.definition.isHisto {
-				converted, err = models.ConvertHistogram(result.definition.kialiName, result.histo, conversionParams)
-				if err != nil {
-					return nil, err
+				for _, stat := range result.histo {
+					warnings.Add(stat.Warnings...)
 				}
+				converted, err = models.ConvertHistogram(result.definition.kialiName, result.histo, conversionParams)
 			} else {
+				warnings.Add(result.metric.Warnings...)
 				converted, err = models.ConvertMetric(result.definition.kialiName, result.metric, conversionParams)
-				if err != nil {
-					return nil, err
+			}
+			if err != nil {
+				log.Debugf("Metric %s skipped: %v", result.definition.kialiName, err)
+				warnings.Add(fmt.Sprintf("metric %s skipped: %v", result.definition.kialiName, err))
+				if firstErr == nil {
+					firstErr = err
 				}
+				continue
+			}
+			if len(result.exemplars) > 0 {
+				models.AttachExemplars(converted, result.exemplars, conversionParams.Scale)
 			}
 			metrics[result.definition.kialiName] = append(metrics[result.definition.kialiName], converted...)
 		}
 	}
-	return metrics, nil
+	if firstErr != nil && len(metrics) == 0 && fetched > 0 {
+		return nil, nil, firstErr
+	}
+	return metrics, warnings.List(), nil
+}
+
+// Warnings returns the Prometheus warnings of the queries run by the service, when its client collects them
+func (in *MetricsService) Warnings() []string {
+	if client, ok := in.prom.(interface{ Warnings() []string }); ok {
+		return client.Warnings()
+	}
+	return nil
 }
 
 // GetStats computes metrics stats, currently response times, for a set of queries
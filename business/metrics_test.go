@@ -1,12 +1,16 @@
 package business
 
 import (
+	"context"
 	"fmt"
+	"strings"
 	"testing"
 	"time"
 
+	prom_v1 "github.com/prometheus/client_golang/api/prometheus/v1"
 	"github.com/prometheus/common/model"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/kiali/kiali/config"
 	"github.com/kiali/kiali/models"
@@ -468,3 +472,45 @@ func createSample(value float64) *model.Sample {
 		Metric:    model.Metric{},
 	}
 }
+
+// partialRangeAPI times out the range queries of the request sizes, and returns a warning with the others
+type partialRangeAPI struct {
+	prom_v1.API
+}
+
+func (a partialRangeAPI) QueryRange(ctx context.Context, query string, r prom_v1.Range) (model.Value, prom_v1.Warnings, error) {
+	if strings.Contains(query, "istio_request_bytes") {
+		return nil, nil, context.DeadlineExceeded
+	}
+	return model.Matrix{}, prom_v1.Warnings{"partial data"}, nil
+}
+
+func TestGetMetricsSkipsFailedMetrics(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+	config.Set(config.NewConfig())
+	client, err := prometheus.NewClient()
+	require.NoError(err)
+	client.Inject(partialRangeAPI{})
+	srv := NewMetricsService(client)
+
+	q := models.IstioMetricsQuery{
+		Namespace: "bookinfo",
+		App:       "productpage",
+	}
+	q.FillDefaults()
+	q.Filters = []string{"request_count", "request_size"}
+	metrics, warnings, err := srv.GetMetricsWithWarnings(q, nil)
+
+	require.NoError(err)
+	assert.Contains(metrics, "request_count")
+	assert.NotContains(metrics, "request_size")
+	require.Len(warnings, 2)
+	assert.Equal("partial data", warnings[0])
+	assert.Contains(warnings[1], "metric request_size skipped: ")
+
+	// It's an error when all the metrics fail
+	q.Filters = []string{"request_size"}
+	_, _, err = srv.GetMetricsWithWarnings(q, nil)
+	assert.Error(err)
+}
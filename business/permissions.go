@@ -0,0 +1,80 @@
+package business
+
+import (
+	"context"
+
+	"golang.org/x/sync/singleflight"
+	api_errors "k8s.io/apimachinery/pkg/api/errors"
+
+	"github.com/kiali/kiali/kubernetes"
+)
+
+// permissionsFlightGroup merges the concurrent reviews of the permissions of a user in the same namespace
+var permissionsFlightGroup singleflight.Group
+
+// getNamespacePermissions returns the permissions of the user of the client in the namespace. They are read with a
+// single SelfSubjectRulesReview, and cached per user in the Kiali cache.
+func getNamespacePermissions(ctx context.Context, k8s kubernetes.ClientInterface, cluster, namespace string) (*kubernetes.NamespacePermissions, error) {
+	tokenHash := kubernetes.GetTokenHash(k8s.GetAuthInfo())
+	if kialiCache != nil {
+		if permissions := kialiCache.GetPermissions(tokenHash, cluster, namespace); permissions != nil {
+			return permissions, nil
+		}
+	}
+
+	result, err, _ := permissionsFlightGroup.Do(tokenHash+"/"+cluster+"/"+namespace, func() (interface{}, error) {
+		review, err := k8s.GetSelfSubjectRulesReview(ctx, namespace)
+		if err != nil {
+			return nil, err
+		}
+		permissions := kubernetes.NewNamespacePermissions(review)
+		if kialiCache != nil {
+			kialiCache.SetPermissions(tokenHash, cluster, namespace, permissions)
+		}
+		return permissions, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result.(*kubernetes.NamespacePermissions), nil
+}
+
+// canI returns which verbs the user of the client is allowed on the resource type in the namespace. When the
+// rules of the user are incomplete, the verbs that they don't grant are checked with SelfSubjectAccessReviews.
+func canI(ctx context.Context, k8s kubernetes.ClientInterface, cluster, namespace, api, resourceType string, verbs []string) (map[string]bool, error) {
+	permissions, err := getNamespacePermissions(ctx, k8s, cluster, namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	allowed := make(map[string]bool, len(verbs))
+	unknown := []string{}
+	for _, verb := range verbs {
+		allowed[verb] = permissions.Allows(api, resourceType, verb)
+		if !allowed[verb] && permissions.Incomplete {
+			unknown = append(unknown, verb)
+		}
+	}
+	if len(unknown) == 0 {
+		return allowed, nil
+	}
+
+	ssars, err := k8s.GetSelfSubjectAccessReview(ctx, namespace, api, resourceType, unknown)
+	if err != nil {
+		return nil, err
+	}
+	for _, ssar := range ssars {
+		if ssar.Spec.ResourceAttributes != nil {
+			allowed[ssar.Spec.ResourceAttributes.Verb] = ssar.Status.Allowed
+		}
+	}
+	return allowed, nil
+}
+
+// refreshPermissionsOnForbidden drops the cached permissions of the user of the client when the cluster denied
+// an operation: they may be stale.
+func refreshPermissionsOnForbidden(k8s kubernetes.ClientInterface, err error) {
+	if kialiCache != nil && api_errors.IsForbidden(err) {
+		kialiCache.RefreshTokenPermissions(kubernetes.GetTokenHash(k8s.GetAuthInfo()))
+	}
+}
@@ -0,0 +1,105 @@
+package business
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	auth_v1 "k8s.io/api/authorization/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/kiali/kiali/config"
+	"github.com/kiali/kiali/kubernetes"
+	"github.com/kiali/kiali/kubernetes/kubetest"
+)
+
+// countingAccessReview counts the reviews of the permissions of a user, allowed to patch the VirtualServices
+type countingAccessReview struct {
+	*kubetest.FakeK8sClient
+	mutex       sync.Mutex
+	incomplete  bool
+	rulesCalls  int
+	accessCalls int
+}
+
+func (c *countingAccessReview) GetSelfSubjectRulesReview(ctx context.Context, namespace string) (*auth_v1.SelfSubjectRulesReview, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.rulesCalls++
+	return &auth_v1.SelfSubjectRulesReview{
+		Status: auth_v1.SubjectRulesReviewStatus{
+			ResourceRules: []auth_v1.ResourceRule{
+				{Verbs: []string{"patch"}, APIGroups: []string{"networking.istio.io"}, Resources: []string{"virtualservices"}},
+			},
+			Incomplete: c.incomplete,
+		},
+	}, nil
+}
+
+func (c *countingAccessReview) GetSelfSubjectAccessReview(ctx context.Context, namespace, api, resourceType string, verbs []string) ([]*auth_v1.SelfSubjectAccessReview, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.accessCalls++
+	reviews := []*auth_v1.SelfSubjectAccessReview{}
+	for _, verb := range verbs {
+		reviews = append(reviews, &auth_v1.SelfSubjectAccessReview{
+			Spec:   auth_v1.SelfSubjectAccessReviewSpec{ResourceAttributes: &auth_v1.ResourceAttributes{Verb: verb}},
+			Status: auth_v1.SubjectAccessReviewStatus{Allowed: verb == "create"},
+		})
+	}
+	return reviews, nil
+}
+
+func setupPermissions(t *testing.T, token string) *countingAccessReview {
+	conf := config.NewConfig()
+	config.Set(conf)
+	k8s := kubetest.NewFakeK8sClient()
+	k8s.Token = token
+	SetupBusinessLayer(t, k8s, *conf)
+	return &countingAccessReview{FakeK8sClient: k8s}
+}
+
+func TestPermissionsAreReviewedOncePerNamespace(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+	client := setupPermissions(t, "jdoe")
+
+	for i := 0; i < 3; i++ {
+		allowed, err := canI(context.TODO(), client, "east", "bookinfo", "networking.istio.io", "virtualservices", []string{"create", "patch", "delete"})
+		require.NoError(err)
+		assert.Equal(map[string]bool{"create": false, "patch": true, "delete": false}, allowed)
+	}
+	assert.Equal(1, client.rulesCalls)
+	assert.Equal(0, client.accessCalls)
+
+	// The permissions are cached per namespace, and per user
+	_, err := canI(context.TODO(), client, "east", "travels", "networking.istio.io", "virtualservices", []string{"patch"})
+	require.NoError(err)
+	assert.Equal(2, client.rulesCalls)
+
+	other := &countingAccessReview{FakeK8sClient: kubetest.NewFakeK8sClient()}
+	other.Token = "alice"
+	_, err = canI(context.TODO(), other, "east", "bookinfo", "networking.istio.io", "virtualservices", []string{"patch"})
+	require.NoError(err)
+	assert.Equal(1, other.rulesCalls)
+
+	// The permissions denied by the cluster are reviewed again
+	refreshPermissionsOnForbidden(client, errors.NewForbidden(schema.GroupResource{Resource: "virtualservices"}, "reviews", nil))
+	_, err = canI(context.TODO(), client, "east", "bookinfo", "networking.istio.io", "virtualservices", []string{"patch"})
+	require.NoError(err)
+	assert.Equal(3, client.rulesCalls)
+}
+
+func TestIncompletePermissionsAreCheckedWithAccessReviews(t *testing.T) {
+	assert := assert.New(t)
+	client := setupPermissions(t, "jdoe")
+	client.incomplete = true
+
+	allowed, err := canI(context.TODO(), client, kubernetes.HomeClusterName, "bookinfo", "networking.istio.io", "virtualservices", []string{"create", "patch", "delete"})
+	assert.NoError(err)
+	assert.Equal(map[string]bool{"create": true, "patch": true, "delete": false}, allowed)
+	assert.Equal(1, client.accessCalls)
+}
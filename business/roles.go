@@ -0,0 +1,129 @@
+package business
+
+import (
+	"context"
+
+	"github.com/kiali/kiali/config"
+	"github.com/kiali/kiali/kubernetes"
+)
+
+type (
+	roleContextKey         struct{}
+	apiTokenRoleContextKey struct{}
+)
+
+// Capabilities are the changes a user can do through Kiali, depending on the Kiali role of the user and on the
+// view-only mode. The Kubernetes RBAC is still checked on top of them.
+// swagger:model
+type Capabilities struct {
+	// The Kiali role of the user. Empty when the roles are disabled.
+	Role string `json:"role,omitempty"`
+	// Managing the sessions and reading the audit log. The permission to delete the secrets of the Kiali
+	// namespace is also required.
+	AdministerKiali bool `json:"administerKiali"`
+	// Creating, updating and deleting any Istio config
+	EditIstioConfig bool `json:"editIstioConfig"`
+	// Updating the workloads, the services and the namespaces, e.g. their labels or their sidecar injection
+	EditResources bool `json:"editResources"`
+	// Creating, updating and deleting the traffic routing config: VirtualServices, DestinationRules and HTTPRoutes
+	EditTrafficRouting bool `json:"editTrafficRouting"`
+	// Changing the log level of the proxies
+	SetProxyLogLevel bool `json:"setProxyLogLevel"`
+}
+
+// trafficRoutingTypes are the Istio config types that can be changed to shift the traffic
+var trafficRoutingTypes = map[string]bool{
+	kubernetes.DestinationRules: true,
+	kubernetes.K8sHTTPRoutes:    true,
+	kubernetes.VirtualServices:  true,
+}
+
+// roleRanks orders the roles from the least to the most privileged
+var roleRanks = map[string]int{
+	config.RoleViewer:   1,
+	config.RoleOperator: 2,
+	config.RoleAdmin:    3,
+}
+
+// CanEditIstioConfig returns true if the Istio config of this type can be created, updated and deleted
+func (c Capabilities) CanEditIstioConfig(objectType string) bool {
+	return c.EditIstioConfig || (c.EditTrafficRouting && trafficRoutingTypes[objectType])
+}
+
+// RoleOfGroups returns the most privileged role mapped to the groups, or the default role. Empty is returned
+// when the roles are disabled.
+func RoleOfGroups(groups []string) string {
+	conf := config.Get().Auth.Roles
+	if !conf.Enabled {
+		return ""
+	}
+	role := conf.DefaultRole
+	for _, group := range groups {
+		if groupRole, ok := conf.GroupRoles[group]; ok && roleRanks[groupRole] > roleRanks[role] {
+			role = groupRole
+		}
+	}
+	return role
+}
+
+// CapabilitiesOf returns the capabilities of a role. Everything is allowed when the roles are disabled. Nothing
+// but the administration of Kiali is allowed in view-only mode.
+func CapabilitiesOf(role string) Capabilities {
+	effectiveRole := role
+	if !config.Get().Auth.Roles.Enabled {
+		effectiveRole = config.RoleAdmin
+	}
+	return capabilitiesOfRole(role, effectiveRole)
+}
+
+// capabilitiesOfRole returns the capabilities of the effective role, reported as the given role
+func capabilitiesOfRole(role, effectiveRole string) Capabilities {
+	capabilities := Capabilities{Role: role, AdministerKiali: effectiveRole == config.RoleAdmin}
+	if config.Get().Deployment.ViewOnlyMode {
+		return capabilities
+	}
+	switch effectiveRole {
+	case config.RoleAdmin:
+		capabilities.EditIstioConfig = true
+		capabilities.EditResources = true
+		capabilities.EditTrafficRouting = true
+		capabilities.SetProxyLogLevel = true
+	case config.RoleOperator:
+		capabilities.EditTrafficRouting = true
+	}
+	return capabilities
+}
+
+// RoleAllows returns true if a user of the role can delegate the requested role, i.e. if the requested role is
+// not more privileged. Any role is allowed when the roles are disabled.
+func RoleAllows(role, requested string) bool {
+	return !config.Get().Auth.Roles.Enabled || roleRanks[role] >= roleRanks[requested]
+}
+
+// SetRoleContext returns a copy of the context holding the Kiali role of the user
+func SetRoleContext(ctx context.Context, role string) context.Context {
+	return context.WithValue(ctx, roleContextKey{}, role)
+}
+
+// SetAPITokenRoleContext returns a copy of the context holding the role of the API token authenticating the
+// request. The capabilities of this role apply even when the roles are disabled.
+func SetAPITokenRoleContext(ctx context.Context, role string) context.Context {
+	return context.WithValue(ctx, apiTokenRoleContextKey{}, role)
+}
+
+// GetCapabilitiesContext returns the capabilities of the user whose role is held by the context. The default role
+// is assumed if the context holds no role. An API token gets the lower of its role and the current role of its
+// owner, so that it loses the permissions its owner lost since its creation.
+func GetCapabilitiesContext(ctx context.Context) Capabilities {
+	role, ok := ctx.Value(roleContextKey{}).(string)
+	if !ok {
+		role = RoleOfGroups(nil)
+	}
+	if tokenRole, ok := ctx.Value(apiTokenRoleContextKey{}).(string); ok {
+		if config.Get().Auth.Roles.Enabled && roleRanks[role] < roleRanks[tokenRole] {
+			tokenRole = role
+		}
+		return capabilitiesOfRole(tokenRole, tokenRole)
+	}
+	return CapabilitiesOf(role)
+}
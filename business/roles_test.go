@@ -0,0 +1,120 @@
+package business
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/kiali/kiali/config"
+	"github.com/kiali/kiali/kubernetes"
+)
+
+func setupRoles(t *testing.T, viewOnly bool) {
+	cfg := config.NewConfig()
+	cfg.Auth.Roles.Enabled = true
+	cfg.Auth.Roles.GroupRoles = map[string]string{
+		"sre":      config.RoleAdmin,
+		"releases": config.RoleOperator,
+		"devs":     config.RoleViewer,
+	}
+	cfg.Deployment.ViewOnlyMode = viewOnly
+	config.Set(cfg)
+	t.Cleanup(func() {
+		config.Set(config.NewConfig())
+	})
+}
+
+func TestRoleOfGroupsIsTheMostPrivileged(t *testing.T) {
+	assert := assert.New(t)
+	setupRoles(t, false)
+
+	assert.Equal(config.RoleViewer, RoleOfGroups(nil))
+	assert.Equal(config.RoleViewer, RoleOfGroups([]string{"devs", "unknown"}))
+	assert.Equal(config.RoleOperator, RoleOfGroups([]string{"devs", "releases"}))
+	assert.Equal(config.RoleAdmin, RoleOfGroups([]string{"sre", "releases", "devs"}))
+
+	config.Set(config.NewConfig())
+	assert.Equal("", RoleOfGroups([]string{"sre"}))
+}
+
+func TestCapabilitiesOfRoles(t *testing.T) {
+	assert := assert.New(t)
+	setupRoles(t, false)
+
+	viewer := CapabilitiesOf(config.RoleViewer)
+	assert.Equal(Capabilities{Role: config.RoleViewer}, viewer)
+	assert.False(viewer.CanEditIstioConfig(kubernetes.VirtualServices))
+
+	operator := CapabilitiesOf(config.RoleOperator)
+	assert.True(operator.CanEditIstioConfig(kubernetes.VirtualServices))
+	assert.True(operator.CanEditIstioConfig(kubernetes.DestinationRules))
+	assert.True(operator.CanEditIstioConfig(kubernetes.K8sHTTPRoutes))
+	assert.False(operator.CanEditIstioConfig(kubernetes.AuthorizationPolicies))
+	assert.False(operator.EditResources)
+	assert.False(operator.SetProxyLogLevel)
+	assert.False(operator.AdministerKiali)
+
+	admin := CapabilitiesOf(config.RoleAdmin)
+	assert.True(admin.CanEditIstioConfig(kubernetes.AuthorizationPolicies))
+	assert.True(admin.EditResources)
+	assert.True(admin.SetProxyLogLevel)
+	assert.True(admin.AdministerKiali)
+}
+
+func TestCapabilitiesInViewOnlyMode(t *testing.T) {
+	setupRoles(t, true)
+
+	assert.Equal(t, Capabilities{Role: config.RoleAdmin, AdministerKiali: true}, CapabilitiesOf(config.RoleAdmin))
+}
+
+func TestCapabilitiesWithoutRoles(t *testing.T) {
+	config.Set(config.NewConfig())
+
+	capabilities := GetCapabilitiesContext(context.TODO())
+	assert.Equal(t, Capabilities{AdministerKiali: true, EditIstioConfig: true, EditResources: true, EditTrafficRouting: true, SetProxyLogLevel: true}, capabilities)
+}
+
+func TestCapabilitiesContext(t *testing.T) {
+	assert := assert.New(t)
+	setupRoles(t, false)
+
+	// The default role is assumed without a role in the context
+	assert.Equal(config.RoleViewer, GetCapabilitiesContext(context.TODO()).Role)
+
+	ctx := SetRoleContext(context.TODO(), config.RoleOperator)
+	assert.Equal(CapabilitiesOf(config.RoleOperator), GetCapabilitiesContext(ctx))
+
+	// The role restricts the permissions, whatever the RBAC allows
+	canCreate, canPatch, canDelete := getPermissions(ctx, nil, "east", "bookinfo", kubernetes.AuthorizationPolicies)
+	assert.False(canCreate || canPatch || canDelete)
+}
+
+func TestAPITokenRoleAppliesWithoutRoles(t *testing.T) {
+	assert := assert.New(t)
+	config.Set(config.NewConfig())
+
+	ctx := SetRoleContext(context.TODO(), RoleOfGroups(nil))
+	assert.True(GetCapabilitiesContext(ctx).EditIstioConfig)
+
+	ctx = SetAPITokenRoleContext(ctx, config.RoleViewer)
+	capabilities := GetCapabilitiesContext(ctx)
+	assert.Equal(config.RoleViewer, capabilities.Role)
+	assert.False(capabilities.EditTrafficRouting)
+	assert.False(capabilities.AdministerKiali)
+
+	// The token can't outrank its owner
+	setupRoles(t, false)
+	ctx = SetRoleContext(context.TODO(), config.RoleOperator)
+	assert.Equal(config.RoleViewer, GetCapabilitiesContext(SetAPITokenRoleContext(ctx, config.RoleViewer)).Role)
+	capabilities = GetCapabilitiesContext(SetAPITokenRoleContext(ctx, config.RoleAdmin))
+	assert.Equal(config.RoleOperator, capabilities.Role)
+	assert.False(capabilities.EditIstioConfig)
+	assert.True(capabilities.EditTrafficRouting)
+
+	config.Set(config.NewConfig())
+	assert.True(RoleAllows("", config.RoleAdmin))
+	setupRoles(t, false)
+	assert.True(RoleAllows(config.RoleOperator, config.RoleViewer))
+	assert.False(RoleAllows(config.RoleOperator, config.RoleAdmin))
+}
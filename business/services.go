@@ -620,7 +620,7 @@ func (in *SvcService) GetServiceDetails(ctx context.Context, cluster, namespace,
 
 	var vsCreate, vsUpdate, vsDelete bool
 	wg.Add(1)
-	go func() {
+	go func(ctx context.Context) {
 		defer wg.Done()
 		/*
 			We can safely assume that permissions for VirtualServices will be similar as DestinationRules.
@@ -633,8 +633,8 @@ func (in *SvcService) GetServiceDetails(ctx context.Context, cluster, namespace,
 			errChan <- fmt.Errorf("client not found for cluster: %s", cluster)
 			return
 		}
-		vsCreate, vsUpdate, vsDelete = getPermissions(context.TODO(), userClient, cluster, namespace, kubernetes.VirtualServices)
-	}()
+		vsCreate, vsUpdate, vsDelete = getPermissions(ctx, userClient, cluster, namespace, kubernetes.VirtualServices)
+	}(ctx)
 
 	wg.Wait()
 	if len(errChan) != 0 {
@@ -0,0 +1,131 @@
+package business
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/common/model"
+
+	"github.com/kiali/kiali/config"
+	"github.com/kiali/kiali/jaeger"
+	"github.com/kiali/kiali/models"
+	"github.com/kiali/kiali/observability"
+	"github.com/kiali/kiali/util"
+)
+
+// spanStatsTracesLimit is the maximum number of sampled traces used to compute the span stats of a target
+const spanStatsTracesLimit = 200
+
+// GetOperationStats computes response times, error ratio and throughput per operation for the target of a stats
+// query, from sampled traces. Inbound queries use the server spans of the target, outbound queries its client spans.
+// The peer target, if any, is ignored.
+func (in *JaegerService) GetOperationStats(ctx context.Context, q models.MetricsStatsQuery) ([]models.OperationStats, error) {
+	var end observability.EndFunc
+	ctx, end = observability.StartSpan(ctx, "GetOperationStats",
+		observability.Attribute("package", "business"),
+		observability.Attribute("namespace", q.Target.Namespace),
+		observability.Attribute("kind", q.Target.Kind),
+		observability.Attribute("name", q.Target.Name),
+	)
+	defer end()
+
+	interval, err := model.ParseDuration(q.Interval)
+	if err != nil || interval <= 0 {
+		return nil, fmt.Errorf("invalid interval [%s]", q.Interval)
+	}
+	query := models.TracingQuery{
+		Start: q.QueryTime.Add(-time.Duration(interval)),
+		End:   q.QueryTime,
+		Limit: spanStatsTracesLimit,
+		Tags:  map[string]string{},
+	}
+	for key, value := range config.Get().ExternalServices.Tracing.QueryScope {
+		query.Tags[key] = value
+	}
+
+	var spans []jaeger.JaegerSpan
+	switch q.Target.Kind {
+	case "app":
+		spans, err = in.GetAppSpans(q.Target.Namespace, q.Target.Name, query)
+	case "workload":
+		spans, err = in.GetWorkloadSpans(ctx, q.Target.Namespace, q.Target.Name, query)
+	case "service":
+		spans, err = in.GetServiceSpans(ctx, q.Target.Namespace, q.Target.Name, query)
+	default:
+		return nil, fmt.Errorf("unsupported target kind [%s]", q.Target.Kind)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return computeOperationStats(spans, q, time.Duration(interval)), nil
+}
+
+func computeOperationStats(spans []jaeger.JaegerSpan, q models.MetricsStatsQuery, interval time.Duration) []models.OperationStats {
+	kinds := map[string]bool{"server": true, "consumer": true}
+	if q.Direction == "outbound" {
+		kinds = map[string]bool{"client": true, "producer": true}
+	}
+
+	type operationSamples struct {
+		durations []float64
+		errors    int
+	}
+	samples := make(map[string]*operationSamples)
+	for _, span := range spans {
+		kind, isErr := "", false
+		for _, tag := range span.Tags {
+			switch tag.Key {
+			case "span.kind":
+				kind, _ = tag.Value.(string)
+			case "error":
+				isErr = tag.Value == true || tag.Value == "true"
+			}
+		}
+		if !kinds[kind] {
+			continue
+		}
+		s, ok := samples[span.OperationName]
+		if !ok {
+			s = &operationSamples{}
+			samples[span.OperationName] = s
+		}
+		s.durations = append(s.durations, float64(span.Duration)/1000)
+		if isErr {
+			s.errors++
+		}
+	}
+
+	stats := make([]models.OperationStats, 0, len(samples))
+	for operation, s := range samples {
+		sorted := util.SortedCopy(s.durations)
+		responseTimes := []models.Stat{}
+		if q.Avg {
+			responseTimes = append(responseTimes, models.Stat{Name: "avg", Value: util.Average(sorted)})
+		}
+		for _, quantile := range q.Quantiles {
+			if value, err := strconv.ParseFloat(quantile, 64); err == nil {
+				responseTimes = append(responseTimes, models.Stat{Name: quantile, Value: util.Percentile(sorted, value*100)})
+			}
+		}
+		sort.Slice(responseTimes, func(i, j int) bool {
+			return responseTimes[i].Name < responseTimes[j].Name
+		})
+		stats = append(stats, models.OperationStats{
+			Operation:     operation,
+			SampleSize:    len(sorted),
+			ResponseTimes: responseTimes,
+			ErrorRatio:    float64(s.errors) / float64(len(sorted)),
+			Throughput:    float64(len(sorted)) / interval.Seconds(),
+		})
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].SampleSize != stats[j].SampleSize {
+			return stats[i].SampleSize > stats[j].SampleSize
+		}
+		return stats[i].Operation < stats[j].Operation
+	})
+	return stats
+}
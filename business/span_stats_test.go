@@ -0,0 +1,51 @@
+package business
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kiali/kiali/jaeger"
+	jaegerModels "github.com/kiali/kiali/jaeger/model/json"
+	"github.com/kiali/kiali/models"
+)
+
+func statsSpan(operation, kind string, durationMs uint64, isErr bool) jaeger.JaegerSpan {
+	tags := []jaegerModels.KeyValue{{Key: "span.kind", Type: jaegerModels.StringType, Value: kind}}
+	if isErr {
+		tags = append(tags, jaegerModels.KeyValue{Key: "error", Type: jaegerModels.BoolType, Value: true})
+	}
+	return jaeger.JaegerSpan{Span: jaegerModels.Span{OperationName: operation, Duration: durationMs * 1000, Tags: tags}}
+}
+
+func TestComputeOperationStats(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	spans := []jaeger.JaegerSpan{
+		statsSpan("GET /reviews", "server", 10, false),
+		statsSpan("GET /reviews", "server", 20, false),
+		statsSpan("GET /reviews", "server", 30, true),
+		statsSpan("GET /reviews", "server", 40, false),
+		statsSpan("POST /reviews", "server", 100, true),
+		statsSpan("GET /ratings", "client", 5, false),
+	}
+	q := models.MetricsStatsQuery{Direction: "inbound", Avg: true, Quantiles: []string{"0.5", "0.99"}}
+
+	stats := computeOperationStats(spans, q, 10*time.Second)
+	require.Len(stats, 2)
+	assert.Equal("GET /reviews", stats[0].Operation)
+	assert.Equal(4, stats[0].SampleSize)
+	assert.Equal([]models.Stat{{Name: "0.5", Value: 20}, {Name: "0.99", Value: 40}, {Name: "avg", Value: 25}}, stats[0].ResponseTimes)
+	assert.Equal(0.25, stats[0].ErrorRatio)
+	assert.Equal(0.4, stats[0].Throughput)
+	assert.Equal("POST /reviews", stats[1].Operation)
+	assert.Equal(1.0, stats[1].ErrorRatio)
+
+	q.Direction = "outbound"
+	stats = computeOperationStats(spans, q, 10*time.Second)
+	require.Len(stats, 1)
+	assert.Equal("GET /ratings", stats[0].Operation)
+}
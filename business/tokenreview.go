@@ -1,8 +1,11 @@
 package business
 
 import (
+	"context"
+
 	"k8s.io/client-go/tools/clientcmd/api"
 
+	"github.com/kiali/kiali/config"
 	"github.com/kiali/kiali/kubernetes"
 )
 
@@ -29,3 +32,14 @@ func NewTokenReview(k8s kubernetes.ClientInterface) TokenReviewService {
 func (in *TokenReviewService) GetTokenSubject(authInfo *api.AuthInfo) (string, error) {
 	return in.k8s.GetTokenSubject(authInfo)
 }
+
+// IsKialiAdmin checks that the user can delete the secrets of the Kiali namespace. Such a user already controls
+// the Kiali credentials, and is allowed to administer Kiali, e.g. to revoke the sessions of the other users.
+func (in *TokenReviewService) IsKialiAdmin(ctx context.Context) (bool, error) {
+	conf := config.Get()
+	allowed, err := canI(ctx, in.k8s, conf.KubernetesConfig.ClusterName, conf.Deployment.Namespace, "", "secrets", []string{"delete"})
+	if err != nil {
+		return false, err
+	}
+	return allowed["delete"], nil
+}
@@ -0,0 +1,325 @@
+package business
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+
+	jaegerModels "github.com/kiali/kiali/jaeger/model/json"
+	"github.com/kiali/kiali/models"
+	"github.com/kiali/kiali/observability"
+)
+
+// CompareTraces compares two traces from their IDs. Returns nil when one of the traces is not found.
+func (in *JaegerService) CompareTraces(baselineID, candidateID string) (*models.TraceComparison, error) {
+	baseline, err := in.GetJaegerTraceDetail(baselineID)
+	if err != nil || baseline == nil {
+		return nil, err
+	}
+	candidate, err := in.GetJaegerTraceDetail(candidateID)
+	if err != nil || candidate == nil {
+		return nil, err
+	}
+	comparison := CompareTraceSets([]jaegerModels.Trace{baseline.Data}, []jaegerModels.Trace{candidate.Data})
+	return &comparison, nil
+}
+
+// CompareServiceTraces compares the traces of a service for two time windows, e.g. before and after a deployment
+func (in *JaegerService) CompareServiceTraces(ctx context.Context, ns, service string, baselineQuery, candidateQuery models.TracingQuery) (*models.TraceComparison, error) {
+	var end observability.EndFunc
+	ctx, end = observability.StartSpan(ctx, "CompareServiceTraces",
+		observability.Attribute("package", "business"),
+		observability.Attribute("cluster", candidateQuery.Cluster),
+		observability.Attribute("namespace", ns),
+		observability.Attribute("service", service),
+	)
+	defer end()
+
+	baseline, err := in.GetServiceTraces(ctx, ns, service, baselineQuery)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching the baseline traces: %w", err)
+	}
+	candidate, err := in.GetServiceTraces(ctx, ns, service, candidateQuery)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching the candidate traces: %w", err)
+	}
+	comparison := CompareTraceSets(baseline.Data, candidate.Data)
+	return &comparison, nil
+}
+
+// CompareTraceSets computes the latency breakdown of both sets of traces and aligns their operations
+func CompareTraceSets(baseline, candidate []jaegerModels.Trace) models.TraceComparison {
+	comparison := models.TraceComparison{
+		Baseline:          breakdownTraceSet(baseline),
+		Candidate:         breakdownTraceSet(candidate),
+		Operations:        []models.OperationDelta{},
+		NewOperations:     []models.SpanOperation{},
+		MissingOperations: []models.SpanOperation{},
+	}
+
+	baselineOps := make(map[models.SpanOperation]models.OperationBreakdown, len(comparison.Baseline.Operations))
+	for _, op := range comparison.Baseline.Operations {
+		baselineOps[op.SpanOperation] = op
+	}
+	candidateOps := make(map[models.SpanOperation]bool, len(comparison.Candidate.Operations))
+	for _, c := range comparison.Candidate.Operations {
+		candidateOps[c.SpanOperation] = true
+		b, ok := baselineOps[c.SpanOperation]
+		if !ok {
+			comparison.NewOperations = append(comparison.NewOperations, c.SpanOperation)
+			continue
+		}
+		baselineCount := float64(b.Spans) / float64(len(baseline))
+		candidateCount := float64(c.Spans) / float64(len(candidate))
+		comparison.Operations = append(comparison.Operations, models.OperationDelta{
+			SpanOperation:     c.SpanOperation,
+			BaselineCount:     baselineCount,
+			CandidateCount:    candidateCount,
+			CountDelta:        candidateCount - baselineCount,
+			BaselineDuration:  b.Duration,
+			CandidateDuration: c.Duration,
+			DurationDelta:     c.Duration - b.Duration,
+			BaselineSelfTime:  b.SelfTime,
+			CandidateSelfTime: c.SelfTime,
+			SelfTimeDelta:     c.SelfTime - b.SelfTime,
+		})
+	}
+	for _, b := range comparison.Baseline.Operations {
+		if !candidateOps[b.SpanOperation] {
+			comparison.MissingOperations = append(comparison.MissingOperations, b.SpanOperation)
+		}
+	}
+	sort.SliceStable(comparison.Operations, func(i, j int) bool {
+		return math.Abs(comparison.Operations[i].DurationDelta) > math.Abs(comparison.Operations[j].DurationDelta)
+	})
+	return comparison
+}
+
+// spanTree indexes the spans of a trace by parent
+type spanTree struct {
+	trace    *jaegerModels.Trace
+	children map[jaegerModels.SpanID][]*jaegerModels.Span
+	roots    []*jaegerModels.Span
+}
+
+func newSpanTree(trace *jaegerModels.Trace) spanTree {
+	tree := spanTree{trace: trace, children: make(map[jaegerModels.SpanID][]*jaegerModels.Span)}
+	ids := make(map[jaegerModels.SpanID]bool, len(trace.Spans))
+	for i := range trace.Spans {
+		ids[trace.Spans[i].SpanID] = true
+	}
+	for i := range trace.Spans {
+		span := &trace.Spans[i]
+		// A span referencing itself is malformed, it is handled as a root
+		if parent := SpanParentID(span); parent != "" && parent != span.SpanID && ids[parent] {
+			tree.children[parent] = append(tree.children[parent], span)
+		} else {
+			tree.roots = append(tree.roots, span)
+		}
+	}
+	return tree
+}
+
+func (t spanTree) operation(span *jaegerModels.Span) models.SpanOperation {
+	service := ""
+	if process, ok := t.trace.Processes[span.ProcessID]; ok {
+		service = process.ServiceName
+	} else if span.Process != nil {
+		service = span.Process.ServiceName
+	}
+	return models.SpanOperation{Service: service, Operation: span.OperationName}
+}
+
+// selfTime returns the part of the span duration (microseconds) not covered by any of its children
+func (t spanTree) selfTime(span *jaegerModels.Span) uint64 {
+	start, end := span.StartTime, span.StartTime+span.Duration
+	children := t.children[span.SpanID]
+	if len(children) == 0 {
+		return span.Duration
+	}
+	intervals := make([][2]uint64, 0, len(children))
+	for _, child := range children {
+		childStart, childEnd := child.StartTime, child.StartTime+child.Duration
+		if childStart < start {
+			childStart = start
+		}
+		if childEnd > end {
+			childEnd = end
+		}
+		if childEnd > childStart {
+			intervals = append(intervals, [2]uint64{childStart, childEnd})
+		}
+	}
+	sort.Slice(intervals, func(i, j int) bool { return intervals[i][0] < intervals[j][0] })
+
+	covered := uint64(0)
+	cursor := start
+	for _, interval := range intervals {
+		if interval[1] <= cursor {
+			continue
+		}
+		if interval[0] > cursor {
+			cursor = interval[0]
+		}
+		covered += interval[1] - cursor
+		cursor = interval[1]
+	}
+	return span.Duration - covered
+}
+
+// criticalPath returns the segments of the span and its descendants that determine the span end, in chronological
+// order. Walking back from the span end, the last finishing child is on the critical path, then the child that
+// finishes before it starts, and so on. The time not covered by these children is spent in the span itself.
+// The spans already on the path are skipped, as malformed traces (e.g. with duplicated span IDs) may have cycles.
+func (t spanTree) criticalPath(span *jaegerModels.Span, end uint64, visited map[jaegerModels.SpanID]bool) []*pathSegment {
+	visited[span.SpanID] = true
+	spanEnd := span.StartTime + span.Duration
+	if end > spanEnd {
+		end = spanEnd
+	}
+	children := append([]*jaegerModels.Span{}, t.children[span.SpanID]...)
+	sort.Slice(children, func(i, j int) bool {
+		return children[i].StartTime+children[i].Duration > children[j].StartTime+children[j].Duration
+	})
+
+	// segments are collected backwards
+	var reversed []*pathSegment
+	for _, child := range children {
+		if end <= span.StartTime {
+			break
+		}
+		if child.StartTime >= end || visited[child.SpanID] {
+			continue
+		}
+		childEnd := child.StartTime + child.Duration
+		if childEnd > end {
+			childEnd = end
+		}
+		if childEnd < end {
+			reversed = append(reversed, &pathSegment{span: span, start: childEnd, end: end})
+		}
+		childPath := t.criticalPath(child, childEnd, visited)
+		for i := len(childPath) - 1; i >= 0; i-- {
+			reversed = append(reversed, childPath[i])
+		}
+		end = child.StartTime
+	}
+	if end > span.StartTime {
+		reversed = append(reversed, &pathSegment{span: span, start: span.StartTime, end: end})
+	}
+
+	path := make([]*pathSegment, 0, len(reversed))
+	for i := len(reversed) - 1; i >= 0; i-- {
+		path = append(path, reversed[i])
+	}
+	return path
+}
+
+type pathSegment struct {
+	span       *jaegerModels.Span
+	start, end uint64
+}
+
+type operationStats struct {
+	spans    int
+	traces   map[jaegerModels.TraceID]bool
+	duration uint64
+	selfTime uint64
+}
+
+func breakdownTraceSet(traces []jaegerModels.Trace) models.TraceSetBreakdown {
+	breakdown := models.TraceSetBreakdown{
+		Traces:     []models.TraceBreakdown{},
+		Operations: []models.OperationBreakdown{},
+	}
+	stats := make(map[models.SpanOperation]*operationStats)
+	var order []models.SpanOperation
+
+	for i := range traces {
+		trace := &traces[i]
+		tree := newSpanTree(trace)
+		for j := range trace.Spans {
+			span := &trace.Spans[j]
+			op := tree.operation(span)
+			s, ok := stats[op]
+			if !ok {
+				s = &operationStats{traces: make(map[jaegerModels.TraceID]bool)}
+				stats[op] = s
+				order = append(order, op)
+			}
+			s.spans++
+			s.traces[trace.TraceID] = true
+			s.duration += span.Duration
+			s.selfTime += tree.selfTime(span)
+		}
+		breakdown.Traces = append(breakdown.Traces, traceBreakdown(tree))
+	}
+
+	for _, op := range order {
+		s := stats[op]
+		duration := microsToMillis(s.duration) / float64(s.spans)
+		selfTime := microsToMillis(s.selfTime) / float64(s.spans)
+		breakdown.Operations = append(breakdown.Operations, models.OperationBreakdown{
+			SpanOperation: op,
+			Spans:         s.spans,
+			Traces:        len(s.traces),
+			Duration:      duration,
+			SelfTime:      selfTime,
+			ChildTime:     duration - selfTime,
+		})
+	}
+	return breakdown
+}
+
+// traceBreakdown returns the duration and the critical path of a trace, starting from its longest root span
+func traceBreakdown(tree spanTree) models.TraceBreakdown {
+	breakdown := models.TraceBreakdown{
+		TraceID:      string(tree.trace.TraceID),
+		CriticalPath: []models.CriticalPathSegment{},
+	}
+	if len(tree.roots) == 0 {
+		return breakdown
+	}
+
+	traceStart, traceEnd := tree.roots[0].StartTime, uint64(0)
+	var root *jaegerModels.Span
+	for _, span := range tree.trace.Spans {
+		if span.StartTime < traceStart {
+			traceStart = span.StartTime
+		}
+		if span.StartTime+span.Duration > traceEnd {
+			traceEnd = span.StartTime + span.Duration
+		}
+	}
+	for _, span := range tree.roots {
+		if root == nil || span.Duration > root.Duration {
+			root = span
+		}
+	}
+	breakdown.Duration = microsToMillis(traceEnd - traceStart)
+
+	for _, segment := range tree.criticalPath(root, root.StartTime+root.Duration, map[jaegerModels.SpanID]bool{}) {
+		breakdown.CriticalPath = append(breakdown.CriticalPath, models.CriticalPathSegment{
+			SpanOperation: tree.operation(segment.span),
+			SpanID:        string(segment.span.SpanID),
+			Start:         microsToMillis(segment.start - traceStart),
+			Duration:      microsToMillis(segment.end - segment.start),
+		})
+	}
+	return breakdown
+}
+
+// SpanParentID returns the ID of the parent span of the span, or an empty ID for a root span
+func SpanParentID(span *jaegerModels.Span) jaegerModels.SpanID {
+	for _, ref := range span.References {
+		if ref.RefType == jaegerModels.ChildOf {
+			return ref.SpanID
+		}
+	}
+	return span.ParentSpanID
+}
+
+func microsToMillis(micros uint64) float64 {
+	return float64(micros) / 1000
+}
@@ -0,0 +1,120 @@
+package business
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	jaegerModels "github.com/kiali/kiali/jaeger/model/json"
+	"github.com/kiali/kiali/models"
+)
+
+func comparedSpan(id, parent string, process jaegerModels.ProcessID, operation string, start, duration uint64) jaegerModels.Span {
+	span := jaegerModels.Span{SpanID: jaegerModels.SpanID(id), ProcessID: process, OperationName: operation, StartTime: start, Duration: duration}
+	if parent != "" {
+		span.References = []jaegerModels.Reference{{RefType: jaegerModels.ChildOf, SpanID: jaegerModels.SpanID(parent)}}
+	}
+	return span
+}
+
+var comparedProcesses = map[jaegerModels.ProcessID]jaegerModels.Process{
+	"p1": {ServiceName: "productpage"},
+	"p2": {ServiceName: "reviews"},
+	"p3": {ServiceName: "ratings"},
+	"p4": {ServiceName: "details"},
+}
+
+func TestCompareTraceSets(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	// productpage [0-100ms] -> details [10-30ms], reviews [40-90ms] -> ratings [50-60ms]
+	baseline := jaegerModels.Trace{
+		TraceID:   "a",
+		Processes: comparedProcesses,
+		Spans: []jaegerModels.Span{
+			comparedSpan("1", "", "p1", "GET /productpage", 0, 100000),
+			comparedSpan("2", "1", "p4", "GET /details", 10000, 20000),
+			comparedSpan("3", "1", "p2", "GET /reviews", 40000, 50000),
+			comparedSpan("4", "3", "p3", "GET /ratings", 50000, 10000),
+		},
+	}
+	// productpage [0-150ms] -> details [10-30ms], reviews [20-140ms] (no ratings call, slower)
+	candidate := jaegerModels.Trace{
+		TraceID:   "b",
+		Processes: comparedProcesses,
+		Spans: []jaegerModels.Span{
+			comparedSpan("1", "", "p1", "GET /productpage", 0, 150000),
+			comparedSpan("2", "1", "p4", "GET /details", 10000, 20000),
+			comparedSpan("3", "1", "p2", "GET /reviews", 20000, 120000),
+			comparedSpan("5", "3", "p2", "cache lookup", 30000, 5000),
+		},
+	}
+
+	comparison := CompareTraceSets([]jaegerModels.Trace{baseline}, []jaegerModels.Trace{candidate})
+
+	require.Len(comparison.Baseline.Operations, 4)
+	productpage := comparison.Baseline.Operations[0]
+	assert.Equal(models.SpanOperation{Service: "productpage", Operation: "GET /productpage"}, productpage.SpanOperation)
+	assert.Equal(100.0, productpage.Duration)
+	assert.Equal(30.0, productpage.SelfTime)
+	assert.Equal(70.0, productpage.ChildTime)
+
+	assert.Equal([]models.SpanOperation{{Service: "reviews", Operation: "cache lookup"}}, comparison.NewOperations)
+	assert.Equal([]models.SpanOperation{{Service: "ratings", Operation: "GET /ratings"}}, comparison.MissingOperations)
+
+	require.Len(comparison.Operations, 3)
+	reviews := comparison.Operations[0]
+	assert.Equal("reviews", reviews.Service)
+	assert.Equal(70.0, reviews.DurationDelta)
+	assert.Equal(40.0, reviews.BaselineSelfTime)
+	assert.Equal(115.0, reviews.CandidateSelfTime)
+	assert.Equal(50.0, comparison.Operations[1].DurationDelta)
+	assert.Equal(0.0, comparison.Operations[2].DurationDelta)
+	assert.Equal(0.0, comparison.Operations[2].CountDelta)
+
+	require.Len(comparison.Baseline.Traces, 1)
+	trace := comparison.Baseline.Traces[0]
+	assert.Equal("a", trace.TraceID)
+	assert.Equal(100.0, trace.Duration)
+	// productpage [0-10], details [10-30], productpage [30-40], reviews [40-50], ratings [50-60], reviews [60-90], productpage [90-100]
+	path := []string{}
+	total := 0.0
+	for _, segment := range trace.CriticalPath {
+		path = append(path, segment.Service)
+		total += segment.Duration
+	}
+	assert.Equal([]string{"productpage", "details", "productpage", "reviews", "ratings", "reviews", "productpage"}, path)
+	assert.Equal(100.0, total)
+	assert.Equal(50.0, trace.CriticalPath[4].Start)
+}
+
+func TestCompareTraceSetsWithCycles(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	// The span 2 is its own parent, and the second span 1 closes a cycle of parents 1 -> 3 -> 1, it is left out
+	// of the critical path
+	trace := jaegerModels.Trace{
+		TraceID:   "a",
+		Processes: comparedProcesses,
+		Spans: []jaegerModels.Span{
+			comparedSpan("1", "", "p1", "GET /productpage", 0, 100000),
+			comparedSpan("2", "2", "p4", "GET /details", 10000, 20000),
+			comparedSpan("3", "1", "p2", "GET /reviews", 40000, 50000),
+			comparedSpan("1", "3", "p3", "GET /ratings", 50000, 10000),
+		},
+	}
+
+	breakdown := CompareTraceSets([]jaegerModels.Trace{trace}, []jaegerModels.Trace{trace}).Baseline
+
+	require.Len(breakdown.Operations, 4)
+	assert.Equal(20.0, breakdown.Operations[1].SelfTime)
+	require.Len(breakdown.Traces, 1)
+	path := []string{}
+	for _, segment := range breakdown.Traces[0].CriticalPath {
+		path = append(path, segment.Service)
+	}
+	assert.Equal([]string{"productpage", "reviews", "productpage"}, path)
+}
@@ -0,0 +1,152 @@
+package business
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/kiali/kiali/jaeger"
+	jaegerModels "github.com/kiali/kiali/jaeger/model/json"
+	"github.com/kiali/kiali/models"
+)
+
+// paginate sets the cursor of the next page when the tracing backend returned as many traces as the query limit:
+// the next page ends just before the oldest trace of this page.
+func paginate(r *jaeger.JaegerResponse, query models.TracingQuery) {
+	if query.Limit <= 0 || len(r.Data) < query.Limit {
+		return
+	}
+	oldest := uint64(0)
+	for _, trace := range r.Data {
+		for _, span := range trace.Spans {
+			if oldest == 0 || span.StartTime < oldest {
+				oldest = span.StartTime
+			}
+		}
+	}
+	if oldest == 0 {
+		return
+	}
+	next := int64(oldest) - 1
+	if next > query.Start.UnixMicro() {
+		r.NextEndMicros = next
+	}
+}
+
+// matchesTracingQuery checks the filters of a query that aren't handled by the tracing backend: span status, HTTP
+// status codes and service-to-service calls
+func matchesTracingQuery(trace *jaegerModels.Trace, query models.TracingQuery) bool {
+	switch query.SpanStatus {
+	case "error":
+		if !traceHasError(trace) {
+			return false
+		}
+	case "ok":
+		if traceHasError(trace) {
+			return false
+		}
+	}
+	if len(query.StatusCodes) > 0 && !traceHasStatusCode(trace, query.StatusCodes) {
+		return false
+	}
+	for _, call := range query.Calls {
+		if !traceHasCall(trace, call) {
+			return false
+		}
+	}
+	return true
+}
+
+func traceHasError(trace *jaegerModels.Trace) bool {
+	for i := range trace.Spans {
+		if spanHasError(&trace.Spans[i]) {
+			return true
+		}
+	}
+	return false
+}
+
+func traceHasStatusCode(trace *jaegerModels.Trace, codes []string) bool {
+	for i := range trace.Spans {
+		if code := spanStatusCode(&trace.Spans[i]); code != "" && matchesStatusCode(codes, code) {
+			return true
+		}
+	}
+	return false
+}
+
+// traceHasCall checks that a span of the source service has a child span of the destination service. When the call
+// has status codes, the code of the child span (or of the parent span when the child has none) must match.
+func traceHasCall(trace *jaegerModels.Trace, call models.TracingCall) bool {
+	spans := make(map[jaegerModels.SpanID]*jaegerModels.Span, len(trace.Spans))
+	for i := range trace.Spans {
+		spans[trace.Spans[i].SpanID] = &trace.Spans[i]
+	}
+	for i := range trace.Spans {
+		child := &trace.Spans[i]
+		parent, ok := spans[SpanParentID(child)]
+		if !ok {
+			continue
+		}
+		if !matchesSpanService(trace, parent, call.Source) || !matchesSpanService(trace, child, call.Destination) {
+			continue
+		}
+		if len(call.StatusCodes) == 0 {
+			return true
+		}
+		code := spanStatusCode(child)
+		if code == "" {
+			code = spanStatusCode(parent)
+		}
+		if code != "" && matchesStatusCode(call.StatusCodes, code) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesSpanService checks the service name of the span process, with or without the namespace suffix
+// (e.g. "reviews" matches "reviews.bookinfo")
+func matchesSpanService(trace *jaegerModels.Trace, span *jaegerModels.Span, service string) bool {
+	name := ""
+	if process, ok := trace.Processes[span.ProcessID]; ok {
+		name = process.ServiceName
+	} else if span.Process != nil {
+		name = span.Process.ServiceName
+	}
+	return name == service || strings.HasPrefix(name, service+".")
+}
+
+func spanHasError(span *jaegerModels.Span) bool {
+	for _, tag := range span.Tags {
+		switch tag.Key {
+		case "error":
+			if tag.Value == true || tag.Value == "true" {
+				return true
+			}
+		case "otel.status_code":
+			if tag.Value == "ERROR" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// spanStatusCode returns the HTTP status code of a span, or an empty string
+func spanStatusCode(span *jaegerModels.Span) string {
+	for _, tag := range span.Tags {
+		if tag.Key != "http.status_code" && tag.Key != "http.response.status_code" {
+			continue
+		}
+		switch v := tag.Value.(type) {
+		case string:
+			return v
+		case float64:
+			return strconv.FormatFloat(v, 'f', -1, 64)
+		default:
+			return fmt.Sprint(v)
+		}
+	}
+	return ""
+}
@@ -0,0 +1,74 @@
+package business
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/kiali/kiali/jaeger"
+	jaegerModels "github.com/kiali/kiali/jaeger/model/json"
+	"github.com/kiali/kiali/models"
+)
+
+func searchedTrace(reviewsCode float64) jaegerModels.Trace {
+	productpage := comparedSpan("1", "", "p1", "GET /productpage", 1000, 100000)
+	productpage.Tags = []jaegerModels.KeyValue{{Key: "http.status_code", Type: jaegerModels.Int64Type, Value: 200.0}}
+	reviews := comparedSpan("2", "1", "p2", "GET /reviews", 2000, 50000)
+	reviews.Tags = []jaegerModels.KeyValue{{Key: "http.status_code", Type: jaegerModels.Int64Type, Value: reviewsCode}}
+	if reviewsCode >= 500 {
+		reviews.Tags = append(reviews.Tags, jaegerModels.KeyValue{Key: "error", Type: jaegerModels.BoolType, Value: true})
+	}
+	return jaegerModels.Trace{
+		TraceID: "a",
+		Processes: map[jaegerModels.ProcessID]jaegerModels.Process{
+			"p1": {ServiceName: "productpage.bookinfo"},
+			"p2": {ServiceName: "reviews.bookinfo"},
+		},
+		Spans: []jaegerModels.Span{productpage, reviews},
+	}
+}
+
+func TestMatchesTracingQuery(t *testing.T) {
+	assert := assert.New(t)
+
+	ok := searchedTrace(200)
+	failed := searchedTrace(503)
+
+	q := models.TracingQuery{SpanStatus: "error"}
+	assert.False(matchesTracingQuery(&ok, q))
+	assert.True(matchesTracingQuery(&failed, q))
+	q.SpanStatus = "ok"
+	assert.True(matchesTracingQuery(&ok, q))
+	assert.False(matchesTracingQuery(&failed, q))
+
+	q = models.TracingQuery{StatusCodes: []string{"500-504"}}
+	assert.False(matchesTracingQuery(&ok, q))
+	assert.True(matchesTracingQuery(&failed, q))
+
+	q = models.TracingQuery{Calls: []models.TracingCall{{Source: "productpage", Destination: "reviews", StatusCodes: []string{"5xx"}}}}
+	assert.False(matchesTracingQuery(&ok, q))
+	assert.True(matchesTracingQuery(&failed, q))
+	q.Calls[0].StatusCodes = nil
+	assert.True(matchesTracingQuery(&ok, q))
+	q.Calls[0] = models.TracingCall{Source: "reviews", Destination: "productpage"}
+	assert.False(matchesTracingQuery(&ok, q))
+}
+
+func TestPaginate(t *testing.T) {
+	assert := assert.New(t)
+
+	r := &jaeger.JaegerResponse{Data: []jaegerModels.Trace{searchedTrace(200)}}
+	q := models.TracingQuery{Start: time.UnixMicro(0), Limit: 2}
+	paginate(r, q)
+	assert.Zero(r.NextEndMicros)
+
+	q.Limit = 1
+	paginate(r, q)
+	assert.Equal(int64(999), r.NextEndMicros)
+
+	q.Start = time.UnixMicro(999)
+	r.NextEndMicros = 0
+	paginate(r, q)
+	assert.Zero(r.NextEndMicros)
+}
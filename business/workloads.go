@@ -2,10 +2,12 @@ package business
 
 import (
 	"bufio"
+	"container/heap"
 	"context"
 	"encoding/json"
 	"fmt"
 	"io"
+	"math"
 	"net/http"
 	"regexp"
 	"sort"
@@ -88,13 +90,20 @@ type LogEntry struct {
 	Timestamp     string            `json:"timestamp,omitempty"`
 	TimestampUnix int64             `json:"timestampUnix,omitempty"`
 	AccessLog     *parser.AccessLog `json:"accessLog,omitempty"`
+	Pod           string            `json:"pod,omitempty"`
+	Container     string            `json:"container,omitempty"`
+	TraceID       string            `json:"traceId,omitempty"`
+	SpanID        string            `json:"spanId,omitempty"`
+	RequestID     string            `json:"requestId,omitempty"`
 }
 
 // LogOptions holds query parameter values
 type LogOptions struct {
-	Duration *time.Duration
-	IsProxy  bool // fetching logs for Istio Proxy (Envoy access log)
-	MaxLines *int
+	Containers []string // containers to read when fetching logs of several pods; all app containers if empty
+	Duration   *time.Duration
+	Filter     AccessLogFilter // only applies to Istio Proxy logs
+	IsProxy    bool            // fetching logs for Istio Proxy (Envoy access log)
+	MaxLines   *int
 	core_v1.PodLogOptions
 }
 
@@ -548,6 +557,8 @@ func parseLogLine(line string, isProxy bool, engardeParser *parser.Parser) *LogE
 	entry.Timestamp = timestamp
 	entry.TimestampUnix = parsedTimestamp.UnixMilli()
 
+	extractTraceContext(&entry)
+
 	return &entry
 }
 
@@ -2029,6 +2040,10 @@ func (in *WorkloadService) streamParsedLogs(cluster, namespace, name string, opt
 			}
 		}
 
+		if opts.IsProxy && !opts.Filter.Matches(entry.AccessLog) {
+			continue
+		}
+
 		// Send to client the processed log line
 
 		response, err := json.Marshal(entry)
@@ -2080,3 +2095,273 @@ func (in *WorkloadService) streamParsedLogs(cluster, namespace, name string, opt
 func (in *WorkloadService) StreamPodLogs(cluster, namespace, name string, opts *LogOptions, w http.ResponseWriter) error {
 	return in.streamParsedLogs(cluster, namespace, name, opts, w)
 }
+
+// containerLogStream identifies the log stream of a single container of a pod
+type containerLogStream struct {
+	namespace string
+	pod       string
+	container string
+	isProxy   bool
+}
+
+// containerLogEvent is sent by a container log reader to the log merger. A nil entry means that the stream ended.
+type containerLogEvent struct {
+	stream int
+	entry  *LogEntry
+}
+
+// logEntryHeap is a min-heap of log entries sorted by timestamp
+type logEntryHeap []*LogEntry
+
+func (h logEntryHeap) Len() int { return len(h) }
+func (h logEntryHeap) Less(i, j int) bool {
+	return h[i].TimestampUnix < h[j].TimestampUnix
+}
+func (h logEntryHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *logEntryHeap) Push(x interface{}) { *h = append(*h, x.(*LogEntry)) }
+func (h *logEntryHeap) Pop() interface{} {
+	old := *h
+	n := len(old)
+	entry := old[n-1]
+	*h = old[:n-1]
+	return entry
+}
+
+// followReorderWindow is how long the entries of followed streams are held before being sent, so entries
+// coming from different pods can still be sorted when some of the pods are not logging.
+var followReorderWindow = 2 * time.Second
+
+// buildContainerLogStreams resolves the container log streams to read from a list of pods. When no
+// containers are requested, all app containers are read, plus the proxy containers if opts.IsProxy is set.
+func buildContainerLogStreams(namespace string, pods models.Pods, opts *LogOptions) []containerLogStream {
+	requested := make(map[string]bool, len(opts.Containers))
+	for _, c := range opts.Containers {
+		requested[c] = true
+	}
+
+	streams := []containerLogStream{}
+	for _, pod := range pods {
+		for _, c := range pod.Containers {
+			if (len(requested) == 0 && (!c.IsProxy || opts.IsProxy)) || requested[c.Name] {
+				streams = append(streams, containerLogStream{namespace: namespace, pod: pod.Name, container: c.Name, isProxy: c.IsProxy || opts.IsProxy && requested[c.Name]})
+			}
+		}
+		for _, c := range pod.IstioContainers {
+			if (len(requested) == 0 && opts.IsProxy) || requested[c.Name] {
+				streams = append(streams, containerLogStream{namespace: namespace, pod: pod.Name, container: c.Name, isProxy: c.IsProxy})
+			}
+		}
+	}
+	return streams
+}
+
+// readContainerLogs reads the logs of a single container, sending every parsed entry to the events channel.
+// The end of the stream is signaled with a nil entry.
+func (in *WorkloadService) readContainerLogs(ctx context.Context, userClient kubernetes.ClientInterface, index int, stream containerLogStream, opts *LogOptions, events chan<- containerLogEvent) {
+	send := func(entry *LogEntry) bool {
+		select {
+		case events <- containerLogEvent{stream: index, entry: entry}:
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	}
+	defer send(nil)
+
+	k8sOpts := opts.PodLogOptions
+	k8sOpts.Container = stream.container
+	logsReader, err := userClient.StreamPodLogs(stream.namespace, stream.pod, &k8sOpts)
+	if err != nil {
+		log.Errorf("Error when streaming logs of container [%s] of pod [%s]: %s", stream.container, stream.pod, err.Error())
+		return
+	}
+	defer func() {
+		e := logsReader.Close()
+		if e != nil {
+			log.Errorf("Error when closing the connection streaming logs of a pod: %s", e.Error())
+		}
+	}()
+
+	// Unblock the reader when the client goes away while following logs
+	go func() {
+		<-ctx.Done()
+		logsReader.Close()
+	}()
+
+	var startTime *time.Time
+	var endTime *time.Time
+	if k8sOpts.SinceTime != nil {
+		startTime = &k8sOpts.SinceTime.Time
+	}
+
+	engardeParser := parser.New(parser.IstioProxyAccessLogsPattern)
+	bufferedReader := bufio.NewReader(logsReader)
+	line, readErr := bufferedReader.ReadString('\n')
+	for ; readErr == nil || (readErr == io.EOF && len(line) > 0); line, readErr = bufferedReader.ReadString('\n') {
+		entry := parseLogLine(line, stream.isProxy, engardeParser)
+		if entry == nil {
+			continue
+		}
+
+		if startTime == nil {
+			startTime = &entry.OriginalTime
+		}
+		if opts.Duration != nil {
+			if endTime == nil {
+				end := startTime.Add(*opts.Duration)
+				endTime = &end
+			}
+			if entry.OriginalTime.After(*endTime) {
+				return
+			}
+		}
+
+		if stream.isProxy && !opts.Filter.Matches(entry.AccessLog) {
+			continue
+		}
+
+		entry.Pod = stream.pod
+		entry.Container = stream.container
+		if !send(entry) {
+			return
+		}
+	}
+}
+
+// streamMergedLogs concurrently reads the logs of several containers of several pods, and sends them to the
+// client merged in timestamp order, in the same JSON format used by streamParsedLogs. Every entry is tagged
+// with the pod and container it belongs to. An entry is sent once every open stream has produced a later
+// entry; when following logs, entries older than followReorderWindow are also sent so that idle pods do not
+// hold the output back.
+func (in *WorkloadService) streamMergedLogs(ctx context.Context, cluster, namespace string, pods models.Pods, opts *LogOptions, w http.ResponseWriter) error {
+	userClient, ok := in.userClients[cluster]
+	if !ok {
+		return fmt.Errorf("user client for cluster [%s] not found", cluster)
+	}
+
+	streams := buildContainerLogStreams(namespace, pods, opts)
+	if len(streams) == 0 {
+		return kubernetes.NewNotFound(strings.Join(opts.Containers, ","), "Kiali", "Container")
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	events := make(chan containerLogEvent, len(streams))
+	for i, stream := range streams {
+		go in.readContainerLogs(ctx, userClient, i, stream, opts, events)
+	}
+
+	// Same as streamParsedLogs, from now on errors can no longer be reported with a status code.
+	w.Header().Set("Content-Type", "application/json")
+	if _, err := w.Write([]byte("{\"entries\":[")); err != nil {
+		return err
+	}
+	flusher, _ := w.(http.Flusher)
+
+	pending := &logEntryHeap{}
+	latest := make([]int64, len(streams))
+	open := make([]bool, len(streams))
+	for i := range open {
+		open[i] = true
+	}
+	openStreams := len(streams)
+	linesWritten := 0
+	truncated := false
+
+	// writeUpTo sends all pending entries up to the given unix millis, returns false when the response must end.
+	writeUpTo := func(watermark int64) bool {
+		for pending.Len() > 0 && (*pending)[0].TimestampUnix <= watermark {
+			if opts.MaxLines != nil && linesWritten >= *opts.MaxLines {
+				truncated = true
+				return false
+			}
+			entry := heap.Pop(pending).(*LogEntry)
+			response, err := json.Marshal(entry)
+			if err != nil {
+				log.Errorf("Error when marshalling JSON while streaming logs: %s", err.Error())
+				return false
+			}
+			if linesWritten > 0 {
+				response = append([]byte{','}, response...)
+			}
+			if _, err = w.Write(response); err != nil {
+				log.Errorf("Error when writing a processed log entry while streaming logs: %s", err.Error())
+				return false
+			}
+			linesWritten++
+		}
+		if flusher != nil {
+			flusher.Flush()
+		}
+		return true
+	}
+
+	// watermark returns the time up to which all open streams have produced entries
+	watermark := func() int64 {
+		mark := int64(math.MaxInt64)
+		for i, isOpen := range open {
+			if isOpen && latest[i] < mark {
+				mark = latest[i]
+			}
+		}
+		if opts.Follow {
+			if reordered := time.Now().Add(-followReorderWindow).UnixMilli(); reordered > mark {
+				mark = reordered
+			}
+		}
+		return mark
+	}
+
+	var tick <-chan time.Time
+	if opts.Follow {
+		ticker := time.NewTicker(followReorderWindow / 2)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+
+	running := true
+	for running && openStreams > 0 {
+		select {
+		case event := <-events:
+			if event.entry == nil {
+				open[event.stream] = false
+				openStreams--
+			} else {
+				heap.Push(pending, event.entry)
+				if event.entry.TimestampUnix > latest[event.stream] {
+					latest[event.stream] = event.entry.TimestampUnix
+				}
+			}
+			running = writeUpTo(watermark())
+		case <-tick:
+			running = writeUpTo(watermark())
+		case <-ctx.Done():
+			running = false
+		}
+	}
+	if running {
+		writeUpTo(math.MaxInt64)
+	}
+
+	var writeErr error
+	if truncated {
+		_, writeErr = w.Write([]byte("], \"linesTruncated\": true}"))
+	} else {
+		_, writeErr = w.Write([]byte("]}"))
+	}
+	if writeErr != nil {
+		log.Errorf("Error when writing the outro of the JSON document while streaming logs: %s", writeErr.Error())
+	}
+
+	return nil
+}
+
+// StreamWorkloadLogs streams the logs of all the pods of a workload to an HTTP Response, merged in timestamp order
+func (in *WorkloadService) StreamWorkloadLogs(ctx context.Context, cluster, namespace, workload string, opts *LogOptions, w http.ResponseWriter) error {
+	wk, err := in.fetchWorkload(ctx, WorkloadCriteria{Cluster: cluster, Namespace: namespace, WorkloadName: workload})
+	if err != nil {
+		return err
+	}
+	return in.streamMergedLogs(ctx, cluster, namespace, wk.Pods, opts, w)
+}
@@ -697,6 +697,74 @@ func TestGetPodLogsProxy(t *testing.T) {
 	assert.Equal(int64(1612215275533), entry.TimestampUnix)
 }
 
+// a fake log streamer that returns a fixed string per pod and container for testing.
+type multiPodLogStreamer struct {
+	logs map[string]string // key is pod/container
+	kubernetes.ClientInterface
+}
+
+func (l *multiPodLogStreamer) StreamPodLogs(namespace, name string, opts *core_v1.PodLogOptions) (io.ReadCloser, error) {
+	return io.NopCloser(strings.NewReader(l.logs[name+"/"+opts.Container])), nil
+}
+
+func callStreamMergedLogs(svc WorkloadService, pods models.Pods, opts *LogOptions) PodLog {
+	w := httptest.NewRecorder()
+	_ = svc.streamMergedLogs(context.TODO(), svc.config.KubernetesConfig.ClusterName, "Namespace", pods, opts, w)
+
+	response := w.Result()
+	body, _ := io.ReadAll(response.Body)
+
+	var podLogs PodLog
+	_ = json.Unmarshal(body, &podLogs)
+
+	return podLogs
+}
+
+func TestStreamMergedLogs(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	k8s := &multiPodLogStreamer{
+		logs: map[string]string{
+			"details-1/details": `2018-01-02T03:34:28+00:00 INFO #1 Log Message
+2018-01-02T05:34:28+00:00 #3 Log Message`,
+			"details-2/details": `2018-01-02T04:34:28+00:00 WARN #2 Log Message
+2018-01-02T06:34:28+00:00 #4 Log error Message`,
+			"details-2/istio-proxy": `2018-01-02T04:00:00+00:00 proxy Log Message`,
+		},
+		ClientInterface: kubetest.NewFakeK8sClient(&osproject_v1.Project{ObjectMeta: v1.ObjectMeta{Name: "Namespace"}}),
+	}
+	conf := config.NewConfig()
+	SetupBusinessLayer(t, k8s, *conf)
+	svc := setupWorkloadService(k8s, conf)
+
+	pods := models.Pods{
+		{Name: "details-1", Containers: []*models.ContainerInfo{{Name: "details"}}, IstioContainers: []*models.ContainerInfo{{Name: "istio-proxy", IsProxy: true}}},
+		{Name: "details-2", Containers: []*models.ContainerInfo{{Name: "details"}}, IstioContainers: []*models.ContainerInfo{{Name: "istio-proxy", IsProxy: true}}},
+	}
+
+	podLogs := callStreamMergedLogs(svc, pods, &LogOptions{})
+	require.Equal(4, len(podLogs.Entries))
+	assert.Equal("INFO #1 Log Message", podLogs.Entries[0].Message)
+	assert.Equal("details-1", podLogs.Entries[0].Pod)
+	assert.Equal("details", podLogs.Entries[0].Container)
+	assert.Equal("WARN #2 Log Message", podLogs.Entries[1].Message)
+	assert.Equal("details-2", podLogs.Entries[1].Pod)
+	assert.Equal("#3 Log Message", podLogs.Entries[2].Message)
+	assert.Equal("details-1", podLogs.Entries[2].Pod)
+	assert.Equal("#4 Log error Message", podLogs.Entries[3].Message)
+	assert.Equal("details-2", podLogs.Entries[3].Pod)
+	assert.False(podLogs.LinesTruncated)
+
+	maxLines := 2
+	podLogs = callStreamMergedLogs(svc, pods, &LogOptions{Containers: []string{"details", "istio-proxy"}, MaxLines: &maxLines})
+	require.Equal(2, len(podLogs.Entries))
+	assert.Equal("INFO #1 Log Message", podLogs.Entries[0].Message)
+	assert.Equal("proxy Log Message", podLogs.Entries[1].Message)
+	assert.Equal("istio-proxy", podLogs.Entries[1].Container)
+	assert.True(podLogs.LinesTruncated)
+}
+
 func TestDuplicatedControllers(t *testing.T) {
 	assert := assert.New(t)
 	require := require.New(t)
@@ -45,6 +45,21 @@ const (
 	AuthTypeNone   = "none"
 )
 
+// The places where the user sessions can be kept
+const (
+	SessionStoreCookie    = "cookie"
+	SessionStoreMemory    = "memory"
+	SessionStoreSecret    = "secret"
+	SessionStoreConfigMap = "configmap"
+)
+
+// The Kiali roles, from the least to the most privileged
+const (
+	RoleViewer   = "viewer"
+	RoleOperator = "operator"
+	RoleAdmin    = "admin"
+)
+
 const (
 	IstioMultiClusterHostSuffix = "global"
 	OidcClientSecretFile        = "/kiali-secret/oidc-secret"
@@ -55,6 +70,12 @@ const (
 	DashboardsDiscoveryAuto    = "auto"
 )
 
+// The supported tracing backends
+const (
+	JaegerProvider = "jaeger"
+	TempoProvider  = "tempo"
+)
+
 // FeatureName is the enum type used for named features that can be disabled via KialiFeatureFlags.DisabledFeatures
 type FeatureName string
 
@@ -99,9 +120,34 @@ type Observability struct {
 	Tracing Tracing `yaml:"tracing,omitempty"`
 }
 
+// Constants for the audit sinks
+const (
+	AuditSinkFile    = "file"
+	AuditSinkLog     = "log"
+	AuditSinkStdout  = "stdout"
+	AuditSinkWebhook = "webhook"
+)
+
+// AuditConfig configures where the audit records of the write operations are sent, when the audit log is enabled.
+// The "log" sink writes them to the Kiali log; the "file" and "stdout" sinks write them as JSON lines; the "webhook"
+// sink posts each record as JSON to the webhook URL.
+type AuditConfig struct {
+	// File is the path of the file the records are appended to
+	File string `yaml:"file,omitempty"`
+	// RecentEntries is how many records each Kiali replica keeps in memory, to be queried with the audit API
+	RecentEntries int    `yaml:"recent_entries,omitempty"`
+	Sink          string `yaml:"sink,omitempty"`
+	// WebhookHeaders are added to the webhook requests, e.g. for authentication
+	WebhookHeaders map[string]string `yaml:"webhook_headers,omitempty"`
+	// WebhookTimeout is the timeout of the webhook requests, in seconds
+	WebhookTimeout int    `yaml:"webhook_timeout,omitempty"`
+	WebhookURL     string `yaml:"webhook_url,omitempty"`
+}
+
 // Server configuration
 type Server struct {
 	Address                    string        `yaml:",omitempty"`
+	Audit                      AuditConfig   `yaml:"audit,omitempty"`
 	AuditLog                   bool          `yaml:"audit_log,omitempty"` // When true, allows additional audit logging on Write operations
 	CORSAllowAll               bool          `yaml:"cors_allow_all,omitempty"`
 	GzipEnabled                bool          `yaml:"gzip_enabled,omitempty"`
@@ -117,8 +163,11 @@ type Server struct {
 
 // Auth provides authentication data for external services
 type Auth struct {
-	CAFile             string `yaml:"ca_file"`
+	CAFile string `yaml:"ca_file"`
+	// CertFile and KeyFile are the client certificate and its private key, presented for mutual TLS
+	CertFile           string `yaml:"cert_file,omitempty"`
 	InsecureSkipVerify bool   `yaml:"insecure_skip_verify"`
+	KeyFile            string `yaml:"key_file,omitempty"`
 	Password           string `yaml:"password"`
 	Token              string `yaml:"token"`
 	Type               string `yaml:"type"`
@@ -131,6 +180,7 @@ func (a *Auth) Obfuscate() {
 	a.Password = "xxx"
 	a.Username = "xxx"
 	a.CAFile = "xxx"
+	a.KeyFile = "xxx"
 }
 
 // ThanosProxy describes configuration of the Thanos proxy component
@@ -140,28 +190,69 @@ type ThanosProxy struct {
 	ScrapeInterval  string `yaml:"scrape_interval,omitempty"`
 }
 
+// PrometheusClusterConfig describes the Prometheus (or Thanos querier) holding the metrics of a single cluster, for
+// multi-cluster meshes without a central Prometheus. The queries are federated across the clusters' backends; the
+// main Prometheus URL is used as the home cluster backend, unless the home cluster is listed.
+type PrometheusClusterConfig struct {
+	Auth          Auth              `yaml:"auth,omitempty"`
+	CustomHeaders map[string]string `yaml:"custom_headers,omitempty"`
+	URL           string            `yaml:"url,omitempty"`
+}
+
+// PrometheusRecordingRule describes a recording rule pre-aggregating the rate of a counter, e.g.
+// sum(rate(istio_requests_total[1m])) by (reporter,source_workload,...)
+type PrometheusRecordingRule struct {
+	Labels []string `yaml:"labels"` // Labels kept by the rule aggregation
+	Metric string   `yaml:"metric"` // Counter aggregated by the rule, e.g. istio_requests_total
+	Name   string   `yaml:"name"`   // Name of the recorded metric
+	Window string   `yaml:"window"` // Rate window of the rule, e.g. 1m
+}
+
+// PrometheusRecordingRules describes the recording rules that can replace the raw rate queries of the graph and health
+type PrometheusRecordingRules struct {
+	Discovery bool                      `yaml:"discovery,omitempty"` // Discover the recording rules from the Prometheus rules API
+	Rules     []PrometheusRecordingRule `yaml:"rules,omitempty"`
+}
+
 // PrometheusConfig describes configuration of the Prometheus component
 type PrometheusConfig struct {
-	Auth            Auth              `yaml:"auth,omitempty"`
-	CacheDuration   int               `yaml:"cache_duration,omitempty"`   // Cache duration per query expressed in seconds
-	CacheEnabled    bool              `yaml:"cache_enabled,omitempty"`    // Enable cache for Prometheus queries
-	CacheExpiration int               `yaml:"cache_expiration,omitempty"` // Global cache expiration expressed in seconds
-	CustomHeaders   map[string]string `yaml:"custom_headers,omitempty"`
-	HealthCheckUrl  string            `yaml:"health_check_url,omitempty"`
-	IsCore          bool              `yaml:"is_core,omitempty"`
-	QueryScope      map[string]string `yaml:"query_scope,omitempty"`
-	ThanosProxy     ThanosProxy       `yaml:"thanos_proxy,omitempty"`
-	URL             string            `yaml:"url,omitempty"`
+	Auth                 Auth                               `yaml:"auth,omitempty"`
+	CacheDuration        int                                `yaml:"cache_duration,omitempty"`   // Cache duration per query expressed in seconds
+	CacheEnabled         bool                               `yaml:"cache_enabled,omitempty"`    // Enable cache for Prometheus queries
+	CacheExpiration      int                                `yaml:"cache_expiration,omitempty"` // Global cache expiration expressed in seconds
+	Clusters             map[string]PrometheusClusterConfig `yaml:"clusters,omitempty"`         // Prometheus per cluster name, queries are federated when set
+	CustomHeaders        map[string]string                  `yaml:"custom_headers,omitempty"`
+	HealthCheckUrl       string                             `yaml:"health_check_url,omitempty"`
+	IsCore               bool                               `yaml:"is_core,omitempty"`
+	MaxConcurrentQueries int                                `yaml:"max_concurrent_queries,omitempty"` // Max queries run at once against Prometheus, 0 for no limit
+	QueryBudget          int                                `yaml:"query_budget,omitempty"`           // Max time spent querying Prometheus per graph request, in seconds, 0 for no limit
+	QueryScope           map[string]string                  `yaml:"query_scope,omitempty"`
+	QueryTimeout         int                                `yaml:"query_timeout,omitempty"` // Max time of a single Prometheus query, in seconds, 0 for no limit
+	RecordingRules       PrometheusRecordingRules           `yaml:"recording_rules,omitempty"`
+	ThanosProxy          ThanosProxy                        `yaml:"thanos_proxy,omitempty"`
+	URL                  string                             `yaml:"url,omitempty"`
+}
+
+// MetricQueryTemplate is a PromQL expression run by the metric templates API, for charts that aren't a single
+// metric aggregation (ratios, saturation, queue depth...). Variables $namespace, $cluster, $app, $version, $service,
+// $workload and $rateInterval are substituted with the request values. Every selector of the expression must match
+// a namespace label on "$namespace", so that the query is confined to the requested namespace.
+type MetricQueryTemplate struct {
+	Description string `yaml:"description,omitempty"`
+	Name        string `yaml:"name"`
+	Query       string `yaml:"query"`
+	Unit        string `yaml:"unit,omitempty"`
 }
 
 // CustomDashboardsConfig describes configuration specific to Custom Dashboards
 type CustomDashboardsConfig struct {
-	DiscoveryEnabled       string           `yaml:"discovery_enabled,omitempty"`
-	DiscoveryAutoThreshold int              `yaml:"discovery_auto_threshold,omitempty"`
-	Enabled                bool             `yaml:"enabled,omitempty"`
-	IsCore                 bool             `yaml:"is_core,omitempty"`
-	NamespaceLabel         string           `yaml:"namespace_label,omitempty"`
-	Prometheus             PrometheusConfig `yaml:"prometheus,omitempty"`
+	DiscoveryEnabled       string                `yaml:"discovery_enabled,omitempty"`
+	DiscoveryAutoThreshold int                   `yaml:"discovery_auto_threshold,omitempty"`
+	Enabled                bool                  `yaml:"enabled,omitempty"`
+	IsCore                 bool                  `yaml:"is_core,omitempty"`
+	NamespaceLabel         string                `yaml:"namespace_label,omitempty"`
+	Prometheus             PrometheusConfig      `yaml:"prometheus,omitempty"`
+	QueryTemplates         []MetricQueryTemplate `yaml:"query_templates,omitempty"`
 }
 
 // GrafanaConfig describes configuration used for Grafana links
@@ -195,13 +286,23 @@ type TracingConfig struct {
 	InClusterURL         string            `yaml:"in_cluster_url"`
 	IsCore               bool              `yaml:"is_core,omitempty"`
 	NamespaceSelector    bool              `yaml:"namespace_selector"`
+	Provider             string            `yaml:"provider,omitempty"` // jaeger | tempo
 	QueryScope           map[string]string `yaml:"query_scope,omitempty"`
 	QueryTimeout         int               `yaml:"query_timeout,omitempty"`
+	TempoConfig          TempoConfig       `yaml:"tempo_config,omitempty"`
 	URL                  string            `yaml:"url"`
-	UseGRPC              bool              `yaml:"use_grpc"`
+	UseGRPC              bool              `yaml:"use_grpc"` // only for the jaeger provider
 	WhiteListIstioSystem []string          `yaml:"whitelist_istio_system"`
 }
 
+// TempoConfig holds the settings specific to the Grafana Tempo tracing backend
+type TempoConfig struct {
+	// Tenant sent in the X-Scope-OrgID header, for multi-tenant Tempo deployments
+	OrgID string `yaml:"org_id,omitempty"`
+	// Maximum number of traces fetched in parallel after a search
+	QueryConcurrency int `yaml:"query_concurrency,omitempty"`
+}
+
 // RegistryConfig contains configuration for connecting to an external istiod.
 // This is used when Kiali should connect to the istiod via a url instead of port forwarding.
 type RegistryConfig struct {
@@ -279,6 +380,35 @@ type AdditionalDisplayItem struct {
 	Title          string `yaml:"title"`
 }
 
+// FederationConfig configures the federation of the Kiali instances of the clusters this Kiali can't access directly.
+// The graph, health, validations and workloads of a peer cluster are read from the API of its Kiali, and merged into
+// the responses of this Kiali. The requests to a peer are authenticated with the peer auth, an API token created on
+// the peer and a client certificate trusted by the peer. They are made on behalf of the users of this Kiali that can
+// access the namespace in the home cluster: the API token should be restricted to the viewer role and to the
+// namespaces to share.
+type FederationConfig struct {
+	Enabled bool             `yaml:"enabled,omitempty"`
+	Peers   []FederationPeer `yaml:"peers,omitempty"`
+	// Timeout, in seconds, of the requests to the peers
+	Timeout int `yaml:"timeout,omitempty"`
+	// TrustedPeers are the common names of the client certificates of the peers allowed to query this Kiali,
+	// verified with the CA of the identity client_ca_file. The requests of the peers are rejected without one of
+	// these certificates.
+	TrustedPeers []string `yaml:"trusted_peers,omitempty"`
+}
+
+// FederationPeer is the Kiali instance of a peer cluster
+type FederationPeer struct {
+	Auth Auth `yaml:"auth,omitempty"`
+	// Cluster is the name of the cluster observed by the peer, as known by the control plane
+	Cluster string `yaml:"cluster"`
+	// Namespaces of the peer cluster, missing in the home cluster, that the users of this Kiali can read from the
+	// peer. The namespaces of the home cluster are read from the peer by the users who can access them locally.
+	Namespaces []string `yaml:"namespaces,omitempty"`
+	// URL of the peer, including its web root, e.g. https://kiali.east.example.com/kiali
+	URL string `yaml:"url"`
+}
+
 // KubernetesConfig holds the k8s client, caching and performance configuration
 type KubernetesConfig struct {
 	Burst int `yaml:"burst,omitempty"`
@@ -297,6 +427,10 @@ type KubernetesConfig struct {
 	// Kiali cache list of namespaces per user, this is typically short lived cache compared with the duration of the
 	// namespace cache defined by previous CacheDuration parameter
 	CacheTokenNamespaceDuration int `yaml:"cache_token_namespace_duration,omitempty"`
+	// Cache duration expressed in seconds
+	// Kiali caches the permissions of each user per namespace, read with a single SelfSubjectRulesReview. A change
+	// of the RBAC of a user may be ignored during this duration.
+	CacheTokenPermissionsDuration int `yaml:"cache_token_permissions_duration,omitempty"`
 	// ClusterName is the name of the kubernetes cluster that Kiali is running in.
 	// If empty, then it will default to 'Kubernetes'.
 	ClusterName string `yaml:"cluster_name,omitempty"`
@@ -306,6 +440,20 @@ type KubernetesConfig struct {
 	// can be skipped from Kiali workloads query if they are present in this list
 	ExcludeWorkloads []string `yaml:"excluded_workloads,omitempty"`
 	QPS              float32  `yaml:"qps,omitempty"`
+	// RemoteClusterSecrets configures the registration of the remote clusters at runtime, from labeled Secrets
+	RemoteClusterSecrets RemoteClusterSecretsConfig `yaml:"remote_cluster_secrets,omitempty"`
+}
+
+// RemoteClusterSecretsConfig configures the watch of the remote cluster secrets. Like the Istio remote secrets, each
+// data key of a labeled Secret is the name of a cluster holding the kubeconfig to access it. The clusters are added
+// and removed as the Secrets change, in addition to the secrets mounted in the Kiali pod at startup. The Kiali
+// service account must be allowed to list and watch the Secrets of the namespace.
+type RemoteClusterSecretsConfig struct {
+	Enabled bool `yaml:"enabled,omitempty"`
+	// LabelSelector selects the remote cluster secrets
+	LabelSelector string `yaml:"label_selector,omitempty"`
+	// Namespace of the remote cluster secrets. Defaults to the Istio namespace.
+	Namespace string `yaml:"namespace,omitempty"`
 }
 
 // ApiConfig contains API specific configuration.
@@ -323,9 +471,102 @@ type ApiNamespacesConfig struct {
 
 // AuthConfig provides details on how users are to authenticate
 type AuthConfig struct {
-	OpenId    OpenIdConfig    `yaml:"openid,omitempty"`
-	OpenShift OpenShiftConfig `yaml:"openshift,omitempty"`
-	Strategy  string          `yaml:"strategy,omitempty"`
+	APITokens     APITokensConfig     `yaml:"api_tokens,omitempty"`
+	Impersonation ImpersonationConfig `yaml:"impersonation,omitempty"`
+	OpenId        OpenIdConfig        `yaml:"openid,omitempty"`
+	OpenShift     OpenShiftConfig     `yaml:"openshift,omitempty"`
+	Roles         RolesConfig         `yaml:"roles,omitempty"`
+	SessionStore  SessionStoreConfig  `yaml:"session_store,omitempty"`
+	Strategy      string              `yaml:"strategy,omitempty"`
+	// Strategies lists the enabled strategies in order of preference, e.g. openid for the users and token for the
+	// scripts calling the API. When set, the first one replaces Strategy. The anonymous strategy can't be combined.
+	Strategies []string `yaml:"strategies,omitempty"`
+}
+
+// APITokensConfig configures the personal access tokens issued by Kiali to the scripts calling its API. A token is
+// created by a logged in user, and is then accepted as a bearer token in place of the session. Kiali calls the
+// cluster API with its own service account, impersonating the Kubernetes identity of the user that created the
+// token: the Kiali service account must be granted the "impersonate" verb on the users and groups. A token can be
+// restricted to some namespaces and to a Kiali role. Only a hash of the tokens is kept, in a Secret of the Kiali
+// namespace.
+type APITokensConfig struct {
+	Enabled bool `yaml:"enabled,omitempty"`
+	// MaxExpiration is the longest lifetime, in seconds, of a token. It is also the lifetime of the tokens created
+	// without expiration.
+	MaxExpiration int `yaml:"max_expiration,omitempty"`
+	// How often, in seconds, a replica reloads the tokens created by the other replicas. A token created on a
+	// replica may be rejected by the other replicas during this interval.
+	RefreshInterval int `yaml:"refresh_interval,omitempty"`
+	// SecretName is the name of the Secret holding the hashed tokens
+	SecretName string `yaml:"secret_name,omitempty"`
+}
+
+// ImpersonationConfig configures the impersonation mode of the openid and header strategies. In this mode, Kiali
+// calls the cluster API with its own service account, impersonating the user authenticated by the OpenId provider
+// or by the reverse proxy in front of Kiali: this works with clusters that can't be configured for OpenId. The Kiali
+// service account must be granted the "impersonate" verb on the users and groups. The other enabled strategies
+// keep calling the cluster API with the credentials of the users.
+type ImpersonationConfig struct {
+	Enabled bool `yaml:"enabled,omitempty"`
+	// GroupsClaim is the id_token claim holding the groups of the user (openid strategy)
+	GroupsClaim string `yaml:"groups_claim,omitempty"`
+	// GroupsHeader is the header holding the comma separated groups of the user (header strategy)
+	GroupsHeader string `yaml:"groups_header,omitempty"`
+	// GroupsPrefix is prepended to the impersonated groups
+	GroupsPrefix string `yaml:"groups_prefix,omitempty"`
+	// ProxySecret is the secret sent by the reverse proxy in the ProxySecretHeader (header strategy). The user and
+	// groups headers are only read from the requests with this secret, or with a client certificate of a trusted proxy,
+	// also when the groups map to roles without impersonation.
+	ProxySecret string `yaml:"proxy_secret,omitempty"`
+	// ProxySecretHeader is the header holding the ProxySecret
+	ProxySecretHeader string `yaml:"proxy_secret_header,omitempty"`
+	// Strategies are the strategies impersonating the users, among openid and header. By default, all the enabled
+	// ones.
+	Strategies []string `yaml:"strategies,omitempty"`
+	// TrustedProxies are the common names of the client certificates of the reverse proxies (header strategy). The
+	// certificates are verified with the CA of the identity client_ca_file.
+	TrustedProxies []string `yaml:"trusted_proxies,omitempty"`
+	// UserHeader is the header holding the name of the user authenticated by the reverse proxy (header strategy).
+	// The proxy must drop this header from the client requests.
+	UserHeader string `yaml:"user_header,omitempty"`
+	// UsernamePrefix is prepended to the impersonated user name
+	UsernamePrefix string `yaml:"username_prefix,omitempty"`
+}
+
+// RolesConfig maps the groups of the users to Kiali roles, which restrict what the users can change through Kiali
+// on top of what the Kubernetes RBAC allows them. A "viewer" can't change anything; an "operator" can only change
+// the traffic routing (VirtualServices, DestinationRules and HTTPRoutes), e.g. to shift traffic; an "admin" can
+// change everything. The groups are read from the id_token claim (openid strategy) or from the header (header
+// strategy) configured for the impersonation, even if the impersonation is disabled: the header is only read from
+// the requests of the trusted proxy, see the impersonation proxy secret and trusted proxies. The users of the other
+// strategies get the default role.
+type RolesConfig struct {
+	// DefaultRole is the role of the users in none of the mapped groups
+	DefaultRole string `yaml:"default_role,omitempty"`
+	Enabled     bool   `yaml:"enabled,omitempty"`
+	// GroupRoles maps the groups to roles. A user in several groups gets the most privileged role.
+	GroupRoles map[string]string `yaml:"group_roles,omitempty"`
+}
+
+// IsValidRole returns true if the role is one of the Kiali roles
+func IsValidRole(role string) bool {
+	return role == RoleViewer || role == RoleOperator || role == RoleAdmin
+}
+
+// SessionStoreConfig configures where the user sessions are kept. By default ("cookie") the whole session is kept,
+// encrypted, in the browser cookies. The other stores keep the session on the server side, and only an opaque session
+// ID in the cookies, which allows to list and revoke the sessions. The "memory" store is local to the Kiali replica;
+// the "secret" and "configmap" stores are shared by the replicas, and are kept in the Kiali namespace.
+type SessionStoreConfig struct {
+	// Name of the Secret or ConfigMap holding the sessions
+	Name string `yaml:"name,omitempty"`
+	// The maximum number of sessions kept in the Secret or ConfigMap. When it is reached, a login evicts the oldest
+	// session of the user, or else the oldest session.
+	MaxSessions int `yaml:"max_sessions,omitempty"`
+	// How often, in seconds, a replica reloads the sessions stored by the other replicas. A session created by a
+	// replica may be unknown to the other replicas during this interval.
+	RefreshInterval int    `yaml:"refresh_interval,omitempty"`
+	Type            string `yaml:"type,omitempty"`
 }
 
 // OpenShiftConfig contains specific configuration for authentication when on OpenShift
@@ -355,7 +596,11 @@ type OpenIdConfig struct {
 	InsecureSkipVerifyTLS   bool              `yaml:"insecure_skip_verify_tls,omitempty"`
 	IssuerUri               string            `yaml:"issuer_uri,omitempty"`
 	Scopes                  []string          `yaml:"scopes,omitempty"`
-	UsernameClaim           string            `yaml:"username_claim,omitempty"`
+	// TokenRenewalWindow is how many seconds before the expiration of the tokens a session is renewed using
+	// the refresh token issued by the OpenId server. It should be shorter than the lifetime of the tokens.
+	// Sessions aren't renewed if it's zero.
+	TokenRenewalWindow int    `yaml:"token_renewal_window,omitempty"`
+	UsernameClaim      string `yaml:"username_claim,omitempty"`
 }
 
 // DeploymentConfig provides details on how Kiali was deployed.
@@ -484,6 +729,7 @@ type Config struct {
 	CustomDashboards         dashboards.MonitoringDashboardsList `yaml:"custom_dashboards,omitempty"`
 	Deployment               DeploymentConfig                    `yaml:"deployment,omitempty"`
 	ExternalServices         ExternalServices                    `yaml:"external_services,omitempty"`
+	Federation               FederationConfig                    `yaml:"federation,omitempty"`
 	HealthConfig             HealthConfig                        `yaml:"health_config,omitempty" json:"healthConfig,omitempty"`
 	Identity                 security.Identity                   `yaml:",omitempty"`
 	InCluster                bool                                `yaml:"in_cluster,omitempty"`
@@ -515,7 +761,22 @@ func NewConfig() (c *Config) {
 			},
 		},
 		Auth: AuthConfig{
+			APITokens: APITokensConfig{
+				Enabled:         false,
+				MaxExpiration:   7776000,
+				RefreshInterval: 10,
+				SecretName:      "kiali-api-tokens",
+			},
 			Strategy: "token",
+			Impersonation: ImpersonationConfig{
+				Enabled:           false,
+				GroupsClaim:       "groups",
+				GroupsHeader:      "X-Forwarded-Groups",
+				ProxySecretHeader: "X-Kiali-Proxy-Secret",
+				Strategies:        []string{},
+				TrustedProxies:    []string{},
+				UserHeader:        "X-Forwarded-User",
+			},
 			OpenId: OpenIdConfig{
 				AdditionalRequestParams: map[string]string{},
 				AllowedDomains:          []string{},
@@ -530,6 +791,7 @@ func NewConfig() (c *Config) {
 				InsecureSkipVerifyTLS:   false,
 				IssuerUri:               "",
 				Scopes:                  []string{"openid", "profile", "email"},
+				TokenRenewalWindow:      300,
 				UsernameClaim:           "sub",
 			},
 			OpenShift: OpenShiftConfig{
@@ -537,6 +799,16 @@ func NewConfig() (c *Config) {
 				ClientIdPrefix: "kiali",
 				ServerPrefix:   "https://kubernetes.default.svc/",
 			},
+			Roles: RolesConfig{
+				DefaultRole: RoleViewer,
+				Enabled:     false,
+			},
+			SessionStore: SessionStoreConfig{
+				MaxSessions:     1000,
+				Name:            "kiali-sessions",
+				RefreshInterval: 10,
+				Type:            SessionStoreCookie,
+			},
 		},
 		CustomDashboards: dashboards.GetBuiltInMonitoringDashboards(),
 		Deployment: DeploymentConfig{
@@ -623,12 +895,16 @@ func NewConfig() (c *Config) {
 				Auth: Auth{
 					Type: AuthTypeNone,
 				},
-				Enabled:              true,
-				InClusterURL:         "http://tracing.istio-system:16685/jaeger",
-				IsCore:               false,
-				NamespaceSelector:    true,
-				QueryScope:           map[string]string{},
-				QueryTimeout:         5,
+				Enabled:           true,
+				InClusterURL:      "http://tracing.istio-system:16685/jaeger",
+				IsCore:            false,
+				NamespaceSelector: true,
+				Provider:          JaegerProvider,
+				QueryScope:        map[string]string{},
+				QueryTimeout:      5,
+				TempoConfig: TempoConfig{
+					QueryConcurrency: 10,
+				},
 				URL:                  "",
 				UseGRPC:              true,
 				WhiteListIstioSystem: []string{"jaeger-query", "istio-ingressgateway"},
@@ -710,22 +986,36 @@ func NewConfig() (c *Config) {
 				Ignore: make([]string, 0),
 			},
 		},
+		Federation: FederationConfig{
+			Peers:        []FederationPeer{},
+			Timeout:      10,
+			TrustedPeers: []string{},
+		},
 		KubernetesConfig: KubernetesConfig{
-			Burst:                       200,
-			CacheDuration:               5 * 60,
-			CacheEnabled:                true,
-			CacheIstioTypes:             []string{"AuthorizationPolicy", "DestinationRule", "EnvoyFilter", "Gateway", "PeerAuthentication", "RequestAuthentication", "ServiceEntry", "Sidecar", "VirtualService", "WorkloadEntry", "WorkloadGroup", "WasmPlugin", "Telemetry", "K8sGateway", "K8sHTTPRoute"},
-			CacheNamespaces:             []string{".*"},
-			CacheTokenNamespaceDuration: 10,
-			ClusterName:                 "",
-			ExcludeWorkloads:            []string{"CronJob", "DeploymentConfig", "Job", "ReplicationController"},
-			QPS:                         175,
+			Burst:                         200,
+			CacheDuration:                 5 * 60,
+			CacheEnabled:                  true,
+			CacheIstioTypes:               []string{"AuthorizationPolicy", "DestinationRule", "EnvoyFilter", "Gateway", "PeerAuthentication", "RequestAuthentication", "ServiceEntry", "Sidecar", "VirtualService", "WorkloadEntry", "WorkloadGroup", "WasmPlugin", "Telemetry", "K8sGateway", "K8sHTTPRoute"},
+			CacheNamespaces:               []string{".*"},
+			CacheTokenNamespaceDuration:   10,
+			CacheTokenPermissionsDuration: 60,
+			ClusterName:                   "",
+			ExcludeWorkloads:              []string{"CronJob", "DeploymentConfig", "Job", "ReplicationController"},
+			QPS:                           175,
+			RemoteClusterSecrets: RemoteClusterSecretsConfig{
+				LabelSelector: "istio/multiCluster=true",
+			},
 		},
 		LoginToken: LoginToken{
 			ExpirationSeconds: 24 * 3600,
 			SigningKey:        "kiali",
 		},
 		Server: Server{
+			Audit: AuditConfig{
+				RecentEntries:  1000,
+				Sink:           AuditSinkLog,
+				WebhookTimeout: 10,
+			},
 			AuditLog:    true,
 			GzipEnabled: true,
 			Observability: Observability{
@@ -807,6 +1097,42 @@ func (conf *Config) AllNamespacesAccessible() bool {
 	return conf.Deployment.ClusterWideAccess
 }
 
+// IsImpersonating returns true if Kiali calls the cluster API impersonating the users of the strategy, instead
+// of using their credentials. Impersonation is only supported by the openid and header strategies.
+func (conf *Config) IsImpersonating(strategy string) bool {
+	impersonation := conf.Auth.Impersonation
+	if !impersonation.Enabled || (strategy != AuthStrategyOpenId && strategy != AuthStrategyHeader) || !conf.HasAuthStrategy(strategy) {
+		return false
+	}
+	if len(impersonation.Strategies) == 0 {
+		return true
+	}
+	for _, s := range impersonation.Strategies {
+		if s == strategy {
+			return true
+		}
+	}
+	return false
+}
+
+// AuthStrategies returns the enabled authentication strategies, in order of preference
+func (conf *Config) AuthStrategies() []string {
+	if len(conf.Auth.Strategies) == 0 {
+		return []string{conf.Auth.Strategy}
+	}
+	return conf.Auth.Strategies
+}
+
+// HasAuthStrategy returns true if the authentication strategy is enabled
+func (conf *Config) HasAuthStrategy(strategy string) bool {
+	for _, s := range conf.AuthStrategies() {
+		if s == strategy {
+			return true
+		}
+	}
+	return false
+}
+
 // Get the global Config
 func Get() (conf *Config) {
 	rwMutex.RLock()
@@ -833,8 +1159,24 @@ func (conf Config) String() (str string) {
 	obf.ExternalServices.Prometheus.Auth.Obfuscate()
 	obf.ExternalServices.Tracing.Auth.Obfuscate()
 	obf.Identity.Obfuscate()
+	if len(obf.Federation.Peers) > 0 {
+		peers := make([]FederationPeer, len(obf.Federation.Peers))
+		for i, peer := range obf.Federation.Peers {
+			peer.Auth.Obfuscate()
+			peers[i] = peer
+		}
+		obf.Federation.Peers = peers
+	}
 	obf.LoginToken.Obfuscate()
 	obf.Auth.OpenId.ClientSecret = "xxx"
+	obf.Auth.Impersonation.ProxySecret = "xxx"
+	if len(obf.Server.Audit.WebhookHeaders) > 0 {
+		headers := make(map[string]string, len(obf.Server.Audit.WebhookHeaders))
+		for name := range obf.Server.Audit.WebhookHeaders {
+			headers[name] = "xxx"
+		}
+		obf.Server.Audit.WebhookHeaders = headers
+	}
 	str, err := Marshal(&obf)
 	if err != nil {
 		str = fmt.Sprintf("Failed to marshal config to string. err=%v", err)
@@ -877,6 +1219,11 @@ func Unmarshal(yamlString string) (conf *Config, err error) {
 
 	conf.prepareDashboards()
 
+	// The preferred strategy is the main one
+	if len(conf.Auth.Strategies) > 0 {
+		conf.Auth.Strategy = conf.Auth.Strategies[0]
+	}
+
 	// Some config settings (such as sensitive settings like passwords) are overrideable
 	// via secrets mounted on the file system rather than storing them directly in the config map itself.
 	// The names of the files in /kiali-override-secrets denote which credentials they are.
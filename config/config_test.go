@@ -124,6 +124,34 @@ func TestMarshalUnmarshalStaticContentRootDirectory(t *testing.T) {
 	}
 }
 
+func TestUnmarshalAuthStrategies(t *testing.T) {
+	conf, err := Unmarshal("auth:\n  strategies:\n  - openid\n  - token\n")
+	assert.NoError(t, err)
+	assert.Equal(t, AuthStrategyOpenId, conf.Auth.Strategy)
+	assert.Equal(t, []string{AuthStrategyOpenId, AuthStrategyToken}, conf.AuthStrategies())
+	assert.True(t, conf.HasAuthStrategy(AuthStrategyToken))
+	assert.False(t, conf.HasAuthStrategy(AuthStrategyHeader))
+
+	conf, err = Unmarshal("auth:\n  strategy: header\n")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{AuthStrategyHeader}, conf.AuthStrategies())
+}
+
+func TestImpersonationPerStrategy(t *testing.T) {
+	conf, err := Unmarshal("auth:\n  strategies:\n  - openid\n  - header\n  - token\n  impersonation:\n    enabled: true\n")
+	assert.NoError(t, err)
+	assert.True(t, conf.IsImpersonating(AuthStrategyOpenId))
+	assert.True(t, conf.IsImpersonating(AuthStrategyHeader))
+	assert.False(t, conf.IsImpersonating(AuthStrategyToken))
+
+	conf.Auth.Impersonation.Strategies = []string{AuthStrategyOpenId}
+	assert.True(t, conf.IsImpersonating(AuthStrategyOpenId))
+	assert.False(t, conf.IsImpersonating(AuthStrategyHeader))
+
+	conf.Auth.Impersonation.Enabled = false
+	assert.False(t, conf.IsImpersonating(AuthStrategyOpenId))
+}
+
 func TestMarshalUnmarshalApiConfig(t *testing.T) {
 	testConf := Config{
 		API: ApiConfig{
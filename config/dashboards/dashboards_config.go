@@ -1,6 +1,7 @@
 package dashboards
 
 import (
+	"encoding/json"
 	"fmt"
 	"strings"
 
@@ -12,6 +13,9 @@ import (
 const (
 	// DashboardTemplateAnnotation is the key name of the namespace annotation for dashboard templates
 	DashboardTemplateAnnotation string = "dashboards.kiali.io/templates"
+	// DashboardConfigMapLabel is the label of the ConfigMaps holding dashboards: every data entry is the yaml of
+	// a dashboard, or of a list of dashboards
+	DashboardConfigMapLabel string = "dashboards.kiali.io/dashboard"
 	// Raw constant for DataType
 	Raw = "raw"
 	// Rate constant for DataType
@@ -157,6 +161,70 @@ func GetWorkloadMonitoringDashboards(namespace string, workload string, annotati
 	return MonitoringDashboardsList(empty)
 }
 
+// ParseMonitoringDashboards parses the yaml of a dashboard, or of a list of dashboards, e.g. from a ConfigMap entry
+func ParseMonitoringDashboards(yamlString string) (MonitoringDashboardsList, error) {
+	if list, err := unmarshal(yamlString); err == nil {
+		return list, nil
+	}
+	var dashboard MonitoringDashboard
+	if err := yaml.Unmarshal([]byte(yamlString), &dashboard); err != nil {
+		return nil, fmt.Errorf("failed to parse monitoring dashboard yaml data. error=%v", err)
+	}
+	return MonitoringDashboardsList{dashboard}, nil
+}
+
+// FromResourceSpec returns the dashboard described by the spec of a MonitoringDashboard resource. The dashboard
+// is named after the resource, unless the spec sets a name.
+func FromResourceSpec(name string, spec map[string]interface{}) (*MonitoringDashboard, error) {
+	// The spec fields are the yaml fields of the dashboard, and json is yaml
+	raw, err := json.Marshal(spec)
+	if err != nil {
+		return nil, err
+	}
+	var dashboard MonitoringDashboard
+	if err := yaml.Unmarshal(raw, &dashboard); err != nil {
+		return nil, fmt.Errorf("failed to parse MonitoringDashboard %s. error=%v", name, err)
+	}
+	if dashboard.Name == "" {
+		dashboard.Name = name
+	}
+	return &dashboard, nil
+}
+
+// ToResourceSpec returns the spec of the MonitoringDashboard resource describing the dashboard, which is the
+// counterpart of FromResourceSpec
+func ToResourceSpec(dashboard MonitoringDashboard) (map[string]interface{}, error) {
+	raw, err := yaml.Marshal(dashboard)
+	if err != nil {
+		return nil, err
+	}
+	var spec map[interface{}]interface{}
+	if err := yaml.Unmarshal(raw, &spec); err != nil {
+		return nil, err
+	}
+	return jsonCompatible(spec).(map[string]interface{}), nil
+}
+
+// jsonCompatible converts the yaml maps, which can have any key, to maps with string keys
+func jsonCompatible(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[interface{}]interface{}:
+		out := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			out[fmt.Sprint(key)] = jsonCompatible(item)
+		}
+		return out
+	case []interface{}:
+		out := make([]interface{}, len(v))
+		for i, item := range v {
+			out[i] = jsonCompatible(item)
+		}
+		return out
+	default:
+		return value
+	}
+}
+
 // OrganizeByName returns a map with the key being the names of the dashboards; values are the dashboards themselves
 func (in *MonitoringDashboardsList) OrganizeByName() map[string]MonitoringDashboard {
 	out := make(map[string]MonitoringDashboard, len(*in))
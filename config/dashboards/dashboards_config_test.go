@@ -97,3 +97,29 @@ func TestDeepCopy(t *testing.T) {
 	assert.Equal(t, len(list[0].Items), len((*dup)[0].Items))
 	assert.Equal(t, list[0].Items[0].Chart.Name, (*dup)[0].Items[0].Chart.Name)
 }
+
+func TestParseMonitoringDashboards(t *testing.T) {
+	list, err := ParseMonitoringDashboards("- name: a\n  title: A\n- name: b\n  title: B\n")
+	assert.NoError(t, err)
+	assert.Len(t, list, 2)
+
+	list, err = ParseMonitoringDashboards("name: queue\ntitle: Queue\n")
+	assert.NoError(t, err)
+	assert.Len(t, list, 1)
+	assert.Equal(t, "queue", list[0].Name)
+
+	_, err = ParseMonitoringDashboards("name: [")
+	assert.Error(t, err)
+}
+
+func TestFromResourceSpec(t *testing.T) {
+	dashboard, err := FromResourceSpec("jvm", map[string]interface{}{"title": "JVM", "discoverOn": "jvm_info"})
+	assert.NoError(t, err)
+	assert.Equal(t, "jvm", dashboard.Name)
+	assert.Equal(t, "JVM", dashboard.Title)
+	assert.Equal(t, "jvm_info", dashboard.DiscoverOn)
+
+	dashboard, err = FromResourceSpec("jvm", map[string]interface{}{"name": "custom"})
+	assert.NoError(t, err)
+	assert.Equal(t, "custom", dashboard.Name)
+}
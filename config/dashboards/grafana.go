@@ -0,0 +1,581 @@
+package dashboards
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	// Width of the Grafana grid, which is 12 in Kiali (the chart spans)
+	grafanaGridWidth   = 24
+	grafanaPanelHeight = 8
+	// The rate interval computed by Grafana for its Prometheus datasources
+	grafanaRateInterval = "$__rate_interval"
+)
+
+// GrafanaDashboard is the subset of the Grafana dashboard JSON model used for the conversions
+type GrafanaDashboard struct {
+	UID           string            `json:"uid,omitempty"`
+	Title         string            `json:"title"`
+	Tags          []string          `json:"tags,omitempty"`
+	SchemaVersion int               `json:"schemaVersion,omitempty"`
+	Templating    GrafanaTemplating `json:"templating"`
+	Panels        []GrafanaPanel    `json:"panels"`
+	Links         []GrafanaLink     `json:"links,omitempty"`
+}
+
+type GrafanaTemplating struct {
+	List []GrafanaVariable `json:"list"`
+}
+
+type GrafanaVariable struct {
+	Name       string      `json:"name"`
+	Label      string      `json:"label,omitempty"`
+	Type       string      `json:"type"`
+	Query      interface{} `json:"query,omitempty"`
+	Datasource interface{} `json:"datasource,omitempty"`
+	IncludeAll bool        `json:"includeAll,omitempty"`
+	AllValue   string      `json:"allValue,omitempty"`
+	Refresh    int         `json:"refresh,omitempty"`
+}
+
+type GrafanaPanel struct {
+	ID          int                `json:"id"`
+	Type        string             `json:"type"`
+	Title       string             `json:"title"`
+	GridPos     GrafanaGridPos     `json:"gridPos"`
+	Datasource  interface{}        `json:"datasource,omitempty"`
+	Targets     []GrafanaTarget    `json:"targets,omitempty"`
+	FieldConfig GrafanaFieldConfig `json:"fieldConfig"`
+	// Yaxes is only set by the legacy graph panels
+	Yaxes []GrafanaYAxis `json:"yaxes,omitempty"`
+	// Collapsed row panels hold their panels
+	Collapsed bool           `json:"collapsed,omitempty"`
+	Panels    []GrafanaPanel `json:"panels,omitempty"`
+}
+
+type GrafanaGridPos struct {
+	H int `json:"h"`
+	W int `json:"w"`
+	X int `json:"x"`
+	Y int `json:"y"`
+}
+
+type GrafanaTarget struct {
+	Expr         string `json:"expr"`
+	LegendFormat string `json:"legendFormat,omitempty"`
+	RefID        string `json:"refId,omitempty"`
+}
+
+type GrafanaFieldConfig struct {
+	Defaults GrafanaFieldDefaults `json:"defaults"`
+}
+
+type GrafanaFieldDefaults struct {
+	Unit   string                 `json:"unit,omitempty"`
+	Min    *float64               `json:"min,omitempty"`
+	Max    *float64               `json:"max,omitempty"`
+	Custom map[string]interface{} `json:"custom,omitempty"`
+}
+
+type GrafanaYAxis struct {
+	Format string `json:"format,omitempty"`
+}
+
+type GrafanaLink struct {
+	Title       string   `json:"title"`
+	Type        string   `json:"type"`
+	URL         string   `json:"url,omitempty"`
+	Tags        []string `json:"tags,omitempty"`
+	IncludeVars bool     `json:"includeVars,omitempty"`
+}
+
+// GrafanaOptions describes the labels that Kiali uses to filter the dashboard metrics: the namespace label and the
+// labels of the workload (e.g. app and version). They become variables of the exported dashboards, and they are
+// dropped from the imported queries as Kiali sets them itself.
+type GrafanaOptions struct {
+	NamespaceLabel string
+	FilterLabels   []string
+	// Scope is the query scope of the Prometheus configuration, added to the exported queries
+	Scope map[string]string
+}
+
+// Units that are named differently in Kiali and in Grafana; the other ones are kept as is
+var kialiToGrafanaUnits = map[string]string{"bitrate": "bps", "bytes": "bytes", "rps": "reqps", "seconds": "s"}
+
+type grafanaUnit struct {
+	unit  string
+	scale float64
+}
+
+var grafanaToKialiUnits = map[string]grafanaUnit{
+	"bps": {"bitrate", 0}, "bytes": {"bytes", 0}, "decbytes": {"bytes", 0}, "reqps": {"rps", 0},
+	"s": {"seconds", 0}, "ms": {"seconds", 0.001}, "µs": {"seconds", 0.000001},
+	"none": {"", 0}, "short": {"", 0},
+}
+
+// ToGrafana converts the dashboard to a Grafana dashboard. The dashboard references must be resolved.
+func ToGrafana(dashboard MonitoringDashboard, opts GrafanaOptions) GrafanaDashboard {
+	out := GrafanaDashboard{
+		UID:           grafanaUID(dashboard.Name),
+		Title:         dashboard.Title,
+		Tags:          []string{"kiali"},
+		SchemaVersion: 36,
+		Panels:        []GrafanaPanel{},
+	}
+	if dashboard.Runtime != "" {
+		out.Tags = append(out.Tags, dashboard.Runtime)
+	}
+
+	// The variables are listed from the metric used to discover the dashboard, or from its first metric
+	discoverOn := dashboard.DiscoverOn
+	for i := 0; discoverOn == "" && i < len(dashboard.Items); i++ {
+		for _, metric := range dashboard.Items[i].Chart.GetMetrics() {
+			if metric.MetricName != "" {
+				discoverOn = seriesName(metric.MetricName, dashboard.Items[i].Chart.DataType)
+				break
+			}
+		}
+	}
+	out.Templating.List = []GrafanaVariable{{Name: "datasource", Label: "Datasource", Type: "datasource", Query: "prometheus"}}
+	out.Templating.List = append(out.Templating.List, GrafanaVariable{
+		Name:       "namespace",
+		Label:      "Namespace",
+		Type:       "query",
+		Query:      fmt.Sprintf("label_values(%s, %s)", discoverOn, opts.NamespaceLabel),
+		Datasource: "${datasource}",
+		Refresh:    2,
+	})
+	for _, label := range opts.FilterLabels {
+		out.Templating.List = append(out.Templating.List, GrafanaVariable{
+			Name:       label,
+			Type:       "query",
+			Query:      fmt.Sprintf(`label_values(%s{%s="$namespace"}, %s)`, discoverOn, opts.NamespaceLabel, label),
+			Datasource: "${datasource}",
+			IncludeAll: true,
+			AllValue:   ".*",
+			Refresh:    2,
+		})
+	}
+
+	selector := grafanaSelector(opts)
+	x, y, rowHeight := 0, 0, 0
+	for i, item := range dashboard.Items {
+		if item.Include != "" {
+			// Unresolved reference
+			continue
+		}
+		chart := item.Chart
+		spans := chart.Spans
+		if spans <= 0 || spans > 12 {
+			spans = 12
+		}
+		w := spans * grafanaGridWidth / 12
+		if x+w > grafanaGridWidth {
+			x, y = 0, y+rowHeight
+		}
+		panel := GrafanaPanel{
+			ID:          i + 1,
+			Type:        "timeseries",
+			Title:       chart.Name,
+			GridPos:     GrafanaGridPos{H: grafanaPanelHeight, W: w, X: x, Y: y},
+			Datasource:  "${datasource}",
+			Targets:     chartTargets(chart, selector),
+			FieldConfig: GrafanaFieldConfig{Defaults: chartFieldDefaults(chart)},
+		}
+		out.Panels = append(out.Panels, panel)
+		x += w
+		rowHeight = grafanaPanelHeight
+	}
+
+	for _, link := range dashboard.ExternalLinks {
+		if link.Type != "grafana" {
+			continue
+		}
+		out.Links = append(out.Links, GrafanaLink{
+			Title:       link.Name,
+			Type:        "link",
+			URL:         "/dashboards?query=" + url.QueryEscape(link.Name),
+			IncludeVars: true,
+		})
+	}
+	return out
+}
+
+// grafanaUID derives a Grafana uid, limited to 40 characters, from the dashboard name
+func grafanaUID(name string) string {
+	uid := "kiali-" + name
+	if len(uid) > 40 {
+		uid = uid[:40]
+	}
+	return uid
+}
+
+// seriesName returns the name of a series stored for the chart metric
+func seriesName(metricName, dataType string) string {
+	if dataType == Histogram {
+		return metricName + "_bucket"
+	}
+	return metricName
+}
+
+func grafanaSelector(opts GrafanaOptions) string {
+	matchers := []string{fmt.Sprintf(`%s="$namespace"`, opts.NamespaceLabel)}
+	for _, label := range opts.FilterLabels {
+		matchers = append(matchers, fmt.Sprintf(`%s=~"$%s"`, label, label))
+	}
+	scope := make([]string, 0, len(opts.Scope))
+	for label, value := range opts.Scope {
+		scope = append(scope, fmt.Sprintf(`%s="%s"`, label, value))
+	}
+	sort.Strings(scope)
+	return "{" + strings.Join(append(matchers, scope...), ",") + "}"
+}
+
+// chartTargets returns the Grafana queries of the chart, which are the queries run by Kiali: see
+// DashboardsService.GetDashboard. Histograms are exported with their average and the usual quantiles.
+func chartTargets(chart MonitoringDashboardChart, selector string) []GrafanaTarget {
+	grouping := append([]string{}, chart.GroupLabels...)
+	if chart.SortLabel != "" && !containsString(grouping, chart.SortLabel) {
+		grouping = append(grouping, chart.SortLabel)
+	}
+	by := ""
+	legendLabels := ""
+	if len(grouping) > 0 {
+		by = fmt.Sprintf(" by (%s)", strings.Join(grouping, ","))
+		for _, label := range grouping {
+			legendLabels += fmt.Sprintf(" {{%s}}", label)
+		}
+	}
+
+	targets := []GrafanaTarget{}
+	addTarget := func(expr, legend string) {
+		targets = append(targets, GrafanaTarget{Expr: expr, LegendFormat: legend + legendLabels, RefID: refID(len(targets))})
+	}
+	for _, metric := range chart.GetMetrics() {
+		switch chart.DataType {
+		case Rate:
+			addTarget(fmt.Sprintf("sum(rate(%s%s[%s]))%s", metric.MetricName, selector, grafanaRateInterval, by), metric.DisplayName)
+		case Histogram:
+			addTarget(fmt.Sprintf("sum(rate(%s_sum%s[%s]))%s / sum(rate(%s_count%s[%s]))%s",
+				metric.MetricName, selector, grafanaRateInterval, by, metric.MetricName, selector, grafanaRateInterval, by), metric.DisplayName+" avg")
+			byLe := " by (le)"
+			if len(grouping) > 0 {
+				byLe = fmt.Sprintf(" by (le,%s)", strings.Join(grouping, ","))
+			}
+			for _, quantile := range []string{"0.5", "0.95", "0.99"} {
+				addTarget(fmt.Sprintf("histogram_quantile(%s, sum(rate(%s_bucket%s[%s]))%s)",
+					quantile, metric.MetricName, selector, grafanaRateInterval, byLe), fmt.Sprintf("%s p%s", metric.DisplayName, strings.TrimPrefix(quantile, "0.")))
+			}
+		default:
+			aggregator := chart.Aggregator
+			if aggregator == "" {
+				aggregator = "sum"
+			}
+			addTarget(fmt.Sprintf("%s(%s%s)%s", aggregator, metric.MetricName, selector, by), metric.DisplayName)
+		}
+	}
+	return targets
+}
+
+func refID(i int) string {
+	if i < 26 {
+		return string(rune('A' + i))
+	}
+	return "Q" + strconv.Itoa(i)
+}
+
+func chartFieldDefaults(chart MonitoringDashboardChart) GrafanaFieldDefaults {
+	defaults := GrafanaFieldDefaults{Unit: chart.Unit}
+	if unit, ok := kialiToGrafanaUnits[chart.Unit]; ok {
+		defaults.Unit = unit
+	}
+	if chart.Unit == "seconds" {
+		switch chart.UnitScale {
+		case 0.001:
+			defaults.Unit = "ms"
+		case 0.000001:
+			defaults.Unit = "µs"
+		}
+	}
+	if chart.Min != nil {
+		min := float64(*chart.Min)
+		defaults.Min = &min
+	}
+	if chart.Max != nil {
+		max := float64(*chart.Max)
+		defaults.Max = &max
+	}
+	if chart.ChartType != nil {
+		switch *chart.ChartType {
+		case "area":
+			defaults.Custom = map[string]interface{}{"fillOpacity": 30}
+		case "bar":
+			defaults.Custom = map[string]interface{}{"drawStyle": "bars"}
+		case "scatter":
+			defaults.Custom = map[string]interface{}{"drawStyle": "points"}
+		}
+	}
+	return defaults
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+var (
+	nameSlugRegexp  = regexp.MustCompile(`[^a-z0-9]+`)
+	legendVarRegexp = regexp.MustCompile(`\{\{[^}]*\}\}`)
+	// The suffix of the exported histogram legends
+	histogramLegendRegexp = regexp.MustCompile(`\s+(avg|p[0-9]+)$`)
+	// sum(rate(metric{...}[5m])) by (labels), the grouping can also come first: sum by (labels) (rate(...))
+	rateExprRegexp = regexp.MustCompile(`^sum\s*(?:by\s*\(([^)]*)\))?\s*\(\s*(?:rate|irate|increase)\s*\(\s*([a-zA-Z_:][a-zA-Z0-9_:]*)\s*(\{[^}]*\})?\s*\[[^\]]+\]\s*\)\s*\)\s*(?:by\s*\(([^)]*)\))?$`)
+	// rate(metric{...}[5m]), not aggregated
+	bareRateExprRegexp = regexp.MustCompile(`^(?:rate|irate)\s*\(\s*([a-zA-Z_:][a-zA-Z0-9_:]*)\s*(\{[^}]*\})?\s*\[[^\]]+\]\s*\)$`)
+	// histogram_quantile(0.99, sum(rate(metric_bucket{...}[5m])) by (le, labels))
+	quantileExprRegexp = regexp.MustCompile(`^histogram_quantile\s*\(\s*[0-9.]+\s*,\s*sum\s*(?:by\s*\(([^)]*)\))?\s*\(\s*(?:rate|irate)\s*\(\s*([a-zA-Z_:][a-zA-Z0-9_:]*)_bucket\s*(\{[^}]*\})?\s*\[[^\]]+\]\s*\)\s*\)\s*(?:by\s*\(([^)]*)\))?\s*\)$`)
+	// sum(rate(metric_sum{...}[5m])) by (labels) / sum(rate(metric_count{...}[5m])) by (labels)
+	averageExprRegexp = regexp.MustCompile(`^sum\s*\(\s*rate\s*\(\s*([a-zA-Z_:][a-zA-Z0-9_:]*)_sum\s*(\{[^}]*\})?\s*\[[^\]]+\]\s*\)\s*\)\s*(?:by\s*\(([^)]*)\))?\s*/\s*sum\s*\(\s*rate\s*\(\s*([a-zA-Z_:][a-zA-Z0-9_:]*)_count`)
+	// avg(metric{...}) by (labels), or a bare metric{...}
+	rawExprRegexp  = regexp.MustCompile(`^(sum|avg|min|max|count)\s*(?:by\s*\(([^)]*)\))?\s*\(\s*([a-zA-Z_:][a-zA-Z0-9_:]*)\s*(\{[^}]*\})?\s*\)\s*(?:by\s*\(([^)]*)\))?$`)
+	bareExprRegexp = regexp.MustCompile(`^([a-zA-Z_:][a-zA-Z0-9_:]*)\s*(\{[^}]*\})?$`)
+	matcherRegexp  = regexp.MustCompile(`([a-zA-Z_][a-zA-Z0-9_]*)\s*(=~|!=|!~|=)\s*"((?:[^"\\]|\\.)*)"`)
+)
+
+// grafanaQuery is a Grafana query recognized as a query of a Kiali chart
+type grafanaQuery struct {
+	metricName  string
+	dataType    string
+	aggregator  string
+	groupLabels []string
+	selector    string
+}
+
+// parseGrafanaQuery recognizes the queries that Kiali runs for its charts: aggregated raw metrics, summed rates and
+// histograms. The others can't be imported.
+func parseGrafanaQuery(expr string) (*grafanaQuery, bool) {
+	expr = strings.TrimSpace(expr)
+	if m := rateExprRegexp.FindStringSubmatch(expr); m != nil {
+		return &grafanaQuery{metricName: m[2], dataType: Rate, groupLabels: splitLabels(m[1] + "," + m[4]), selector: m[3]}, true
+	}
+	if m := bareRateExprRegexp.FindStringSubmatch(expr); m != nil {
+		return &grafanaQuery{metricName: m[1], dataType: Rate, selector: m[2]}, true
+	}
+	if m := quantileExprRegexp.FindStringSubmatch(expr); m != nil {
+		labels := []string{}
+		for _, label := range splitLabels(m[1] + "," + m[4]) {
+			if label != "le" {
+				labels = append(labels, label)
+			}
+		}
+		return &grafanaQuery{metricName: m[2], dataType: Histogram, groupLabels: labels, selector: m[3]}, true
+	}
+	if m := averageExprRegexp.FindStringSubmatch(expr); m != nil && m[1] == m[4] {
+		return &grafanaQuery{metricName: m[1], dataType: Histogram, groupLabels: splitLabels(m[3]), selector: m[2]}, true
+	}
+	if m := rawExprRegexp.FindStringSubmatch(expr); m != nil {
+		return &grafanaQuery{metricName: m[3], dataType: Raw, aggregator: m[1], groupLabels: splitLabels(m[2] + "," + m[5]), selector: m[4]}, true
+	}
+	if m := bareExprRegexp.FindStringSubmatch(expr); m != nil {
+		return &grafanaQuery{metricName: m[1], dataType: Raw, selector: m[2]}, true
+	}
+	return nil, false
+}
+
+func splitLabels(labels string) []string {
+	out := []string{}
+	for _, label := range strings.Split(labels, ",") {
+		if label = strings.TrimSpace(label); label != "" && !containsString(out, label) {
+			out = append(out, label)
+		}
+	}
+	return out
+}
+
+// FromGrafana converts a Grafana dashboard to a dashboard. The panels that can't be converted are skipped, and
+// described by the returned warnings, as well as the parts of the panels that are lost in the conversion.
+func FromGrafana(grafana GrafanaDashboard, name string, opts GrafanaOptions) (*MonitoringDashboard, []string) {
+	warnings := []string{}
+	if name == "" {
+		name = strings.Trim(nameSlugRegexp.ReplaceAllString(strings.ToLower(grafana.Title), "-"), "-")
+	}
+	dashboard := MonitoringDashboard{
+		Name:  name,
+		Title: grafana.Title,
+		Items: []MonitoringDashboardItem{},
+	}
+
+	// The panels of the collapsed rows are nested
+	panels := []GrafanaPanel{}
+	for _, panel := range grafana.Panels {
+		if panel.Type == "row" {
+			panels = append(panels, panel.Panels...)
+			continue
+		}
+		panels = append(panels, panel)
+	}
+	sort.SliceStable(panels, func(i, j int) bool {
+		if panels[i].GridPos.Y != panels[j].GridPos.Y {
+			return panels[i].GridPos.Y < panels[j].GridPos.Y
+		}
+		return panels[i].GridPos.X < panels[j].GridPos.X
+	})
+
+	kialiLabels := map[string]bool{opts.NamespaceLabel: true}
+	for _, label := range opts.FilterLabels {
+		kialiLabels[label] = true
+	}
+	for label := range opts.Scope {
+		kialiLabels[label] = true
+	}
+
+	for _, panel := range panels {
+		if panel.Type != "timeseries" && panel.Type != "graph" {
+			warnings = append(warnings, fmt.Sprintf("panel %q skipped: %s panels are not supported", panel.Title, panel.Type))
+			continue
+		}
+		chart, chartWarnings := panelChart(panel, kialiLabels)
+		warnings = append(warnings, chartWarnings...)
+		if chart == nil {
+			continue
+		}
+		dashboard.Items = append(dashboard.Items, MonitoringDashboardItem{Chart: *chart})
+		if dashboard.DiscoverOn == "" {
+			dashboard.DiscoverOn = seriesName(chart.Metrics[0].MetricName, chart.DataType)
+		}
+	}
+
+	for _, link := range grafana.Links {
+		if (link.Type != "link" && link.Type != "dashboards") || link.Title == "" {
+			continue
+		}
+		externalLink := MonitoringDashboardExternalLink{Type: "grafana", Name: link.Title}
+		for _, variable := range grafana.Templating.List {
+			switch variable.Name {
+			case "namespace":
+				externalLink.Variables.Namespace = "var-namespace"
+			case "app":
+				externalLink.Variables.App = "var-app"
+			case "version":
+				externalLink.Variables.Version = "var-version"
+			case "workload":
+				externalLink.Variables.Workload = "var-workload"
+			case "service":
+				externalLink.Variables.Service = "var-service"
+			}
+		}
+		dashboard.ExternalLinks = append(dashboard.ExternalLinks, externalLink)
+	}
+	return &dashboard, warnings
+}
+
+// panelChart converts the panel to a chart. All the queries of a chart share the same data type and grouping: the
+// first query decides, and the others are skipped when they don't match it.
+func panelChart(panel GrafanaPanel, kialiLabels map[string]bool) (*MonitoringDashboardChart, []string) {
+	warnings := []string{}
+	var chart *MonitoringDashboardChart
+	for _, target := range panel.Targets {
+		if strings.TrimSpace(target.Expr) == "" {
+			continue
+		}
+		query, ok := parseGrafanaQuery(target.Expr)
+		if !ok {
+			warnings = append(warnings, fmt.Sprintf("panel %q: query %q skipped, it is not supported", panel.Title, target.Expr))
+			continue
+		}
+		if dropped := droppedMatchers(query.selector, kialiLabels); len(dropped) > 0 {
+			warnings = append(warnings, fmt.Sprintf("panel %q: label matchers %s dropped", panel.Title, strings.Join(dropped, ",")))
+		}
+		if chart == nil {
+			chart = &MonitoringDashboardChart{
+				Name:        panel.Title,
+				DataType:    query.dataType,
+				Aggregator:  query.aggregator,
+				GroupLabels: query.groupLabels,
+			}
+		} else if chart.DataType != query.dataType || chart.Aggregator != query.aggregator || strings.Join(chart.GroupLabels, ",") != strings.Join(query.groupLabels, ",") {
+			warnings = append(warnings, fmt.Sprintf("panel %q: query %q skipped, it doesn't match the other queries of the panel", panel.Title, target.Expr))
+			continue
+		}
+		// The histogram average and quantiles are a single metric in Kiali
+		if chart.DataType == Histogram && len(chart.Metrics) > 0 && chart.Metrics[len(chart.Metrics)-1].MetricName == query.metricName {
+			continue
+		}
+		displayName := strings.TrimSpace(legendVarRegexp.ReplaceAllString(target.LegendFormat, ""))
+		if chart.DataType == Histogram {
+			displayName = strings.TrimSpace(histogramLegendRegexp.ReplaceAllString(displayName, ""))
+		}
+		if displayName == "" {
+			displayName = query.metricName
+		}
+		chart.Metrics = append(chart.Metrics, MonitoringDashboardMetric{MetricName: query.metricName, DisplayName: displayName})
+	}
+	if chart == nil {
+		warnings = append(warnings, fmt.Sprintf("panel %q skipped: no supported query", panel.Title))
+		return nil, warnings
+	}
+	if len(chart.GroupLabels) == 0 {
+		chart.GroupLabels = nil
+	}
+
+	spans := panel.GridPos.W * 12 / grafanaGridWidth
+	if spans < 1 {
+		spans = 1
+	}
+	chart.Spans = spans
+
+	defaults := panel.FieldConfig.Defaults
+	unit := defaults.Unit
+	if unit == "" && len(panel.Yaxes) > 0 {
+		unit = panel.Yaxes[0].Format
+	}
+	if converted, ok := grafanaToKialiUnits[unit]; ok {
+		chart.Unit = converted.unit
+		chart.UnitScale = converted.scale
+	} else {
+		chart.Unit = unit
+	}
+	if defaults.Min != nil {
+		min := int(*defaults.Min)
+		chart.Min = &min
+	}
+	if defaults.Max != nil {
+		max := int(*defaults.Max)
+		chart.Max = &max
+	}
+	var chartType string
+	switch {
+	case defaults.Custom["drawStyle"] == "bars":
+		chartType = "bar"
+	case defaults.Custom["drawStyle"] == "points":
+		chartType = "scatter"
+	case defaults.Custom["fillOpacity"] != nil && defaults.Custom["fillOpacity"] != float64(0):
+		chartType = "area"
+	}
+	if chartType != "" {
+		chart.ChartType = &chartType
+	}
+	return chart, warnings
+}
+
+// droppedMatchers returns the matchers of the selector that are lost in the conversion: Kiali only sets its own
+// filters, and the matchers on variables are assumed to be these filters.
+func droppedMatchers(selector string, kialiLabels map[string]bool) []string {
+	dropped := []string{}
+	for _, m := range matcherRegexp.FindAllStringSubmatch(selector, -1) {
+		if kialiLabels[m[1]] || strings.Contains(m[3], "$") {
+			continue
+		}
+		dropped = append(dropped, m[0])
+	}
+	return dropped
+}
@@ -0,0 +1,202 @@
+package dashboards
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v2"
+)
+
+var testGrafanaOptions = GrafanaOptions{NamespaceLabel: "namespace", FilterLabels: []string{"app", "version"}}
+
+func TestToGrafana(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	builtIn := GetBuiltInMonitoringDashboards()
+	dashboard := builtIn.OrganizeByName()["go"]
+	grafana := ToGrafana(dashboard, GrafanaOptions{NamespaceLabel: "namespace", FilterLabels: []string{"app", "version"}, Scope: map[string]string{"mesh": "a"}})
+
+	assert.Equal("kiali-go", grafana.UID)
+	assert.Equal("Go Metrics", grafana.Title)
+	assert.Equal([]string{"kiali", "Go"}, grafana.Tags)
+	require.Len(grafana.Templating.List, 4)
+	assert.Equal("label_values(go_info, namespace)", grafana.Templating.List[1].Query)
+	assert.Equal(`label_values(go_info{namespace="$namespace"}, app)`, grafana.Templating.List[2].Query)
+
+	require.Len(grafana.Panels, len(dashboard.Items))
+	cpu := grafana.Panels[0]
+	assert.Equal("CPU ratio", cpu.Title)
+	assert.Equal(GrafanaGridPos{H: 8, W: 8, X: 0, Y: 0}, cpu.GridPos)
+	require.Len(cpu.Targets, 1)
+	assert.Equal(`sum(rate(process_cpu_seconds_total{namespace="$namespace",app=~"$app",version=~"$version",mesh="a"}[$__rate_interval]))`, cpu.Targets[0].Expr)
+	memory := grafana.Panels[1]
+	assert.Equal(GrafanaGridPos{H: 8, W: 8, X: 8, Y: 0}, memory.GridPos)
+	assert.Equal("bytes", memory.FieldConfig.Defaults.Unit)
+	assert.Equal(`sum(process_resident_memory_bytes{namespace="$namespace",app=~"$app",version=~"$version",mesh="a"})`, memory.Targets[0].Expr)
+	assert.Equal(GrafanaGridPos{H: 8, W: 8, X: 0, Y: 8}, grafana.Panels[3].GridPos)
+}
+
+func TestToGrafanaHistogram(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	min := 0
+	grafana := ToGrafana(MonitoringDashboard{
+		Name:  "latency",
+		Title: "Latency",
+		Items: []MonitoringDashboardItem{{Chart: MonitoringDashboardChart{
+			Name: "Latency", Unit: "seconds", UnitScale: 0.001, Spans: 6, Min: &min,
+			Metrics:     []MonitoringDashboardMetric{{MetricName: "request_duration", DisplayName: "Duration"}},
+			DataType:    Histogram,
+			GroupLabels: []string{"method"},
+		}}},
+		ExternalLinks: []MonitoringDashboardExternalLink{{Type: "grafana", Name: "Latency details"}},
+	}, testGrafanaOptions)
+
+	require.Len(grafana.Panels, 1)
+	panel := grafana.Panels[0]
+	assert.Equal("ms", panel.FieldConfig.Defaults.Unit)
+	assert.Equal(float64(0), *panel.FieldConfig.Defaults.Min)
+	require.Len(panel.Targets, 4)
+	assert.Equal("Duration avg {{method}}", panel.Targets[0].LegendFormat)
+	assert.Equal(`histogram_quantile(0.99, sum(rate(request_duration_bucket{namespace="$namespace",app=~"$app",version=~"$version"}[$__rate_interval])) by (le,method))`, panel.Targets[3].Expr)
+	assert.Equal("D", panel.Targets[3].RefID)
+	assert.Equal(`label_values(request_duration_bucket, namespace)`, grafana.Templating.List[1].Query)
+	require.Len(grafana.Links, 1)
+	assert.Equal("/dashboards?query=Latency+details", grafana.Links[0].URL)
+}
+
+func TestGrafanaRoundTrip(t *testing.T) {
+	assert := assert.New(t)
+
+	builtIn := GetBuiltInMonitoringDashboards()
+	for name, dashboard := range builtIn.OrganizeByName() {
+		// The references aren't resolved here
+		charts := []MonitoringDashboardItem{}
+		for _, item := range dashboard.Items {
+			if item.Include == "" {
+				charts = append(charts, item)
+			}
+		}
+		dashboard.Items = charts
+		imported, warnings := FromGrafana(ToGrafana(dashboard, testGrafanaOptions), name, testGrafanaOptions)
+		assert.Empty(warnings, name)
+		assert.Equal(dashboard.Title, imported.Title, name)
+		if !assert.Len(imported.Items, len(dashboard.Items), name) {
+			continue
+		}
+		for i, item := range dashboard.Items {
+			chart := imported.Items[i].Chart
+			assert.Equal(item.Chart.Name, chart.Name, name)
+			assert.Equal(item.Chart.DataType, chart.DataType, name)
+			assert.Equal(item.Chart.Unit, chart.Unit, name)
+			assert.Equal(item.Chart.Spans, chart.Spans, name)
+			assert.Equal(item.Chart.GetMetrics(), chart.GetMetrics(), name)
+		}
+	}
+}
+
+func TestFromGrafana(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	max := float64(100)
+	grafana := GrafanaDashboard{
+		Title: "Queue Workers",
+		Templating: GrafanaTemplating{List: []GrafanaVariable{
+			{Name: "namespace", Type: "query"},
+			{Name: "app", Type: "query"},
+		}},
+		Panels: []GrafanaPanel{
+			{
+				Type: "timeseries", Title: "Throughput", GridPos: GrafanaGridPos{W: 12, Y: 0, X: 12},
+				Targets: []GrafanaTarget{
+					{Expr: `sum by (queue) (rate(jobs_total{namespace="$namespace",app="$app",status="done"}[$__rate_interval]))`, LegendFormat: "Done {{queue}}"},
+					{Expr: `sum(rate(jobs_failed_total{namespace="$namespace"}[5m])) by (queue)`},
+					{Expr: `sum(rate(jobs_failed_total{namespace="$namespace"}[5m])) / sum(rate(jobs_total{namespace="$namespace"}[5m]))`},
+				},
+				FieldConfig: GrafanaFieldConfig{Defaults: GrafanaFieldDefaults{Unit: "reqps", Max: &max, Custom: map[string]interface{}{"drawStyle": "bars"}}},
+			},
+			{
+				Type: "row", Title: "Details", Collapsed: true, GridPos: GrafanaGridPos{Y: 8},
+				Panels: []GrafanaPanel{{
+					Type: "graph", Title: "Depth", GridPos: GrafanaGridPos{W: 8, Y: 9},
+					Targets: []GrafanaTarget{{Expr: `max(queue_depth{namespace=~"$namespace"}) by (queue)`}},
+					Yaxes:   []GrafanaYAxis{{Format: "short"}},
+				}},
+			},
+			{
+				Type: "timeseries", Title: "Wait", GridPos: GrafanaGridPos{W: 12, Y: 0, X: 0},
+				Targets: []GrafanaTarget{{
+					Expr:         `histogram_quantile(0.95, sum(rate(job_wait_seconds_bucket{namespace="$namespace"}[5m])) by (le))`,
+					LegendFormat: "Wait p95",
+				}},
+				FieldConfig: GrafanaFieldConfig{Defaults: GrafanaFieldDefaults{Unit: "ms"}},
+			},
+			{Type: "stat", Title: "Workers"},
+		},
+		Links: []GrafanaLink{{Title: "Queue overview", Type: "dashboards"}},
+	}
+
+	dashboard, warnings := FromGrafana(grafana, "", testGrafanaOptions)
+	assert.Equal("queue-workers", dashboard.Name)
+	assert.Equal("Queue Workers", dashboard.Title)
+	assert.Equal("job_wait_seconds_bucket", dashboard.DiscoverOn)
+	assert.Equal([]string{
+		`panel "Workers" skipped: stat panels are not supported`,
+		`panel "Throughput": label matchers status="done" dropped`,
+		`panel "Throughput": query "sum(rate(jobs_failed_total{namespace=\"$namespace\"}[5m])) / sum(rate(jobs_total{namespace=\"$namespace\"}[5m]))" skipped, it is not supported`,
+	}, warnings)
+
+	require.Len(dashboard.Items, 3)
+	wait := dashboard.Items[0].Chart
+	assert.Equal("Wait", wait.Name)
+	assert.Equal(Histogram, wait.DataType)
+	assert.Equal("seconds", wait.Unit)
+	assert.Equal(0.001, wait.UnitScale)
+	assert.Equal([]MonitoringDashboardMetric{{MetricName: "job_wait_seconds", DisplayName: "Wait"}}, wait.Metrics)
+
+	throughput := dashboard.Items[1].Chart
+	assert.Equal(Rate, throughput.DataType)
+	assert.Equal(6, throughput.Spans)
+	assert.Equal("rps", throughput.Unit)
+	assert.Equal([]string{"queue"}, throughput.GroupLabels)
+	assert.Equal(100, *throughput.Max)
+	assert.Equal("bar", *throughput.ChartType)
+	assert.Equal([]MonitoringDashboardMetric{
+		{MetricName: "jobs_total", DisplayName: "Done"},
+		{MetricName: "jobs_failed_total", DisplayName: "jobs_failed_total"},
+	}, throughput.Metrics)
+
+	depth := dashboard.Items[2].Chart
+	assert.Equal(Raw, depth.DataType)
+	assert.Equal("max", depth.Aggregator)
+	assert.Equal(4, depth.Spans)
+	assert.Equal("", depth.Unit)
+
+	require.Len(dashboard.ExternalLinks, 1)
+	assert.Equal(MonitoringDashboardExternalLink{
+		Type:      "grafana",
+		Name:      "Queue overview",
+		Variables: MonitoringDashboardExternalLinkVariables{Namespace: "var-namespace", App: "var-app"},
+	}, dashboard.ExternalLinks[0])
+}
+
+func TestToResourceSpec(t *testing.T) {
+	assert := assert.New(t)
+
+	builtIn := GetBuiltInMonitoringDashboards()
+	dashboard := builtIn.OrganizeByName()["go"]
+	spec, err := ToResourceSpec(dashboard)
+	assert.NoError(err)
+	assert.Equal("Go Metrics", spec["title"])
+
+	parsed, err := FromResourceSpec("go", spec)
+	assert.NoError(err)
+	// Empty lists aren't nil anymore
+	expected, _ := yaml.Marshal(dashboard)
+	actual, _ := yaml.Marshal(parsed)
+	assert.Equal(string(expected), string(actual))
+}
@@ -7,7 +7,10 @@ import (
 
 // Identity security details about a client.
 type Identity struct {
-	CertFile       string `yaml:"cert_file"`
+	CertFile string `yaml:"cert_file"`
+	// ClientCAFile is the CA of the client certificates verified by the server, e.g. the certificates of the
+	// federated Kiali instances. The clients without certificate are still accepted, except the federated requests.
+	ClientCAFile   string `yaml:"client_ca_file,omitempty"`
 	PrivateKeyFile string `yaml:"private_key_file"`
 }
 
@@ -3,8 +3,12 @@ package main
 import (
 	jaegerModels "github.com/kiali/kiali/jaeger/model/json"
 
+	"github.com/kiali/kiali/audit"
+	"github.com/kiali/kiali/business"
 	"github.com/kiali/kiali/business/authentication"
+	"github.com/kiali/kiali/config/dashboards"
 	"github.com/kiali/kiali/graph/config/cytoscape"
+	"github.com/kiali/kiali/handlers"
 	"github.com/kiali/kiali/jaeger"
 	"github.com/kiali/kiali/kubernetes"
 	"github.com/kiali/kiali/models"
@@ -34,7 +38,7 @@ type AggregateValueParam struct {
 	Name string `json:"aggregateValue"`
 }
 
-// swagger:parameters appMetrics appDetails graphApp graphAppVersion appDashboard appSpans appTraces errorTraces
+// swagger:parameters appMetrics appDetails graphApp graphAppVersion appDashboard appSpans appTraces errorTraces appLogs
 type AppParam struct {
 	// The app name (label value).
 	//
@@ -61,9 +65,10 @@ type ClusterParam struct {
 	Name string `json:"container"`
 }
 
-// swagger:parameters podLogs
+// swagger:parameters podLogs appLogs workloadLogs
 type ContainerParam struct {
 	// The pod container name. Optional for single-container pod. Otherwise required.
+	// For app and workload logs, a comma-separated list of containers. Default is all the app containers.
 	//
 	// in: query
 	// required: false
@@ -79,7 +84,7 @@ type LoggingParam struct {
 	Level ProxyLogLevel `json:"level"`
 }
 
-// swagger:parameters istioConfigList workloadList workloadDetails workloadUpdate serviceDetails serviceUpdate appSpans serviceSpans workloadSpans appTraces serviceTraces workloadTraces errorTraces workloadValidations appList serviceMetrics aggregateMetrics appMetrics workloadMetrics istioConfigDetails istioConfigDetailsSubtype istioConfigDelete istioConfigDeleteSubtype istioConfigUpdate istioConfigUpdateSubtype serviceList appDetails graphAggregate graphAggregateByService graphApp graphAppVersion graphNamespace graphService graphWorkload namespaceMetrics customDashboard appDashboard serviceDashboard workloadDashboard istioConfigCreate istioConfigCreateSubtype namespaceUpdate namespaceTls podDetails podLogs namespaceValidations podProxyDump podProxyResource podProxyLogging
+// swagger:parameters istioConfigList workloadList workloadDetails workloadUpdate serviceDetails serviceUpdate appSpans serviceSpans workloadSpans appTraces serviceTraces workloadTraces errorTraces workloadValidations appList serviceMetrics aggregateMetrics appMetrics workloadMetrics istioConfigDetails istioConfigDetailsSubtype istioConfigDelete istioConfigDeleteSubtype istioConfigUpdate istioConfigUpdateSubtype serviceList appDetails graphAggregate graphAggregateByService graphApp graphAppVersion graphNamespace graphService graphWorkload namespaceMetrics customDashboard appDashboard serviceDashboard workloadDashboard istioConfigCreate istioConfigCreateSubtype namespaceUpdate namespaceTls podDetails podLogs namespaceValidations podProxyDump podProxyResource podProxyLogging appLogs workloadLogs podAccessLogStats workloadAccessLogStats logTrace serviceTracesCompare metricTemplate customDashboardGrafanaExport customDashboardGrafanaImport
 type NamespaceParam struct {
 	// The namespace name.
 	//
@@ -116,7 +121,7 @@ type ValidateParam struct {
 	Name string `json:"validate"`
 }
 
-// swagger:parameters podDetails podLogs podProxyDump podProxyResource podProxyLogging
+// swagger:parameters podDetails podLogs podProxyDump podProxyResource podProxyLogging podAccessLogStats
 type PodParam struct {
 	// The pod name.
 	//
@@ -134,7 +139,7 @@ type ResourceParam struct {
 	Name string `json:"resource"`
 }
 
-// swagger:parameters serviceDetails serviceUpdate serviceMetrics graphService graphAggregateByService serviceDashboard serviceSpans serviceTraces
+// swagger:parameters serviceDetails serviceUpdate serviceMetrics graphService graphAggregateByService serviceDashboard serviceSpans serviceTraces serviceTracesCompare
 type ServiceParam struct {
 	// The service name.
 	//
@@ -143,7 +148,7 @@ type ServiceParam struct {
 	Name string `json:"service"`
 }
 
-// swagger:parameters podLogs
+// swagger:parameters podLogs appLogs workloadLogs podAccessLogStats workloadAccessLogStats
 type SinceTimeParam struct {
 	// The start time for fetching logs. UNIX time in seconds. Default is all logs.
 	//
@@ -152,7 +157,7 @@ type SinceTimeParam struct {
 	Name string `json:"sinceTime"`
 }
 
-// swagger:parameters podLogs
+// swagger:parameters podLogs appLogs workloadLogs podAccessLogStats workloadAccessLogStats
 type DurationLogParam struct {
 	// Query time-range duration (Golang string duration). Duration starts on
 	// `sinceTime` if set, or the time for the first log message if not set.
@@ -162,7 +167,52 @@ type DurationLogParam struct {
 	Name string `json:"duration"`
 }
 
-// swagger:parameters traceDetails
+// swagger:parameters podLogs appLogs workloadLogs podAccessLogStats workloadAccessLogStats
+type AuthorityLogParam struct {
+	// Only return proxy access log entries for this request authority (host).
+	//
+	// in: query
+	// required: false
+	Name string `json:"authority"`
+}
+
+// swagger:parameters podLogs appLogs workloadLogs podAccessLogStats workloadAccessLogStats
+type ResponseFlagsLogParam struct {
+	// Comma-separated list of Envoy response flags (e.g. UH,UF). Only return proxy access log entries with any of them.
+	//
+	// in: query
+	// required: false
+	Name string `json:"responseFlags"`
+}
+
+// swagger:parameters podLogs appLogs workloadLogs podAccessLogStats workloadAccessLogStats
+type StatusCodeLogParam struct {
+	// Comma-separated list of response codes, or classes such as 5xx. Only return proxy access log entries with any of them.
+	//
+	// in: query
+	// required: false
+	Name string `json:"statusCode"`
+}
+
+// swagger:parameters podAccessLogStats workloadAccessLogStats
+type LimitAccessLogStatsParam struct {
+	// Maximum number of response flags, upstream hosts and routes returned. Default is 10.
+	//
+	// in: query
+	// required: false
+	Name int `json:"limit"`
+}
+
+// swagger:parameters appLogs workloadLogs
+type FollowLogParam struct {
+	// Keep streaming new log entries of all the pods as they are produced. Default is false.
+	//
+	// in: query
+	// required: false
+	Name bool `json:"follow"`
+}
+
+// swagger:parameters traceDetails traceLogs tracesCompare
 type TraceIDParam struct {
 	// The trace ID.
 	//
@@ -171,7 +221,157 @@ type TraceIDParam struct {
 	Name string `json:"traceID"`
 }
 
-// swagger:parameters customDashboard
+// swagger:parameters tracesCompare
+type OtherTraceIDParam struct {
+	// The ID of the trace to compare with.
+	//
+	// in: path
+	// required: true
+	Name string `json:"otherTraceID"`
+}
+
+// swagger:parameters serviceTracesCompare
+type BaselineStartMicrosParam struct {
+	// Start of the baseline time window, in microseconds since epoch. The traces of the requested time window
+	// (startMicros, endMicros) are compared with the traces of the baseline time window.
+	//
+	// in: query
+	// required: true
+	Name string `json:"baselineStartMicros"`
+}
+
+// swagger:parameters serviceTracesCompare
+type BaselineEndMicrosParam struct {
+	// End of the baseline time window, in microseconds since epoch.
+	//
+	// in: query
+	// required: true
+	Name string `json:"baselineEndMicros"`
+}
+
+// swagger:parameters appTraces serviceTraces workloadTraces
+type MaxDurationParam struct {
+	// Maximum duration of the matching spans, in microseconds.
+	//
+	// in: query
+	// required: false
+	Name string `json:"maxDuration"`
+}
+
+// swagger:parameters appTraces serviceTraces workloadTraces
+type SpanStatusParam struct {
+	// Returns only the traces having at least one span in error ("error"), or none ("ok").
+	//
+	// in: query
+	// required: false
+	Name string `json:"spanStatus"`
+}
+
+// swagger:parameters appTraces serviceTraces workloadTraces
+type StatusCodeParam struct {
+	// Comma-separated HTTP status codes (404), classes (5xx) or ranges (500-504). Returns only the traces having at
+	// least one span with a matching status code.
+	//
+	// in: query
+	// required: false
+	Name string `json:"statusCode"`
+}
+
+// swagger:parameters appTraces serviceTraces workloadTraces
+type CallParam struct {
+	// Service-to-service call formatted as source->destination[:statusCodes], e.g. productpage->reviews:5xx.
+	// Returns only the traces having this call. Can be repeated, all calls must match.
+	//
+	// in: query
+	// required: false
+	Name []string `json:"call"`
+}
+
+// swagger:parameters logTrace
+type LogTraceParam struct {
+	// The trace ID found in the log entry.
+	//
+	// in: query
+	// required: false
+	TraceID string `json:"traceId"`
+	// The request ID (x-request-id) found in the log entry, used when there is no trace ID.
+	//
+	// in: query
+	// required: false
+	RequestID string `json:"requestId"`
+	// The app of the log entry. Required to find a trace by request ID.
+	//
+	// in: query
+	// required: false
+	App string `json:"app"`
+}
+
+// swagger:parameters traceLogs
+type TraceLogsParam struct {
+	// Only return the log entries carrying the trace ID or a request ID of the trace. Default is false.
+	//
+	// in: query
+	// required: false
+	CorrelatedOnly bool `json:"correlatedOnly"`
+	// Maximum number of log entries. Default is 1000.
+	//
+	// in: query
+	// required: false
+	MaxLines int `json:"maxLines"`
+}
+
+// swagger:parameters metricTemplate
+type MetricTemplateParam struct {
+	// The metric template name, from the custom dashboards configuration.
+	//
+	// in: path
+	// required: true
+	Name string `json:"template"`
+}
+
+// swagger:parameters metricTemplate
+type MetricTemplateVariablesParam struct {
+	// Value of the $app variable, when used by the template.
+	//
+	// in: query
+	// required: false
+	App string `json:"app"`
+	// Value of the $service variable, when used by the template.
+	//
+	// in: query
+	// required: false
+	Service string `json:"service"`
+	// Value of the $version variable, when used by the template.
+	//
+	// in: query
+	// required: false
+	Version string `json:"version"`
+	// Value of the $workload variable, when used by the template.
+	//
+	// in: query
+	// required: false
+	Workload string `json:"workload"`
+}
+
+// swagger:parameters sessionRevoke
+type SessionParam struct {
+	// The session ID, as listed by the sessions endpoint.
+	//
+	// in: path
+	// required: true
+	Name string `json:"session"`
+}
+
+// swagger:parameters userSessionsRevoke
+type SessionUsernameParam struct {
+	// The user whose sessions are revoked.
+	//
+	// in: query
+	// required: true
+	Name string `json:"username"`
+}
+
+// swagger:parameters customDashboard customDashboardGrafanaExport
 type DashboardParam struct {
 	// The dashboard resource name.
 	//
@@ -180,7 +380,7 @@ type DashboardParam struct {
 	Name string `json:"dashboard"`
 }
 
-// swagger:parameters workloadDetails workloadUpdate workloadValidations workloadMetrics graphWorkload workloadDashboard workloadSpans workloadTraces
+// swagger:parameters workloadDetails workloadUpdate workloadValidations workloadMetrics graphWorkload workloadDashboard workloadSpans workloadTraces workloadLogs workloadAccessLogStats
 type WorkloadParam struct {
 	// The workload name.
 	//
@@ -189,6 +389,83 @@ type WorkloadParam struct {
 	Name string `json:"workload"`
 }
 
+// swagger:parameters authenticate openshiftCheckToken
+type AuthProviderParam struct {
+	// The authentication strategy to log in with, when several strategies are enabled. Defaults to the preferred one.
+	//
+	// in: query
+	// required: false
+	Name string `json:"provider"`
+}
+
+// swagger:parameters auditEntries
+type AuditQueryParams struct {
+	// Only the records of the operations done on this cluster.
+	//
+	// in: query
+	// required: false
+	Cluster string `json:"cluster"`
+	// Maximum number of records.
+	//
+	// in: query
+	// required: false
+	// default: 100
+	Limit int `json:"limit"`
+	// Only the records of the operations done in this namespace.
+	//
+	// in: query
+	// required: false
+	Namespace string `json:"namespace"`
+	// Only the records of the operations done on the objects with this name.
+	//
+	// in: query
+	// required: false
+	Object string `json:"object"`
+	// Only the records of the operations done on this type of objects, e.g. virtualservices or workloads.
+	//
+	// in: query
+	// required: false
+	ObjectType string `json:"objectType"`
+	// Only the records of the operations with this outcome: success or failure.
+	//
+	// in: query
+	// required: false
+	Outcome string `json:"outcome"`
+	// Only the records after this time, in Unix seconds.
+	//
+	// in: query
+	// required: false
+	Since int64 `json:"since"`
+	// Only the records of the operations done by this user.
+	//
+	// in: query
+	// required: false
+	User string `json:"user"`
+	// Only the records of this operation, e.g. create, update or delete.
+	//
+	// in: query
+	// required: false
+	Verb string `json:"verb"`
+}
+
+// swagger:parameters apiTokenCreate
+type APITokenCreateParams struct {
+	// The name, the scope and the lifetime of the token.
+	//
+	// in: body
+	// required: true
+	Body authentication.APITokenRequest
+}
+
+// swagger:parameters apiTokenRevoke
+type APITokenParam struct {
+	// The token ID, as listed by the tokens endpoint.
+	//
+	// in: path
+	// required: true
+	Name string `json:"token"`
+}
+
 /////////////////////
 // SWAGGER PARAMETERS - GRAPH
 // - keep this alphabetized
@@ -313,7 +590,7 @@ type RateTcpParam struct {
 
 // swagger:parameters graphApp graphAppVersion graphNamespaces graphService graphWorkload
 type ResponseTimeParam struct {
-	// Used only with responseTime appender. One of: avg | 50 | 95 | 99.
+	// Used only with responseTime appender, or with the tracing telemetry vendor. One of: avg | 50 | 95 | 99.
 	//
 	// in: query
 	// required: false
@@ -321,6 +598,16 @@ type ResponseTimeParam struct {
 	Name string `json:"responseTime"`
 }
 
+// swagger:parameters graphNamespaces
+type TelemetryVendorParam struct {
+	// The source of the graph telemetry. One of: istio (Istio metrics) | tracing (sampled traces).
+	//
+	// in: query
+	// required: false
+	// default: istio
+	Name string `json:"telemetryVendor"`
+}
+
 // swagger:parameters graphApp graphAppVersion graphNamespaces graphService graphWorkload
 type ThroughputParam struct {
 	// Used only with throughput appender. One of: request | response.
@@ -331,6 +618,16 @@ type ThroughputParam struct {
 	Name string `json:"throughput"`
 }
 
+// swagger:parameters graphNamespaces
+type TraceLimitParam struct {
+	// Used only with the tracing telemetry vendor. Maximum number of traces fetched for each app.
+	//
+	// in: query
+	// required: false
+	// default: 100
+	Name string `json:"traceLimit"`
+}
+
 /////////////////////
 // SWAGGER PARAMETERS - METRICS
 // - keep this alphabetized
@@ -375,7 +672,7 @@ type DirectionParam struct {
 	Name string `json:"direction"`
 }
 
-// swagger:parameters serviceMetrics aggregateMetrics appMetrics workloadMetrics customDashboard appDashboard serviceDashboard workloadDashboard
+// swagger:parameters serviceMetrics aggregateMetrics appMetrics workloadMetrics customDashboard appDashboard serviceDashboard workloadDashboard metricTemplate
 type DurationParam struct {
 	// Duration of the query period, in seconds.
 	//
@@ -395,6 +692,24 @@ type FiltersParam struct {
 	Name []string `json:"filters[]"`
 }
 
+// swagger:parameters customDashboardGrafanaImport
+type GrafanaDashboardParam struct {
+	// The Grafana dashboard JSON model, or the response of the Grafana dashboard API.
+	//
+	// in: body
+	// required: true
+	Dashboard dashboards.GrafanaDashboard `json:"dashboard"`
+}
+
+// swagger:parameters customDashboardGrafanaImport
+type GrafanaDashboardNameParam struct {
+	// The name of the MonitoringDashboard resource. Defaults to the Grafana dashboard title.
+	//
+	// in: query
+	// required: false
+	Name string `json:"name"`
+}
+
 // swagger:parameters customDashboard
 type LabelsFiltersParam struct {
 	// In custom dashboards, labels filters to use when fetching metrics, formatted as key:value pairs. Ex: "app:foo,version:bar".
@@ -425,7 +740,7 @@ type RateFuncParam struct {
 	Name string `json:"rateFunc"`
 }
 
-// swagger:parameters serviceMetrics aggregateMetrics appMetrics workloadMetrics customDashboard appDashboard serviceDashboard workloadDashboard
+// swagger:parameters serviceMetrics aggregateMetrics appMetrics workloadMetrics customDashboard appDashboard serviceDashboard workloadDashboard metricTemplate
 type RateIntervalParam struct {
 	// Interval used for rate and histogram calculation.
 	//
@@ -455,7 +770,17 @@ type ReporterParam struct {
 	Name string `json:"reporter"`
 }
 
-// swagger:parameters serviceMetrics aggregateMetrics appMetrics workloadMetrics customDashboard appDashboard serviceDashboard workloadDashboard
+// swagger:parameters serviceMetrics appMetrics workloadMetrics appDashboard serviceDashboard workloadDashboard
+type ExemplarsParam struct {
+	// When true, the datapoints are linked to representative traces, using the exemplars stored by Prometheus.
+	//
+	// in: query
+	// required: false
+	// default: false
+	Name bool `json:"exemplars"`
+}
+
+// swagger:parameters serviceMetrics aggregateMetrics appMetrics workloadMetrics customDashboard appDashboard serviceDashboard workloadDashboard metricTemplate
 type StepParam struct {
 	// Step between [graph] datapoints, in seconds.
 	//
@@ -575,6 +900,41 @@ type swaggTokenGeneratedResp struct {
 	Body authentication.UserSessionData
 }
 
+// Recent audit records
+// swagger:response auditEntriesResponse
+type AuditEntriesResponse struct {
+	// in:body
+	Body []audit.Entry
+}
+
+// API tokens of the user
+// swagger:response apiTokensResponse
+type APITokensResponse struct {
+	// in:body
+	Body []authentication.APIToken
+}
+
+// New API token
+// swagger:response createdAPITokenResponse
+type CreatedAPITokenResponse struct {
+	// in:body
+	Body handlers.CreatedAPIToken
+}
+
+// Active sessions
+// swagger:response sessionsResponse
+type SessionsResponse struct {
+	// in:body
+	Body []authentication.SessionInfo
+}
+
+// Number of revoked sessions
+// swagger:response revokedSessionsResponse
+type RevokedSessionsResponse struct {
+	// in:body
+	Body handlers.RevokedSessions
+}
+
 // HTTP status code 200 and cytoscapejs Config in data
 // swagger:response graphResponse
 type GraphResponse struct {
@@ -659,6 +1019,34 @@ type WorkloadDetailsResponse struct {
 	Body models.Workload
 }
 
+// Log entries of one or several pods
+// swagger:response podLogs
+type PodLogsResponse struct {
+	// in:body
+	Body business.PodLog
+}
+
+// Statistics computed from proxy access logs
+// swagger:response accessLogStatsResponse
+type AccessLogStatsResponse struct {
+	// in:body
+	Body models.AccessLogStats
+}
+
+// Comparison of two traces or two sets of traces
+// swagger:response traceComparisonResponse
+type TraceComparisonResponse struct {
+	// in:body
+	Body models.TraceComparison
+}
+
+// Log entries of the pods involved in a trace
+// swagger:response traceLogsResponse
+type TraceLogsResponse struct {
+	// in:body
+	Body business.TraceLogs
+}
+
 // Metrics response model
 // swagger:response metricsResponse
 type MetricsResponse struct {
@@ -673,6 +1061,34 @@ type DashboardResponse struct {
 	Body models.MonitoringDashboard
 }
 
+// Metric templates response model
+// swagger:response metricTemplatesResponse
+type MetricTemplatesResponse struct {
+	// in:body
+	Body []models.MetricTemplate
+}
+
+// Metric template response model
+// swagger:response metricTemplateResponse
+type MetricTemplateResponse struct {
+	// in:body
+	Body models.MetricTemplateResult
+}
+
+// Grafana dashboard export response model
+// swagger:response grafanaDashboardResponse
+type GrafanaDashboardResponse struct {
+	// in:body
+	Body dashboards.GrafanaDashboard
+}
+
+// Grafana dashboard import response model
+// swagger:response grafanaDashboardImportResponse
+type GrafanaDashboardImportResponse struct {
+	// in:body
+	Body models.GrafanaDashboardImport
+}
+
 // IstioConfig details of an specific Istio Object
 // swagger:response istioConfigDetailsResponse
 type IstioConfigDetailsResponse struct {
@@ -0,0 +1,132 @@
+// Package federation reads the graph, health, validations and workloads of the clusters that this Kiali can't access
+// directly from the API of their own Kiali instance, the peers declared in the federation config.
+package federation
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/kiali/kiali/config"
+	"github.com/kiali/kiali/util/httputil"
+)
+
+// FederatedRequestHeader marks the requests sent to a peer. A peer answers them with its own data only, so that two
+// Kialis federating each other don't loop.
+const FederatedRequestHeader = "Kiali-Federated"
+
+// Client for the API of a peer Kiali
+type Client struct {
+	peer    config.FederationPeer
+	timeout time.Duration
+}
+
+// PeerError is returned when a peer answered a request with an error code
+type PeerError struct {
+	Cluster string
+	Code    int
+	Message string
+}
+
+func (e *PeerError) Error() string {
+	return fmt.Sprintf("Kiali of cluster [%s] returned [%d]: %s", e.Cluster, e.Code, e.Message)
+}
+
+// Peers returns the clients of all the peers, or none when the federation is disabled
+func Peers() []*Client {
+	cfg := config.Get()
+	if !cfg.Federation.Enabled {
+		return []*Client{}
+	}
+
+	clients := make([]*Client, 0, len(cfg.Federation.Peers))
+	for _, peer := range cfg.Federation.Peers {
+		clients = append(clients, newClient(peer, cfg.Federation.Timeout))
+	}
+	return clients
+}
+
+// Peer returns the client of the peer of the cluster, or nil when the cluster isn't federated
+func Peer(cluster string) *Client {
+	cfg := config.Get()
+	if !cfg.Federation.Enabled || cluster == "" {
+		return nil
+	}
+
+	for _, peer := range cfg.Federation.Peers {
+		if peer.Cluster == cluster {
+			return newClient(peer, cfg.Federation.Timeout)
+		}
+	}
+	return nil
+}
+
+func newClient(peer config.FederationPeer, timeout int) *Client {
+	return &Client{
+		peer:    peer,
+		timeout: time.Duration(timeout) * time.Second,
+	}
+}
+
+// Cluster returns the name of the cluster observed by the peer
+func (c *Client) Cluster() string {
+	return c.peer.Cluster
+}
+
+// SharesNamespace returns true if the namespace, missing in the home cluster, can be read from the peer
+func (c *Client) SharesNamespace(namespace string) bool {
+	for _, shared := range c.peer.Namespaces {
+		if shared == namespace {
+			return true
+		}
+	}
+	return false
+}
+
+// URL returns the URL of the peer
+func (c *Client) URL() string {
+	return c.peer.URL
+}
+
+// Get sends a GET request to an API path of the peer, e.g. /api/namespaces/graph, and returns the body and the code
+// of the response. An error is only returned when the peer couldn't be reached.
+func (c *Client) Get(path string, query url.Values) ([]byte, int, error) {
+	u := strings.TrimSuffix(c.peer.URL, "/") + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+
+	body, code, _, err := httputil.HttpGet(u, &c.peer.Auth, c.timeout, map[string]string{FederatedRequestHeader: "true"}, nil)
+	if err != nil {
+		return nil, 0, fmt.Errorf("Kiali of cluster [%s] is unreachable: %v", c.peer.Cluster, err)
+	}
+	return body, code, nil
+}
+
+// GetJSON sends a GET request to an API path of the peer and unmarshals the response into the result. A response
+// with an error code is returned as a PeerError.
+func (c *Client) GetJSON(path string, query url.Values, result interface{}) error {
+	body, code, err := c.Get(path, query)
+	if err != nil {
+		return err
+	}
+
+	if code != 200 {
+		peerErr := &PeerError{Cluster: c.peer.Cluster, Code: code, Message: string(body)}
+		// The Kiali API errors are returned as {"error": "..."}
+		var errorResponse struct {
+			Error string `json:"error"`
+		}
+		if json.Unmarshal(body, &errorResponse) == nil && errorResponse.Error != "" {
+			peerErr.Message = errorResponse.Error
+		}
+		return peerErr
+	}
+
+	if err := json.Unmarshal(body, result); err != nil {
+		return fmt.Errorf("invalid response of the Kiali of cluster [%s]: %v", c.peer.Cluster, err)
+	}
+	return nil
+}
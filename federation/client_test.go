@@ -0,0 +1,75 @@
+package federation
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kiali/kiali/config"
+	"github.com/kiali/kiali/models"
+)
+
+func setupPeer(t *testing.T, handler http.HandlerFunc) *Client {
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	conf := config.NewConfig()
+	conf.Federation.Enabled = true
+	conf.Federation.Peers = []config.FederationPeer{
+		{Cluster: "east", URL: server.URL + "/kiali/", Auth: config.Auth{Type: config.AuthTypeBearer, Token: "kiali_token"}},
+	}
+	config.Set(conf)
+	t.Cleanup(func() {
+		config.Set(config.NewConfig())
+	})
+
+	return Peer("east")
+}
+
+func TestGetSendsAuthenticatedFederatedRequests(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+	peer := setupPeer(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal("/kiali/api/namespaces/bookinfo/workloads", r.URL.Path)
+		assert.Equal("false", r.URL.Query().Get("health"))
+		assert.Equal("Bearer kiali_token", r.Header.Get("Authorization"))
+		assert.Equal("true", r.Header.Get(FederatedRequestHeader))
+		_, _ = w.Write([]byte(`{"workloads": [{"name": "reviews-v1"}]}`))
+	})
+	require.NotNil(peer)
+	assert.Len(Peers(), 1)
+	assert.Nil(Peer("west"))
+
+	workloads := models.WorkloadList{}
+	require.NoError(peer.GetJSON("/api/namespaces/bookinfo/workloads", url.Values{"health": []string{"false"}}, &workloads))
+	require.Len(workloads.Workloads, 1)
+	assert.Equal("reviews-v1", workloads.Workloads[0].Name)
+}
+
+func TestGetJSONReturnsThePeerErrors(t *testing.T) {
+	peer := setupPeer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+		_, _ = w.Write([]byte(`{"error": "The API token is restricted to the namespaces [travels]"}`))
+	})
+
+	err := peer.GetJSON("/api/namespaces/bookinfo/workloads", nil, &models.WorkloadList{})
+	peerErr, ok := err.(*PeerError)
+	if assert.True(t, ok) {
+		assert.Equal(t, http.StatusForbidden, peerErr.Code)
+		assert.Equal(t, "The API token is restricted to the namespaces [travels]", peerErr.Message)
+	}
+}
+
+func TestPeersAreDisabledWithTheFederation(t *testing.T) {
+	setupPeer(t, func(w http.ResponseWriter, r *http.Request) {})
+	conf := config.Get()
+	conf.Federation.Enabled = false
+	config.Set(conf)
+
+	assert.Empty(t, Peers())
+	assert.Nil(t, Peer("east"))
+}
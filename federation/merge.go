@@ -0,0 +1,52 @@
+package federation
+
+import (
+	"github.com/kiali/kiali/graph/config/cytoscape"
+	"github.com/kiali/kiali/models"
+)
+
+// MergeGraph adds the nodes and edges of the graph of a peer to the local graph. The node IDs are hashes of the node
+// attributes, including the cluster: a node found in both graphs is kept once. The edge IDs are only unique in a
+// graph, the edges of the peer are given new IDs.
+func MergeGraph(local *cytoscape.Config, cluster string, peer cytoscape.Config) {
+	nodes := make(map[string]bool, len(local.Elements.Nodes))
+	for _, node := range local.Elements.Nodes {
+		nodes[node.Data.ID] = true
+	}
+	edges := make(map[string]bool, len(local.Elements.Edges))
+	for _, edge := range local.Elements.Edges {
+		edges[edgeKey(edge.Data)] = true
+	}
+
+	for _, node := range peer.Elements.Nodes {
+		if !nodes[node.Data.ID] {
+			nodes[node.Data.ID] = true
+			local.Elements.Nodes = append(local.Elements.Nodes, node)
+		}
+	}
+	for _, edge := range peer.Elements.Edges {
+		if !edges[edgeKey(edge.Data)] {
+			edges[edgeKey(edge.Data)] = true
+			edge.Data.ID = cluster + "-" + edge.Data.ID
+			local.Elements.Edges = append(local.Elements.Edges, edge)
+		}
+	}
+
+	for _, warning := range peer.Warnings {
+		local.Warnings = append(local.Warnings, "Cluster "+cluster+": "+warning)
+	}
+}
+
+func edgeKey(edge *cytoscape.EdgeData) string {
+	return edge.Source + " " + edge.Target + " " + edge.Traffic.Protocol
+}
+
+// MergeWorkloads adds the workloads of a peer to the local list of workloads of the namespace
+func MergeWorkloads(local *models.WorkloadList, cluster string, peer models.WorkloadList) {
+	for _, workload := range peer.Workloads {
+		if workload.Cluster == "" {
+			workload.Cluster = cluster
+		}
+		local.Workloads = append(local.Workloads, workload)
+	}
+}
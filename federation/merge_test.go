@@ -0,0 +1,54 @@
+package federation
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/kiali/kiali/graph/config/cytoscape"
+	"github.com/kiali/kiali/models"
+)
+
+func graphConfig(nodes []string, edges ...[2]string) cytoscape.Config {
+	config := cytoscape.Config{}
+	for _, id := range nodes {
+		config.Elements.Nodes = append(config.Elements.Nodes, &cytoscape.NodeWrapper{Data: &cytoscape.NodeData{ID: id}})
+	}
+	for i, edge := range edges {
+		config.Elements.Edges = append(config.Elements.Edges, &cytoscape.EdgeWrapper{Data: &cytoscape.EdgeData{
+			ID:      "e" + string(rune('0'+i)),
+			Source:  edge[0],
+			Target:  edge[1],
+			Traffic: cytoscape.ProtocolTraffic{Protocol: "http"},
+		}})
+	}
+	return config
+}
+
+func TestMergeGraphKeepsTheSharedNodesOnce(t *testing.T) {
+	assert := assert.New(t)
+	local := graphConfig([]string{"gateway", "productpage"}, [2]string{"gateway", "productpage"})
+	peer := graphConfig([]string{"gateway", "productpage", "reviews"}, [2]string{"gateway", "productpage"}, [2]string{"productpage", "reviews"})
+	peer.Warnings = []string{"appender [deadNode] skipped"}
+
+	MergeGraph(&local, "east", peer)
+
+	assert.Len(local.Elements.Nodes, 3)
+	assert.Len(local.Elements.Edges, 2)
+	assert.Equal("e0", local.Elements.Edges[0].Data.ID)
+	assert.Equal("east-e1", local.Elements.Edges[1].Data.ID)
+	assert.Equal([]string{"Cluster east: appender [deadNode] skipped"}, local.Warnings)
+}
+
+func TestMergeWorkloadsSetsTheCluster(t *testing.T) {
+	local := models.WorkloadList{Workloads: []models.WorkloadListItem{{Name: "reviews-v1", Cluster: "west"}}}
+	peer := models.WorkloadList{Workloads: []models.WorkloadListItem{{Name: "reviews-v1"}, {Name: "reviews-v2", Cluster: "east"}}}
+
+	MergeWorkloads(&local, "east", peer)
+
+	assert.Equal(t, []models.WorkloadListItem{
+		{Name: "reviews-v1", Cluster: "west"},
+		{Name: "reviews-v1", Cluster: "east"},
+		{Name: "reviews-v2", Cluster: "east"},
+	}, local.Workloads)
+}
@@ -4,11 +4,14 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"time"
 
 	"github.com/kiali/kiali/business"
+	"github.com/kiali/kiali/config"
 	"github.com/kiali/kiali/graph"
 	"github.com/kiali/kiali/graph/config/cytoscape"
 	"github.com/kiali/kiali/graph/telemetry/istio"
+	"github.com/kiali/kiali/graph/telemetry/tracing"
 	"github.com/kiali/kiali/log"
 	"github.com/kiali/kiali/observability"
 	"github.com/kiali/kiali/prometheus"
@@ -31,6 +34,8 @@ func GraphNamespaces(ctx context.Context, business *business.Layer, o graph.Opti
 		prom, err := prometheus.NewClient()
 		graph.CheckError(err)
 		code, config = graphNamespacesIstio(ctx, business, prom, o)
+	case graph.VendorTracing:
+		code, config = graphNamespacesTracing(ctx, business, o)
 	default:
 		graph.Error(fmt.Sprintf("TelemetryVendor [%s] not supported", o.TelemetryVendor))
 	}
@@ -49,8 +54,28 @@ func graphNamespacesIstio(ctx context.Context, business *business.Layer, prom *p
 	globalInfo.Business = business
 	globalInfo.Context = ctx
 
+	// Bound the time spent querying Prometheus for this graph, the namespaces and appenders not queried in time
+	// are skipped with a warning
+	queryCtx, cancel := withQueryBudget(ctx)
+	defer cancel()
+	prom = prom.WithContext(queryCtx)
+	globalInfo.PromClient = prom
+
 	trafficMap := istio.BuildNamespacesTrafficMap(ctx, o.TelemetryOptions, prom, globalInfo)
-	code, config = generateGraph(trafficMap, o)
+	code, config = generateGraph(trafficMap, o, append(globalInfo.Warnings(), prom.Warnings()...))
+
+	return code, config
+}
+
+// graphNamespacesTracing builds the namespaces graph from sampled traces
+func graphNamespacesTracing(ctx context.Context, business *business.Layer, o graph.Options) (code int, config interface{}) {
+	// Create a 'global' object to store the business. Global only to the request.
+	globalInfo := graph.NewAppenderGlobalInfo()
+	globalInfo.Business = business
+	globalInfo.Context = ctx
+
+	trafficMap := tracing.BuildNamespacesTrafficMap(ctx, o.TelemetryOptions, globalInfo)
+	code, config = generateGraph(trafficMap, o, globalInfo.Warnings())
 
 	return code, config
 }
@@ -87,13 +112,28 @@ func graphNodeIstio(ctx context.Context, business *business.Layer, client *prome
 	globalInfo.Business = business
 	globalInfo.Context = ctx
 
+	queryCtx, cancel := withQueryBudget(ctx)
+	defer cancel()
+	client = client.WithContext(queryCtx)
+	globalInfo.PromClient = client
+
 	trafficMap, _ := istio.BuildNodeTrafficMap(o.TelemetryOptions, client, globalInfo)
-	code, config = generateGraph(trafficMap, o)
+	code, config = generateGraph(trafficMap, o, append(globalInfo.Warnings(), client.Warnings()...))
 
 	return code, config
 }
 
-func generateGraph(trafficMap graph.TrafficMap, o graph.Options) (int, interface{}) {
+// withQueryBudget returns the context bounded by the Prometheus query budget of a graph request, if configured
+func withQueryBudget(ctx context.Context) (context.Context, context.CancelFunc) {
+	if budget := config.Get().ExternalServices.Prometheus.QueryBudget; budget > 0 {
+		return context.WithTimeout(ctx, time.Duration(budget)*time.Second)
+	}
+	return context.WithCancel(ctx)
+}
+
+// generateGraph returns the graph in the requested config vendor format, with the warnings gathered while
+// building it (e.g. skipped namespaces, Prometheus warnings)
+func generateGraph(trafficMap graph.TrafficMap, o graph.Options, warnings []string) (int, interface{}) {
 	log.Tracef("Generating config for [%s] graph...", o.ConfigVendor)
 
 	promtimer := internalmetrics.GetGraphMarshalTimePrometheusTimer(o.GetGraphKind(), o.TelemetryOptions.GraphType, o.InjectServiceNodes)
@@ -102,7 +142,11 @@ func generateGraph(trafficMap graph.TrafficMap, o graph.Options) (int, interface
 	var vendorConfig interface{}
 	switch o.ConfigVendor {
 	case graph.VendorCytoscape:
-		vendorConfig = cytoscape.NewConfig(trafficMap, o.ConfigOptions)
+		cytoscapeConfig := cytoscape.NewConfig(trafficMap, o.ConfigOptions)
+		if len(warnings) > 0 {
+			cytoscapeConfig.Warnings = warnings
+		}
+		vendorConfig = cytoscapeConfig
 	default:
 		graph.Error(fmt.Sprintf("ConfigVendor [%s] not supported", o.ConfigVendor))
 	}
@@ -19,6 +19,18 @@ type AppenderGlobalInfo struct {
 	HomeCluster string
 	PromClient  *prometheus.Client
 	Vendor      AppenderVendorInfo // telemetry vendor's global info
+
+	warnings prometheus.QueryWarnings // parts of the graph skipped, returned with the graph
+}
+
+// AddWarning records a warning to be returned with the graph, e.g. a namespace skipped because its queries failed
+func (in *AppenderGlobalInfo) AddWarning(warning string) {
+	in.warnings.Add(warning)
+}
+
+// Warnings returns the warnings recorded while building the graph
+func (in *AppenderGlobalInfo) Warnings() []string {
+	return in.warnings.List()
 }
 
 // AppenderNamespaceInfo caches information relevant to a single namespace. It allows
@@ -129,12 +129,13 @@ type EdgeData struct {
 	Target string `json:"target"` // child node ID
 
 	// App Fields (not required by Cytoscape)
-	DestPrincipal   string          `json:"destPrincipal,omitempty"`   // principal used for the edge destination
-	IsMTLS          string          `json:"isMTLS,omitempty"`          // set to the percentage of traffic using a mutual TLS connection
-	ResponseTime    string          `json:"responseTime,omitempty"`    // in millis
-	SourcePrincipal string          `json:"sourcePrincipal,omitempty"` // principal used for the edge source
-	Throughput      string          `json:"throughput,omitempty"`      // in bytes/sec (request or response, depends on client request)
-	Traffic         ProtocolTraffic `json:"traffic,omitempty"`         // traffic rates for the edge protocol
+	DestPrincipal   string            `json:"destPrincipal,omitempty"`   // principal used for the edge destination
+	IsMTLS          string            `json:"isMTLS,omitempty"`          // set to the percentage of traffic using a mutual TLS connection
+	ResponseTime    string            `json:"responseTime,omitempty"`    // in millis
+	ResponseTimes   map[string]string `json:"responseTimes,omitempty"`   // in millis, by percentile (avg | 50 | 95 | 99)
+	SourcePrincipal string            `json:"sourcePrincipal,omitempty"` // principal used for the edge source
+	Throughput      string            `json:"throughput,omitempty"`      // in bytes/sec (request or response, depends on client request)
+	Traffic         ProtocolTraffic   `json:"traffic,omitempty"`         // traffic rates for the edge protocol
 }
 
 type NodeWrapper struct {
@@ -155,6 +156,7 @@ type Config struct {
 	Duration  int64    `json:"duration"`
 	GraphType string   `json:"graphType"`
 	Elements  Elements `json:"elements"`
+	Warnings  []string `json:"warnings,omitempty"` // e.g. namespaces or appenders skipped because their queries failed
 }
 
 func nodeHash(id string) string {
@@ -465,6 +467,12 @@ func addEdgeTelemetry(e *graph.Edge, ed *EdgeData) {
 		responseTime := val.(float64)
 		ed.ResponseTime = fmt.Sprintf("%.0f", responseTime)
 	}
+	if val, ok := e.Metadata[graph.ResponseTimes]; ok {
+		ed.ResponseTimes = make(map[string]string)
+		for percentile, responseTime := range val.(graph.ResponseTimesMetadata) {
+			ed.ResponseTimes[percentile] = fmt.Sprintf("%.0f", responseTime)
+		}
+	}
 	if val, ok := e.Metadata[graph.Throughput]; ok {
 		throughput := val.(float64)
 		ed.Throughput = fmt.Sprintf("%.0f", throughput)
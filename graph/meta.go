@@ -44,6 +44,7 @@ const (
 	Labels                MetadataKey = "labels"
 	ProtocolKey           MetadataKey = "protocol"
 	ResponseTime          MetadataKey = "responseTime"
+	ResponseTimes         MetadataKey = "responseTimes" // response time percentiles, when available (trace-based graphs)
 	SourcePrincipal       MetadataKey = "sourcePrincipal"
 	Throughput            MetadataKey = "throughput"
 )
@@ -62,6 +63,9 @@ func (dsm DestServicesMetadata) Add(key string, service ServiceName) DestService
 	return dsm
 }
 
+// ResponseTimesMetadata key=percentile (avg | 50 | 95 | 99), value=response time in millis
+type ResponseTimesMetadata map[string]float64
+
 type GatewaysMetadata map[string][]string
 type LabelsMetadata map[string]string
 type VirtualServicesMetadata map[string][]string
@@ -83,7 +83,7 @@ func (a AggregateNodeAppender) appendGraph(trafficMap graph.TrafficMap, namespac
 		int(duration.Seconds()), // range duration for the query
 		groupBy)
 	query := httpQuery
-	vector := promQuery(query, time.Unix(a.QueryTime, 0), client.GetContext(), client.API(), a)
+	vector := promQuery(query, time.Unix(a.QueryTime, 0), client, a)
 	a.injectAggregates(trafficMap, &vector)
 
 	// 2) query for requests originating from a workload inside of the namespace
@@ -94,7 +94,7 @@ func (a AggregateNodeAppender) appendGraph(trafficMap graph.TrafficMap, namespac
 		int(duration.Seconds()), // range duration for the query
 		groupBy)
 	query = httpQuery
-	vector = promQuery(query, time.Unix(a.QueryTime, 0), client.GetContext(), client.API(), a)
+	vector = promQuery(query, time.Unix(a.QueryTime, 0), client, a)
 	a.injectAggregates(trafficMap, &vector)
 }
 
@@ -119,7 +119,7 @@ func (a AggregateNodeAppender) appendNodeGraph(trafficMap graph.TrafficMap, name
 		int(duration.Seconds()), // range duration for the query
 		groupBy)
 	query := httpQuery
-	vector := promQuery(query, time.Unix(a.QueryTime, 0), client.GetContext(), client.API(), a)
+	vector := promQuery(query, time.Unix(a.QueryTime, 0), client, a)
 	a.injectAggregates(trafficMap, &vector)
 }
 
@@ -89,7 +89,7 @@ func (a ResponseTimeAppender) appendGraph(trafficMap graph.TrafficMap, namespace
 			namespace,
 			int(duration.Seconds()), // range duration for the query
 			groupBy)
-		incomingVector := promQuery(query, time.Unix(a.QueryTime, 0), client.GetContext(), client.API(), a)
+		incomingVector := promQuery(query, time.Unix(a.QueryTime, 0), client, a)
 		a.populateResponseTimeMap(responseTimeMap, &incomingVector)
 
 		// 2) Outgoing: query source telemetry to capture namespace workloads' outgoing traffic
@@ -102,7 +102,7 @@ func (a ResponseTimeAppender) appendGraph(trafficMap graph.TrafficMap, namespace
 			namespace,
 			int(duration.Seconds()), // range duration for the query
 			groupBy)
-		outgoingVector := promQuery(query, time.Unix(a.QueryTime, 0), client.GetContext(), client.API(), a)
+		outgoingVector := promQuery(query, time.Unix(a.QueryTime, 0), client, a)
 		a.populateResponseTimeMap(responseTimeMap, &outgoingVector)
 
 	} else {
@@ -120,7 +120,7 @@ func (a ResponseTimeAppender) appendGraph(trafficMap graph.TrafficMap, namespace
 			namespace,
 			int(duration.Seconds()), // range duration for the query
 			groupBy)
-		incomingVector := promQuery(query, time.Unix(a.QueryTime, 0), client.GetContext(), client.API(), a)
+		incomingVector := promQuery(query, time.Unix(a.QueryTime, 0), client, a)
 		a.populateResponseTimeMap(responseTimeMap, &incomingVector)
 
 		// 2) Outgoing: query source telemetry to capture namespace workloads' outgoing traffic
@@ -130,7 +130,7 @@ func (a ResponseTimeAppender) appendGraph(trafficMap graph.TrafficMap, namespace
 			namespace,
 			int(duration.Seconds()), // range duration for the query
 			groupBy)
-		outgoingVector := promQuery(query, time.Unix(a.QueryTime, 0), client.GetContext(), client.API(), a)
+		outgoingVector := promQuery(query, time.Unix(a.QueryTime, 0), client, a)
 		a.populateResponseTimeMap(responseTimeMap, &outgoingVector)
 	}
 
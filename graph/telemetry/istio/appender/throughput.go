@@ -86,7 +86,7 @@ func (a ThroughputAppender) appendGraph(trafficMap graph.TrafficMap, namespace s
 		namespace,
 		int(duration.Seconds()), // range duration for the query
 		groupBy)
-	vector := promQuery(query, time.Unix(a.QueryTime, 0), client.GetContext(), client.API(), a)
+	vector := promQuery(query, time.Unix(a.QueryTime, 0), client, a)
 	a.populateThroughputMap(throughputMap, &vector)
 
 	// 2) query for requests originating from a workload inside of the namespace
@@ -96,7 +96,7 @@ func (a ThroughputAppender) appendGraph(trafficMap graph.TrafficMap, namespace s
 		namespace,
 		int(duration.Seconds()), // range duration for the query
 		groupBy)
-	vector = promQuery(query, time.Unix(a.QueryTime, 0), client.GetContext(), client.API(), a)
+	vector = promQuery(query, time.Unix(a.QueryTime, 0), client, a)
 	a.populateThroughputMap(throughputMap, &vector)
 
 	applyThroughput(trafficMap, throughputMap)
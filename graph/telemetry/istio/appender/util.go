@@ -1,23 +1,22 @@
 package appender
 
 import (
-	"context"
 	"fmt"
 	"strings"
 	"time"
 
-	prom_v1 "github.com/prometheus/client_golang/api/prometheus/v1"
 	"github.com/prometheus/common/model"
 
 	"github.com/kiali/kiali/graph"
 	"github.com/kiali/kiali/graph/telemetry/istio/util"
 	"github.com/kiali/kiali/log"
+	"github.com/kiali/kiali/prometheus"
 	"github.com/kiali/kiali/prometheus/internalmetrics"
 )
 
 // package-private util functions (used by multiple files)
 
-func promQuery(query string, queryTime time.Time, ctx context.Context, api prom_v1.API, a graph.Appender) model.Vector {
+func promQuery(query string, queryTime time.Time, client *prometheus.Client, a graph.Appender) model.Vector {
 	if query == "" {
 		return model.Vector{}
 	}
@@ -30,9 +29,10 @@ func promQuery(query string, queryTime time.Time, ctx context.Context, api prom_
 	log.Tracef("Appender query:\n%s&time=%v (now=%v, %v)\n", query, queryTime.Format(graph.TF), time.Now().Format(graph.TF), queryTime.Unix())
 
 	promtimer := internalmetrics.GetPrometheusProcessingTimePrometheusTimer("Graph-Appender-" + a.Name())
-	value, warnings, err := api.Query(ctx, query, queryTime)
+	value, warnings, err := client.API().Query(client.GetContext(), query, queryTime)
 	if len(warnings) > 0 {
 		log.Warningf("promQuery. Prometheus Warnings: [%s]", strings.Join(warnings, ","))
+		client.AddWarnings(warnings...)
 	}
 	graph.CheckUnavailable(err)
 	promtimer.ObserveDuration() // notice we only collect metrics for successful prom queries
@@ -27,11 +27,11 @@ import (
 	"context"
 	"crypto/md5"
 	"fmt"
+	"net/http"
 	"regexp"
 	"strings"
 	"time"
 
-	prom_v1 "github.com/prometheus/client_golang/api/prometheus/v1"
 	"github.com/prometheus/common/model"
 
 	"github.com/kiali/kiali/graph"
@@ -64,9 +64,21 @@ func BuildNamespacesTrafficMap(ctx context.Context, o graph.TelemetryOptions, cl
 	appenders, finalizers := appender.ParseAppenders(o)
 	trafficMap := graph.NewTrafficMap()
 
+	// A namespace, or an appender, whose queries fail (e.g. time out) is skipped with a warning, so that a partial
+	// graph is returned. It's an error only when all the namespaces fail.
+	failure, skippedNamespaces := "", 0
 	for _, namespace := range o.Namespaces {
 		log.Tracef("Build traffic map for namespace [%v]", namespace)
-		namespaceTrafficMap := buildNamespaceTrafficMap(ctx, namespace.Name, o, client)
+		namespaceTrafficMap, skipped := buildNamespaceTrafficMapOrSkip(ctx, namespace.Name, o, client)
+		if skipped != "" {
+			log.Debugf("Namespace [%s] skipped from the graph: %s", namespace.Name, skipped)
+			globalInfo.AddWarning(fmt.Sprintf("namespace %s skipped: %s", namespace.Name, skipped))
+			if failure == "" {
+				failure = skipped
+			}
+			skippedNamespaces++
+			continue
+		}
 
 		// The appenders can add/remove/alter nodes for the namespace
 		namespaceInfo := graph.NewAppenderNamespaceInfo(namespace.Name)
@@ -77,7 +89,7 @@ func BuildNamespacesTrafficMap(ctx context.Context, o graph.TelemetryOptions, cl
 				observability.Attribute("namespace", namespace.Name),
 			)
 			appenderTimer := internalmetrics.GetGraphAppenderTimePrometheusTimer(a.Name())
-			a.AppendGraph(namespaceTrafficMap, globalInfo, namespaceInfo)
+			appendGraphOrSkip(a, namespaceTrafficMap, globalInfo, namespaceInfo)
 			appenderTimer.ObserveDuration()
 			appenderEnd()
 		}
@@ -85,10 +97,13 @@ func BuildNamespacesTrafficMap(ctx context.Context, o graph.TelemetryOptions, cl
 		// Merge this namespace into the final TrafficMap
 		telemetry.MergeTrafficMaps(trafficMap, namespace.Name, namespaceTrafficMap)
 	}
+	if skippedNamespaces > 0 && skippedNamespaces == len(o.Namespaces) {
+		graph.Panic(failure, http.StatusServiceUnavailable)
+	}
 
 	// The finalizers can perform final manipulations on the complete graph
 	for _, f := range finalizers {
-		f.AppendGraph(trafficMap, globalInfo, nil)
+		appendGraphOrSkip(f, trafficMap, globalInfo, nil)
 	}
 
 	if graph.GraphTypeService == o.GraphType {
@@ -98,6 +113,28 @@ func BuildNamespacesTrafficMap(ctx context.Context, o graph.TelemetryOptions, cl
 	return trafficMap
 }
 
+// buildNamespaceTrafficMapOrSkip returns the namespace traffic map, or the reason why the namespace is skipped
+// when its queries fail.
+func buildNamespaceTrafficMapOrSkip(ctx context.Context, namespace string, o graph.TelemetryOptions, client *prometheus.Client) (trafficMap graph.TrafficMap, skipped string) {
+	defer graph.SkipUnavailable(func(message string) {
+		trafficMap, skipped = nil, message
+	})
+	return buildNamespaceTrafficMap(ctx, namespace, o, client), ""
+}
+
+// appendGraphOrSkip runs the appender, skipping it with a warning when its queries fail. namespaceInfo is nil for
+// the finalizers.
+func appendGraphOrSkip(a graph.Appender, trafficMap graph.TrafficMap, globalInfo *graph.AppenderGlobalInfo, namespaceInfo *graph.AppenderNamespaceInfo) {
+	defer graph.SkipUnavailable(func(message string) {
+		if namespaceInfo == nil {
+			globalInfo.AddWarning(fmt.Sprintf("appender %s skipped: %s", a.Name(), message))
+			return
+		}
+		globalInfo.AddWarning(fmt.Sprintf("appender %s skipped for namespace %s: %s", a.Name(), namespaceInfo.Namespace, message))
+	})
+	a.AppendGraph(trafficMap, globalInfo, namespaceInfo)
+}
+
 // buildNamespaceTrafficMap returns a map of all namespace nodes (key=id).  All
 // nodes either directly send and/or receive requests from a node in the namespace.
 func buildNamespaceTrafficMap(ctx context.Context, namespace string, o graph.TelemetryOptions, client *prometheus.Client) graph.TrafficMap {
@@ -128,7 +165,7 @@ func buildNamespaceTrafficMap(ctx context.Context, namespace string, o graph.Tel
 			int(duration.Seconds()), // range duration for the query
 			groupBy,
 			idleCondition)
-		incomingVector := promQuery(query, time.Unix(o.QueryTime, 0), client.API())
+		incomingVector := promQuery(query, time.Unix(o.QueryTime, 0), client)
 		populateTrafficMap(trafficMap, &incomingVector, metric, o)
 
 		// 1) Incoming: query destination telemetry to capture namespace services' incoming traffic
@@ -138,7 +175,7 @@ func buildNamespaceTrafficMap(ctx context.Context, namespace string, o graph.Tel
 			int(duration.Seconds()), // range duration for the query
 			groupBy,
 			idleCondition)
-		incomingVector = promQuery(query, time.Unix(o.QueryTime, 0), client.API())
+		incomingVector = promQuery(query, time.Unix(o.QueryTime, 0), client)
 		populateTrafficMap(trafficMap, &incomingVector, metric, o)
 
 		// 2) Outgoing: query source telemetry to capture namespace workloads' outgoing traffic
@@ -148,7 +185,7 @@ func buildNamespaceTrafficMap(ctx context.Context, namespace string, o graph.Tel
 			int(duration.Seconds()), // range duration for the query
 			groupBy,
 			idleCondition)
-		outgoingVector := promQuery(query, time.Unix(o.QueryTime, 0), client.API())
+		outgoingVector := promQuery(query, time.Unix(o.QueryTime, 0), client)
 		populateTrafficMap(trafficMap, &outgoingVector, metric, o)
 	}
 
@@ -177,7 +214,7 @@ func buildNamespaceTrafficMap(ctx context.Context, namespace string, o graph.Tel
 				int(duration.Seconds()), // range duration for the query
 				groupBy,
 				idleCondition)
-			incomingVector := promQuery(query, time.Unix(o.QueryTime, 0), client.API())
+			incomingVector := promQuery(query, time.Unix(o.QueryTime, 0), client)
 			populateTrafficMap(trafficMap, &incomingVector, metric, o)
 
 			// 1) Incoming: query destination telemetry to capture namespace services' incoming traffic	query = fmt.Sprintf(`sum(rate(%s{reporter="destination",destination_service_namespace="%s"} [%vs])) by (%s) %s`,
@@ -187,7 +224,7 @@ func buildNamespaceTrafficMap(ctx context.Context, namespace string, o graph.Tel
 				int(duration.Seconds()), // range duration for the query
 				groupBy,
 				idleCondition)
-			incomingVector = promQuery(query, time.Unix(o.QueryTime, 0), client.API())
+			incomingVector = promQuery(query, time.Unix(o.QueryTime, 0), client)
 			populateTrafficMap(trafficMap, &incomingVector, metric, o)
 
 			// 2) Outgoing: query source telemetry to capture namespace workloads' outgoing traffic
@@ -197,7 +234,7 @@ func buildNamespaceTrafficMap(ctx context.Context, namespace string, o graph.Tel
 				int(duration.Seconds()), // range duration for the query
 				groupBy,
 				idleCondition)
-			outgoingVector := promQuery(query, time.Unix(o.QueryTime, 0), client.API())
+			outgoingVector := promQuery(query, time.Unix(o.QueryTime, 0), client)
 			populateTrafficMap(trafficMap, &outgoingVector, metric, o)
 		}
 	}
@@ -227,7 +264,7 @@ func buildNamespaceTrafficMap(ctx context.Context, namespace string, o graph.Tel
 				int(duration.Seconds()), // range duration for the query
 				groupBy,
 				idleCondition)
-			incomingVector := promQuery(query, time.Unix(o.QueryTime, 0), client.API())
+			incomingVector := promQuery(query, time.Unix(o.QueryTime, 0), client)
 			populateTrafficMap(trafficMap, &incomingVector, metric, o)
 
 			// 1) Incoming: query destination telemetry to capture namespace services' incoming traffic	query = fmt.Sprintf(`sum(rate(%s{reporter="destination",destination_service_namespace="%s"} [%vs])) by (%s) %s`,
@@ -237,7 +274,7 @@ func buildNamespaceTrafficMap(ctx context.Context, namespace string, o graph.Tel
 				int(duration.Seconds()), // range duration for the query
 				groupBy,
 				idleCondition)
-			incomingVector = promQuery(query, time.Unix(o.QueryTime, 0), client.API())
+			incomingVector = promQuery(query, time.Unix(o.QueryTime, 0), client)
 			populateTrafficMap(trafficMap, &incomingVector, metric, o)
 
 			// 2) Outgoing: query source telemetry to capture namespace workloads' outgoing traffic
@@ -247,7 +284,7 @@ func buildNamespaceTrafficMap(ctx context.Context, namespace string, o graph.Tel
 				int(duration.Seconds()), // range duration for the query
 				groupBy,
 				idleCondition)
-			outgoingVector := promQuery(query, time.Unix(o.QueryTime, 0), client.API())
+			outgoingVector := promQuery(query, time.Unix(o.QueryTime, 0), client)
 			populateTrafficMap(trafficMap, &outgoingVector, metric, o)
 		}
 	}
@@ -481,13 +518,13 @@ func BuildNodeTrafficMap(o graph.TelemetryOptions, client *prometheus.Client, gl
 
 	for _, a := range appenders {
 		appenderTimer := internalmetrics.GetGraphAppenderTimePrometheusTimer(a.Name())
-		a.AppendGraph(trafficMap, globalInfo, namespaceInfo)
+		appendGraphOrSkip(a, trafficMap, globalInfo, namespaceInfo)
 		appenderTimer.ObserveDuration()
 	}
 
 	// The finalizers can perform final manipulations on the complete graph
 	for _, f := range finalizers {
-		f.AppendGraph(trafficMap, globalInfo, nil)
+		appendGraphOrSkip(f, trafficMap, globalInfo, nil)
 	}
 
 	// Note that this is where we would call reduceToServiceGraph for graphTypeService but
@@ -566,7 +603,7 @@ func buildNodeTrafficMap(cluster, namespace string, n *graph.Node, o graph.Telem
 				int(duration.Seconds()), // range duration for the query
 				groupBy,
 				idleCondition)
-			vector := promQuery(query, time.Unix(o.QueryTime, 0), client.API())
+			vector := promQuery(query, time.Unix(o.QueryTime, 0), client)
 			populateTrafficMap(trafficMap, &vector, metric, o)
 
 			// 1.b) query dest telemetry for requests to the service, serviced by service workloads
@@ -582,7 +619,7 @@ func buildNodeTrafficMap(cluster, namespace string, n *graph.Node, o graph.Telem
 		default:
 			graph.Error(fmt.Sprintf("NodeType [%s] not supported", n.NodeType))
 		}
-		inVector := promQuery(query, time.Unix(o.QueryTime, 0), client.API())
+		inVector := promQuery(query, time.Unix(o.QueryTime, 0), client)
 		populateTrafficMap(trafficMap, &inVector, metric, o)
 
 		// 2) query for outbound traffic
@@ -622,7 +659,7 @@ func buildNodeTrafficMap(cluster, namespace string, n *graph.Node, o graph.Telem
 		default:
 			graph.Error(fmt.Sprintf("NodeType [%s] not supported", n.NodeType))
 		}
-		outVector := promQuery(query, time.Unix(o.QueryTime, 0), client.API())
+		outVector := promQuery(query, time.Unix(o.QueryTime, 0), client)
 		populateTrafficMap(trafficMap, &outVector, metric, o)
 	}
 
@@ -690,7 +727,7 @@ func buildNodeTrafficMap(cluster, namespace string, n *graph.Node, o graph.Telem
 			default:
 				graph.Error(fmt.Sprintf("NodeType [%s] not supported", n.NodeType))
 			}
-			incomingVector := promQuery(query, time.Unix(o.QueryTime, 0), client.API())
+			incomingVector := promQuery(query, time.Unix(o.QueryTime, 0), client)
 			populateTrafficMap(trafficMap, &incomingVector, metric, o)
 
 			// 2) query for outbound traffic
@@ -730,7 +767,7 @@ func buildNodeTrafficMap(cluster, namespace string, n *graph.Node, o graph.Telem
 			default:
 				graph.Error(fmt.Sprintf("NodeType [%s] not supported", n.NodeType))
 			}
-			outgoingVector := promQuery(query, time.Unix(o.QueryTime, 0), client.API())
+			outgoingVector := promQuery(query, time.Unix(o.QueryTime, 0), client)
 			populateTrafficMap(trafficMap, &outgoingVector, metric, o)
 		}
 	}
@@ -799,7 +836,7 @@ func buildNodeTrafficMap(cluster, namespace string, n *graph.Node, o graph.Telem
 			default:
 				graph.Error(fmt.Sprintf("NodeType [%s] not supported", n.NodeType))
 			}
-			incomingVector := promQuery(query, time.Unix(o.QueryTime, 0), client.API())
+			incomingVector := promQuery(query, time.Unix(o.QueryTime, 0), client)
 			populateTrafficMap(trafficMap, &incomingVector, metric, o)
 
 			// 2) query for outbound traffic
@@ -839,7 +876,7 @@ func buildNodeTrafficMap(cluster, namespace string, n *graph.Node, o graph.Telem
 			default:
 				graph.Error(fmt.Sprintf("NodeType [%s] not supported", n.NodeType))
 			}
-			outgoingVector := promQuery(query, time.Unix(o.QueryTime, 0), client.API())
+			outgoingVector := promQuery(query, time.Unix(o.QueryTime, 0), client)
 			populateTrafficMap(trafficMap, &outgoingVector, metric, o)
 		}
 	}
@@ -862,13 +899,13 @@ func handleAggregateNodeTrafficMap(o graph.TelemetryOptions, client *prometheus.
 
 	for _, a := range appenders {
 		appenderTimer := internalmetrics.GetGraphAppenderTimePrometheusTimer(a.Name())
-		a.AppendGraph(trafficMap, globalInfo, namespaceInfo)
+		appendGraphOrSkip(a, trafficMap, globalInfo, namespaceInfo)
 		appenderTimer.ObserveDuration()
 	}
 
 	// The finalizers can perform final manipulations on the complete graph
 	for _, f := range finalizers {
-		f.AppendGraph(trafficMap, globalInfo, nil)
+		appendGraphOrSkip(f, trafficMap, globalInfo, nil)
 	}
 
 	return trafficMap
@@ -909,33 +946,34 @@ func buildAggregateNodeTrafficMap(namespace string, n graph.Node, o graph.Teleme
 	query := fmt.Sprintf(`(%s) OR (%s)`, httpQuery, tcpQuery)
 	*/
 	query := httpQuery
-	vector := promQuery(query, time.Unix(o.QueryTime, 0), client.API())
+	vector := promQuery(query, time.Unix(o.QueryTime, 0), client)
 	populateTrafficMap(trafficMap, &vector, metric, o)
 
 	return trafficMap
 }
 
 // TODO: Can this be combined with graph.telemetry.istio.appender.promQuery?
-func promQuery(query string, queryTime time.Time, api prom_v1.API) model.Vector {
+func promQuery(query string, queryTime time.Time, client *prometheus.Client) model.Vector {
 	if query == "" {
 		return model.Vector{}
 	}
 
-	ctx, cancel := context.WithCancel(context.Background())
-	defer cancel()
-
 	// add scope if necessary
 	query = util.AddQueryScope(query)
 
+	// use recording rules when possible
+	query = client.PlanQuery(query)
+
 	// wrap with a round() to be in line with metrics api
 	query = fmt.Sprintf("round(%s,0.001)", query)
 	log.Tracef("Graph query:\n%s@time=%v (now=%v, %v)\n", query, queryTime.Format(graph.TF), time.Now().Format(graph.TF), queryTime.Unix())
 
 	promtimer := internalmetrics.GetPrometheusProcessingTimePrometheusTimer("Graph-Generation")
-	value, warnings, err := api.Query(ctx, query, queryTime)
+	value, warnings, err := client.API().Query(client.GetContext(), query, queryTime)
 
 	if len(warnings) > 0 {
 		log.Warningf("promQuery. Prometheus Warnings: [%s]", strings.Join(warnings, ","))
+		client.AddWarnings(warnings...)
 	}
 	graph.CheckUnavailable(err)
 	promtimer.ObserveDuration() // notice we only collect metrics for successful prom queries
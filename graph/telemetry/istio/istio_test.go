@@ -0,0 +1,135 @@
+package istio
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	prom_v1 "github.com/prometheus/client_golang/api/prometheus/v1"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kiali/kiali/config"
+	"github.com/kiali/kiali/graph"
+	"github.com/kiali/kiali/prometheus"
+)
+
+// partialAPI times out the queries about a namespace, and returns a request to reviews, with a warning, for the others
+type partialAPI struct {
+	prom_v1.API
+	failingNamespace string
+}
+
+func (a partialAPI) Query(ctx context.Context, query string, ts time.Time) (model.Value, prom_v1.Warnings, error) {
+	if strings.Contains(query, `"`+a.failingNamespace+`"`) {
+		return nil, nil, context.DeadlineExceeded
+	}
+	if !strings.Contains(query, `reporter="destination",destination_workload_namespace="bookinfo"`) {
+		return model.Vector{}, nil, nil
+	}
+	return model.Vector{{
+		Metric: model.Metric{
+			"source_cluster":                 "east",
+			"source_workload_namespace":      "bookinfo",
+			"source_workload":                "productpage-v1",
+			"source_canonical_service":       "productpage",
+			"source_canonical_revision":      "v1",
+			"destination_cluster":            "east",
+			"destination_service_namespace":  "bookinfo",
+			"destination_service":            "reviews.bookinfo.svc.cluster.local",
+			"destination_service_name":       "reviews",
+			"destination_workload_namespace": "bookinfo",
+			"destination_workload":           "reviews-v1",
+			"destination_canonical_service":  "reviews",
+			"destination_canonical_revision": "v1",
+			"request_protocol":               "http",
+			"response_code":                  "200",
+			"grpc_response_status":           "",
+			"response_flags":                 "-",
+		},
+		Value: 1,
+	}}, prom_v1.Warnings{"partial data"}, nil
+}
+
+func setupPartialGraph(t *testing.T, failingNamespace string) (graph.TelemetryOptions, *prometheus.Client) {
+	config.Set(config.NewConfig())
+	client, err := prometheus.NewClient()
+	require.NoError(t, err)
+	client.Inject(partialAPI{failingNamespace: failingNamespace})
+
+	o := graph.TelemetryOptions{
+		Namespaces: graph.NamespaceInfoMap{
+			"bookinfo": {Name: "bookinfo", Duration: 10 * time.Minute},
+			"tutorial": {Name: "tutorial", Duration: 10 * time.Minute},
+		},
+		Rates: graph.RequestedRates{Grpc: graph.RateRequests, Http: graph.RateRequests, Tcp: graph.RateSent},
+	}
+	o.GraphType = graph.GraphTypeWorkload
+	o.QueryTime = time.Now().Unix()
+	return o, client.WithContext(context.Background())
+}
+
+func TestBuildNamespacesTrafficMapSkipsFailedNamespaces(t *testing.T) {
+	assert := assert.New(t)
+	o, client := setupPartialGraph(t, "tutorial")
+	globalInfo := graph.NewAppenderGlobalInfo()
+
+	trafficMap := BuildNamespacesTrafficMap(context.Background(), o, client, globalInfo)
+
+	assert.Len(trafficMap, 2)
+	assert.Equal([]string{"namespace tutorial skipped: " + context.DeadlineExceeded.Error()}, globalInfo.Warnings())
+	assert.Equal([]string{"partial data"}, client.Warnings())
+}
+
+func TestBuildNamespacesTrafficMapFailsWithAllNamespaces(t *testing.T) {
+	o, client := setupPartialGraph(t, "tutorial")
+	delete(o.Namespaces, "bookinfo")
+
+	defer func() {
+		response, ok := recover().(graph.Response)
+		require.True(t, ok)
+		assert.Equal(t, http.StatusServiceUnavailable, response.Code)
+	}()
+	BuildNamespacesTrafficMap(context.Background(), o, client, graph.NewAppenderGlobalInfo())
+}
+
+// failingAppender panics as a failed Prometheus query, or with another panic
+type failingAppender struct {
+	unavailable bool
+}
+
+func (a failingAppender) AppendGraph(trafficMap graph.TrafficMap, globalInfo *graph.AppenderGlobalInfo, namespaceInfo *graph.AppenderNamespaceInfo) {
+	if a.unavailable {
+		graph.CheckUnavailable(context.DeadlineExceeded)
+	}
+	panic("unexpected")
+}
+
+func (a failingAppender) IsFinalizer() bool {
+	return false
+}
+
+func (a failingAppender) Name() string {
+	return "failing"
+}
+
+func TestAppendGraphOrSkip(t *testing.T) {
+	assert := assert.New(t)
+	trafficMap := graph.NewTrafficMap()
+	globalInfo := graph.NewAppenderGlobalInfo()
+
+	appendGraphOrSkip(failingAppender{unavailable: true}, trafficMap, globalInfo, graph.NewAppenderNamespaceInfo("bookinfo"))
+	appendGraphOrSkip(failingAppender{unavailable: true}, trafficMap, globalInfo, nil)
+	assert.Equal([]string{
+		"appender failing skipped for namespace bookinfo: " + context.DeadlineExceeded.Error(),
+		"appender failing skipped: " + context.DeadlineExceeded.Error(),
+	}, globalInfo.Warnings())
+
+	// Only the failed queries are skipped
+	assert.Panics(func() {
+		appendGraphOrSkip(failingAppender{}, trafficMap, globalInfo, nil)
+	})
+}
@@ -0,0 +1,416 @@
+// Package tracing provides a trace-based implementation of graph/TelemetryProvider.
+package tracing
+
+// Tracing.go is responsible for generating TrafficMaps from sampled traces. It is an alternative to the
+// Istio telemetry, useful for services not reporting Istio metrics (no sidecar, serverless callers, etc).
+//
+// The algorithm:
+//   Step 1) For each namespace:
+//     a) Fetch the sampled traces of every app of the namespace, for the requested time window.
+//
+//     b) For every span having a parent span emitted by a different node, add an edge from the parent
+//        node to the child node. Client spans without a traced child, but with a peer.service tag, add an
+//        edge to a service node for that peer (e.g. a database).
+//
+//   Step 2) Convert the sampled calls to rates and response times, and apply the finalizers.
+//
+// Note that rates are computed from the sampled calls only, they reflect the topology more than the real
+// traffic volume.
+//
+// Supports two vendor-specific query parameters:
+//   responseTime: Must be one of: avg | 50 | 95 | 99 (default: 95)
+//   traceLimit: Maximum number of traces fetched for each app (default: 100)
+//
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kiali/kiali/business"
+	"github.com/kiali/kiali/config"
+	"github.com/kiali/kiali/graph"
+	"github.com/kiali/kiali/graph/telemetry"
+	"github.com/kiali/kiali/graph/telemetry/istio/appender"
+	jaegerModels "github.com/kiali/kiali/jaeger/model/json"
+	"github.com/kiali/kiali/log"
+	"github.com/kiali/kiali/models"
+	"github.com/kiali/kiali/observability"
+	"github.com/kiali/kiali/util"
+)
+
+const (
+	defaultResponseTime = "95"
+	defaultTraceLimit   = 100
+	// maximum number of apps for which traces are fetched concurrently
+	fetchConcurrency = 5
+
+	spanDurations graph.MetadataKey = "spanDurations" // temporary, durations (ms) of the calls sampled for an edge
+)
+
+// BuildNamespacesTrafficMap is required by the graph/TelemetryVendor interface
+func BuildNamespacesTrafficMap(ctx context.Context, o graph.TelemetryOptions, globalInfo *graph.AppenderGlobalInfo) graph.TrafficMap {
+	var end observability.EndFunc
+	ctx, end = observability.StartSpan(ctx, "BuildNamespacesTrafficMap",
+		observability.Attribute("package", "tracing"),
+	)
+	defer end()
+
+	log.Tracef("Build [%s] trace graph for [%d] namespaces [%v]", o.GraphType, len(o.Namespaces), o.Namespaces)
+
+	if !config.Get().ExternalServices.Tracing.Enabled {
+		graph.BadRequest(fmt.Sprintf("TelemetryVendor [%s] requires tracing to be enabled", graph.VendorTracing))
+	}
+	if globalInfo.HomeCluster == "" {
+		globalInfo.HomeCluster = business.DefaultClusterID
+		c, err := globalInfo.Business.Mesh.ResolveKialiControlPlaneCluster(nil)
+		graph.CheckError(err)
+		if c != nil {
+			globalInfo.HomeCluster = c.Name
+		}
+	}
+
+	responseTime, traceLimit := parseParams(o)
+	trafficMap := graph.NewTrafficMap()
+
+	for _, namespace := range o.Namespaces {
+		log.Tracef("Build trace traffic map for namespace [%v]", namespace)
+		traces := fetchNamespaceTraces(ctx, namespace, o, traceLimit, globalInfo)
+		namespaceTrafficMap := BuildTrafficMap(traces, globalInfo.HomeCluster, namespace, o, responseTime)
+		telemetry.MergeTrafficMaps(trafficMap, namespace.Name, namespaceTrafficMap)
+	}
+
+	finalizers := []graph.Appender{
+		&appender.OutsiderAppender{
+			AccessibleNamespaces: o.AccessibleNamespaces,
+			Namespaces:           o.Namespaces,
+		},
+		&appender.TrafficGeneratorAppender{},
+	}
+	for _, f := range finalizers {
+		f.AppendGraph(trafficMap, globalInfo, nil)
+	}
+
+	if graph.GraphTypeService == o.GraphType {
+		trafficMap = telemetry.ReduceToServiceGraph(trafficMap)
+	}
+
+	return trafficMap
+}
+
+func parseParams(o graph.TelemetryOptions) (responseTime string, traceLimit int) {
+	responseTime = defaultResponseTime
+	if v := o.Params.Get("responseTime"); v != "" {
+		switch v {
+		case "avg", "50", "95", "99":
+			responseTime = v
+		default:
+			graph.BadRequest(fmt.Sprintf(`Invalid responseTime, must be one of: avg | 50 | 95 | 99: [%s]`, v))
+		}
+	}
+	traceLimit = defaultTraceLimit
+	if v := o.Params.Get("traceLimit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit <= 0 {
+			graph.BadRequest(fmt.Sprintf("Invalid traceLimit [%s]", v))
+		}
+		traceLimit = limit
+	}
+	return responseTime, traceLimit
+}
+
+// fetchNamespaceTraces returns the sampled traces of every app of the namespace, without duplicates
+func fetchNamespaceTraces(ctx context.Context, namespace graph.NamespaceInfo, o graph.TelemetryOptions, traceLimit int, globalInfo *graph.AppenderGlobalInfo) []jaegerModels.Trace {
+	appList, err := globalInfo.Business.App.GetAppList(ctx, business.AppCriteria{Namespace: namespace.Name, Cluster: globalInfo.HomeCluster})
+	graph.CheckError(err)
+
+	queryTime := time.Unix(o.QueryTime, 0)
+	query := models.TracingQuery{
+		Start: queryTime.Add(-namespace.Duration),
+		End:   queryTime,
+		Limit: traceLimit,
+		Tags:  map[string]string{},
+	}
+	for key, value := range config.Get().ExternalServices.Tracing.QueryScope {
+		query.Tags[key] = value
+	}
+
+	traces := []jaegerModels.Trace{}
+	seen := map[jaegerModels.TraceID]bool{}
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	semaphore := make(chan struct{}, fetchConcurrency)
+	for _, app := range appList.Apps {
+		wg.Add(1)
+		go func(app string) {
+			defer wg.Done()
+			semaphore <- struct{}{}
+			defer func() { <-semaphore }()
+			r, err := globalInfo.Business.Jaeger.GetAppTraces(namespace.Name, app, query)
+			if err != nil {
+				// Keep building the graph with the traces of the other apps
+				log.Errorf("Trace graph: error fetching traces of app [%s.%s]: %v", app, namespace.Name, err)
+				return
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			for _, trace := range r.Data {
+				if !seen[trace.TraceID] {
+					seen[trace.TraceID] = true
+					traces = append(traces, trace)
+				}
+			}
+		}(app.Name)
+	}
+	wg.Wait()
+	return traces
+}
+
+// BuildTrafficMap builds the traffic map of a namespace from its sampled traces. Each call between two spans
+// of different nodes counts as one request, rates are the sampled calls per second over the namespace duration.
+func BuildTrafficMap(traces []jaegerModels.Trace, cluster string, namespace graph.NamespaceInfo, o graph.TelemetryOptions, responseTime string) graph.TrafficMap {
+	trafficMap := graph.NewTrafficMap()
+	seconds := namespace.Duration.Seconds()
+	if seconds <= 0 {
+		seconds = 1
+	}
+	val := 1.0 / seconds
+
+	for i := range traces {
+		trace := &traces[i]
+		spans := make(map[jaegerModels.SpanID]*jaegerModels.Span, len(trace.Spans))
+		nodes := make(map[jaegerModels.SpanID]*graph.Node, len(trace.Spans))
+		hasChild := make(map[jaegerModels.SpanID]bool, len(trace.Spans))
+		for j := range trace.Spans {
+			span := &trace.Spans[j]
+			spans[span.SpanID] = span
+			process, ok := trace.Processes[span.ProcessID]
+			if !ok && span.Process != nil {
+				process = *span.Process
+			}
+			if node := addNode(trafficMap, cluster, span, &process, o); node != nil {
+				nodes[span.SpanID] = node
+			}
+		}
+
+		for j := range trace.Spans {
+			span := &trace.Spans[j]
+			child, ok := nodes[span.SpanID]
+			if !ok {
+				continue
+			}
+			parentID := business.SpanParentID(span)
+			parentSpan, ok := spans[parentID]
+			if !ok {
+				continue
+			}
+			hasChild[parentID] = true
+			parent, ok := nodes[parentSpan.SpanID]
+			if !ok || parent.ID == child.ID {
+				continue
+			}
+			addEdgeTraffic(parent, child, span, val)
+		}
+
+		// Leaf calls to untraced peers, such as databases
+		for j := range trace.Spans {
+			span := &trace.Spans[j]
+			if hasChild[span.SpanID] || tagString(span.Tags, "span.kind") != "client" {
+				continue
+			}
+			source, ok := nodes[span.SpanID]
+			if !ok {
+				continue
+			}
+			peer := tagString(span.Tags, "peer.service")
+			if peer == "" {
+				continue
+			}
+			dest, err := graph.NewNode(cluster, graph.Unknown, peer, "", "", "", "", o.GraphType)
+			if err != nil {
+				log.Warningf("Trace graph: skipping peer [%s]: %v", peer, err)
+				continue
+			}
+			if existing, found := trafficMap[dest.ID]; found {
+				dest = existing
+			} else {
+				trafficMap[dest.ID] = dest
+			}
+			addEdgeTraffic(source, dest, span, val)
+		}
+	}
+
+	applyResponseTimes(trafficMap, responseTime)
+	return trafficMap
+}
+
+// addNode adds, or returns the existing, node emitting the span. It returns nil when the span can't be identified.
+func addNode(trafficMap graph.TrafficMap, cluster string, span *jaegerModels.Span, process *jaegerModels.Process, o graph.TelemetryOptions) *graph.Node {
+	namespace, workload, app, version := spanIdentity(span, process, o)
+	if app == "" {
+		return nil
+	}
+	// without workload information, fall back to a service node named after the app
+	service := ""
+	if !graph.IsOK(workload) {
+		service = app
+	}
+	id, nodeType, err := graph.Id(cluster, namespace, service, namespace, workload, app, version, o.GraphType)
+	if err != nil {
+		log.Warningf("Trace graph: skipping span [%s]: %v", span.SpanID, err)
+		return nil
+	}
+	node, found := trafficMap[id]
+	if !found {
+		node = graph.NewNodeExplicit(id, cluster, namespace, workload, app, version, service, nodeType, o.GraphType)
+		trafficMap[id] = node
+	}
+	return node
+}
+
+// spanIdentity resolves the namespace, workload, app and version of the span emitter, from the Istio tags or from
+// the OpenTelemetry resource attributes. Unresolved values are set to unknown.
+func spanIdentity(span *jaegerModels.Span, process *jaegerModels.Process, o graph.TelemetryOptions) (namespace, workload, app, version string) {
+	lookup := func(keys ...string) string {
+		for _, key := range keys {
+			if v := tagString(span.Tags, key); v != "" {
+				return v
+			}
+			if v := tagString(process.Tags, key); v != "" {
+				return v
+			}
+		}
+		return ""
+	}
+
+	app = process.ServiceName
+	namespace = lookup("istio.namespace", "k8s.namespace.name")
+	if namespace == "" {
+		// Service names may be suffixed by the namespace (see the namespace_selector tracing option)
+		if i := strings.LastIndex(app, "."); i > 0 {
+			if _, ok := o.AccessibleNamespaces[app[i+1:]]; ok {
+				namespace = app[i+1:]
+			}
+		}
+	}
+	if namespace != "" {
+		app = strings.TrimSuffix(app, "."+namespace)
+	} else {
+		namespace = graph.Unknown
+	}
+	if canonical := lookup("istio.canonical_service"); canonical != "" {
+		app = canonical
+	}
+
+	workload = lookup("k8s.deployment.name", "k8s.statefulset.name", "k8s.daemonset.name", "k8s.job.name")
+	if workload == "" {
+		workload = graph.Unknown
+	}
+	version = lookup("istio.canonical_revision", "service.version")
+	if version == "" {
+		version = graph.Unknown
+	}
+	return namespace, workload, app, version
+}
+
+// addEdgeTraffic adds a sampled call to the edge between source and dest, creating the edge when needed
+func addEdgeTraffic(source, dest *graph.Node, span *jaegerModels.Span, val float64) {
+	protocol, code, flags := spanResponse(span)
+
+	var edge *graph.Edge
+	for _, e := range source.Edges {
+		if dest.ID == e.Dest.ID && e.Metadata[graph.ProtocolKey] == protocol {
+			edge = e
+			break
+		}
+	}
+	if edge == nil {
+		edge = source.AddEdge(dest)
+		edge.Metadata[graph.ProtocolKey] = protocol
+		edge.Metadata[spanDurations] = []float64{}
+	}
+	graph.AddToMetadata(protocol, val, code, flags, "", source.Metadata, dest.Metadata, edge.Metadata)
+	edge.Metadata[spanDurations] = append(edge.Metadata[spanDurations].([]float64), float64(span.Duration)/1000.0)
+}
+
+// spanResponse returns the protocol, response code and response flags of the call represented by the span
+func spanResponse(span *jaegerModels.Span) (protocol, code, flags string) {
+	isErr := tagString(span.Tags, "error") == "true"
+	flags = tagString(span.Tags, "response_flags")
+	if flags == "-" {
+		flags = ""
+	}
+
+	if grpcCode := firstTag(span.Tags, "rpc.grpc.status_code", "grpc.status_code"); grpcCode != "" || tagString(span.Tags, "rpc.system") == "grpc" {
+		if grpcCode == "" {
+			grpcCode = "0"
+			if isErr {
+				// UNKNOWN
+				grpcCode = "2"
+			}
+		}
+		return "grpc", grpcCode, flags
+	}
+
+	code = firstTag(span.Tags, "http.status_code", "http.response.status_code")
+	if code == "" {
+		code = "200"
+		if isErr {
+			// no response code reported for a failed call
+			code = "-"
+		}
+	}
+	return "http", code, flags
+}
+
+// applyResponseTimes sets the response time percentiles of every edge, from the sampled call durations
+func applyResponseTimes(trafficMap graph.TrafficMap, responseTime string) {
+	for _, n := range trafficMap {
+		for _, e := range n.Edges {
+			durations, ok := e.Metadata[spanDurations].([]float64)
+			if !ok {
+				continue
+			}
+			delete(e.Metadata, spanDurations)
+			sorted := util.SortedCopy(durations)
+			responseTimes := graph.ResponseTimesMetadata{
+				"avg": util.Average(sorted),
+				"50":  util.Percentile(sorted, 50),
+				"95":  util.Percentile(sorted, 95),
+				"99":  util.Percentile(sorted, 99),
+			}
+			e.Metadata[graph.ResponseTimes] = responseTimes
+			e.Metadata[graph.ResponseTime] = responseTimes[responseTime]
+		}
+	}
+}
+
+func firstTag(tags []jaegerModels.KeyValue, keys ...string) string {
+	for _, key := range keys {
+		if v := tagString(tags, key); v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// tagString returns the value of a tag as a string, empty if not found. Numeric values may have been decoded as
+// float64, they are formatted without decimals when integral.
+func tagString(tags []jaegerModels.KeyValue, key string) string {
+	for _, tag := range tags {
+		if tag.Key != key {
+			continue
+		}
+		switch v := tag.Value.(type) {
+		case string:
+			return v
+		case float64:
+			return strconv.FormatFloat(v, 'f', -1, 64)
+		default:
+			return fmt.Sprintf("%v", v)
+		}
+	}
+	return ""
+}
@@ -0,0 +1,98 @@
+package tracing
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kiali/kiali/graph"
+	jaegerModels "github.com/kiali/kiali/jaeger/model/json"
+)
+
+func stringTag(key, value string) jaegerModels.KeyValue {
+	return jaegerModels.KeyValue{Key: key, Type: jaegerModels.StringType, Value: value}
+}
+
+func childOf(parent jaegerModels.SpanID) []jaegerModels.Reference {
+	return []jaegerModels.Reference{{RefType: jaegerModels.ChildOf, SpanID: parent}}
+}
+
+// productpage (sidecar) -> reviews (sidecar) -> ratings (no sidecar, OTel SDK) -> mysql (untraced)
+func fakeTrace(id string, ratingsDuration uint64, ratingsErr bool) jaegerModels.Trace {
+	ratingsTags := []jaegerModels.KeyValue{stringTag("span.kind", "server")}
+	if ratingsErr {
+		ratingsTags = append(ratingsTags, jaegerModels.KeyValue{Key: "error", Type: jaegerModels.BoolType, Value: true}, jaegerModels.KeyValue{Key: "http.status_code", Type: jaegerModels.Int64Type, Value: float64(503)})
+	}
+	return jaegerModels.Trace{
+		TraceID: jaegerModels.TraceID(id),
+		Spans: []jaegerModels.Span{
+			{SpanID: "1", ProcessID: "p1", Duration: 50000, Tags: []jaegerModels.KeyValue{stringTag("istio.namespace", "bookinfo"), stringTag("istio.canonical_service", "productpage")}},
+			{SpanID: "2", ProcessID: "p2", Duration: 30000, References: childOf("1"), Tags: []jaegerModels.KeyValue{stringTag("istio.namespace", "bookinfo"), stringTag("istio.canonical_service", "reviews"), stringTag("http.status_code", "200")}},
+			{SpanID: "3", ProcessID: "p3", Duration: ratingsDuration, References: childOf("2"), Tags: ratingsTags},
+			{SpanID: "4", ProcessID: "p3", Duration: 1000, References: childOf("3"), Tags: []jaegerModels.KeyValue{stringTag("span.kind", "client"), stringTag("peer.service", "mysql")}},
+		},
+		Processes: map[jaegerModels.ProcessID]jaegerModels.Process{
+			"p1": {ServiceName: "productpage.bookinfo"},
+			"p2": {ServiceName: "reviews.bookinfo"},
+			"p3": {ServiceName: "ratings", Tags: []jaegerModels.KeyValue{stringTag("k8s.namespace.name", "bookinfo"), stringTag("k8s.deployment.name", "ratings-v1")}},
+		},
+	}
+}
+
+func TestBuildTrafficMap(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	o := graph.TelemetryOptions{
+		AccessibleNamespaces: map[string]time.Time{"bookinfo": {}},
+		CommonOptions:        graph.CommonOptions{GraphType: graph.GraphTypeWorkload},
+	}
+	namespace := graph.NamespaceInfo{Name: "bookinfo", Duration: 10 * time.Second}
+	traces := []jaegerModels.Trace{fakeTrace("a", 10000, false), fakeTrace("b", 20000, true)}
+
+	trafficMap := BuildTrafficMap(traces, "east", namespace, o, "50")
+	require.Len(trafficMap, 4)
+
+	productpage, ok := trafficMap["svc_east_bookinfo_productpage"]
+	require.True(ok)
+	reviews, ok := trafficMap["svc_east_bookinfo_reviews"]
+	require.True(ok)
+	ratings, ok := trafficMap["wl_east_bookinfo_ratings-v1"]
+	require.True(ok)
+	assert.Equal("ratings", ratings.App)
+	mysql, ok := trafficMap["svc_east_unknown_mysql"]
+	require.True(ok)
+
+	require.Len(productpage.Edges, 1)
+	assert.Equal(reviews, productpage.Edges[0].Dest)
+	assert.Equal(0.2, productpage.Edges[0].Metadata["http"])
+
+	require.Len(reviews.Edges, 1)
+	edge := reviews.Edges[0]
+	assert.Equal(ratings, edge.Dest)
+	assert.Equal("http", edge.Metadata[graph.ProtocolKey])
+	assert.Equal(0.2, edge.Metadata["http"])
+	assert.Equal(0.1, edge.Metadata["http5xx"])
+	assert.Contains(edge.Metadata["httpResponses"], "503")
+	assert.Equal(graph.ResponseTimesMetadata{"avg": 15.0, "50": 10.0, "95": 20.0, "99": 20.0}, edge.Metadata[graph.ResponseTimes])
+	assert.Equal(10.0, edge.Metadata[graph.ResponseTime])
+	assert.NotContains(edge.Metadata, spanDurations)
+
+	require.Len(ratings.Edges, 1)
+	assert.Equal(mysql, ratings.Edges[0].Dest)
+	assert.Empty(mysql.Edges)
+}
+
+func TestSpanResponse(t *testing.T) {
+	protocol, code, flags := spanResponse(&jaegerModels.Span{Tags: []jaegerModels.KeyValue{stringTag("rpc.system", "grpc"), {Key: "error", Value: true}}})
+	assert.Equal(t, "grpc", protocol)
+	assert.Equal(t, "2", code)
+	assert.Equal(t, "", flags)
+
+	protocol, code, flags = spanResponse(&jaegerModels.Span{Tags: []jaegerModels.KeyValue{{Key: "error", Value: true}, stringTag("response_flags", "UF")}})
+	assert.Equal(t, "http", protocol)
+	assert.Equal(t, "-", code)
+	assert.Equal(t, "UF", flags)
+}
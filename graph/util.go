@@ -46,6 +46,19 @@ func CheckUnavailable(err error) {
 	}
 }
 
+// SkipUnavailable must be deferred. It recovers from the panic of an unavailable backend (503), e.g. a failed or
+// timed out Prometheus query, and passes its message to skip, so that the caller can return a partial result.
+// Any other panic is propagated.
+func SkipUnavailable(skip func(message string)) {
+	if r := recover(); r != nil {
+		if response, ok := r.(Response); ok && response.Code == nethttp.StatusServiceUnavailable {
+			skip(response.Message)
+			return
+		}
+		panic(r)
+	}
+}
+
 // IsOK just validates that a telemetry label value is not empty or unknown
 func IsOK(telemetryVal string) bool {
 	return telemetryVal != "" && telemetryVal != Unknown
@@ -0,0 +1,164 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+
+	"github.com/kiali/kiali/audit"
+	"github.com/kiali/kiali/business"
+	"github.com/kiali/kiali/business/authentication"
+	"github.com/kiali/kiali/config"
+)
+
+// CreatedAPIToken is a new API token. The token is only returned once.
+type CreatedAPIToken struct {
+	authentication.APIToken
+	// The token to send as a bearer token in the Authorization header
+	Token string `json:"token"`
+}
+
+// requestNamespaces returns the namespaces named by the request, in its path or in its query
+func requestNamespaces(r *http.Request) []string {
+	namespaces := []string{}
+	if namespace := mux.Vars(r)["namespace"]; namespace != "" {
+		namespaces = append(namespaces, namespace)
+	}
+	query := r.URL.Query()
+	for _, namespace := range query["namespace"] {
+		if namespace != "" {
+			namespaces = append(namespaces, namespace)
+		}
+	}
+	for _, list := range query["namespaces"] {
+		for _, namespace := range strings.Split(list, ",") {
+			if namespace = strings.TrimSpace(namespace); namespace != "" {
+				namespaces = append(namespaces, namespace)
+			}
+		}
+	}
+	return namespaces
+}
+
+// apiTokenAllows returns true if the request only reaches the namespaces of the API token. A token restricted to
+// namespaces can't call the endpoints naming no namespace, e.g. the list of the namespaces.
+func apiTokenAllows(r *http.Request, token *authentication.APIToken) bool {
+	if len(token.Namespaces) == 0 {
+		return true
+	}
+	namespaces := requestNamespaces(r)
+	return len(namespaces) > 0 && token.AllowsNamespaces(namespaces...)
+}
+
+// getAPITokenManager returns the manager of the API tokens, or responds with an error when the API tokens are
+// disabled or when the request is itself authenticated with an API token: the tokens are managed by the users.
+func getAPITokenManager(w http.ResponseWriter, r *http.Request) *authentication.APITokenManager {
+	manager := authentication.GetAPITokenManager()
+	if manager == nil {
+		RespondWithError(w, http.StatusServiceUnavailable, "The API tokens are disabled")
+		return nil
+	}
+	if authentication.GetAPITokenContext(r.Context()) != nil {
+		RespondWithError(w, http.StatusForbidden, "The API tokens can't be managed with an API token")
+		return nil
+	}
+	return manager
+}
+
+// APITokens is the API handler to list the API tokens of the user
+func APITokens(w http.ResponseWriter, r *http.Request) {
+	manager := getAPITokenManager(w, r)
+	if manager == nil {
+		return
+	}
+	tokens, err := manager.ListTokens(r.Header.Get("Kiali-User"))
+	if err != nil {
+		RespondWithError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	RespondWithJSON(w, http.StatusOK, tokens)
+}
+
+// APITokenCreate is the API handler to create an API token, on behalf of the user
+func APITokenCreate(w http.ResponseWriter, r *http.Request) {
+	manager := getAPITokenManager(w, r)
+	if manager == nil {
+		return
+	}
+	username := r.Header.Get("Kiali-User")
+	if username == "" {
+		RespondWithError(w, http.StatusBadRequest, "The user of the session is unknown")
+		return
+	}
+
+	var request authentication.APITokenRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		RespondWithError(w, http.StatusBadRequest, "Invalid token request: "+err.Error())
+		return
+	}
+	if request.Name == "" {
+		RespondWithError(w, http.StatusBadRequest, "The name of the token is required")
+		return
+	}
+	if request.Role == "" {
+		request.Role = config.RoleViewer
+	}
+	if !config.IsValidRole(request.Role) {
+		RespondWithError(w, http.StatusBadRequest, "Invalid role: "+request.Role)
+		return
+	}
+	if !business.RoleAllows(getCapabilities(r).Role, request.Role) {
+		RespondWithError(w, http.StatusForbidden, fmt.Sprintf("The role %s is more privileged than the role of the user", request.Role))
+		return
+	}
+	maxExpiration := config.Get().Auth.APITokens.MaxExpiration
+	if request.ExpiresIn < 0 || request.ExpiresIn > maxExpiration {
+		RespondWithError(w, http.StatusBadRequest, fmt.Sprintf("The token lifetime must be between 1 and %d seconds", maxExpiration))
+		return
+	}
+
+	authInfo, err := getAuthInfo(r)
+	if err != nil {
+		RespondWithError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	token, created, err := manager.CreateToken(username, authInfo, request)
+	auditMutation(r, audit.Entry{Verb: "create", ObjectType: "apitokens", Object: request.Name}, err)
+	if err != nil {
+		RespondWithError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	RespondWithJSON(w, http.StatusCreated, CreatedAPIToken{APIToken: *created, Token: token})
+}
+
+// APITokenRevoke is the API handler to revoke an API token. The Kiali administrators can revoke the tokens of
+// the other users.
+func APITokenRevoke(w http.ResponseWriter, r *http.Request) {
+	manager := getAPITokenManager(w, r)
+	if manager == nil {
+		return
+	}
+	id := mux.Vars(r)["token"]
+	token, err := manager.GetToken(id)
+	if err != nil {
+		RespondWithError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if token == nil {
+		RespondWithError(w, http.StatusNotFound, "API token not found: "+id)
+		return
+	}
+	if token.Owner != r.Header.Get("Kiali-User") && !checkKialiAdmin(w, r) {
+		return
+	}
+	err = manager.RevokeToken(id)
+	auditMutation(r, audit.Entry{Verb: "revoke", ObjectType: "apitokens", Object: token.Name}, err)
+	if err != nil {
+		RespondWithError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	RespondWithCode(w, http.StatusNoContent)
+}
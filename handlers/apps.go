@@ -7,6 +7,7 @@ import (
 	"github.com/gorilla/mux"
 
 	"github.com/kiali/kiali/business"
+	"github.com/kiali/kiali/config"
 )
 
 // appParams holds the path and query parameters for appList and appDetails
@@ -101,3 +102,34 @@ func AppDetails(w http.ResponseWriter, r *http.Request) {
 
 	RespondWithJSON(w, http.StatusOK, appDetails)
 }
+
+// AppLogs is the API handler to fetch the logs of all the pods of an app, merged in timestamp order
+func AppLogs(w http.ResponseWriter, r *http.Request) {
+	if config.IsFeatureDisabled(config.FeatureLogView) {
+		RespondWithError(w, http.StatusForbidden, "Pod Logs access is disabled")
+		return
+	}
+	vars := mux.Vars(r)
+
+	// Get business layer
+	business, err := getBusiness(r)
+	if err != nil {
+		RespondWithError(w, http.StatusInternalServerError, "App Logs initialization error: "+err.Error())
+		return
+	}
+	cluster := clusterNameFromQuery(r.URL.Query())
+	namespace := vars["namespace"]
+	app := vars["app"]
+
+	opts, err := buildMultiPodLogOptions(business, r)
+	if err != nil {
+		RespondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	err = business.App.StreamAppLogs(r.Context(), cluster, namespace, app, opts, w)
+	if err != nil {
+		handleErrorResponse(w, err)
+		return
+	}
+}
@@ -0,0 +1,66 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/kiali/kiali/audit"
+	"github.com/kiali/kiali/config"
+)
+
+// auditMutation records a write operation done on behalf of the user of the request, when the audit log is enabled
+func auditMutation(r *http.Request, entry audit.Entry, err error) {
+	if !config.Get().Server.AuditLog {
+		return
+	}
+	entry.User = r.Header.Get("Kiali-User")
+	entry.Outcome = audit.OutcomeSuccess
+	if err != nil {
+		entry.Outcome = audit.OutcomeFailure
+		entry.Error = err.Error()
+	}
+	audit.Record(entry)
+}
+
+// AuditEntries is the API handler to query the recent audit records of the write operations. Each Kiali replica
+// only keeps the records of the operations it did.
+func AuditEntries(w http.ResponseWriter, r *http.Request) {
+	if !config.Get().Server.AuditLog {
+		RespondWithError(w, http.StatusServiceUnavailable, "The audit log is disabled")
+		return
+	}
+	if !checkKialiAdmin(w, r) {
+		return
+	}
+
+	params := r.URL.Query()
+	query := audit.Query{
+		Cluster:    params.Get("cluster"),
+		Namespace:  params.Get("namespace"),
+		Object:     params.Get("object"),
+		ObjectType: params.Get("objectType"),
+		Outcome:    params.Get("outcome"),
+		User:       params.Get("user"),
+		Verb:       params.Get("verb"),
+		Limit:      100,
+	}
+	if since := params.Get("since"); since != "" {
+		num, err := strconv.ParseInt(since, 10, 64)
+		if err != nil {
+			RespondWithError(w, http.StatusBadRequest, "Invalid since: "+err.Error())
+			return
+		}
+		query.Since = time.Unix(num, 0)
+	}
+	if limit := params.Get("limit"); limit != "" {
+		num, err := strconv.Atoi(limit)
+		if err != nil || num < 0 {
+			RespondWithError(w, http.StatusBadRequest, "Invalid limit: "+limit)
+			return
+		}
+		query.Limit = num
+	}
+
+	RespondWithJSON(w, http.StatusOK, audit.Recent(query))
+}
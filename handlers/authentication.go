@@ -3,6 +3,7 @@ package handlers
 import (
 	"fmt"
 	"net/http"
+	"strings"
 	"time"
 
 	"k8s.io/client-go/tools/clientcmd/api"
@@ -26,6 +27,17 @@ type AuthInfo struct {
 	LogoutRedirect        string      `json:"logoutRedirect,omitempty"`
 	SessionInfo           sessionInfo `json:"sessionInfo"`
 	SecretMissing         bool        `json:"secretMissing,omitempty"`
+	// The enabled strategies, in order of preference. The first one is also described by the fields above.
+	Providers []AuthProvider `json:"providers,omitempty"`
+}
+
+// AuthProvider describes how to log in with one of the enabled strategies. The login requests choose the
+// provider with the "provider" query parameter.
+type AuthProvider struct {
+	Strategy              string `json:"strategy"`
+	AuthorizationEndpoint string `json:"authorizationEndpoint,omitempty"`
+	LogoutEndpoint        string `json:"logoutEndpoint,omitempty"`
+	LogoutRedirect        string `json:"logoutRedirect,omitempty"`
 }
 
 type sessionInfo struct {
@@ -48,15 +60,33 @@ func (aHandler AuthenticationHandler) Handle(next http.Handler) http.Handler {
 		conf := config.Get()
 
 		var authInfo *api.AuthInfo
+		var groups []string
+		var apiToken *authentication.APIToken
+
+		// The user recorded in the audit log is set by the session validation, never by the client
+		r.Header.Del("Kiali-User")
 
 		switch conf.Auth.Strategy {
 		case config.AuthStrategyToken, config.AuthStrategyOpenId, config.AuthStrategyOpenshift, config.AuthStrategyHeader:
-			session, validateErr := authentication.GetAuthController().ValidateSession(r, w)
+			var session *authentication.UserSessionData
+			var validateErr error
+			if manager := authentication.GetAPITokenManager(); manager != nil && authentication.HasAPIToken(r) {
+				var tokenSession *authentication.APITokenSession
+				tokenSession, validateErr = manager.ValidateToken(r)
+				if tokenSession != nil {
+					session = &tokenSession.UserSessionData
+					apiToken = &tokenSession.Token
+				}
+			} else {
+				session, validateErr = authentication.GetAuthController().ValidateSession(r, w)
+			}
 			if validateErr != nil {
 				statusCode = http.StatusInternalServerError
 			} else if session != nil {
 				authInfo = session.AuthInfo
+				groups = session.Groups
 				statusCode = http.StatusOK
+				r.Header.Set("Kiali-User", session.Username)
 			} else {
 				statusCode = http.StatusUnauthorized
 			}
@@ -76,11 +106,23 @@ func (aHandler AuthenticationHandler) Handle(next http.Handler) http.Handler {
 				log.Errorf("No authInfo: %v", http.StatusBadRequest)
 			}
 			ctx := authentication.SetAuthInfoContext(r.Context(), authInfo)
+			ctx = business.SetRoleContext(ctx, business.RoleOfGroups(groups))
+			if apiToken != nil {
+				if !apiTokenAllows(r, apiToken) {
+					RespondWithError(w, http.StatusForbidden, "The API token is restricted to the namespaces "+strings.Join(apiToken.Namespaces, ","))
+					return
+				}
+				ctx = authentication.SetAPITokenContext(ctx, apiToken)
+				ctx = business.SetAPITokenRoleContext(ctx, apiToken.Role)
+			}
 			next.ServeHTTP(w, r.WithContext(ctx))
 		case http.StatusUnauthorized:
-			err := authentication.GetAuthController().TerminateSession(r, w)
-			if err != nil {
-				log.Errorf("Failed to clean a stale session: %s", err.Error())
+			// There is no session to clean for the requests with an API token
+			if !authentication.HasAPIToken(r) {
+				err := authentication.GetAuthController().TerminateSession(r, w)
+				if err != nil {
+					log.Errorf("Failed to clean a stale session: %s", err.Error())
+				}
 			}
 			http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
 		default:
@@ -129,48 +171,64 @@ func AuthenticationInfo(w http.ResponseWriter, r *http.Request) {
 
 	conf := config.Get()
 
+	for _, strategy := range conf.AuthStrategies() {
+		provider, message, err := getAuthProvider(r, strategy)
+		if err != nil {
+			RespondWithDetailedError(w, http.StatusInternalServerError, message, err.Error())
+			return
+		}
+		response.Providers = append(response.Providers, provider)
+	}
+
 	response.Strategy = conf.Auth.Strategy
+	response.AuthorizationEndpoint = response.Providers[0].AuthorizationEndpoint
+	response.LogoutEndpoint = response.Providers[0].LogoutEndpoint
+	response.LogoutRedirect = response.Providers[0].LogoutRedirect
 
-	switch conf.Auth.Strategy {
+	if conf.Auth.Strategy != config.AuthStrategyAnonymous {
+		session, _ := authentication.GetAuthController().ValidateSession(r, w)
+		if session != nil {
+			response.SessionInfo = sessionInfo{
+				ExpiresOn: session.ExpiresOn.Format(time.RFC1123Z),
+				Username:  session.Username,
+			}
+		}
+	}
+
+	RespondWithJSON(w, http.StatusOK, response)
+}
+
+// getAuthProvider returns how to log in with the strategy. On error, the failed step is also returned.
+func getAuthProvider(r *http.Request, strategy string) (AuthProvider, string, error) {
+	provider := AuthProvider{Strategy: strategy}
+
+	switch strategy {
 	case config.AuthStrategyOpenshift:
 		token, err := kubernetes.GetKialiTokenForHomeCluster()
 		if err != nil {
-			RespondWithDetailedError(w, http.StatusInternalServerError, "Error obtaining Kiali SA token", err.Error())
-			return
+			return provider, "Error obtaining Kiali SA token", err
 		}
 
 		layer, err := business.Get(&api.AuthInfo{Token: token})
 		if err != nil {
-			RespondWithDetailedError(w, http.StatusInternalServerError, "Error authenticating (getting business layer)", err.Error())
-			return
+			return provider, "Error authenticating (getting business layer)", err
 		}
 
 		metadata, err := layer.OpenshiftOAuth.Metadata(r)
 		if err != nil {
-			RespondWithDetailedError(w, http.StatusInternalServerError, "Error trying to get OAuth metadata", err.Error())
-			return
+			return provider, "Error trying to get OAuth metadata", err
 		}
 
-		response.AuthorizationEndpoint = metadata.AuthorizationEndpoint
-		response.LogoutEndpoint = metadata.LogoutEndpoint
-		response.LogoutRedirect = metadata.LogoutRedirect
+		provider.AuthorizationEndpoint = metadata.AuthorizationEndpoint
+		provider.LogoutEndpoint = metadata.LogoutEndpoint
+		provider.LogoutRedirect = metadata.LogoutRedirect
 	case config.AuthStrategyOpenId:
 		// Do the redirection through an intermediary own endpoint
-		response.AuthorizationEndpoint = fmt.Sprintf("%s/api/auth/openid_redirect",
+		provider.AuthorizationEndpoint = fmt.Sprintf("%s/api/auth/openid_redirect",
 			httputil.GuessKialiURL(r))
 	}
 
-	if conf.Auth.Strategy != config.AuthStrategyAnonymous {
-		session, _ := authentication.GetAuthController().ValidateSession(r, w)
-		if session != nil {
-			response.SessionInfo = sessionInfo{
-				ExpiresOn: session.ExpiresOn.Format(time.RFC1123Z),
-				Username:  session.Username,
-			}
-		}
-	}
-
-	RespondWithJSON(w, http.StatusOK, response)
+	return provider, "", nil
 }
 
 func Logout(w http.ResponseWriter, r *http.Request) {
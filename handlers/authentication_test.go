@@ -30,7 +30,7 @@ func TestStrategyTokenAuthentication(t *testing.T) {
 	cfg.LoginToken.SigningKey = util.RandomString(16)
 	config.Set(cfg)
 
-	authentication.InitializeAuthenticationController("token")
+	assert.NoError(t, authentication.InitializeAuthenticationController("token"))
 
 	clockTime := time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
 	util.Clock = util.ClockMock{Time: clockTime}
@@ -260,3 +260,22 @@ func mockK8s(t *testing.T, reject bool) {
 	}
 	business.SetupBusinessLayer(t, kubeClient, *config.Get())
 }
+
+// TestStrategyAnonymousIgnoresKialiUserHeader checks that the client can't choose the user recorded in the
+// audit log, even when the users aren't authenticated
+func TestStrategyAnonymousIgnoresKialiUserHeader(t *testing.T) {
+	cfg := config.NewConfig()
+	cfg.Auth.Strategy = config.AuthStrategyAnonymous
+	config.Set(cfg)
+
+	request := httptest.NewRequest(http.MethodPost, "http://kiali/api/namespaces/bookinfo/istio/virtualservices", nil)
+	request.Header.Set("Kiali-User", "admin")
+
+	called := false
+	handler := AuthenticationHandler{}.Handle(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+		assert.Empty(t, r.Header.Get("Kiali-User"))
+	}))
+	handler.ServeHTTP(httptest.NewRecorder(), request)
+	assert.True(t, called)
+}
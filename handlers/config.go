@@ -53,6 +53,7 @@ type PublicConfig struct {
 	AuthStrategy         string                      `json:"authStrategy,omitempty"`
 	AmbientEnabled       bool                        `json:"ambientEnabled,omitempty"`
 	ClusterInfo          ClusterInfo                 `json:"clusterInfo,omitempty"`
+	Capabilities         business.Capabilities       `json:"capabilities"`
 	Clusters             map[string]business.Cluster `json:"clusters,omitempty"`
 	Deployment           DeploymentConfig            `json:"deployment,omitempty"`
 	GatewayAPIEnabled    bool                        `json:"gatewayAPIEnabled,omitempty"`
@@ -81,6 +82,7 @@ func Config(w http.ResponseWriter, r *http.Request) {
 	publicConfig := PublicConfig{
 		AccessibleNamespaces: config.Deployment.AccessibleNamespaces,
 		AuthStrategy:         config.Auth.Strategy,
+		Capabilities:         getCapabilities(r),
 		Clusters:             make(map[string]business.Cluster),
 		Deployment: DeploymentConfig{
 			ViewOnlyMode: config.Deployment.ViewOnlyMode,
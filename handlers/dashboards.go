@@ -1,6 +1,10 @@
 package handlers
 
 import (
+	"encoding/json"
+	goerrors "errors"
+	"fmt"
+	"io"
 	"net/http"
 	"net/url"
 	"strings"
@@ -9,6 +13,7 @@ import (
 	"k8s.io/apimachinery/pkg/api/errors"
 
 	"github.com/kiali/kiali/business"
+	"github.com/kiali/kiali/config/dashboards"
 	"github.com/kiali/kiali/models"
 )
 
@@ -131,11 +136,12 @@ func AppDashboard(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	metrics, err := metricsService.GetMetrics(params, business.GetIstioScaler())
+	metrics, warnings, err := metricsService.GetMetricsWithWarnings(params, business.GetIstioScaler())
 	if err != nil {
 		RespondWithError(w, http.StatusServiceUnavailable, err.Error())
 		return
 	}
+	setWarningHeaders(w, warnings)
 	dashboard := business.NewDashboardsService(namespaceInfo, nil).BuildIstioDashboard(metrics, params.Direction)
 	RespondWithJSON(w, http.StatusOK, dashboard)
 }
@@ -181,11 +187,12 @@ func ServiceDashboard(w http.ResponseWriter, r *http.Request) {
 		params.Namespace = "unknown"
 	}
 
-	metrics, err := metricsService.GetMetrics(params, business.GetIstioScaler())
+	metrics, warnings, err := metricsService.GetMetricsWithWarnings(params, business.GetIstioScaler())
 	if err != nil {
 		RespondWithError(w, http.StatusServiceUnavailable, err.Error())
 		return
 	}
+	setWarningHeaders(w, warnings)
 	dashboard := business.NewDashboardsService(namespaceInfo, nil).BuildIstioDashboard(metrics, params.Direction)
 	RespondWithJSON(w, http.StatusOK, dashboard)
 }
@@ -209,11 +216,164 @@ func WorkloadDashboard(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	metrics, err := metricsService.GetMetrics(params, business.GetIstioScaler())
+	metrics, warnings, err := metricsService.GetMetricsWithWarnings(params, business.GetIstioScaler())
 	if err != nil {
 		RespondWithError(w, http.StatusServiceUnavailable, err.Error())
 		return
 	}
+	setWarningHeaders(w, warnings)
 	dashboard := business.NewDashboardsService(namespaceInfo, nil).BuildIstioDashboard(metrics, params.Direction)
 	RespondWithJSON(w, http.StatusOK, dashboard)
 }
+
+// MetricTemplates is the API handler to list the PromQL templates that can be run by MetricTemplate
+func MetricTemplates(w http.ResponseWriter, r *http.Request) {
+	svc := business.NewDashboardsService(nil, nil)
+	RespondWithJSON(w, http.StatusOK, svc.GetMetricTemplates())
+}
+
+// MetricTemplate is the API handler to run a PromQL template of the configuration in a namespace
+func MetricTemplate(w http.ResponseWriter, r *http.Request) {
+	queryParams := r.URL.Query()
+	pathParams := mux.Vars(r)
+	namespace := pathParams["namespace"]
+	templateName := pathParams["template"]
+
+	layer, err := getBusiness(r)
+	if err != nil {
+		RespondWithError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	// Check namespace access: the template is confined to this namespace
+	params := models.MetricTemplateQuery{Cluster: clusterNameFromQuery(queryParams), Namespace: namespace, Variables: map[string]string{}}
+	info, err := layer.Namespace.GetNamespaceByCluster(r.Context(), namespace, params.Cluster)
+	if err != nil {
+		RespondWithError(w, http.StatusForbidden, "Cannot access namespace data: "+err.Error())
+		return
+	}
+
+	params.RangeQuery.FillDefaults()
+	if err = extractBaseMetricsQueryParams(queryParams, &params.RangeQuery, info); err != nil {
+		RespondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	for _, variable := range []string{"app", "service", "version", "workload"} {
+		if value := queryParams.Get(variable); value != "" {
+			params.Variables[variable] = value
+		}
+	}
+
+	result, warnings, err := business.NewDashboardsService(info, nil).GetMetricTemplate(params, templateName)
+	if err != nil {
+		switch {
+		case errors.IsNotFound(err):
+			RespondWithError(w, http.StatusNotFound, err.Error())
+		case errors.IsBadRequest(err):
+			RespondWithError(w, http.StatusBadRequest, err.Error())
+		case errors.IsInternalError(err):
+			RespondWithError(w, http.StatusInternalServerError, err.Error())
+		default:
+			RespondWithError(w, http.StatusServiceUnavailable, err.Error())
+		}
+		return
+	}
+	setWarningHeaders(w, warnings)
+	RespondWithJSON(w, http.StatusOK, result)
+}
+
+// CustomDashboardGrafanaExport is the API handler to export a custom dashboard as a Grafana dashboard
+func CustomDashboardGrafanaExport(w http.ResponseWriter, r *http.Request) {
+	pathParams := mux.Vars(r)
+	namespace := pathParams["namespace"]
+	dashboardName := pathParams["dashboard"]
+
+	layer, err := getBusiness(r)
+	if err != nil {
+		RespondWithError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	// Check namespace access
+	info, err := layer.Namespace.GetNamespaceByCluster(r.Context(), namespace, clusterNameFromQuery(r.URL.Query()))
+	if err != nil {
+		RespondWithError(w, http.StatusForbidden, "Cannot access namespace data: "+err.Error())
+		return
+	}
+
+	svc := business.NewDashboardsService(info, nil)
+	if !svc.CustomEnabled {
+		RespondWithError(w, http.StatusServiceUnavailable, "Custom dashboards are disabled in config")
+		return
+	}
+
+	grafana, err := svc.ExportGrafanaDashboard(dashboardName)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			RespondWithError(w, http.StatusNotFound, err.Error())
+		} else {
+			RespondWithError(w, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+	RespondWithJSON(w, http.StatusOK, grafana)
+}
+
+// maxGrafanaDashboardSize is the largest Grafana dashboard JSON accepted by the import
+const maxGrafanaDashboardSize = 5 * 1024 * 1024
+
+// CustomDashboardGrafanaImport is the API handler to convert a Grafana dashboard to a MonitoringDashboard resource
+// of the namespace. The body is the Grafana dashboard JSON model, or the response of the Grafana dashboard API.
+func CustomDashboardGrafanaImport(w http.ResponseWriter, r *http.Request) {
+	queryParams := r.URL.Query()
+	namespace := mux.Vars(r)["namespace"]
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxGrafanaDashboardSize))
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if goerrors.As(err, &tooLarge) {
+			RespondWithError(w, http.StatusRequestEntityTooLarge, fmt.Sprintf("The Grafana dashboard is larger than %d bytes", tooLarge.Limit))
+			return
+		}
+		RespondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	var envelope struct {
+		Dashboard *dashboards.GrafanaDashboard `json:"dashboard"`
+	}
+	if err = json.Unmarshal(body, &envelope); err != nil {
+		RespondWithError(w, http.StatusBadRequest, "Invalid Grafana dashboard: "+err.Error())
+		return
+	}
+	var grafana dashboards.GrafanaDashboard
+	if envelope.Dashboard != nil {
+		grafana = *envelope.Dashboard
+	} else if err = json.Unmarshal(body, &grafana); err != nil {
+		RespondWithError(w, http.StatusBadRequest, "Invalid Grafana dashboard: "+err.Error())
+		return
+	}
+
+	layer, err := getBusiness(r)
+	if err != nil {
+		RespondWithError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	// Check namespace access
+	info, err := layer.Namespace.GetNamespaceByCluster(r.Context(), namespace, clusterNameFromQuery(queryParams))
+	if err != nil {
+		RespondWithError(w, http.StatusForbidden, "Cannot access namespace data: "+err.Error())
+		return
+	}
+
+	imported, err := business.NewDashboardsService(info, nil).ImportGrafanaDashboard(grafana, namespace, queryParams.Get("name"))
+	if err != nil {
+		if errors.IsBadRequest(err) {
+			RespondWithError(w, http.StatusBadRequest, err.Error())
+		} else {
+			RespondWithError(w, http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+	RespondWithJSON(w, http.StatusOK, imported)
+}
@@ -1,7 +1,10 @@
 package handlers
 
 import (
+	"net/http"
+	"net/http/httptest"
 	"net/url"
+	"strings"
 	"testing"
 	"time"
 
@@ -53,3 +56,11 @@ func TestExtractDashboardQueryParams(t *testing.T) {
 		DisplayName: "YY",
 	}, params.AdditionalLabels[1])
 }
+
+func TestCustomDashboardGrafanaImportRejectsLargeDashboards(t *testing.T) {
+	body := strings.NewReader(`{"title": "large"` + strings.Repeat(" ", maxGrafanaDashboardSize) + `}`)
+	request := httptest.NewRequest(http.MethodPost, "/api/namespaces/bookinfo/customdashboards/grafana", body)
+	response := httptest.NewRecorder()
+	CustomDashboardGrafanaImport(response, request)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, response.Code)
+}
@@ -0,0 +1,207 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+
+	api_errors "k8s.io/apimachinery/pkg/api/errors"
+
+	"github.com/kiali/kiali/config"
+	"github.com/kiali/kiali/federation"
+	"github.com/kiali/kiali/graph/config/cytoscape"
+	"github.com/kiali/kiali/log"
+	"github.com/kiali/kiali/models"
+	"github.com/kiali/kiali/util/httputil"
+)
+
+// CheckPeerRequest rejects the requests of the federated Kiali instances, marked by the federation header, sent
+// without the client certificate of a trusted peer.
+func CheckPeerRequest(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get(federation.FederatedRequestHeader) != "" && !httputil.HasClientCertificate(r, config.Get().Federation.TrustedPeers) {
+			RespondWithError(w, http.StatusForbidden, "The requests of the federated Kiali instances require the client certificate of a trusted peer")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// isFederating returns true if the peers must be queried for the request: the federation is enabled, and the
+// request doesn't come from a peer.
+func isFederating(r *http.Request) bool {
+	return config.Get().Federation.Enabled && r.Header.Get(federation.FederatedRequestHeader) == ""
+}
+
+// apiPath returns the path of the request relative to the web root, e.g. /api/namespaces/graph
+func apiPath(r *http.Request) string {
+	return strings.TrimPrefix(r.URL.Path, strings.TrimSuffix(config.Get().Server.WebRoot, "/"))
+}
+
+// peerAccessError is returned when the user can't read the namespaces of a request from a peer
+type peerAccessError struct {
+	msg string
+}
+
+func (e *peerAccessError) Error() string {
+	return e.msg
+}
+
+// localNamespaces returns the namespaces of the request, mapped to true when they exist in the home cluster, and to
+// false when they are missing there. The peers answer with their own credentials, so the access of the user is
+// checked in the home cluster: an error is returned if the user can't get one of the namespaces, or if the request
+// doesn't name any namespace.
+func localNamespaces(r *http.Request) (map[string]bool, error) {
+	namespaces := requestNamespaces(r)
+	if len(namespaces) == 0 {
+		return nil, &peerAccessError{msg: "The requests of the federated clusters must name their namespaces"}
+	}
+	businessLayer, err := getBusiness(r)
+	if err != nil {
+		return nil, err
+	}
+	local := make(map[string]bool, len(namespaces))
+	for _, namespace := range namespaces {
+		_, err := businessLayer.Namespace.GetNamespace(r.Context(), namespace)
+		if err != nil && !api_errors.IsNotFound(err) {
+			return nil, err
+		}
+		local[namespace] = err == nil
+	}
+	return local, nil
+}
+
+// checkPeerAccess returns an error if one of the namespaces missing in the home cluster isn't shared by the peer
+func checkPeerAccess(peer *federation.Client, local map[string]bool) error {
+	for namespace, found := range local {
+		if !found && !peer.SharesNamespace(namespace) {
+			return &peerAccessError{msg: fmt.Sprintf("The namespace [%s] is not shared by cluster [%s]", namespace, peer.Cluster())}
+		}
+	}
+	return nil
+}
+
+// forwardToPeer answers a request for a federated cluster with the response of the Kiali of that cluster. It returns
+// false when the requested cluster isn't federated, and the request must be answered locally.
+func forwardToPeer(w http.ResponseWriter, r *http.Request) bool {
+	if !isFederating(r) {
+		return false
+	}
+	peer := federation.Peer(r.URL.Query().Get("cluster"))
+	if peer == nil {
+		return false
+	}
+
+	local, err := localNamespaces(r)
+	if err == nil {
+		err = checkPeerAccess(peer, local)
+	}
+	var accessErr *peerAccessError
+	if errors.As(err, &accessErr) {
+		RespondWithError(w, http.StatusForbidden, err.Error())
+		return true
+	} else if err != nil {
+		handleErrorResponse(w, err)
+		return true
+	}
+
+	body, code, err := peer.Get(apiPath(r), r.URL.Query())
+	if err != nil {
+		log.Warning(err)
+		RespondWithError(w, http.StatusBadGateway, err.Error())
+		return true
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_, _ = w.Write(body)
+	return true
+}
+
+// queryPeers sends the request to every peer sharing its namespaces concurrently, and calls merge with the response
+// of each peer. The merge calls are serialized. The errors of the peers that couldn't answer, or that the user can't
+// read, are returned by cluster.
+func queryPeers(r *http.Request, newResult func() interface{}, merge func(cluster string, result interface{})) map[string]error {
+	peers := federation.Peers()
+	if !isFederating(r) || len(peers) == 0 {
+		return nil
+	}
+
+	errs := map[string]error{}
+	local, err := localNamespaces(r)
+	if err != nil {
+		for _, peer := range peers {
+			errs[peer.Cluster()] = err
+		}
+		return errs
+	}
+
+	var mutex sync.Mutex
+	wg := sync.WaitGroup{}
+	for _, peer := range peers {
+		if err := checkPeerAccess(peer, local); err != nil {
+			errs[peer.Cluster()] = err
+			continue
+		}
+		wg.Add(1)
+		go func(peer *federation.Client) {
+			defer wg.Done()
+			result := newResult()
+			err := peer.GetJSON(apiPath(r), r.URL.Query(), result)
+
+			mutex.Lock()
+			defer mutex.Unlock()
+			if err != nil {
+				errs[peer.Cluster()] = err
+				return
+			}
+			merge(peer.Cluster(), result)
+		}(peer)
+	}
+	wg.Wait()
+	return errs
+}
+
+// isPeerClientError returns true when a peer rejected the request, e.g. for a namespace that doesn't exist in its
+// cluster, or that its API token doesn't allow, or when the peer doesn't share the namespaces of the request.
+func isPeerClientError(err error) bool {
+	var peerErr *federation.PeerError
+	var accessErr *peerAccessError
+	return (errors.As(err, &peerErr) && peerErr.Code >= 400 && peerErr.Code < 500) || errors.As(err, &accessErr)
+}
+
+// mergePeerGraphs adds the graphs of the peers to the graph. The peers that couldn't be reached are reported as
+// warnings of the graph.
+func mergePeerGraphs(r *http.Request, graph *cytoscape.Config) {
+	errs := queryPeers(r, func() interface{} { return &cytoscape.Config{} }, func(cluster string, result interface{}) {
+		federation.MergeGraph(graph, cluster, *result.(*cytoscape.Config))
+	})
+	for cluster, err := range errs {
+		if isPeerClientError(err) {
+			log.Debugf("No graph from cluster [%s]: %v", cluster, err)
+			continue
+		}
+		log.Warning(err)
+		graph.Warnings = append(graph.Warnings, "The graph of cluster "+cluster+" is missing: "+err.Error())
+	}
+}
+
+// mergePeerWorkloads adds the workloads of the namespace in the clusters of the peers to the list. The peers that
+// couldn't be reached are reported as warnings of the list. It returns true if a peer answered.
+func mergePeerWorkloads(r *http.Request, workloads *models.WorkloadList) bool {
+	answered := false
+	errs := queryPeers(r, func() interface{} { return &models.WorkloadList{} }, func(cluster string, result interface{}) {
+		answered = true
+		federation.MergeWorkloads(workloads, cluster, *result.(*models.WorkloadList))
+	})
+	for cluster, err := range errs {
+		if isPeerClientError(err) {
+			log.Debugf("No workloads from cluster [%s]: %v", cluster, err)
+			continue
+		}
+		log.Warning(err)
+		workloads.Warnings = append(workloads.Warnings, "The workloads of cluster "+cluster+" are missing: "+err.Error())
+	}
+	return answered
+}
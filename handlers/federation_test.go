@@ -0,0 +1,164 @@
+package handlers
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	core_v1 "k8s.io/api/core/v1"
+	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/clientcmd/api"
+
+	"github.com/kiali/kiali/business"
+	"github.com/kiali/kiali/business/authentication"
+	"github.com/kiali/kiali/config"
+	"github.com/kiali/kiali/federation"
+	"github.com/kiali/kiali/kubernetes/kubetest"
+	"github.com/kiali/kiali/models"
+	"github.com/kiali/kiali/util"
+)
+
+func setupFederation(t *testing.T) {
+	peer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/kiali/api/namespaces/bookinfo/health" && r.URL.Query().Get("type") == "workload":
+			_, _ = w.Write([]byte(`{"reviews-v1": {}}`))
+		case r.URL.Path == "/kiali/api/namespaces/tutorial/workloads":
+			_, _ = w.Write([]byte(`{"namespace": {"name": "tutorial"}, "workloads": [{"name": "customer-v1"}]}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(peer.Close)
+
+	conf := config.NewConfig()
+	conf.Server.WebRoot = "/kiali"
+	conf.Federation.Enabled = true
+	conf.Federation.Peers = []config.FederationPeer{{Cluster: "east", URL: peer.URL + "/kiali", Namespaces: []string{"tutorial"}}}
+	conf.Federation.TrustedPeers = []string{"kiali-west"}
+	config.Set(conf)
+	t.Cleanup(func() {
+		config.Set(config.NewConfig())
+	})
+}
+
+// federatedRequest returns a request of a user of this Kiali, routed to the namespace
+func federatedRequest(target, namespace string) *http.Request {
+	request := httptest.NewRequest(http.MethodGet, target, nil)
+	request = mux.SetURLVars(request, map[string]string{"namespace": namespace})
+	return request.WithContext(authentication.SetAuthInfoContext(request.Context(), &api.AuthInfo{Token: "test"}))
+}
+
+func TestRequestsOfFederatedClustersAreForwarded(t *testing.T) {
+	assert := assert.New(t)
+	setupFederation(t)
+	k8s := kubetest.NewFakeK8sClient(&core_v1.Namespace{ObjectMeta: meta_v1.ObjectMeta{Name: "bookinfo"}})
+	business.SetupBusinessLayer(t, k8s, *config.Get())
+
+	request := federatedRequest("/kiali/api/namespaces/bookinfo/health?cluster=east&type=workload", "bookinfo")
+	response := httptest.NewRecorder()
+	assert.True(forwardToPeer(response, request))
+	assert.Equal(http.StatusOK, response.Code)
+	assert.JSONEq(`{"reviews-v1": {}}`, response.Body.String())
+
+	// The other clusters are answered locally
+	request = federatedRequest("/kiali/api/namespaces/bookinfo/health?cluster=west&type=workload", "bookinfo")
+	assert.False(forwardToPeer(httptest.NewRecorder(), request))
+
+	// The requests of a peer are never forwarded again
+	request = federatedRequest("/kiali/api/namespaces/bookinfo/health?cluster=east&type=workload", "bookinfo")
+	request.Header.Set(federation.FederatedRequestHeader, "true")
+	assert.False(forwardToPeer(httptest.NewRecorder(), request))
+}
+
+func TestRequestsOfFederatedClustersRequireNamespaceAccess(t *testing.T) {
+	setupFederation(t)
+	k8s := kubetest.NewFakeK8sClient(&core_v1.Namespace{ObjectMeta: meta_v1.ObjectMeta{Name: "bookinfo"}})
+	business.SetupBusinessLayer(t, &noPrivClient{k8s}, *config.Get())
+
+	request := federatedRequest("/kiali/api/namespaces/bookinfo/health?cluster=east&type=workload", "bookinfo")
+	response := httptest.NewRecorder()
+	assert.True(t, forwardToPeer(response, request))
+	assert.NotEqual(t, http.StatusOK, response.Code)
+	assert.NotContains(t, response.Body.String(), "reviews-v1")
+}
+
+func TestRequestsOfFederatedClustersRequireNamespaces(t *testing.T) {
+	setupFederation(t)
+	k8s := kubetest.NewFakeK8sClient(&core_v1.Namespace{ObjectMeta: meta_v1.ObjectMeta{Name: "bookinfo"}})
+	business.SetupBusinessLayer(t, k8s, *config.Get())
+
+	// The requests naming no namespace can't be checked in the home cluster
+	response := httptest.NewRecorder()
+	assert.True(t, forwardToPeer(response, federatedRequest("/kiali/api/istio/validations?cluster=east", "")))
+	assert.Equal(t, http.StatusForbidden, response.Code)
+
+	// The namespaces missing in the home cluster are only read from the peers sharing them
+	response = httptest.NewRecorder()
+	assert.True(t, forwardToPeer(response, federatedRequest("/kiali/api/namespaces/travels/health?cluster=east&type=workload", "travels")))
+	assert.Equal(t, http.StatusForbidden, response.Code)
+
+	util.Clock = util.RealClock{}
+	response = httptest.NewRecorder()
+	WorkloadList(response, federatedRequest("/kiali/api/namespaces/travels/workloads", "travels"))
+	assert.Equal(t, http.StatusNotFound, response.Code)
+}
+
+func TestWorkloadsOfNamespaceMissingLocally(t *testing.T) {
+	setupFederation(t)
+	k8s := kubetest.NewFakeK8sClient(&core_v1.Namespace{ObjectMeta: meta_v1.ObjectMeta{Name: "bookinfo"}})
+	business.SetupBusinessLayer(t, k8s, *config.Get())
+	util.Clock = util.RealClock{}
+
+	response := httptest.NewRecorder()
+	WorkloadList(response, federatedRequest("/kiali/api/namespaces/tutorial/workloads", "tutorial"))
+	require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+
+	workloads := models.WorkloadList{}
+	require.NoError(t, json.Unmarshal(response.Body.Bytes(), &workloads))
+	require.Len(t, workloads.Workloads, 1)
+	assert.Equal(t, "customer-v1", workloads.Workloads[0].Name)
+	assert.Equal(t, "east", workloads.Workloads[0].Cluster)
+	assert.Empty(t, workloads.Warnings)
+
+	// The peers that can't be reached are reported
+	cfg := config.Get()
+	cfg.Federation.Peers = append(cfg.Federation.Peers, config.FederationPeer{Cluster: "south", URL: "http://127.0.0.1:1/kiali", Namespaces: []string{"tutorial"}})
+	config.Set(cfg)
+	response = httptest.NewRecorder()
+	WorkloadList(response, federatedRequest("/kiali/api/namespaces/tutorial/workloads", "tutorial"))
+	require.Equal(t, http.StatusOK, response.Code, response.Body.String())
+	require.NoError(t, json.Unmarshal(response.Body.Bytes(), &workloads))
+	require.Len(t, workloads.Warnings, 1)
+	assert.Contains(t, workloads.Warnings[0], "The workloads of cluster south are missing")
+}
+
+func TestRequestsOfPeersRequireTrustedCertificate(t *testing.T) {
+	setupFederation(t)
+	handler := CheckPeerRequest(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	// The requests of the users don't need a certificate
+	response := httptest.NewRecorder()
+	handler.ServeHTTP(response, httptest.NewRequest(http.MethodGet, "/kiali/api/namespaces/bookinfo/health", nil))
+	assert.Equal(t, http.StatusOK, response.Code)
+
+	for cn, code := range map[string]int{"": http.StatusForbidden, "browser": http.StatusForbidden, "kiali-west": http.StatusOK} {
+		request := httptest.NewRequest(http.MethodGet, "/kiali/api/namespaces/bookinfo/health", nil)
+		request.Header.Set(federation.FederatedRequestHeader, "true")
+		if cn != "" {
+			request.TLS = &tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{{Subject: pkix.Name{CommonName: cn}}}}}
+		}
+		response = httptest.NewRecorder()
+		handler.ServeHTTP(response, request)
+		assert.Equal(t, code, response.Code, cn)
+	}
+}
@@ -36,6 +36,7 @@ import (
 
 	"github.com/kiali/kiali/graph"
 	"github.com/kiali/kiali/graph/api"
+	"github.com/kiali/kiali/graph/config/cytoscape"
 	"github.com/kiali/kiali/log"
 )
 
@@ -49,6 +50,10 @@ func GraphNamespaces(w http.ResponseWriter, r *http.Request) {
 	graph.CheckError(err)
 
 	code, payload := api.GraphNamespaces(r.Context(), business, o)
+	if graphConfig, ok := payload.(cytoscape.Config); ok && code == http.StatusOK {
+		mergePeerGraphs(r, &graphConfig)
+		payload = graphConfig
+	}
 	respond(w, code, payload)
 }
 
@@ -17,6 +17,10 @@ const defaultHealthRateInterval = "10m"
 
 // NamespaceHealth is the API handler to get app-based health of every services in the given namespace
 func NamespaceHealth(w http.ResponseWriter, r *http.Request) {
+	if forwardToPeer(w, r) {
+		return
+	}
+
 	// Get business layer
 	businessLayer, err := getBusiness(r)
 	if err != nil {
@@ -2,16 +2,18 @@ package handlers
 
 import (
 	"context"
+	"encoding/json"
 	"io"
 	"net/http"
 	"strings"
 	"sync"
 
 	"github.com/gorilla/mux"
+	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
+	"github.com/kiali/kiali/audit"
 	"github.com/kiali/kiali/business"
 	"github.com/kiali/kiali/config"
-	"github.com/kiali/kiali/log"
 	"github.com/kiali/kiali/models"
 )
 
@@ -216,6 +218,9 @@ func IstioConfigDelete(w http.ResponseWriter, r *http.Request) {
 		RespondWithError(w, http.StatusBadRequest, "Object type not managed: "+objectType)
 		return
 	}
+	if !checkCapability(w, getCapabilities(r).CanEditIstioConfig(objectType), "Changing the "+objectType) {
+		return
+	}
 
 	// Get business layer
 	business, err := getBusiness(r)
@@ -224,11 +229,11 @@ func IstioConfigDelete(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	err = business.IstioConfig.DeleteIstioConfigDetail(cluster, namespace, objectType, object)
+	auditMutation(r, audit.Entry{Verb: "delete", Cluster: cluster, Namespace: namespace, ObjectType: objectType, Object: object}, err)
 	if err != nil {
 		handleErrorResponse(w, err)
 		return
 	} else {
-		audit(r, "DELETE on Namespace: "+namespace+" Type: "+objectType+" Name: "+object)
 		RespondWithCode(w, http.StatusOK)
 	}
 }
@@ -246,6 +251,9 @@ func IstioConfigUpdate(w http.ResponseWriter, r *http.Request) {
 		RespondWithError(w, http.StatusBadRequest, "Object type not managed: "+objectType)
 		return
 	}
+	if !checkCapability(w, getCapabilities(r).CanEditIstioConfig(objectType), "Changing the "+objectType) {
+		return
+	}
 
 	// Get business layer
 	business, err := getBusiness(r)
@@ -260,13 +268,13 @@ func IstioConfigUpdate(w http.ResponseWriter, r *http.Request) {
 	}
 	jsonPatch := string(body)
 	updatedConfigDetails, err := business.IstioConfig.UpdateIstioConfigDetail(cluster, namespace, objectType, object, jsonPatch)
+	auditMutation(r, audit.Entry{Verb: "update", Cluster: cluster, Namespace: namespace, ObjectType: objectType, Object: object, Body: jsonPatch}, err)
 
 	if err != nil {
 		handleErrorResponse(w, err)
 		return
 	}
 
-	audit(r, "UPDATE on Namespace: "+namespace+" Type: "+objectType+" Name: "+object+" Patch: "+jsonPatch)
 	RespondWithJSON(w, http.StatusOK, updatedConfigDetails)
 }
 
@@ -283,6 +291,9 @@ func IstioConfigCreate(w http.ResponseWriter, r *http.Request) {
 		RespondWithError(w, http.StatusBadRequest, "Object type not managed: "+objectType)
 		return
 	}
+	if !checkCapability(w, getCapabilities(r).CanEditIstioConfig(objectType), "Changing the "+objectType) {
+		return
+	}
 
 	// Get business layer
 	business, err := getBusiness(r)
@@ -297,24 +308,26 @@ func IstioConfigCreate(w http.ResponseWriter, r *http.Request) {
 	}
 
 	createdConfigDetails, err := business.IstioConfig.CreateIstioConfigDetail(cluster, namespace, objectType, body)
+	auditMutation(r, audit.Entry{Verb: "create", Cluster: cluster, Namespace: namespace, ObjectType: objectType, Object: createdObjectName(body), Body: string(body)}, err)
 	if err != nil {
 		handleErrorResponse(w, err)
 		return
 	}
 
-	audit(r, "CREATE on Namespace: "+namespace+" Type: "+objectType+" Object: "+string(body))
 	RespondWithJSON(w, http.StatusOK, createdConfigDetails)
 }
 
-func checkObjectType(objectType string) bool {
-	return business.GetIstioAPI(objectType)
+// createdObjectName returns the name of the object in the body of a create request
+func createdObjectName(body []byte) string {
+	object := meta_v1.PartialObjectMetadata{}
+	if err := json.Unmarshal(body, &object); err != nil {
+		return ""
+	}
+	return object.Name
 }
 
-func audit(r *http.Request, message string) {
-	if config.Get().Server.AuditLog {
-		user := r.Header.Get("Kiali-User")
-		log.Infof("AUDIT User [%s] Msg [%s]", user, message)
-	}
+func checkObjectType(objectType string) bool {
+	return business.GetIstioAPI(objectType)
 }
 
 func IstioConfigPermissions(w http.ResponseWriter, r *http.Request) {
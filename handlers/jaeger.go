@@ -5,7 +5,9 @@ import (
 	"fmt"
 	"net/http"
 	"net/url"
+	"regexp"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gorilla/mux"
@@ -22,6 +24,7 @@ func GetJaegerInfo(w http.ResponseWriter, r *http.Request) {
 		info = models.JaegerInfo{
 			Enabled:              true,
 			Integration:          jaegerConfig.InClusterURL != "",
+			Provider:             jaegerConfig.Provider,
 			URL:                  jaegerConfig.URL,
 			NamespaceSelector:    jaegerConfig.NamespaceSelector,
 			WhiteListIstioSystem: jaegerConfig.WhiteListIstioSystem,
@@ -149,6 +152,147 @@ func TraceDetails(w http.ResponseWriter, r *http.Request) {
 	RespondWithJSON(w, http.StatusOK, trace)
 }
 
+// TracesCompare is the API handler to compare two traces from their IDs
+func TracesCompare(w http.ResponseWriter, r *http.Request) {
+	business, err := getBusiness(r)
+	if err != nil {
+		RespondWithError(w, http.StatusInternalServerError, "Traces Compare initialization error: "+err.Error())
+		return
+	}
+	params := mux.Vars(r)
+	traceID := params["traceID"]
+	otherTraceID := params["otherTraceID"]
+	comparison, err := business.Jaeger.CompareTraces(traceID, otherTraceID)
+	if err != nil {
+		RespondWithError(w, http.StatusServiceUnavailable, err.Error())
+		return
+	}
+	if comparison == nil {
+		RespondWithError(w, http.StatusNotFound, fmt.Sprintf("Trace %s or %s not found", traceID, otherTraceID))
+		return
+	}
+	RespondWithJSON(w, http.StatusOK, comparison)
+}
+
+// ServiceTracesCompare is the API handler to compare the traces of a service between a baseline time window and
+// the requested time window
+func ServiceTracesCompare(w http.ResponseWriter, r *http.Request) {
+	business, err := getBusiness(r)
+	if err != nil {
+		RespondWithError(w, http.StatusInternalServerError, "Service Traces Compare initialization error: "+err.Error())
+		return
+	}
+	params := mux.Vars(r)
+	namespace := params["namespace"]
+	service := params["service"]
+	queryParams := r.URL.Query()
+	if queryParams.Get("baselineStartMicros") == "" || queryParams.Get("baselineEndMicros") == "" {
+		RespondWithError(w, http.StatusBadRequest, "'baselineStartMicros' and 'baselineEndMicros' parameters are required")
+		return
+	}
+	q, err := readQuery(queryParams)
+	if err != nil {
+		RespondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	// The baseline query is the same, on the baseline time window
+	baselineParams := url.Values{}
+	for k, v := range queryParams {
+		baselineParams[k] = v
+	}
+	baselineParams.Set("startMicros", queryParams.Get("baselineStartMicros"))
+	baselineParams.Set("endMicros", queryParams.Get("baselineEndMicros"))
+	baselineQuery, err := readQuery(baselineParams)
+	if err != nil {
+		RespondWithError(w, http.StatusBadRequest, "Invalid baseline: "+err.Error())
+		return
+	}
+
+	comparison, err := business.Jaeger.CompareServiceTraces(r.Context(), namespace, service, baselineQuery, q)
+	if err != nil {
+		RespondWithError(w, http.StatusServiceUnavailable, err.Error())
+		return
+	}
+	RespondWithJSON(w, http.StatusOK, comparison)
+}
+
+// LogTrace is the API handler to fetch the trace of a log entry, by the trace ID or the request ID found in the entry
+func LogTrace(w http.ResponseWriter, r *http.Request) {
+	business, err := getBusiness(r)
+	if err != nil {
+		RespondWithError(w, http.StatusInternalServerError, "Log Trace initialization error: "+err.Error())
+		return
+	}
+	params := mux.Vars(r)
+	namespace := params["namespace"]
+	queryParams := r.URL.Query()
+	traceID := queryParams.Get("traceId")
+	requestID := queryParams.Get("requestId")
+	app := queryParams.Get("app")
+	if traceID == "" && (requestID == "" || app == "") {
+		RespondWithError(w, http.StatusBadRequest, "Either 'traceId', or 'requestId' and 'app' parameters are required")
+		return
+	}
+	q, err := readQuery(queryParams)
+	if err != nil {
+		RespondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	trace, err := business.Jaeger.GetLogTrace(namespace, app, traceID, requestID, q)
+	if err != nil {
+		RespondWithError(w, http.StatusServiceUnavailable, err.Error())
+		return
+	}
+	if trace == nil {
+		RespondWithError(w, http.StatusNotFound, "Trace not found for the log entry")
+		return
+	}
+	RespondWithJSON(w, http.StatusOK, trace)
+}
+
+// TraceLogs is the API handler to fetch the logs of all the pods involved in a trace
+func TraceLogs(w http.ResponseWriter, r *http.Request) {
+	if config.IsFeatureDisabled(config.FeatureLogView) {
+		RespondWithError(w, http.StatusForbidden, "Pod Logs access is disabled")
+		return
+	}
+	business, err := getBusiness(r)
+	if err != nil {
+		RespondWithError(w, http.StatusInternalServerError, "Trace Logs initialization error: "+err.Error())
+		return
+	}
+	params := mux.Vars(r)
+	traceID := params["traceID"]
+	queryParams := r.URL.Query()
+
+	correlatedOnly := false
+	if v := queryParams.Get("correlatedOnly"); v != "" {
+		if correlatedOnly, err = strconv.ParseBool(v); err != nil {
+			RespondWithError(w, http.StatusBadRequest, "Cannot parse parameter 'correlatedOnly': "+err.Error())
+			return
+		}
+	}
+	maxLines := 1000
+	if v := queryParams.Get("maxLines"); v != "" {
+		if maxLines, err = strconv.Atoi(v); err != nil {
+			RespondWithError(w, http.StatusBadRequest, "Cannot parse parameter 'maxLines': "+err.Error())
+			return
+		}
+	}
+
+	logs, err := business.Jaeger.GetTraceLogs(r.Context(), clusterNameFromQuery(queryParams), traceID, correlatedOnly, maxLines)
+	if err != nil {
+		handleErrorResponse(w, err)
+		return
+	}
+	if logs == nil {
+		RespondWithError(w, http.StatusNotFound, fmt.Sprintf("Trace %s not found", traceID))
+		return
+	}
+	RespondWithJSON(w, http.StatusOK, logs)
+}
+
 // AppSpans is the API handler to fetch Jaeger spans of a specific app
 func AppSpans(w http.ResponseWriter, r *http.Request) {
 	business, err := getBusiness(r)
@@ -271,6 +415,33 @@ func readQuery(values url.Values) (models.TracingQuery, error) {
 			return models.TracingQuery{}, fmt.Errorf("Cannot parse parameter 'minDuration': " + err.Error())
 		}
 	}
+	if strMaxD := values.Get("maxDuration"); strMaxD != "" {
+		if num, err := strconv.Atoi(strMaxD); err == nil {
+			q.MaxDuration = time.Duration(num) * time.Microsecond
+		} else {
+			return models.TracingQuery{}, fmt.Errorf("Cannot parse parameter 'maxDuration': " + err.Error())
+		}
+	}
+	if spanStatus := values.Get("spanStatus"); spanStatus != "" {
+		if spanStatus != "error" && spanStatus != "ok" {
+			return models.TracingQuery{}, fmt.Errorf("Invalid parameter 'spanStatus': %s (expected error or ok)", spanStatus)
+		}
+		q.SpanStatus = spanStatus
+	}
+	if statusCodes := values.Get("statusCode"); statusCodes != "" {
+		codes, err := parseStatusCodes(statusCodes)
+		if err != nil {
+			return models.TracingQuery{}, fmt.Errorf("Invalid parameter 'statusCode': " + err.Error())
+		}
+		q.StatusCodes = codes
+	}
+	for _, rawCall := range values["call"] {
+		call, err := parseTracingCall(rawCall)
+		if err != nil {
+			return models.TracingQuery{}, fmt.Errorf("Invalid parameter 'call': " + err.Error())
+		}
+		q.Calls = append(q.Calls, call)
+	}
 
 	for key, value := range config.Get().ExternalServices.Tracing.QueryScope {
 		q.Tags[key] = value
@@ -278,3 +449,41 @@ func readQuery(values url.Values) (models.TracingQuery, error) {
 
 	return q, nil
 }
+
+var statusCodeRegexp = regexp.MustCompile(`^([1-5][0-9][0-9]|[1-5]xx|[1-5][0-9][0-9]-[1-5][0-9][0-9])$`)
+
+// parseStatusCodes parses a comma-separated list of HTTP status codes (404), classes (5xx) or ranges (500-504)
+func parseStatusCodes(value string) ([]string, error) {
+	codes := []string{}
+	for _, code := range strings.Split(value, ",") {
+		code = strings.ToLower(strings.TrimSpace(code))
+		if !statusCodeRegexp.MatchString(code) {
+			return nil, fmt.Errorf("%s is not a status code, class or range", code)
+		}
+		codes = append(codes, code)
+	}
+	return codes, nil
+}
+
+// parseTracingCall parses a service-to-service call, formatted as source->destination[:statusCodes],
+// e.g. productpage->reviews:5xx
+func parseTracingCall(value string) (models.TracingCall, error) {
+	source, destination, found := strings.Cut(value, "->")
+	if !found {
+		return models.TracingCall{}, fmt.Errorf("%s is not formatted as source->destination[:statusCodes]", value)
+	}
+	call := models.TracingCall{Source: strings.TrimSpace(source)}
+	destination, statusCodes, hasCodes := strings.Cut(destination, ":")
+	call.Destination = strings.TrimSpace(destination)
+	if call.Source == "" || call.Destination == "" {
+		return models.TracingCall{}, fmt.Errorf("%s is not formatted as source->destination[:statusCodes]", value)
+	}
+	if hasCodes {
+		codes, err := parseStatusCodes(statusCodes)
+		if err != nil {
+			return models.TracingCall{}, err
+		}
+		call.StatusCodes = codes
+	}
+	return call, nil
+}
@@ -44,11 +44,12 @@ func getAppMetrics(w http.ResponseWriter, r *http.Request, promSupplier promClie
 		return
 	}
 
-	metrics, err := metricsService.GetMetrics(params, nil)
+	metrics, warnings, err := metricsService.GetMetricsWithWarnings(params, nil)
 	if err != nil {
 		RespondWithError(w, http.StatusServiceUnavailable, err.Error())
 		return
 	}
+	setWarningHeaders(w, warnings)
 	RespondWithJSON(w, http.StatusOK, metrics)
 }
 
@@ -76,11 +77,12 @@ func getWorkloadMetrics(w http.ResponseWriter, r *http.Request, promSupplier pro
 		return
 	}
 
-	metrics, err := metricsService.GetMetrics(params, nil)
+	metrics, warnings, err := metricsService.GetMetricsWithWarnings(params, nil)
 	if err != nil {
 		RespondWithError(w, http.StatusServiceUnavailable, err.Error())
 		return
 	}
+	setWarningHeaders(w, warnings)
 	RespondWithJSON(w, http.StatusOK, metrics)
 }
 
@@ -108,11 +110,12 @@ func getServiceMetrics(w http.ResponseWriter, r *http.Request, promSupplier prom
 		return
 	}
 
-	metrics, err := metricsService.GetMetrics(params, nil)
+	metrics, warnings, err := metricsService.GetMetricsWithWarnings(params, nil)
 	if err != nil {
 		RespondWithError(w, http.StatusServiceUnavailable, err.Error())
 		return
 	}
+	setWarningHeaders(w, warnings)
 	RespondWithJSON(w, http.StatusOK, metrics)
 }
 
@@ -149,11 +152,12 @@ func getAggregateMetrics(w http.ResponseWriter, r *http.Request, promSupplier pr
 		return
 	}
 
-	metrics, err := metricsService.GetMetrics(params, nil)
+	metrics, warnings, err := metricsService.GetMetricsWithWarnings(params, nil)
 	if err != nil {
 		RespondWithError(w, http.StatusServiceUnavailable, err.Error())
 		return
 	}
+	setWarningHeaders(w, warnings)
 	RespondWithJSON(w, http.StatusOK, metrics)
 }
 
@@ -181,11 +185,12 @@ func getNamespaceMetrics(w http.ResponseWriter, r *http.Request, promSupplier pr
 		return
 	}
 
-	metrics, err := metricsService.GetMetrics(params, nil)
+	metrics, warnings, err := metricsService.GetMetricsWithWarnings(params, nil)
 	if err != nil {
 		RespondWithError(w, http.StatusServiceUnavailable, err.Error())
 		return
 	}
+	setWarningHeaders(w, warnings)
 
 	if namespace == config.Get().IstioNamespace {
 		controlPlaneMetrics, err := metricsService.GetControlPlaneMetrics(params, nil)
@@ -207,6 +212,14 @@ func getNamespaceMetrics(w http.ResponseWriter, r *http.Request, promSupplier pr
 	RespondWithJSON(w, http.StatusOK, metrics)
 }
 
+// setWarningHeaders adds the warnings about a partial response (e.g. metrics skipped because their queries failed,
+// Prometheus warnings) as HTTP Warning headers, leaving the response body unchanged
+func setWarningHeaders(w http.ResponseWriter, warnings []string) {
+	for _, warning := range warnings {
+		w.Header().Add("Warning", fmt.Sprintf("199 kiali %s", strconv.Quote(warning)))
+	}
+}
+
 func extractIstioMetricsQueryParams(r *http.Request, q *models.IstioMetricsQuery, namespaceInfo *models.Namespace) error {
 	q.FillDefaults()
 	queryParams := r.URL.Query()
@@ -232,6 +245,13 @@ func extractIstioMetricsQueryParams(r *http.Request, q *models.IstioMetricsQuery
 		}
 		q.Reporter = reporter
 	}
+	if exemplars := queryParams.Get("exemplars"); exemplars != "" {
+		if include, err := strconv.ParseBool(exemplars); err == nil {
+			q.IncludeExemplars = include
+		} else {
+			return errors.New("bad request, cannot parse query parameter 'exemplars'")
+		}
+	}
 	return extractBaseMetricsQueryParams(queryParams, &q.RangeQuery, namespaceInfo)
 }
 
@@ -346,6 +366,21 @@ func MetricsStats(w http.ResponseWriter, r *http.Request) {
 		handleErrorResponse(w, err)
 		return
 	}
+	if promWarnings := metricsService.Warnings(); len(promWarnings) > 0 {
+		if warns == nil {
+			warns = &util.Errors{}
+		}
+		for _, warning := range promWarnings {
+			warns.AddString(warning)
+		}
+	}
+	if spanErrs := addOperationStats(r, queries, stats); spanErrs != nil {
+		if warns == nil {
+			warns = spanErrs
+		} else {
+			warns.Merge(spanErrs)
+		}
+	}
 	result := models.MetricsStatsResult{Stats: stats}
 	if warns != nil {
 		result.Warnings = warns.Strings()
@@ -353,6 +388,42 @@ func MetricsStats(w http.ResponseWriter, r *http.Request) {
 	RespondWithJSON(w, http.StatusOK, result)
 }
 
+// addOperationStats adds the per-operation stats computed from traces, for the queries requesting them. Failures
+// don't fail the whole request, they are returned as warnings.
+func addOperationStats(r *http.Request, queries []models.MetricsStatsQuery, stats map[string]models.MetricsStats) *util.Errors {
+	var errs util.Errors
+	var layer *business.Layer
+	for _, q := range queries {
+		if !q.IncludeSpans {
+			continue
+		}
+		if !config.Get().ExternalServices.Tracing.Enabled {
+			errs.AddString("Span stats are not available: tracing is disabled")
+			break
+		}
+		if layer == nil {
+			var err error
+			if layer, err = getBusiness(r); err != nil {
+				errs.Add(err)
+				break
+			}
+		}
+		operations, err := layer.Jaeger.GetOperationStats(r.Context(), q)
+		if err != nil {
+			errs.Add(fmt.Errorf("Span stats for '%s': %v", q.Target.GenKey(), err))
+			continue
+		}
+		key := q.GenKey()
+		s := stats[key]
+		s.Operations = operations
+		if s.ResponseTimes == nil {
+			s.ResponseTimes = []models.Stat{}
+		}
+		stats[key] = s
+	}
+	return errs.OrNil()
+}
+
 func prepareStatsQueries(w http.ResponseWriter, r *http.Request, rawQ []models.MetricsStatsQuery, promSupplier promClientSupplier) (*business.MetricsService, []models.MetricsStatsQuery, *util.Errors) {
 	// Get unique namespaces list
 	var namespaces []string
@@ -470,3 +470,13 @@ func TestValidateBadRequest(t *testing.T) {
 	assert.Contains(errs.Error(), "bad request")
 	assert.Len(errs.Strings(), 2)
 }
+
+func TestSetWarningHeaders(t *testing.T) {
+	w := httptest.NewRecorder()
+	setWarningHeaders(w, []string{"metric request_size skipped: context deadline exceeded", `label "app" is missing`})
+
+	assert.Equal(t, []string{
+		`199 kiali "metric request_size skipped: context deadline exceeded"`,
+		`199 kiali "label \"app\" is missing"`,
+	}, w.Header().Values("Warning"))
+}
@@ -7,6 +7,7 @@ import (
 
 	"github.com/gorilla/mux"
 
+	"github.com/kiali/kiali/audit"
 	"github.com/kiali/kiali/log"
 	"github.com/kiali/kiali/models"
 )
@@ -32,6 +33,10 @@ func NamespaceList(w http.ResponseWriter, r *http.Request) {
 // NamespaceValidationSummary is the API handler to fetch validations summary to be displayed.
 // It is related to all the Istio Objects within the namespace
 func NamespaceValidationSummary(w http.ResponseWriter, r *http.Request) {
+	if forwardToPeer(w, r) {
+		return
+	}
+
 	query := r.URL.Query()
 	vars := mux.Vars(r)
 	namespace := vars["namespace"]
@@ -60,6 +65,10 @@ func NamespaceValidationSummary(w http.ResponseWriter, r *http.Request) {
 // ConfigValidationSummary is the API handler to fetch validations summary to be displayed.
 // It is related to all the Istio Objects within given namespaces
 func ConfigValidationSummary(w http.ResponseWriter, r *http.Request) {
+	if forwardToPeer(w, r) {
+		return
+	}
+
 	params := r.URL.Query()
 	namespaces := params.Get("namespaces") // csl of namespaces
 	nss := []string{}
@@ -92,6 +101,9 @@ func ConfigValidationSummary(w http.ResponseWriter, r *http.Request) {
 // NamespaceUpdate is the API to perform a patch on a Namespace configuration
 func NamespaceUpdate(w http.ResponseWriter, r *http.Request) {
 	params := mux.Vars(r)
+	if !checkCapability(w, getCapabilities(r).EditResources, "Updating the namespaces") {
+		return
+	}
 	business, err := getBusiness(r)
 	if err != nil {
 		RespondWithError(w, http.StatusInternalServerError, "Namespace initialization error: "+err.Error())
@@ -108,10 +120,10 @@ func NamespaceUpdate(w http.ResponseWriter, r *http.Request) {
 	cluster := clusterNameFromQuery(query)
 
 	ns, err := business.Namespace.UpdateNamespace(r.Context(), namespace, jsonPatch, cluster)
+	auditMutation(r, audit.Entry{Verb: "update", Cluster: cluster, ObjectType: "namespaces", Object: namespace, Body: jsonPatch}, err)
 	if err != nil {
 		handleErrorResponse(w, err)
 		return
 	}
-	audit(r, "UPDATE on Namespace: "+namespace+" Patch: "+jsonPatch)
 	RespondWithJSON(w, http.StatusOK, ns)
 }
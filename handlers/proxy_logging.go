@@ -7,6 +7,7 @@ import (
 
 	"github.com/gorilla/mux"
 
+	"github.com/kiali/kiali/audit"
 	"github.com/kiali/kiali/business"
 	"github.com/kiali/kiali/config"
 )
@@ -18,6 +19,9 @@ func LoggingUpdate(w http.ResponseWriter, r *http.Request) {
 		RespondWithError(w, http.StatusForbidden, "Log level cannot be changed in view-only mode")
 		return
 	}
+	if !checkCapability(w, getCapabilities(r).SetProxyLogLevel, "Changing the log level") {
+		return
+	}
 
 	// Get business layer
 	businessLayer, err := getBusiness(r)
@@ -42,10 +46,11 @@ func LoggingUpdate(w http.ResponseWriter, r *http.Request) {
 
 	cluster := clusterNameFromQuery(query)
 
-	if err := businessLayer.ProxyLogging.SetLogLevel(cluster, namespace, pod, level); err != nil {
+	err = businessLayer.ProxyLogging.SetLogLevel(cluster, namespace, pod, level)
+	auditMutation(r, audit.Entry{Verb: "update", Cluster: cluster, Namespace: namespace, ObjectType: "proxyloglevel", Object: pod, Body: level}, err)
+	if err != nil {
 		handleErrorResponse(w, err)
 		return
 	}
-	audit(r, "UPDATE Envoy log. Cluster: "+cluster+" Namespace: "+namespace+" Pod: "+pod+" Log level:"+level)
 	RespondWithCode(w, 200)
 }
@@ -0,0 +1,21 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/kiali/kiali/business"
+)
+
+// getCapabilities returns what the user of the request can change through Kiali, according to the Kiali role
+// of the user
+func getCapabilities(r *http.Request) business.Capabilities {
+	return business.GetCapabilitiesContext(r.Context())
+}
+
+// checkCapability responds with an error when the operation isn't allowed to the user
+func checkCapability(w http.ResponseWriter, allowed bool, operation string) bool {
+	if !allowed {
+		RespondWithError(w, http.StatusForbidden, operation+" is not allowed to the user")
+	}
+	return allowed
+}
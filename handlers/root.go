@@ -18,6 +18,13 @@ func Root(w http.ResponseWriter, r *http.Request) {
 	getStatus(w, r)
 }
 
+// Status provides the status of the server to the authenticated users, with the reachability of the clusters.
+func Status(w http.ResponseWriter, r *http.Request) {
+	info := status.Get()
+	info.Clusters = status.GetClusterStatuses()
+	RespondWithJSONIndent(w, http.StatusOK, info)
+}
+
 func getStatus(w http.ResponseWriter, r *http.Request) {
 	RespondWithJSONIndent(w, http.StatusOK, status.Get())
 }
@@ -8,6 +8,7 @@ import (
 
 	"github.com/gorilla/mux"
 
+	"github.com/kiali/kiali/audit"
 	"github.com/kiali/kiali/business"
 	"github.com/kiali/kiali/models"
 	"github.com/kiali/kiali/util"
@@ -142,6 +143,10 @@ func ServiceDetails(w http.ResponseWriter, r *http.Request) {
 }
 
 func ServiceUpdate(w http.ResponseWriter, r *http.Request) {
+	if !checkCapability(w, getCapabilities(r).EditResources, "Updating the services") {
+		return
+	}
+
 	// Get business layer
 	business, err := getBusiness(r)
 	if err != nil {
@@ -195,6 +200,7 @@ func ServiceUpdate(w http.ResponseWriter, r *http.Request) {
 	}
 
 	serviceDetails, err := business.Svc.UpdateService(r.Context(), cluster, namespace, service, rateInterval, queryTime, jsonPatch, patchType)
+	auditMutation(r, audit.Entry{Verb: "update", Cluster: cluster, Namespace: namespace, ObjectType: "services", Object: service, Body: jsonPatch}, err)
 
 	if includeValidations && err == nil {
 		wg.Wait()
@@ -207,6 +213,5 @@ func ServiceUpdate(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	audit(r, "UPDATE on Namespace: "+namespace+" Service name: "+service+" Patch: "+jsonPatch)
 	RespondWithJSON(w, http.StatusOK, serviceDetails)
 }
@@ -0,0 +1,131 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"github.com/kiali/kiali/audit"
+	"github.com/kiali/kiali/business/authentication"
+	"github.com/kiali/kiali/config"
+)
+
+// RevokedSessions is the number of sessions revoked by a request
+type RevokedSessions struct {
+	Revoked int `json:"revoked"`
+}
+
+// getSessionManager returns the manager of the sessions, or responds with an error when the sessions are kept in
+// the browser cookies
+func getSessionManager(w http.ResponseWriter) authentication.SessionManager {
+	manager := authentication.GetSessionManager()
+	if manager == nil || config.Get().Auth.Strategy == config.AuthStrategyAnonymous {
+		RespondWithError(w, http.StatusServiceUnavailable, "Sessions are kept in the browser cookies: a server-side session store must be configured to manage them")
+		return nil
+	}
+	return manager
+}
+
+// checkKialiAdmin responds with an error when the user isn't allowed to administer Kiali
+func checkKialiAdmin(w http.ResponseWriter, r *http.Request) bool {
+	if !checkCapability(w, getCapabilities(r).AdministerKiali, "Administering Kiali") {
+		return false
+	}
+	layer, err := getBusiness(r)
+	if err != nil {
+		RespondWithError(w, http.StatusInternalServerError, "Services initialization error: "+err.Error())
+		return false
+	}
+	admin, err := layer.TokenReview.IsKialiAdmin(r.Context())
+	if err != nil {
+		RespondWithError(w, http.StatusInternalServerError, "Cannot check the user permissions: "+err.Error())
+		return false
+	}
+	if !admin {
+		RespondWithError(w, http.StatusForbidden, "This operation requires the permission to delete the secrets of the Kiali namespace")
+		return false
+	}
+	return true
+}
+
+// Sessions is the API handler to list the active sessions
+func Sessions(w http.ResponseWriter, r *http.Request) {
+	manager := getSessionManager(w)
+	if manager == nil || !checkKialiAdmin(w, r) {
+		return
+	}
+	sessions, err := manager.ListSessions()
+	if err != nil {
+		RespondWithError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	RespondWithJSON(w, http.StatusOK, sessions)
+}
+
+// SessionRevoke is the API handler to revoke a session
+func SessionRevoke(w http.ResponseWriter, r *http.Request) {
+	manager := getSessionManager(w)
+	if manager == nil || !checkKialiAdmin(w, r) {
+		return
+	}
+	session := mux.Vars(r)["session"]
+	found, err := manager.RevokeSession(session)
+	auditMutation(r, audit.Entry{Verb: "revoke", ObjectType: "sessions", Object: session}, err)
+	if err != nil {
+		RespondWithError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if !found {
+		RespondWithError(w, http.StatusNotFound, "Session not found: "+session)
+		return
+	}
+	RespondWithCode(w, http.StatusNoContent)
+}
+
+// UserSessionsRevoke is the API handler to revoke all the sessions of a user
+func UserSessionsRevoke(w http.ResponseWriter, r *http.Request) {
+	manager := getSessionManager(w)
+	if manager == nil || !checkKialiAdmin(w, r) {
+		return
+	}
+	username := r.URL.Query().Get("username")
+	if username == "" {
+		RespondWithError(w, http.StatusBadRequest, "The username is required")
+		return
+	}
+	revoked, err := manager.RevokeUserSessions(username)
+	auditMutation(r, audit.Entry{Verb: "revoke", ObjectType: "usersessions", Object: username}, err)
+	if err != nil {
+		RespondWithError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	RespondWithJSON(w, http.StatusOK, RevokedSessions{Revoked: revoked})
+}
+
+// LogoutEverywhere is the API handler to terminate all the sessions of the current user
+func LogoutEverywhere(w http.ResponseWriter, r *http.Request) {
+	manager := getSessionManager(w)
+	if manager == nil {
+		return
+	}
+	session, err := authentication.GetAuthController().ValidateSession(r, w)
+	if err != nil {
+		RespondWithError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if session == nil || session.Username == "" {
+		RespondWithError(w, http.StatusBadRequest, "The user of the session is unknown")
+		return
+	}
+	revoked, err := manager.RevokeUserSessions(session.Username)
+	if err != nil {
+		RespondWithError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	// The current session is one of them
+	if err := authentication.GetAuthController().TerminateSession(r, w); err != nil {
+		RespondWithError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	RespondWithJSON(w, http.StatusOK, RevokedSessions{Revoked: revoked})
+}
@@ -4,11 +4,15 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"strconv"
+	"strings"
 	"sync"
 
 	"github.com/gorilla/mux"
+	api_errors "k8s.io/apimachinery/pkg/api/errors"
 
+	"github.com/kiali/kiali/audit"
 	"github.com/kiali/kiali/business"
 	"github.com/kiali/kiali/config"
 	"github.com/kiali/kiali/log"
@@ -67,19 +71,35 @@ func WorkloadList(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	var errMsg string
 	if criteria.IncludeHealth {
-		rateInterval, err := adjustRateInterval(r.Context(), businessLayer, p.Namespace, p.RateInterval, p.QueryTime)
+		var rateInterval string
+		rateInterval, err = adjustRateInterval(r.Context(), businessLayer, p.Namespace, p.RateInterval, p.QueryTime)
 		if err != nil {
-			handleErrorResponse(w, err, "Adjust rate interval error: "+err.Error())
-			return
+			errMsg = "Adjust rate interval error: " + err.Error()
 		}
 		criteria.RateInterval = rateInterval
 	}
 
 	// Fetch and build workloads
-	workloadList, err := businessLayer.Workload.GetWorkloadList(r.Context(), criteria)
+	var workloadList models.WorkloadList
+	if err == nil {
+		workloadList, err = businessLayer.Workload.GetWorkloadList(r.Context(), criteria)
+		if err != nil {
+			errMsg = err.Error()
+		}
+	}
+
+	// The namespace may only exist in the clusters of the peers
+	if err != nil && (!api_errors.IsNotFound(err) || !isFederating(r)) {
+		handleErrorResponse(w, err, errMsg)
+		return
+	}
 	if err != nil {
-		handleErrorResponse(w, err)
+		workloadList = models.WorkloadList{Namespace: models.Namespace{Name: p.Namespace}, Workloads: []models.WorkloadListItem{}}
+	}
+	if !mergePeerWorkloads(r, &workloadList) && err != nil {
+		handleErrorResponse(w, err, errMsg)
 		return
 	}
 
@@ -88,6 +108,10 @@ func WorkloadList(w http.ResponseWriter, r *http.Request) {
 
 // WorkloadDetails is the API handler to fetch all details to be displayed, related to a single workload
 func WorkloadDetails(w http.ResponseWriter, r *http.Request) {
+	if forwardToPeer(w, r) {
+		return
+	}
+
 	p := workloadParams{}
 	p.extract(r)
 
@@ -145,6 +169,10 @@ func WorkloadUpdate(w http.ResponseWriter, r *http.Request) {
 	params := mux.Vars(r)
 	query := r.URL.Query()
 
+	if !checkCapability(w, getCapabilities(r).EditResources, "Updating the workloads") {
+		return
+	}
+
 	// Get business layer
 	business, err := getBusiness(r)
 	if err != nil {
@@ -187,6 +215,7 @@ func WorkloadUpdate(w http.ResponseWriter, r *http.Request) {
 	}
 
 	workloadDetails, err := business.Workload.UpdateWorkload(r.Context(), cluster, namespace, workload, workloadType, true, jsonPatch, patchType)
+	auditMutation(r, audit.Entry{Verb: "update", Cluster: cluster, Namespace: namespace, ObjectType: "workloads", Object: workload, Body: jsonPatch}, err)
 	if includeValidations && err == nil {
 		wg.Wait()
 		workloadDetails.Validations = istioConfigValidations
@@ -196,8 +225,6 @@ func WorkloadUpdate(w http.ResponseWriter, r *http.Request) {
 		handleErrorResponse(w, err)
 		return
 	}
-	auditMsg := fmt.Sprintf("UPDATE on Cluster: [%s] Namespace: [%s] Workload name: [%s] Type: [%s] Patch: [%s]", cluster, namespace, workload, workloadType, jsonPatch)
-	audit(r, auditMsg)
 	RespondWithJSON(w, http.StatusOK, workloadDetails)
 }
 
@@ -256,6 +283,7 @@ func PodLogs(w http.ResponseWriter, r *http.Request) {
 		handleErrorResponse(w, err)
 		return
 	}
+	opts.Filter = accessLogFilterFromQuery(queryParams)
 
 	// Fetch pod logs
 	err = business.Workload.StreamPodLogs(cluster, namespace, pod, opts, w)
@@ -264,3 +292,149 @@ func PodLogs(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 }
+
+// accessLogFilterFromQuery parses the proxy access log filters. Status codes and response flags are comma-separated lists.
+func accessLogFilterFromQuery(queryParams url.Values) business.AccessLogFilter {
+	filter := business.AccessLogFilter{Authority: queryParams.Get("authority")}
+	if statusCodes := queryParams.Get("statusCode"); statusCodes != "" {
+		filter.StatusCodes = strings.Split(statusCodes, ",")
+	}
+	if responseFlags := queryParams.Get("responseFlags"); responseFlags != "" {
+		filter.ResponseFlags = strings.Split(responseFlags, ",")
+	}
+	return filter
+}
+
+// buildMultiPodLogOptions parses the query parameters of the log endpoints that read several pods at once.
+func buildMultiPodLogOptions(businessLayer *business.Layer, r *http.Request) (*business.LogOptions, error) {
+	queryParams := r.URL.Query()
+	opts, err := businessLayer.Workload.BuildLogOptionsCriteria(
+		"",
+		queryParams.Get("duration"),
+		queryParams.Get("isProxy"),
+		queryParams.Get("sinceTime"),
+		queryParams.Get("maxLines"))
+	if err != nil {
+		return nil, err
+	}
+
+	opts.Filter = accessLogFilterFromQuery(queryParams)
+	if containers := queryParams.Get("container"); containers != "" {
+		opts.Containers = strings.Split(containers, ",")
+	}
+	if follow := queryParams.Get("follow"); follow != "" {
+		opts.Follow, err = strconv.ParseBool(follow)
+		if err != nil {
+			return nil, fmt.Errorf("invalid follow [%s]: %v", follow, err)
+		}
+	}
+	return opts, nil
+}
+
+// WorkloadLogs is the API handler to fetch the logs of all the pods of a workload, merged in timestamp order
+func WorkloadLogs(w http.ResponseWriter, r *http.Request) {
+	if config.IsFeatureDisabled(config.FeatureLogView) {
+		RespondWithError(w, http.StatusForbidden, "Pod Logs access is disabled")
+		return
+	}
+	vars := mux.Vars(r)
+
+	// Get business layer
+	business, err := getBusiness(r)
+	if err != nil {
+		RespondWithError(w, http.StatusInternalServerError, "Workload Logs initialization error: "+err.Error())
+		return
+	}
+	cluster := clusterNameFromQuery(r.URL.Query())
+	namespace := vars["namespace"]
+	workload := vars["workload"]
+
+	opts, err := buildMultiPodLogOptions(business, r)
+	if err != nil {
+		RespondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	err = business.Workload.StreamWorkloadLogs(r.Context(), cluster, namespace, workload, opts, w)
+	if err != nil {
+		handleErrorResponse(w, err)
+		return
+	}
+}
+
+// accessLogStatsOptions parses the query parameters of the access log stats endpoints
+func accessLogStatsOptions(businessLayer *business.Layer, r *http.Request) (*business.LogOptions, int, error) {
+	queryParams := r.URL.Query()
+	opts, err := businessLayer.Workload.BuildLogOptionsCriteria("", queryParams.Get("duration"), "true", queryParams.Get("sinceTime"), "")
+	if err != nil {
+		return nil, 0, err
+	}
+	opts.Filter = accessLogFilterFromQuery(queryParams)
+
+	limit := 10
+	if limitParam := queryParams.Get("limit"); limitParam != "" {
+		limit, err = strconv.Atoi(limitParam)
+		if err != nil {
+			return nil, 0, fmt.Errorf("invalid limit [%s]: %v", limitParam, err)
+		}
+	}
+	return opts, limit, nil
+}
+
+// PodAccessLogStats is the API handler to fetch statistics computed from the proxy access logs of a pod
+func PodAccessLogStats(w http.ResponseWriter, r *http.Request) {
+	if config.IsFeatureDisabled(config.FeatureLogView) {
+		RespondWithError(w, http.StatusForbidden, "Pod Logs access is disabled")
+		return
+	}
+	vars := mux.Vars(r)
+
+	// Get business layer
+	business, err := getBusiness(r)
+	if err != nil {
+		RespondWithError(w, http.StatusInternalServerError, "Access Log Stats initialization error: "+err.Error())
+		return
+	}
+
+	opts, limit, err := accessLogStatsOptions(business, r)
+	if err != nil {
+		RespondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	stats, err := business.Workload.GetPodAccessLogStats(r.Context(), clusterNameFromQuery(r.URL.Query()), vars["namespace"], vars["pod"], opts, limit)
+	if err != nil {
+		handleErrorResponse(w, err)
+		return
+	}
+	RespondWithJSON(w, http.StatusOK, stats)
+}
+
+// WorkloadAccessLogStats is the API handler to fetch statistics computed from the proxy access logs of all the pods of a workload
+func WorkloadAccessLogStats(w http.ResponseWriter, r *http.Request) {
+	if config.IsFeatureDisabled(config.FeatureLogView) {
+		RespondWithError(w, http.StatusForbidden, "Pod Logs access is disabled")
+		return
+	}
+	vars := mux.Vars(r)
+
+	// Get business layer
+	business, err := getBusiness(r)
+	if err != nil {
+		RespondWithError(w, http.StatusInternalServerError, "Access Log Stats initialization error: "+err.Error())
+		return
+	}
+
+	opts, limit, err := accessLogStatsOptions(business, r)
+	if err != nil {
+		RespondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	stats, err := business.Workload.GetWorkloadAccessLogStats(r.Context(), clusterNameFromQuery(r.URL.Query()), vars["namespace"], vars["workload"], opts, limit)
+	if err != nil {
+		handleErrorResponse(w, err)
+		return
+	}
+	RespondWithJSON(w, http.StatusOK, stats)
+}
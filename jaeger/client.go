@@ -131,6 +131,9 @@ func (in *Client) GetAppTraces(namespace, app string, q models.TracingQuery) (*J
 			SearchDepth:  int32(q.Limit),
 		},
 	}
+	if q.MaxDuration > 0 {
+		findTracesRQ.Query.DurationMax = durationpb.New(q.MaxDuration)
+	}
 	ctx, cancel := context.WithTimeout(in.ctx, time.Duration(config.Get().ExternalServices.Tracing.QueryTimeout)*time.Second)
 	defer cancel()
 
@@ -101,6 +101,9 @@ func prepareQuery(u *url.URL, jaegerServiceName string, query models.TracingQuer
 	if query.MinDuration > 0 {
 		q.Set("minDuration", fmt.Sprintf("%d", query.MinDuration.Microseconds()))
 	}
+	if query.MaxDuration > 0 {
+		q.Set("maxDuration", fmt.Sprintf("%dus", query.MaxDuration.Microseconds()))
+	}
 	if query.Limit > 0 {
 		q.Set("limit", strconv.Itoa(query.Limit))
 	}
@@ -18,6 +18,8 @@ type JaegerResponse struct {
 	Data              []jaegerModels.Trace `json:"data"`
 	Errors            []structuredError    `json:"errors"`
 	JaegerServiceName string               `json:"jaegerServiceName"`
+	// Set when the limit was reached: end of the time window (in microseconds) for the next page of results
+	NextEndMicros int64 `json:"nextEndMicros,omitempty"`
 }
 
 type JaegerSingleTrace struct {
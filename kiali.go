@@ -36,7 +36,9 @@ import (
 	"strings"
 
 	_ "go.uber.org/automaxprocs"
+	"k8s.io/apimachinery/pkg/labels"
 
+	"github.com/kiali/kiali/audit"
 	"github.com/kiali/kiali/business/authentication"
 	"github.com/kiali/kiali/config"
 	"github.com/kiali/kiali/kubernetes"
@@ -101,7 +103,19 @@ func main() {
 	status.Put(status.CoreCommitHash, commitHash)
 	status.Put(status.ContainerVersion, determineContainerVersion(version))
 
-	authentication.InitializeAuthenticationController(cfg.Auth.Strategy)
+	if err := authentication.InitializeAuthenticationController(cfg.AuthStrategies()...); err != nil {
+		log.Fatal(err)
+	}
+	authentication.InitializeAPITokens()
+
+	// The reachability of the clusters is checked in the background for the status requests
+	status.StartClusterChecks()
+
+	if cfg.Server.AuditLog {
+		if err := audit.Configure(cfg.Server.Audit); err != nil {
+			log.Fatal(err)
+		}
+	}
 
 	// prepare our internal metrics so Prometheus can scrape them
 	internalmetrics.RegisterInternalMetrics()
@@ -163,14 +177,138 @@ func validateConfig() error {
 
 	// log some messages to let the administrator know when credentials are configured certain ways
 	auth := cfg.Auth
-	log.Infof("Using authentication strategy [%v]", auth.Strategy)
+	strategies := cfg.AuthStrategies()
+	log.Infof("Using authentication strategies [%v]", strings.Join(strategies, ", "))
 	if auth.Strategy == config.AuthStrategyAnonymous {
 		log.Warningf("Kiali auth strategy is configured for anonymous access - users will not be authenticated.")
-	} else if auth.Strategy != config.AuthStrategyOpenId &&
-		auth.Strategy != config.AuthStrategyOpenshift &&
-		auth.Strategy != config.AuthStrategyToken &&
-		auth.Strategy != config.AuthStrategyHeader {
-		return fmt.Errorf("Invalid authentication strategy [%v]", auth.Strategy)
+	}
+	for i, strategy := range strategies {
+		if strategy == config.AuthStrategyAnonymous && len(strategies) > 1 {
+			return fmt.Errorf("the %s strategy can't be combined with other strategies", config.AuthStrategyAnonymous)
+		} else if strategy != config.AuthStrategyAnonymous &&
+			strategy != config.AuthStrategyOpenId &&
+			strategy != config.AuthStrategyOpenshift &&
+			strategy != config.AuthStrategyToken &&
+			strategy != config.AuthStrategyHeader {
+			return fmt.Errorf("Invalid authentication strategy [%v]", strategy)
+		}
+		for _, other := range strategies[:i] {
+			if other == strategy {
+				return fmt.Errorf("the authentication strategy [%v] is listed twice", strategy)
+			}
+		}
+	}
+
+	if auth.Impersonation.Enabled {
+		for _, strategy := range auth.Impersonation.Strategies {
+			if strategy != config.AuthStrategyOpenId && strategy != config.AuthStrategyHeader {
+				return fmt.Errorf("impersonation is only supported by the %s and %s strategies", config.AuthStrategyOpenId, config.AuthStrategyHeader)
+			}
+		}
+		if !cfg.IsImpersonating(config.AuthStrategyOpenId) && !cfg.IsImpersonating(config.AuthStrategyHeader) {
+			return fmt.Errorf("impersonation is only supported by the %s and %s strategies", config.AuthStrategyOpenId, config.AuthStrategyHeader)
+		}
+		if cfg.IsImpersonating(config.AuthStrategyHeader) && auth.Impersonation.UserHeader == "" {
+			return fmt.Errorf("the header holding the user name is required to impersonate the users")
+		}
+		if cfg.IsImpersonating(config.AuthStrategyHeader) && auth.Impersonation.ProxySecret == "" && len(auth.Impersonation.TrustedProxies) == 0 {
+			return fmt.Errorf("a proxy secret or trusted proxies are required to impersonate the users of the header strategy")
+		}
+		if cfg.IsImpersonating(config.AuthStrategyHeader) && len(auth.Impersonation.TrustedProxies) > 0 && cfg.Identity.ClientCAFile == "" {
+			return fmt.Errorf("the CA of the client certificates is required to trust the certificates of the proxies")
+		}
+		for _, strategy := range strategies {
+			if cfg.IsImpersonating(strategy) {
+				log.Infof("Kiali will impersonate the users of the %s strategy with its service account", strategy)
+			}
+		}
+	}
+
+	if auth.Roles.Enabled {
+		if !config.IsValidRole(auth.Roles.DefaultRole) {
+			return fmt.Errorf("Invalid default role [%v]", auth.Roles.DefaultRole)
+		}
+		for group, role := range auth.Roles.GroupRoles {
+			if !config.IsValidRole(role) {
+				return fmt.Errorf("Invalid role [%v] of the group [%v]", role, group)
+			}
+		}
+		if !cfg.HasAuthStrategy(config.AuthStrategyOpenId) && !cfg.HasAuthStrategy(config.AuthStrategyHeader) {
+			log.Warningf("The groups of the users are unknown with the %s strategy: all the users get the default role [%s]", auth.Strategy, auth.Roles.DefaultRole)
+		}
+		if cfg.HasAuthStrategy(config.AuthStrategyHeader) && auth.Impersonation.ProxySecret == "" && len(auth.Impersonation.TrustedProxies) == 0 {
+			log.Warningf("The groups header is ignored without a proxy secret or trusted proxies: the users of the %s strategy get the default role [%s]", config.AuthStrategyHeader, auth.Roles.DefaultRole)
+		}
+	}
+
+	switch auth.SessionStore.Type {
+	case "", config.SessionStoreCookie, config.SessionStoreMemory:
+	case config.SessionStoreSecret, config.SessionStoreConfigMap:
+		if auth.SessionStore.Name == "" {
+			return fmt.Errorf("the name of the %s keeping the sessions is required", auth.SessionStore.Type)
+		}
+	default:
+		return fmt.Errorf("Invalid session store [%v]", auth.SessionStore.Type)
+	}
+
+	if auth.APITokens.Enabled {
+		if auth.Strategy == config.AuthStrategyAnonymous {
+			return fmt.Errorf("the API tokens can't be enabled with the %s strategy", config.AuthStrategyAnonymous)
+		}
+		if auth.APITokens.SecretName == "" {
+			return fmt.Errorf("the name of the Secret keeping the API tokens is required")
+		}
+		if auth.APITokens.MaxExpiration <= 0 {
+			return fmt.Errorf("Invalid maximum expiration of the API tokens [%v]", auth.APITokens.MaxExpiration)
+		}
+	}
+
+	if cfg.Federation.Enabled {
+		if cfg.Federation.Timeout <= 0 {
+			return fmt.Errorf("Invalid timeout of the federation requests [%v]", cfg.Federation.Timeout)
+		}
+		peerClusters := map[string]bool{}
+		for _, peer := range cfg.Federation.Peers {
+			if peer.Cluster == "" || peer.URL == "" {
+				return fmt.Errorf("the cluster and the URL of the federated Kiali instances are required")
+			}
+			if peer.Cluster == cfg.KubernetesConfig.ClusterName {
+				return fmt.Errorf("the home cluster [%s] can't be federated", peer.Cluster)
+			}
+			if peerClusters[peer.Cluster] {
+				return fmt.Errorf("the cluster [%s] is federated twice", peer.Cluster)
+			}
+			peerClusters[peer.Cluster] = true
+		}
+	}
+	if len(cfg.Federation.TrustedPeers) > 0 && cfg.Identity.ClientCAFile == "" {
+		return fmt.Errorf("the CA of the client certificates is required to trust the certificates of the federated Kiali instances")
+	}
+
+	if remoteSecrets := cfg.KubernetesConfig.RemoteClusterSecrets; remoteSecrets.Enabled {
+		// An empty selector would register the clusters of every Secret of the namespace
+		if remoteSecrets.LabelSelector == "" {
+			return fmt.Errorf("the label selector of the remote cluster secrets is required")
+		}
+		if _, err := labels.Parse(remoteSecrets.LabelSelector); err != nil {
+			return fmt.Errorf("Invalid label selector of the remote cluster secrets [%s]: %v", remoteSecrets.LabelSelector, err)
+		}
+	}
+
+	if cfg.Server.AuditLog {
+		switch cfg.Server.Audit.Sink {
+		case "", config.AuditSinkLog, config.AuditSinkStdout:
+		case config.AuditSinkFile:
+			if cfg.Server.Audit.File == "" {
+				return fmt.Errorf("the audit file is required by the %s audit sink", config.AuditSinkFile)
+			}
+		case config.AuditSinkWebhook:
+			if cfg.Server.Audit.WebhookURL == "" {
+				return fmt.Errorf("the webhook URL is required by the %s audit sink", config.AuditSinkWebhook)
+			}
+		default:
+			return fmt.Errorf("Invalid audit sink [%v]", cfg.Server.Audit.Sink)
+		}
 	}
 
 	// Check the ciphering key for sessions
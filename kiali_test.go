@@ -88,3 +88,37 @@ func TestValidateAuthStrategy(t *testing.T) {
 		}
 	}
 }
+
+func TestValidateFederation(t *testing.T) {
+	conf := config.NewConfig()
+	conf.LoginToken.SigningKey = util.RandomString(16)
+	conf.Server.StaticContentRootDirectory = "."
+	conf.KubernetesConfig.ClusterName = "west"
+	conf.Federation.Enabled = true
+
+	validPeers := [][]config.FederationPeer{
+		{},
+		{{Cluster: "east", URL: "https://kiali.east.example.com"}, {Cluster: "north", URL: "https://kiali.north.example.com"}},
+	}
+	invalidPeers := [][]config.FederationPeer{
+		{{Cluster: "east"}},
+		{{Cluster: "west", URL: "https://kiali.west.example.com"}},
+		{{Cluster: "east", URL: "https://kiali.east.example.com"}, {Cluster: "east", URL: "https://kiali2.east.example.com"}},
+	}
+
+	for _, peers := range validPeers {
+		conf.Federation.Peers = peers
+		config.Set(conf)
+		if err := validateConfig(); err != nil {
+			t.Errorf("Federation validation should have succeeded for %v: %v", peers, err)
+		}
+	}
+
+	for _, peers := range invalidPeers {
+		conf.Federation.Peers = peers
+		config.Set(conf)
+		if err := validateConfig(); err == nil {
+			t.Errorf("Federation validation should have failed for %v", peers)
+		}
+	}
+}
@@ -31,6 +31,7 @@ type KialiCache interface {
 	// All business methods should eventually use the multi-cluster cache.
 	KubeCache
 	NamespacesCache
+	PermissionsCache
 	ProxyStatusCache
 	RegistryStatusCache
 }
@@ -61,16 +62,23 @@ type kialiCacheImpl struct {
 	// How often the cache will check for kiali SA client changes.
 	clientRefreshPollingPeriod time.Duration
 	// Maps a cluster name to a KubeCache
-	kubeCache              map[string]KubeCache
+	kubeCache     map[string]KubeCache
+	kubeCacheLock sync.RWMutex
+	// newKubeCache creates the KubeCache of a cluster, including the clusters added at runtime
+	newKubeCache           func(client kubernetes.ClientInterface) (KubeCache, error)
 	refreshDuration        time.Duration
 	tokenLock              sync.RWMutex
 	tokenNamespaces        map[string]namespaceCache // TODO: Another option can be define here the namespaces by token/cluster
 	tokenNamespaceDuration time.Duration
-	proxyStatusLock        sync.RWMutex
-	proxyStatusNamespaces  map[string]map[string]map[string]podProxyStatus
-	registryStatusLock     sync.RWMutex
-	registryStatusCreated  *time.Time
-	registryStatus         *kubernetes.RegistryStatus
+	// Permissions of the users by token hash, then by cluster and namespace
+	permissionsLock          sync.RWMutex
+	tokenPermissions         map[string]map[string]permissionsCache
+	tokenPermissionsDuration time.Duration
+	proxyStatusLock          sync.RWMutex
+	proxyStatusNamespaces    map[string]map[string]map[string]podProxyStatus
+	registryStatusLock       sync.RWMutex
+	registryStatusCreated    *time.Time
+	registryStatus           *kubernetes.RegistryStatus
 }
 
 func NewKialiCache(clientFactory kubernetes.ClientFactory, cfg config.Config, namespaceSeedList ...string) (KialiCache, error) {
@@ -82,29 +90,44 @@ func NewKialiCache(clientFactory kubernetes.ClientFactory, cfg config.Config, na
 		refreshDuration:            time.Duration(cfg.KubernetesConfig.CacheDuration) * time.Second,
 		tokenNamespaces:            make(map[string]namespaceCache),
 		tokenNamespaceDuration:     time.Duration(cfg.KubernetesConfig.CacheTokenNamespaceDuration) * time.Second,
+		tokenPermissions:           make(map[string]map[string]permissionsCache),
+		tokenPermissionsDuration:   time.Duration(cfg.KubernetesConfig.CacheTokenPermissionsDuration) * time.Second,
 	}
 
+	kialiCacheImpl.newKubeCache = func(client kubernetes.ClientInterface) (KubeCache, error) {
+		return NewKubeCache(client, cfg, NewRegistryHandler(kialiCacheImpl.RefreshRegistryStatus), namespaceSeedList...)
+	}
+
+	// The kube caches of the remote clusters follow the clusters added and removed at runtime. The listener is
+	// registered before the caches are seeded, so that no cluster registered in the meantime is missed.
+	clientFactory.AddClusterListener(&kialiCacheImpl)
+
 	for cluster, client := range clientFactory.GetSAClients() {
-		cache, err := NewKubeCache(client, cfg, NewRegistryHandler(kialiCacheImpl.RefreshRegistryStatus), namespaceSeedList...)
+		cache, err := kialiCacheImpl.newKubeCache(client)
 		if err != nil {
 			log.Errorf("[Kiali Cache] Error creating kube cache for cluster: [%s]. Err: %v", cluster, err)
 			return nil, err
 		}
-		log.Infof("[Kiali Cache] Kube cache is active for cluster: [%s] and namespaces: %v", cluster, namespaceSeedList)
-
-		kialiCacheImpl.kubeCache[cluster] = cache
 
-		// TODO: Treat all clusters the same way.
-		if cluster == cfg.KubernetesConfig.ClusterName {
-			kialiCacheImpl.KubeCache = cache
+		// The cluster may have been added or removed by the listener in the meantime
+		kialiCacheImpl.kubeCacheLock.Lock()
+		_, known := clientFactory.GetSAClients()[cluster]
+		stored := known && kialiCacheImpl.storeKubeCache(cluster, cache)
+		kialiCacheImpl.kubeCacheLock.Unlock()
+		if !stored {
+			cache.Stop()
+			continue
 		}
+		log.Infof("[Kiali Cache] Kube cache is active for cluster: [%s] and namespaces: %v", cluster, namespaceSeedList)
 	}
 
 	// TODO: Treat all clusters the same way.
 	// Ensure home client got set.
-	if kialiCacheImpl.KubeCache == nil {
+	homeCache, err := kialiCacheImpl.GetKubeCache(cfg.KubernetesConfig.ClusterName)
+	if err != nil {
 		return nil, errors.New("home cluster not configured in kiali cache")
 	}
+	kialiCacheImpl.KubeCache = homeCache
 
 	// Starting background goroutines to:
 	// 1. Refresh the cache's service account token
@@ -127,11 +150,20 @@ func NewKialiCache(clientFactory kubernetes.ClientFactory, cfg config.Config, na
 }
 
 // GetKubeCaches returns a kube cache for every configured Kiali Service Account client keyed by cluster name.
+// The returned map is a copy: the remote clusters can change at runtime.
 func (c *kialiCacheImpl) GetKubeCaches() map[string]KubeCache {
-	return c.kubeCache
+	c.kubeCacheLock.RLock()
+	defer c.kubeCacheLock.RUnlock()
+	caches := make(map[string]KubeCache, len(c.kubeCache))
+	for cluster, cache := range c.kubeCache {
+		caches[cluster] = cache
+	}
+	return caches
 }
 
 func (c *kialiCacheImpl) GetKubeCache(cluster string) (KubeCache, error) {
+	c.kubeCacheLock.RLock()
+	defer c.kubeCacheLock.RUnlock()
 	cache, found := c.kubeCache[cluster]
 	if !found {
 		// This should not happen but it probably means the user clients have clusters that the cache doesn't know about.
@@ -145,7 +177,7 @@ func (c *kialiCacheImpl) Stop() {
 	log.Infof("Stopping Kiali Cache")
 
 	wg := sync.WaitGroup{}
-	for _, kc := range c.kubeCache {
+	for _, kc := range c.GetKubeCaches() {
 		wg.Add(1)
 		go func(c KubeCache) {
 			defer wg.Done()
@@ -157,6 +189,56 @@ func (c *kialiCacheImpl) Stop() {
 	c.cleanup()
 }
 
+// ClusterAdded creates the kube cache of a remote cluster registered at runtime. The cache of a known cluster is
+// updated with the new client.
+func (c *kialiCacheImpl) ClusterAdded(cluster string, client kubernetes.ClientInterface) {
+	if kc, err := c.GetKubeCache(cluster); err == nil {
+		if err := kc.UpdateClient(client); err != nil {
+			log.Errorf("[Kiali Cache] Error updating kube cache for cluster: [%s]. Err: %v", cluster, err)
+		}
+		return
+	}
+
+	kc, err := c.newKubeCache(client)
+	if err != nil {
+		log.Errorf("[Kiali Cache] Error creating kube cache for cluster: [%s]. Err: %v", cluster, err)
+		return
+	}
+
+	c.kubeCacheLock.Lock()
+	stored := c.storeKubeCache(cluster, kc)
+	c.kubeCacheLock.Unlock()
+	if !stored {
+		// The cache was seeded concurrently
+		kc.Stop()
+		return
+	}
+	log.Infof("[Kiali Cache] Kube cache is active for cluster: [%s]", cluster)
+}
+
+// storeKubeCache stores the kube cache of a cluster that has none yet, and returns false otherwise. The caller holds
+// the lock of the kube caches.
+func (c *kialiCacheImpl) storeKubeCache(cluster string, kc KubeCache) bool {
+	if _, found := c.kubeCache[cluster]; found {
+		return false
+	}
+	c.kubeCache[cluster] = kc
+	return true
+}
+
+// ClusterRemoved stops and drops the kube cache of a remote cluster unregistered at runtime
+func (c *kialiCacheImpl) ClusterRemoved(cluster string) {
+	c.kubeCacheLock.Lock()
+	kc, found := c.kubeCache[cluster]
+	delete(c.kubeCache, cluster)
+	c.kubeCacheLock.Unlock()
+
+	if found {
+		log.Infof("[Kiali Cache] Stopping kube cache for cluster: [%s]", cluster)
+		kc.Stop()
+	}
+}
+
 // watchForClientChanges watches for changes to the cache's service account client
 // and recreates the cache(s) when the client changes. The client is updated when
 // the token for the client changes.
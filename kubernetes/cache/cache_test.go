@@ -2,6 +2,7 @@ package cache
 
 import (
 	"context"
+	"sync"
 	"testing"
 	"time"
 
@@ -117,3 +118,67 @@ func TestKubeCacheCreatedPerClient(t *testing.T) {
 	_, err = kialiCache.GetKubeCache("cluster3")
 	require.Error(err)
 }
+
+func TestKubeCachesFollowTheRemoteClusters(t *testing.T) {
+	require := require.New(t)
+	conf := config.NewConfig()
+	config.Set(conf)
+
+	clientFactory := kubetest.NewK8SClientFactoryMock(kubetest.NewFakeK8sClient())
+	kialiCache, err := NewKialiCache(clientFactory, *conf)
+	require.NoError(err)
+	defer kialiCache.Stop()
+	require.Len(clientFactory.Listeners, 1)
+
+	clientFactory.Listeners[0].ClusterAdded("east", kubetest.NewFakeK8sClient())
+	_, err = kialiCache.GetKubeCache("east")
+	require.NoError(err)
+	require.Len(kialiCache.GetKubeCaches(), 2)
+
+	clientFactory.Listeners[0].ClusterRemoved("east")
+	_, err = kialiCache.GetKubeCache("east")
+	require.Error(err)
+	require.Len(kialiCache.GetKubeCaches(), 1)
+}
+
+// racingClientFactory registers a remote cluster right after the first listing of the clients
+type racingClientFactory struct {
+	*kubetest.K8SClientFactoryMock
+	once    sync.Once
+	cluster string
+	client  kubernetes.ClientInterface
+}
+
+func (f *racingClientFactory) GetSAClients() map[string]kubernetes.ClientInterface {
+	clients := f.K8SClientFactoryMock.GetSAClients()
+	f.once.Do(func() {
+		registered := map[string]kubernetes.ClientInterface{f.cluster: f.client}
+		for cluster, client := range clients {
+			registered[cluster] = client
+		}
+		f.SetClients(registered)
+		for _, listener := range f.Listeners {
+			listener.ClusterAdded(f.cluster, f.client)
+		}
+	})
+	return clients
+}
+
+func TestKubeCachesOfTheClustersRegisteredWhileSeeding(t *testing.T) {
+	require := require.New(t)
+	conf := config.NewConfig()
+	config.Set(conf)
+
+	clientFactory := &racingClientFactory{
+		K8SClientFactoryMock: kubetest.NewK8SClientFactoryMock(kubetest.NewFakeK8sClient()),
+		cluster:              "east",
+		client:               kubetest.NewFakeK8sClient(),
+	}
+	kialiCache, err := NewKialiCache(clientFactory, *conf)
+	require.NoError(err)
+	defer kialiCache.Stop()
+
+	_, err = kialiCache.GetKubeCache("east")
+	require.NoError(err)
+	require.Len(kialiCache.GetKubeCaches(), 2)
+}
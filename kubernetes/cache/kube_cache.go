@@ -1,6 +1,7 @@
 package cache
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"regexp"
@@ -21,7 +22,9 @@ import (
 	istiotelem_v1alpha1_listers "istio.io/client-go/pkg/listers/telemetry/v1alpha1"
 	apps_v1 "k8s.io/api/apps/v1"
 	core_v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/dynamic/dynamicinformer"
 	"k8s.io/client-go/informers"
 	apps_v1_listers "k8s.io/client-go/listers/apps/v1"
 	core_v1_listers "k8s.io/client-go/listers/core/v1"
@@ -31,6 +34,7 @@ import (
 	k8s_v1beta1_listers "sigs.k8s.io/gateway-api/pkg/client/listers/apis/v1beta1"
 
 	"github.com/kiali/kiali/config"
+	"github.com/kiali/kiali/config/dashboards"
 	"github.com/kiali/kiali/kubernetes"
 	"github.com/kiali/kiali/log"
 )
@@ -60,6 +64,7 @@ type KubeCache interface {
 	CheckIstioResource(resourceType string) bool
 
 	GetConfigMap(namespace, name string) (*core_v1.ConfigMap, error)
+	GetConfigMaps(namespace, labelSelector string) ([]core_v1.ConfigMap, error)
 	GetDaemonSets(namespace string) ([]apps_v1.DaemonSet, error)
 	GetDaemonSet(namespace, name string) (*apps_v1.DaemonSet, error)
 	GetDeployments(namespace string) ([]apps_v1.Deployment, error)
@@ -104,6 +109,10 @@ type KubeCache interface {
 	GetPeerAuthentications(namespace, labelSelector string) ([]*security_v1beta1.PeerAuthentication, error)
 	GetRequestAuthentication(namespace, name string) (*security_v1beta1.RequestAuthentication, error)
 	GetRequestAuthentications(namespace, labelSelector string) ([]*security_v1beta1.RequestAuthentication, error)
+
+	// GetMonitoringDashboards returns the dashboards of the MonitoringDashboard resources of the namespace,
+	// or none when the MonitoringDashboard CRD isn't installed
+	GetMonitoringDashboards(namespace string) ([]dashboards.MonitoringDashboard, error)
 }
 
 // cacheLister combines a bunch of lister types into one.
@@ -120,6 +129,9 @@ type cacheLister struct {
 	serviceLister     core_v1_listers.ServiceLister
 	statefulSetLister apps_v1_listers.StatefulSetLister
 
+	// Kiali listers, nil when the CRD isn't installed
+	monitoringDashboardLister cache.GenericLister
+
 	cachesSynced []cache.InformerSynced
 
 	// Istio listers
@@ -342,6 +354,9 @@ func (c *kubeCache) startInformers(namespace string) error {
 		c.createIstioInformers(namespace),
 		c.createGatewayInformers(namespace),
 	}
+	if dashboardInformers := c.createDashboardInformers(namespace); dashboardInformers != nil {
+		informers = append(informers, dashboardInformers)
+	}
 
 	var scope string
 	stop := make(chan struct{})
@@ -467,6 +482,47 @@ func (c *kubeCache) createGatewayInformers(namespace string) gateway.SharedInfor
 	return sharedInformers
 }
 
+// createDashboardInformers creates the informers of the MonitoringDashboard resources, so that the dashboards
+// shipped by the applications are reloaded without restarting Kiali. Returns nil when the CRD isn't installed, or
+// when Kiali can't list and watch the resources: their informer would never sync, and block the cache.
+func (c *kubeCache) createDashboardInformers(namespace string) dynamicinformer.DynamicSharedInformerFactory {
+	if c.client.Dynamic() == nil {
+		return nil
+	}
+	if _, err := c.client.Kube().Discovery().ServerResourcesForGroupVersion(kubernetes.MonitoringDashboardGroupVersionV1Alpha1.String()); err != nil {
+		log.Debugf("[Kiali Cache] MonitoringDashboard resources are not available: %v", err)
+		return nil
+	}
+	if !c.canListAndWatch(namespace, kubernetes.MonitoringDashboardsResource.Group, kubernetes.MonitoringDashboardsResource.Resource) {
+		log.Infof("[Kiali Cache] MonitoringDashboard resources are ignored: Kiali can't list and watch %s.%s in namespace [%s]",
+			kubernetes.MonitoringDashboardsResource.Resource, kubernetes.MonitoringDashboardsResource.Group, namespace)
+		return nil
+	}
+
+	sharedInformers := dynamicinformer.NewFilteredDynamicSharedInformerFactory(c.client.Dynamic(), c.refreshDuration, namespace, nil)
+	informer := sharedInformers.ForResource(kubernetes.MonitoringDashboardsResource)
+	lister := c.getCacheLister(namespace)
+	lister.monitoringDashboardLister = informer.Lister()
+	lister.cachesSynced = append(lister.cachesSynced, informer.Informer().HasSynced)
+	return sharedInformers
+}
+
+// canListAndWatch returns true if Kiali can list and watch the resources in the namespace, or in all the namespaces
+// when the namespace is empty
+func (c *kubeCache) canListAndWatch(namespace, group, resource string) bool {
+	reviews, err := c.client.GetSelfSubjectAccessReview(context.TODO(), namespace, group, resource, []string{"list", "watch"})
+	if err != nil || len(reviews) != 2 {
+		log.Debugf("[Kiali Cache] Unable to review the access to %s.%s: %v", resource, group, err)
+		return false
+	}
+	for _, review := range reviews {
+		if !review.Status.Allowed {
+			return false
+		}
+	}
+	return true
+}
+
 // createKubernetesInformers creates kube informers for all objects kiali watches and
 // saves them to the typeCache. If namespace is not empty, the informers are scoped
 // to the namespace. Otherwise, the informers are cluster-wide.
@@ -534,6 +590,32 @@ func (c *kubeCache) GetConfigMap(namespace, name string) (*core_v1.ConfigMap, er
 	return retCM, nil
 }
 
+func (c *kubeCache) GetConfigMaps(namespace, labelSelector string) ([]core_v1.ConfigMap, error) {
+	// Read lock will prevent the cache from being refreshed while we are reading from the lister
+	// but it won't prevent other routines from reading from the lister.
+	defer c.cacheLock.RUnlock()
+	c.cacheLock.RLock()
+	selector, err := labels.Parse(labelSelector)
+	if err != nil {
+		return nil, err
+	}
+
+	configMaps, err := c.getCacheLister(namespace).configMapLister.ConfigMaps(namespace).List(selector)
+	if err != nil {
+		return nil, err
+	}
+	log.Tracef("[Kiali Cache] Get [resource: ConfigMap] for [namespace: %s] = %d", namespace, len(configMaps))
+
+	retCMs := []core_v1.ConfigMap{}
+	for _, cm := range configMaps {
+		// Do not modify what is returned by the lister since that is shared and will cause data races.
+		configMap := cm.DeepCopy()
+		configMap.Kind = kubernetes.ConfigMapType
+		retCMs = append(retCMs, *configMap)
+	}
+	return retCMs, nil
+}
+
 func (c *kubeCache) GetDaemonSets(namespace string) ([]apps_v1.DaemonSet, error) {
 	// Read lock will prevent the cache from being refreshed while we are reading from the lister
 	// but it won't prevent other routines from reading from the lister.
@@ -1575,3 +1657,41 @@ func (c *kubeCache) GetRequestAuthentications(namespace, labelSelector string) (
 	}
 	return retRAs, nil
 }
+
+func (c *kubeCache) GetMonitoringDashboards(namespace string) ([]dashboards.MonitoringDashboard, error) {
+	// Read lock will prevent the cache from being refreshed while we are reading from the lister
+	// but it won't prevent other routines from reading from the lister.
+	defer c.cacheLock.RUnlock()
+	c.cacheLock.RLock()
+	lister := c.getCacheLister(namespace).monitoringDashboardLister
+	if lister == nil {
+		return []dashboards.MonitoringDashboard{}, nil
+	}
+
+	objects, err := lister.ByNamespace(namespace).List(labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+	log.Tracef("[Kiali Cache] Get [resource: MonitoringDashboard] for [namespace: %s] = %d", namespace, len(objects))
+
+	retDashboards := []dashboards.MonitoringDashboard{}
+	for _, obj := range objects {
+		resource, ok := obj.(*unstructured.Unstructured)
+		if !ok {
+			continue
+		}
+		// NestedMap returns a deep copy: what is returned by the lister is shared
+		spec, _, err := unstructured.NestedMap(resource.Object, "spec")
+		if err != nil {
+			log.Errorf("Invalid MonitoringDashboard %s/%s: %v", namespace, resource.GetName(), err)
+			continue
+		}
+		dashboard, err := dashboards.FromResourceSpec(resource.GetName(), spec)
+		if err != nil {
+			log.Errorf("Invalid MonitoringDashboard %s/%s: %v", namespace, resource.GetName(), err)
+			continue
+		}
+		retDashboards = append(retDashboards, *dashboard)
+	}
+	return retDashboards, nil
+}
@@ -10,11 +10,18 @@ import (
 	"github.com/stretchr/testify/require"
 	networking_v1beta1 "istio.io/client-go/pkg/apis/networking/v1beta1"
 	apps_v1 "k8s.io/api/apps/v1"
+	auth_v1 "k8s.io/api/authorization/v1"
 	core_v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
 
 	"github.com/kiali/kiali/config"
+	"github.com/kiali/kiali/config/dashboards"
 	"github.com/kiali/kiali/kubernetes"
 	"github.com/kiali/kiali/kubernetes/kubetest"
 )
@@ -362,3 +369,96 @@ func TestIstioAPIDisabled(t *testing.T) {
 
 	assert.Error(err)
 }
+
+// newDashboardClient returns a client serving the MonitoringDashboard resources, that Kiali can list and watch
+// when allowed
+func newDashboardClient(allowed bool, objects ...runtime.Object) *kubetest.FakeK8sClient {
+	resource := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": kubernetes.MonitoringDashboardGroupVersionV1Alpha1.String(),
+		"kind":       "MonitoringDashboard",
+		"metadata":   map[string]interface{}{"name": "jvm", "namespace": "bookinfo"},
+		"spec": map[string]interface{}{
+			"title": "JVM",
+			"items": []interface{}{map[string]interface{}{"chart": map[string]interface{}{"name": "Heap", "metricName": "jvm_memory_bytes_used", "dataType": "raw"}}},
+		},
+	}}
+
+	client := kubetest.NewFakeK8sClient(objects...)
+	client.DynamicClient = dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{kubernetes.MonitoringDashboardsResource: "MonitoringDashboardList"}, resource)
+	clientset := client.KubeClientset.(*kubefake.Clientset)
+	clientset.Resources = []*metav1.APIResourceList{{
+		GroupVersion: kubernetes.MonitoringDashboardGroupVersionV1Alpha1.String(),
+		APIResources: []metav1.APIResource{{Name: "monitoringdashboards", Namespaced: true, Kind: "MonitoringDashboard"}},
+	}}
+	clientset.PrependReactor("create", "selfsubjectaccessreviews", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		review := action.(k8stesting.CreateAction).GetObject().(*auth_v1.SelfSubjectAccessReview)
+		review.Status.Allowed = allowed
+		return true, review, nil
+	})
+	return client
+}
+
+func TestGetDashboardResources(t *testing.T) {
+	require := require.New(t)
+	assert := assert.New(t)
+
+	cfg := config.NewConfig()
+	config.Set(cfg)
+
+	labeled := &core_v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "dashboards", Namespace: "bookinfo", Labels: map[string]string{dashboards.DashboardConfigMapLabel: "true"}},
+		Data:       map[string]string{"queue.yaml": "name: queue\ntitle: Queue"},
+	}
+	other := &core_v1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: "bookinfo"}}
+
+	kubeCache, err := NewKubeCache(newDashboardClient(true, labeled, other), *cfg, NewRegistryHandler(func() {}))
+	require.NoError(err)
+	defer kubeCache.Stop()
+
+	configMaps, err := kubeCache.GetConfigMaps("bookinfo", dashboards.DashboardConfigMapLabel)
+	require.NoError(err)
+	require.Len(configMaps, 1)
+	assert.Equal("dashboards", configMaps[0].Name)
+
+	found, err := kubeCache.GetMonitoringDashboards("bookinfo")
+	require.NoError(err)
+	require.Len(found, 1)
+	assert.Equal("jvm", found[0].Name)
+	assert.Equal("JVM", found[0].Title)
+	require.Len(found[0].Items, 1)
+	assert.Equal("jvm_memory_bytes_used", found[0].Items[0].Chart.MetricName)
+}
+
+func TestGetDashboardResourcesWithoutAccess(t *testing.T) {
+	require := require.New(t)
+
+	cfg := config.NewConfig()
+	config.Set(cfg)
+
+	// The cache doesn't wait for the resources Kiali can't list and watch
+	kubeCache, err := NewKubeCache(newDashboardClient(false), *cfg, NewRegistryHandler(func() {}))
+	require.NoError(err)
+	defer kubeCache.Stop()
+
+	found, err := kubeCache.GetMonitoringDashboards("bookinfo")
+	require.NoError(err)
+	require.Empty(found)
+}
+
+func TestGetDashboardResourcesWithoutCRD(t *testing.T) {
+	require := require.New(t)
+
+	cfg := config.NewConfig()
+	config.Set(cfg)
+
+	client := kubetest.NewFakeK8sClient()
+	client.DynamicClient = dynamicfake.NewSimpleDynamicClient(runtime.NewScheme())
+	kubeCache, err := NewKubeCache(client, *cfg, NewRegistryHandler(func() {}))
+	require.NoError(err)
+	defer kubeCache.Stop()
+
+	found, err := kubeCache.GetMonitoringDashboards("bookinfo")
+	require.NoError(err)
+	require.Empty(found)
+}
@@ -0,0 +1,75 @@
+package cache
+
+import (
+	"time"
+
+	"github.com/kiali/kiali/kubernetes"
+)
+
+type (
+	PermissionsCache interface {
+		SetPermissions(tokenHash string, cluster string, namespace string, permissions *kubernetes.NamespacePermissions)
+		GetPermissions(tokenHash string, cluster string, namespace string) *kubernetes.NamespacePermissions
+		RefreshTokenPermissions(tokenHash string)
+	}
+)
+
+// permissionsCache caches the permissions of a user in a namespace
+type permissionsCache struct {
+	created     time.Time
+	permissions *kubernetes.NamespacePermissions
+}
+
+// SetPermissions caches the permissions of the user, identified by the hash of its credentials (see
+// kubernetes.GetTokenHash), in the namespace of the cluster. The expired permissions of the other users are
+// dropped when a new user is cached.
+func (c *kialiCacheImpl) SetPermissions(tokenHash string, cluster string, namespace string, permissions *kubernetes.NamespacePermissions) {
+	defer c.permissionsLock.Unlock()
+	c.permissionsLock.Lock()
+	userPermissions, found := c.tokenPermissions[tokenHash]
+	if !found {
+		c.pruneTokenPermissions()
+		userPermissions = make(map[string]permissionsCache)
+		c.tokenPermissions[tokenHash] = userPermissions
+	}
+	userPermissions[cluster+"/"+namespace] = permissionsCache{
+		created:     time.Now(),
+		permissions: permissions,
+	}
+}
+
+// GetPermissions returns the cached permissions of the user in the namespace of the cluster, or nil if they
+// aren't cached or have expired
+func (c *kialiCacheImpl) GetPermissions(tokenHash string, cluster string, namespace string) *kubernetes.NamespacePermissions {
+	defer c.permissionsLock.RUnlock()
+	c.permissionsLock.RLock()
+	if cached, found := c.tokenPermissions[tokenHash][cluster+"/"+namespace]; found {
+		if time.Since(cached.created) < c.tokenPermissionsDuration {
+			return cached.permissions
+		}
+	}
+	return nil
+}
+
+// RefreshTokenPermissions drops the cached permissions of the user, e.g. when they proved to be stale
+func (c *kialiCacheImpl) RefreshTokenPermissions(tokenHash string) {
+	defer c.permissionsLock.Unlock()
+	c.permissionsLock.Lock()
+	delete(c.tokenPermissions, tokenHash)
+}
+
+// pruneTokenPermissions drops the users whose permissions have all expired. The lock must be held.
+func (c *kialiCacheImpl) pruneTokenPermissions() {
+	for tokenHash, userPermissions := range c.tokenPermissions {
+		expired := true
+		for _, cached := range userPermissions {
+			if time.Since(cached.created) < c.tokenPermissionsDuration {
+				expired = false
+				break
+			}
+		}
+		if expired {
+			delete(c.tokenPermissions, tokenHash)
+		}
+	}
+}
@@ -11,6 +11,7 @@ import (
 	istio "istio.io/client-go/pkg/clientset/versioned"
 	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/version"
+	"k8s.io/client-go/dynamic"
 	kube "k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd/api"
@@ -67,6 +68,8 @@ type K8SClient struct {
 	isGatewayAPI *bool
 	gatewayapi   gatewayapiclient.Interface
 	isIstioAPI   *bool
+	// Used for the resources without typed clientset, e.g. the MonitoringDashboards
+	dynamic dynamic.Interface
 
 	// Separated out for testing purposes
 	getPodPortForwarderFunc func(namespace, name, portMap string) (httputil.PortForwarder, error)
@@ -77,6 +80,17 @@ func (client *K8SClient) GetToken() string {
 	return client.token
 }
 
+// GetAuthInfo returns the credentials of the client: its token and the identity that it impersonates
+func (client *K8SClient) GetAuthInfo() *api.AuthInfo {
+	authInfo := &api.AuthInfo{Token: client.token}
+	if client.restConfig != nil {
+		authInfo.Impersonate = client.restConfig.Impersonate.UserName
+		authInfo.ImpersonateGroups = client.restConfig.Impersonate.Groups
+		authInfo.ImpersonateUserExtra = client.restConfig.Impersonate.Extra
+	}
+	return authInfo
+}
+
 // GetConfigForRemoteClusterInfo points the returned k8s client config to a remote cluster's API server.
 // The returned config will have the user's token associated with it.
 func GetConfigForRemoteClusterInfo(cluster RemoteClusterInfo) (*rest.Config, error) {
@@ -202,6 +216,11 @@ func NewClientFromConfig(config *rest.Config) (*K8SClient, error) {
 		return nil, err
 	}
 
+	client.dynamic, err = dynamic.NewForConfig(config)
+	if err != nil {
+		return nil, err
+	}
+
 	client.ctx = context.Background()
 
 	return &client, nil
@@ -4,6 +4,7 @@ import (
 	"crypto/md5"
 	"errors"
 	"fmt"
+	"sort"
 	"sync"
 	"time"
 
@@ -39,6 +40,15 @@ type ClientFactory interface {
 	GetSAClient(cluster string) ClientInterface
 	GetSAClients() map[string]ClientInterface
 	GetSAHomeClusterClient() ClientInterface
+	GetRemoteClusterInfos() map[string]RemoteClusterInfo
+	AddClusterListener(listener ClusterListener)
+}
+
+// ClusterListener is notified of the remote clusters registered and unregistered at runtime, with the Kiali service
+// account client of the cluster. A cluster whose secret changed is added again with a new client.
+type ClusterListener interface {
+	ClusterAdded(cluster string, client ClientInterface)
+	ClusterRemoved(cluster string)
 }
 
 // clientFactory used to generate per users clients
@@ -67,6 +77,9 @@ type clientFactory struct {
 	// maps cluster name to a kiali client for that cluster. The kiali client uses the
 	// kiali service account to access the cluster API.
 	saClientEntries map[string]ClientInterface
+
+	// clusterListeners are notified of the remote clusters added and removed at runtime
+	clusterListeners []ClusterListener
 }
 
 // GetClientFactory returns the client factory. Creates a new one if necessary
@@ -90,7 +103,24 @@ func GetClientFactory() (ClientFactory, error) {
 		}
 
 		factory, err = newClientFactory(&baseConfig)
+		if err != nil {
+			return
+		}
+
+		// The remote clusters of the secrets are registered in the background, so that the callers don't wait for
+		// them. Kiali keeps working with the home cluster when the remote cluster secrets can't be watched.
+		if kialiConfig.Get().KubernetesConfig.RemoteClusterSecrets.Enabled {
+			go func(cf *clientFactory) {
+				if watchErr := cf.watchRemoteClusterSecrets(make(chan struct{})); watchErr != nil {
+					log.Errorf("Error watching the remote cluster secrets: %v", watchErr)
+				}
+			}(factory)
+		}
 	})
+	if err == nil && factory == nil {
+		// The first call failed to create the factory
+		err = errors.New("the client factory is not initialized")
+	}
 	return factory, err
 }
 
@@ -157,7 +187,7 @@ func (cf *clientFactory) newClient(authInfo *api.AuthInfo, expirationTime time.D
 	// If there is, use it UNLESS the token is the one of the Kiali SA. If
 	// the token is the one of the Kiali SA, the proxy can be bypassed.
 	cfg := kialiConfig.Get()
-	if cfg.Auth.Strategy == kialiConfig.AuthStrategyOpenId && cfg.Auth.OpenId.ApiProxy != "" && cfg.Auth.OpenId.ApiProxyCAData != "" {
+	if cfg.HasAuthStrategy(kialiConfig.AuthStrategyOpenId) && cfg.Auth.OpenId.ApiProxy != "" && cfg.Auth.OpenId.ApiProxyCAData != "" {
 
 		var kialiToken string
 		var err error
@@ -191,8 +221,11 @@ func (cf *clientFactory) newClient(authInfo *api.AuthInfo, expirationTime time.D
 		}
 	}
 
-	// Impersonation is valid only for header authentication strategy
-	if cfg.Auth.Strategy == kialiConfig.AuthStrategyHeader && authInfo.Impersonate != "" {
+	// Impersonation is valid only for header authentication strategy, or when Kiali impersonates the users
+	// with its service account: the users authenticated by the OpenId provider or the proxy, and the owners
+	// of the API tokens. The sessions of the other strategies never get the Kiali privileges.
+	impersonating := authInfo.Impersonate != "" && isKialiHomeToken(authInfo.Token)
+	if (cfg.HasAuthStrategy(kialiConfig.AuthStrategyHeader) || impersonating) && authInfo.Impersonate != "" {
 		config.Impersonate.UserName = authInfo.Impersonate
 		config.Impersonate.Groups = authInfo.ImpersonateGroups
 		config.Impersonate.Extra = authInfo.ImpersonateUserExtra
@@ -210,15 +243,15 @@ func (cf *clientFactory) newClient(authInfo *api.AuthInfo, expirationTime time.D
 
 	} else {
 		// Remote clusters
-		clusterInfo, errClusterInfo := GetRemoteClusterInfos()
-		if errClusterInfo == nil {
+		if clusterInfo, ok := cf.remoteClusterInfos[cluster]; ok {
 			var remoteConfig *rest.Config
 			var err2 error
-			// In auth strategy should we use SA token
-			if cfg.Auth.Strategy == kialiConfig.AuthStrategyAnonymous {
-				remoteConfig, err2 = GetConfigForRemoteClusterInfo(clusterInfo[cluster])
+			// In auth strategy should we use SA token. When impersonating the users, the SA
+			// token of the home cluster isn't valid in the remote cluster: its SA token is used.
+			if cfg.Auth.Strategy == kialiConfig.AuthStrategyAnonymous || (impersonating && authInfo.Impersonate != "") {
+				remoteConfig, err2 = GetConfigForRemoteClusterInfo(clusterInfo)
 			} else {
-				remoteConfig, err2 = GetConfigWithTokenForRemoteCluster(clusterInfo[cluster].Cluster,
+				remoteConfig, err2 = GetConfigWithTokenForRemoteCluster(clusterInfo.Cluster,
 					RemoteSecretUser{
 						Name: authInfo.Username, User: RemoteSecretUserToken{Token: authInfo.Token},
 					})
@@ -226,13 +259,16 @@ func (cf *clientFactory) newClient(authInfo *api.AuthInfo, expirationTime time.D
 
 			if err2 != nil {
 				log.Errorf("Error getting remote cluster [%s] info: %s", cluster, err2)
+			} else {
+				remoteConfig.Impersonate = config.Impersonate
 			}
 			newClient, err = NewClientFromConfig(remoteConfig)
 			if err != nil {
 				log.Errorf("Error getting remote client for cluster %s, %s", cluster, err.Error())
 			}
 		} else {
-			log.Errorf("Error getting remote cluster infos: %c", errClusterInfo)
+			err = fmt.Errorf("unknown remote cluster [%s]", cluster)
+			log.Errorf("Error getting remote cluster info: %s", err)
 		}
 	}
 
@@ -244,11 +280,17 @@ func (cf *clientFactory) newClient(authInfo *api.AuthInfo, expirationTime time.D
 			<-time.After(expirationTime)
 			cf.recycleChan <- token
 		}
-	}(getTokenHash(authInfo), err)
+	}(GetTokenHash(authInfo), err)
 
 	return newClient, err
 }
 
+// isKialiHomeToken returns true if the token is the token of the Kiali service account in the home cluster
+func isKialiHomeToken(token string) bool {
+	kialiToken, err := GetKialiTokenForHomeCluster()
+	return err == nil && kialiToken != "" && token == kialiToken
+}
+
 // newSAClient returns a new client for the given cluster. If clusterInfo is nil then a client for the local cluster is returned.
 func (cf *clientFactory) newSAClient(clusterInfo *RemoteClusterInfo) (*K8SClient, error) {
 	// if no cluster info is provided, we are being asked to create a new client for the home cluster
@@ -271,11 +313,80 @@ func (cf *clientFactory) newSAClient(clusterInfo *RemoteClusterInfo) (*K8SClient
 	}
 }
 
-// getClient returns a client for the specified token. Creating one if necessary.
+// GetSAClients returns the Kiali service account clients of all the clusters, keyed on cluster name.
+// The returned map is a copy: the remote clusters can change at runtime.
 func (cf *clientFactory) GetSAClients() map[string]ClientInterface {
 	cf.mutex.RLock()
 	defer cf.mutex.RUnlock()
-	return cf.saClientEntries
+	clients := make(map[string]ClientInterface, len(cf.saClientEntries))
+	for cluster, client := range cf.saClientEntries {
+		clients[cluster] = client
+	}
+	return clients
+}
+
+// GetRemoteClusterInfos returns a copy of the information on the remote clusters, keyed on cluster name. It includes
+// the clusters of the mounted secrets, and the clusters registered at runtime.
+func (cf *clientFactory) GetRemoteClusterInfos() map[string]RemoteClusterInfo {
+	cf.mutex.RLock()
+	defer cf.mutex.RUnlock()
+	infos := make(map[string]RemoteClusterInfo, len(cf.remoteClusterInfos))
+	for cluster, info := range cf.remoteClusterInfos {
+		infos[cluster] = info
+	}
+	return infos
+}
+
+// AddClusterListener registers a listener of the remote clusters added and removed at runtime
+func (cf *clientFactory) AddClusterListener(listener ClusterListener) {
+	cf.mutex.Lock()
+	defer cf.mutex.Unlock()
+	cf.clusterListeners = append(cf.clusterListeners, listener)
+}
+
+// addRemoteCluster registers a remote cluster, or replaces a known one, and notifies the listeners.
+// The user clients of a replaced cluster are dropped, so that they are created again with the new cluster info.
+func (cf *clientFactory) addRemoteCluster(cluster string, clusterInfo RemoteClusterInfo) error {
+	client, err := cf.newSAClient(&clusterInfo)
+	if err != nil {
+		return err
+	}
+
+	cf.mutex.Lock()
+	cf.remoteClusterInfos[cluster] = clusterInfo
+	cf.saClientEntries[cluster] = client
+	for _, clients := range cf.clientEntries {
+		delete(clients, cluster)
+	}
+	listeners := append([]ClusterListener{}, cf.clusterListeners...)
+	cf.mutex.Unlock()
+
+	log.Infof("Remote cluster [%s] of secret [%s] has been registered", cluster, clusterInfo.SecretName)
+	for _, listener := range listeners {
+		listener.ClusterAdded(cluster, client)
+	}
+	return nil
+}
+
+// removeRemoteCluster unregisters a remote cluster with its clients, and notifies the listeners
+func (cf *clientFactory) removeRemoteCluster(cluster string) {
+	cf.mutex.Lock()
+	if _, ok := cf.remoteClusterInfos[cluster]; !ok {
+		cf.mutex.Unlock()
+		return
+	}
+	delete(cf.remoteClusterInfos, cluster)
+	delete(cf.saClientEntries, cluster)
+	for _, clients := range cf.clientEntries {
+		delete(clients, cluster)
+	}
+	listeners := append([]ClusterListener{}, cf.clusterListeners...)
+	cf.mutex.Unlock()
+
+	log.Infof("Remote cluster [%s] has been unregistered", cluster)
+	for _, listener := range listeners {
+		listener.ClusterRemoved(cluster)
+	}
 }
 
 // getClient returns a client for the specified token. Creating one if necessary.
@@ -287,7 +398,7 @@ func (cf *clientFactory) GetClient(authInfo *api.AuthInfo) (ClientInterface, err
 func (cf *clientFactory) GetClients(authInfo *api.AuthInfo) (map[string]ClientInterface, error) {
 	clients := make(map[string]ClientInterface)
 	// Try to create a user client for each cluster there's a kiali service account configured.
-	for cluster := range cf.saClientEntries {
+	for cluster := range cf.GetSAClients() {
 		ci, err := cf.getRecycleClient(authInfo, defaultExpirationTime, cluster)
 		if err != nil {
 			log.Errorf("Error returning user client for cluster: %s. Err: %s", cluster, err)
@@ -306,7 +417,7 @@ func (cf *clientFactory) GetClients(authInfo *api.AuthInfo) (map[string]ClientIn
 func (cf *clientFactory) getRecycleClient(authInfo *api.AuthInfo, expirationTime time.Duration, cluster string) (ClientInterface, error) {
 	cf.mutex.Lock()
 	defer cf.mutex.Unlock()
-	tokenHash := getTokenHash(authInfo)
+	tokenHash := GetTokenHash(authInfo)
 	if cEntry, ok := cf.clientEntries[tokenHash][cluster]; ok {
 		return cEntry, nil
 	} else {
@@ -329,7 +440,7 @@ func (cf *clientFactory) getRecycleClient(authInfo *api.AuthInfo, expirationTime
 // This is a helper function for testing.
 // It uses the shared lock so beware of nested locking with other methods.
 func (cf *clientFactory) hasClient(authInfo *api.AuthInfo) (map[string]ClientInterface, bool) {
-	tokenHash := getTokenHash(authInfo)
+	tokenHash := GetTokenHash(authInfo)
 	cf.mutex.RLock()
 	cEntry, ok := cf.clientEntries[tokenHash]
 	defer cf.mutex.RUnlock()
@@ -366,37 +477,34 @@ func (cf *clientFactory) deleteClient(token string) {
 	internalmetrics.SetKubernetesClients(len(cf.clientEntries)) // TODO: + 2 dimmension map?
 }
 
-// getTokenHash get the token hash of a client
-func getTokenHash(authInfo *api.AuthInfo) string {
-	tokenData := authInfo.Token
+// GetTokenHash get the token hash of a client. Every field is prefixed by its length, so that different
+// credentials never have the same hash data, e.g. a user and a group swapping some characters.
+func GetTokenHash(authInfo *api.AuthInfo) string {
+	fields := []string{authInfo.Token, authInfo.Impersonate}
+	fields = append(fields, fmt.Sprint(len(authInfo.ImpersonateGroups)))
+	fields = append(fields, authInfo.ImpersonateGroups...)
 
-	if authInfo.Impersonate != "" {
-		tokenData += authInfo.Impersonate
-	}
-
-	if authInfo.ImpersonateGroups != nil {
-		for _, group := range authInfo.ImpersonateGroups {
-			tokenData += group
-		}
+	keys := make([]string, 0, len(authInfo.ImpersonateUserExtra))
+	for key := range authInfo.ImpersonateUserExtra {
+		keys = append(keys, key)
 	}
-
-	if authInfo.ImpersonateUserExtra != nil {
-		for key, element := range authInfo.ImpersonateUserExtra {
-			for _, userExtra := range element {
-				tokenData += key + userExtra
-			}
-		}
+	sort.Strings(keys)
+	for _, key := range keys {
+		fields = append(fields, key, fmt.Sprint(len(authInfo.ImpersonateUserExtra[key])))
+		fields = append(fields, authInfo.ImpersonateUserExtra[key]...)
 	}
 
 	h := md5.New()
-	_, err := h.Write([]byte(tokenData))
-	if err != nil {
-		// errcheck linter want us to check for the error returned by h.Write.
-		// However, docs of md5 say that this Writer never returns an error.
-		// See: https://golang.org/pkg/hash/#Hash
-		// So, let's check the error, and panic. Per the docs, this panic should
-		// never be reached.
-		panic("md5.Write returned error.")
+	for _, field := range fields {
+		_, err := fmt.Fprintf(h, "%d:%s", len(field), field)
+		if err != nil {
+			// errcheck linter want us to check for the error returned by h.Write.
+			// However, docs of md5 say that this Writer never returns an error.
+			// See: https://golang.org/pkg/hash/#Hash
+			// So, let's check the error, and panic. Per the docs, this panic should
+			// never be reached.
+			panic("md5.Write returned error.")
+		}
 	}
 	return string(h.Sum(nil))
 }
@@ -439,6 +547,9 @@ func (cf *clientFactory) refreshClientIfTokenChanged(cluster string) error {
 		cf.mutex.RUnlock()
 		if !ok {
 			return fmt.Errorf("Cannot refresh token for unknown cluster [%s]", cluster)
+		} else if remoteRci.SecretFile == "" {
+			// The clusters of the watched secrets are refreshed when their secret changes
+			return nil
 		} else {
 			if reloadedRci, err := reloadRemoteClusterInfoFromFile(remoteRci); err != nil {
 				return err
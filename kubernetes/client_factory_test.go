@@ -168,3 +168,53 @@ func TestSAClientsUpdateWhenKialiTokenChanges(t *testing.T) {
 	client = clientFactory.GetSAClient(HomeClusterName)
 	require.Equal(KialiTokenForHomeCluster, client.GetToken())
 }
+
+func TestClientImpersonatesUsers(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	kialiConfig := config.NewConfig()
+	kialiConfig.Auth.Strategy = config.AuthStrategyOpenId
+	config.Set(kialiConfig)
+	t.Cleanup(func() {
+		KialiTokenForHomeCluster = ""
+	})
+
+	tokenRead = time.Now()
+	KialiTokenForHomeCluster = "kiali-token"
+
+	restConfig := rest.Config{}
+	clientFactory, err := newClientFactory(&restConfig)
+	require.NoError(err)
+
+	// The impersonation headers are only sent with the Kiali token
+	client, err := clientFactory.newClient(&api.AuthInfo{Token: "user-token", Impersonate: "oidc:jdoe"}, time.Minute, HomeClusterName)
+	require.NoError(err)
+	assert.Empty(client.(*K8SClient).restConfig.Impersonate.UserName)
+
+	authInfo := &api.AuthInfo{Token: "kiali-token", Impersonate: "oidc:jdoe", ImpersonateGroups: []string{"oidc:admins"}}
+	client, err = clientFactory.newClient(authInfo, time.Minute, HomeClusterName)
+	require.NoError(err)
+	restConfig = *client.(*K8SClient).restConfig
+	assert.Equal("kiali-token", restConfig.BearerToken)
+	assert.Equal("oidc:jdoe", restConfig.Impersonate.UserName)
+	assert.Equal([]string{"oidc:admins"}, restConfig.Impersonate.Groups)
+}
+
+func TestGetTokenHashSeparatesFields(t *testing.T) {
+	assert := assert.New(t)
+
+	hash := GetTokenHash(&api.AuthInfo{Token: "token", Impersonate: "jdoe", ImpersonateGroups: []string{"admins"}})
+	assert.Equal(hash, GetTokenHash(&api.AuthInfo{Token: "token", Impersonate: "jdoe", ImpersonateGroups: []string{"admins"}}))
+	assert.NotEqual(hash, GetTokenHash(&api.AuthInfo{Token: "token", Impersonate: "jdoeadmins"}))
+	assert.NotEqual(hash, GetTokenHash(&api.AuthInfo{Token: "tokenjdoe", ImpersonateGroups: []string{"admins"}}))
+	assert.NotEqual(hash, GetTokenHash(&api.AuthInfo{Token: "token", Impersonate: "jdoe", ImpersonateGroups: []string{"ad", "mins"}}))
+
+	extra := map[string][]string{"scopes": {"view"}, "reason": {"audit"}}
+	assert.Equal(
+		GetTokenHash(&api.AuthInfo{Token: "token", ImpersonateUserExtra: extra}),
+		GetTokenHash(&api.AuthInfo{Token: "token", ImpersonateUserExtra: map[string][]string{"reason": {"audit"}, "scopes": {"view"}}}))
+	assert.NotEqual(
+		GetTokenHash(&api.AuthInfo{Token: "token", ImpersonateUserExtra: extra}),
+		GetTokenHash(&api.AuthInfo{Token: "token", ImpersonateUserExtra: map[string][]string{"scopesview": {}, "reason": {"audit"}}}))
+}
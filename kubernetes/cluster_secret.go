@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"os"
 
+	core_v1 "k8s.io/api/core/v1"
+
 	"github.com/kiali/kiali/log"
 )
 
@@ -13,7 +15,8 @@ import (
 type RemoteClusterInfo struct {
 	// Cluster contains information necessary to connect to the remote cluster
 	Cluster RemoteSecretClusterListItem
-	// SecretFile is the absolute file location of the secret as found on the file system
+	// SecretFile is the absolute file location of the secret as found on the file system. It is empty for the
+	// clusters registered at runtime from a watched secret.
 	SecretFile string
 	// SecretName is the name of the secret where the data about this cluster was found
 	SecretName string
@@ -135,6 +138,26 @@ func getRemoteClusterInfosFromDir(rootSecretsDir string) (map[string]RemoteClust
 	return meshClusters, nil
 }
 
+// remoteClusterInfosFromSecret returns the remote clusters of a watched remote cluster secret, keyed on cluster name.
+// Like the mounted secrets, each data key of the secret is the name of a cluster, holding the kubeconfig to access it.
+// The invalid data keys are logged and ignored.
+func remoteClusterInfosFromSecret(secret *core_v1.Secret) map[string]RemoteClusterInfo {
+	clusters := make(map[string]RemoteClusterInfo, len(secret.Data))
+	for clusterName, kubeconfig := range secret.Data {
+		if len(kubeconfig) == 0 {
+			log.Errorf("There is no data for remote cluster [%s] in secret [%s]", clusterName, secret.Name)
+			continue
+		}
+		clusterInfo, err := newRemoteClusterInfo(secret.Name, "", kubeconfig)
+		if err != nil {
+			log.Errorf("Failed to process data for remote cluster [%s] in secret [%s]: %v", clusterName, secret.Name, err)
+			continue
+		}
+		clusters[clusterName] = clusterInfo
+	}
+	return clusters
+}
+
 // reloadRemoteClusterInfoFromFile will re-read the remote cluster secret from the file system and if the data is different
 // than the given RemoteClusterInfo, a new one is returned. Otherwise, nil is returned to indicate nothing has changed and
 // the given RemoteClusterInfo is already up to date.
@@ -0,0 +1,146 @@
+package kubernetes
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	core_v1 "k8s.io/api/core/v1"
+	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/tools/cache"
+
+	kialiConfig "github.com/kiali/kiali/config"
+	"github.com/kiali/kiali/log"
+)
+
+// remoteClusterSecretsSyncTimeout bounds the time waited for the clusters of the existing secrets before an error is
+// reported
+const remoteClusterSecretsSyncTimeout = time.Minute
+
+// remoteClusterSecretWatcher registers and unregisters the remote clusters of the labeled secrets as they change
+type remoteClusterSecretWatcher struct {
+	factory *clientFactory
+	// the clusters registered from each secret, keyed on secret name then cluster name
+	secretClusters map[string]map[string]RemoteClusterInfo
+	mutex          sync.Mutex
+}
+
+func newRemoteClusterSecretWatcher(factory *clientFactory) *remoteClusterSecretWatcher {
+	return &remoteClusterSecretWatcher{
+		factory:        factory,
+		secretClusters: make(map[string]map[string]RemoteClusterInfo),
+	}
+}
+
+// watchRemoteClusterSecrets starts an informer on the remote cluster secrets of the home cluster. It returns once the
+// clusters of the existing secrets are registered, or after a timeout. The informer runs until the stop channel is
+// closed.
+func (cf *clientFactory) watchRemoteClusterSecrets(stop <-chan struct{}) error {
+	cfg := kialiConfig.Get()
+	namespace := cfg.KubernetesConfig.RemoteClusterSecrets.Namespace
+	if namespace == "" {
+		namespace = cfg.IstioNamespace
+	}
+	labelSelector := cfg.KubernetesConfig.RemoteClusterSecrets.LabelSelector
+
+	watcher := newRemoteClusterSecretWatcher(cf)
+	informerFactory := informers.NewSharedInformerFactoryWithOptions(cf.GetSAHomeClusterClient().Kube(),
+		time.Duration(cfg.KubernetesConfig.CacheDuration)*time.Second,
+		informers.WithNamespace(namespace),
+		informers.WithTweakListOptions(func(opts *meta_v1.ListOptions) {
+			opts.LabelSelector = labelSelector
+		}))
+	informer := informerFactory.Core().V1().Secrets().Informer()
+	informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			if secret, ok := obj.(*core_v1.Secret); ok {
+				watcher.syncSecret(secret)
+			}
+		},
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			if secret, ok := newObj.(*core_v1.Secret); ok {
+				watcher.syncSecret(secret)
+			}
+		},
+		DeleteFunc: func(obj interface{}) {
+			if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+				obj = tombstone.Obj
+			}
+			if secret, ok := obj.(*core_v1.Secret); ok {
+				watcher.deleteSecret(secret.Name)
+			}
+		},
+	})
+
+	log.Infof("Watching the remote cluster secrets [%s] of namespace [%s]", labelSelector, namespace)
+	informerFactory.Start(stop)
+
+	// The informer keeps retrying when the secrets can't be listed: report it instead of waiting forever
+	ctx, cancel := context.WithTimeout(context.Background(), remoteClusterSecretsSyncTimeout)
+	defer cancel()
+	if !cache.WaitForCacheSync(ctx.Done(), informer.HasSynced) {
+		return errors.New("timed out waiting for the remote cluster secrets to be listed")
+	}
+	return nil
+}
+
+// syncSecret registers the clusters added to or changed in the secret, and unregisters the clusters removed from it.
+// A cluster already provided by a mounted secret or by another watched secret is ignored.
+func (w *remoteClusterSecretWatcher) syncSecret(secret *core_v1.Secret) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	previous := w.secretClusters[secret.Name]
+	current := remoteClusterInfosFromSecret(secret)
+	registered := make(map[string]RemoteClusterInfo, len(current))
+	known := w.factory.GetRemoteClusterInfos()
+
+	for cluster := range previous {
+		if _, ok := current[cluster]; !ok {
+			w.factory.removeRemoteCluster(cluster)
+		}
+	}
+
+	for cluster, clusterInfo := range current {
+		if cluster == w.factory.homeCluster {
+			log.Errorf("Cluster [%s] of secret [%s] is the home cluster of Kiali. It is ignored.", cluster, secret.Name)
+			continue
+		}
+		if previousInfo, ok := previous[cluster]; ok {
+			if previousInfo == clusterInfo {
+				registered[cluster] = clusterInfo
+				continue
+			}
+		} else if knownInfo, ok := known[cluster]; ok {
+			log.Errorf("Cluster [%s] was already defined in secret [%v]. Two secrets must not provide information on the same cluster.", cluster, knownInfo.SecretName)
+			continue
+		}
+		if err := w.factory.addRemoteCluster(cluster, clusterInfo); err != nil {
+			log.Errorf("Failed to register remote cluster [%s] of secret [%s]: %v", cluster, secret.Name, err)
+			if _, ok := previous[cluster]; ok {
+				w.factory.removeRemoteCluster(cluster)
+			}
+			continue
+		}
+		registered[cluster] = clusterInfo
+	}
+
+	if len(registered) == 0 {
+		delete(w.secretClusters, secret.Name)
+	} else {
+		w.secretClusters[secret.Name] = registered
+	}
+}
+
+// deleteSecret unregisters the clusters of the deleted secret
+func (w *remoteClusterSecretWatcher) deleteSecret(secretName string) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	for cluster := range w.secretClusters[secretName] {
+		w.factory.removeRemoteCluster(cluster)
+	}
+	delete(w.secretClusters, secretName)
+}
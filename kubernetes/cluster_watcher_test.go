@@ -0,0 +1,99 @@
+package kubernetes
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v2"
+	core_v1 "k8s.io/api/core/v1"
+	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/rest"
+
+	"github.com/kiali/kiali/config"
+)
+
+// recordingClusterListener records the clusters added and removed at runtime
+type recordingClusterListener struct {
+	mutex   sync.Mutex
+	added   []string
+	removed []string
+}
+
+func (l *recordingClusterListener) ClusterAdded(cluster string, client ClientInterface) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	l.added = append(l.added, cluster)
+}
+
+func (l *recordingClusterListener) ClusterRemoved(cluster string) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	l.removed = append(l.removed, cluster)
+}
+
+func remoteClusterSecret(t *testing.T, name string, tokens map[string]string) *core_v1.Secret {
+	secret := &core_v1.Secret{
+		ObjectMeta: meta_v1.ObjectMeta{Name: name, Labels: map[string]string{"istio/multiCluster": "true"}},
+		Data:       map[string][]byte{},
+	}
+	for cluster, token := range tokens {
+		kubeconfig, err := yaml.Marshal(RemoteSecret{
+			Clusters: []RemoteSecretClusterListItem{{Name: cluster, Cluster: RemoteSecretCluster{Server: "https://" + cluster + ":6443"}}},
+			Users:    []RemoteSecretUser{{Name: "kiali", User: RemoteSecretUserToken{Token: token}}},
+		})
+		require.NoError(t, err)
+		secret.Data[cluster] = kubeconfig
+	}
+	return secret
+}
+
+func TestRemoteClustersFollowTheWatchedSecrets(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	originalRemoteClusterSecretsDir := RemoteClusterSecretsDir
+	defer func(dir string) {
+		RemoteClusterSecretsDir = dir
+	}(originalRemoteClusterSecretsDir)
+	RemoteClusterSecretsDir = t.TempDir()
+
+	conf := config.NewConfig()
+	conf.InCluster = false
+	conf.KubernetesConfig.ClusterName = "home"
+	config.Set(conf)
+
+	clientFactory, err := newClientFactory(&rest.Config{})
+	require.NoError(err)
+	listener := &recordingClusterListener{}
+	clientFactory.AddClusterListener(listener)
+	watcher := newRemoteClusterSecretWatcher(clientFactory)
+
+	watcher.syncSecret(remoteClusterSecret(t, "istio-remote-secret-east", map[string]string{"east": "token1", "west": "token1"}))
+	assert.ElementsMatch([]string{"east", "west"}, listener.added)
+	assert.Contains(clientFactory.GetSAClients(), "east")
+	assert.Equal("istio-remote-secret-east", clientFactory.GetRemoteClusterInfos()["east"].SecretName)
+	assert.Equal("https://east:6443", clientFactory.GetRemoteClusterInfos()["east"].Cluster.Cluster.Server)
+
+	// The changed clusters are added again, the others are left as is
+	listener.added = nil
+	watcher.syncSecret(remoteClusterSecret(t, "istio-remote-secret-east", map[string]string{"east": "token2"}))
+	assert.Equal([]string{"east"}, listener.added)
+	assert.Equal([]string{"west"}, listener.removed)
+	assert.Equal("token2", clientFactory.GetRemoteClusterInfos()["east"].User.User.Token)
+	assert.NotContains(clientFactory.GetSAClients(), "west")
+
+	// The home cluster and the clusters of other secrets are ignored
+	listener.added = nil
+	watcher.syncSecret(remoteClusterSecret(t, "other", map[string]string{"east": "token3", "home": "token3"}))
+	assert.Empty(listener.added)
+	assert.Equal("token2", clientFactory.GetRemoteClusterInfos()["east"].User.User.Token)
+
+	watcher.deleteSecret("other")
+	assert.Contains(clientFactory.GetSAClients(), "east")
+	watcher.deleteSecret("istio-remote-secret-east")
+	assert.NotContains(clientFactory.GetSAClients(), "east")
+	assert.Empty(clientFactory.GetRemoteClusterInfos())
+	assert.Equal([]string{"west", "east"}, listener.removed)
+}
@@ -25,6 +25,7 @@ import (
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/version"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/client-go/tools/clientcmd/api"
@@ -38,6 +39,8 @@ import (
 type K8SClientInterface interface {
 	// Kube returns the underlying kubernetes client.
 	Kube() kubernetes.Interface
+	// Dynamic returns the dynamic client, for the resources without typed clientset. Can be nil.
+	Dynamic() dynamic.Interface
 	GetClusterServicesByLabels(labelsSelector string) ([]core_v1.Service, error)
 	GetConfigMap(namespace, name string) (*core_v1.ConfigMap, error)
 	GetCronJobs(namespace string) ([]batch_v1.CronJob, error)
@@ -57,6 +60,7 @@ type K8SClientInterface interface {
 	GetReplicaSets(namespace string) ([]apps_v1.ReplicaSet, error)
 	GetSecret(namespace, name string) (*core_v1.Secret, error)
 	GetSelfSubjectAccessReview(ctx context.Context, namespace, api, resourceType string, verbs []string) ([]*auth_v1.SelfSubjectAccessReview, error)
+	GetSelfSubjectRulesReview(ctx context.Context, namespace string) (*auth_v1.SelfSubjectRulesReview, error)
 	GetService(namespace string, name string) (*core_v1.Service, error)
 	GetServices(namespace string, selectorLabels map[string]string) ([]core_v1.Service, error)
 	GetServicesByLabels(namespace string, labelsSelector string) ([]core_v1.Service, error)
@@ -102,6 +106,10 @@ func (in *K8SClient) forwardGetRequest(namespace, podName string, destinationPor
 	return resp, err
 }
 
+func (in *K8SClient) Dynamic() dynamic.Interface {
+	return in.dynamic
+}
+
 func (in *K8SClient) Kube() kubernetes.Interface {
 	return in.k8s
 }
@@ -555,6 +563,24 @@ func (in *K8SClient) GetSelfSubjectAccessReview(ctx context.Context, namespace,
 	return result, err
 }
 
+// GetSelfSubjectRulesReview returns all the rules of the user in the namespace, with a single call
+func (in *K8SClient) GetSelfSubjectRulesReview(ctx context.Context, namespace string) (*auth_v1.SelfSubjectRulesReview, error) {
+	if config.Get().Server.Observability.Tracing.Enabled {
+		var span trace.Span
+		ctx, span = otel.Tracer(observability.TracerName()).Start(ctx, "GetSelfSubjectRulesReview",
+			trace.WithAttributes(
+				attribute.String("package", "kubernetes"),
+				attribute.String("namespace", namespace),
+			),
+		)
+		defer span.End()
+	}
+
+	return in.k8s.AuthorizationV1().SelfSubjectRulesReviews().Create(ctx, &auth_v1.SelfSubjectRulesReview{
+		Spec: auth_v1.SelfSubjectRulesReviewSpec{Namespace: namespace},
+	}, meta_v1.CreateOptions{})
+}
+
 func (in *K8SClient) UpdateWorkload(namespace string, workloadName string, workloadType string, jsonPatch string, patchType string) error {
 	emptyPatchOptions := meta_v1.PatchOptions{}
 	bytePatch := []byte(jsonPatch)
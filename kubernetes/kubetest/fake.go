@@ -12,9 +12,11 @@ import (
 	kubeerrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
 	kubefake "k8s.io/client-go/kubernetes/fake"
 	kubescheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/clientcmd/api"
 	gatewayapi "sigs.k8s.io/gateway-api/pkg/client/clientset/versioned"
 	gatewayapifake "sigs.k8s.io/gateway-api/pkg/client/clientset/versioned/fake"
 	gatewayapischeme "sigs.k8s.io/gateway-api/pkg/client/clientset/versioned/scheme"
@@ -130,6 +132,8 @@ type FakeK8sClient struct {
 	IstioClientset istio.Interface
 	// Underlying gateway api clientset.
 	GatewayAPIClientset gatewayapi.Interface
+	// Underlying dynamic client, nil unless set by the test.
+	DynamicClient dynamic.Interface
 	// Token is the kiali token this client uses.
 	Token string
 }
@@ -139,6 +143,10 @@ func (c *FakeK8sClient) IsGatewayAPI() bool { return c.GatewayAPIEnabled }
 func (c *FakeK8sClient) IsIstioAPI() bool   { return c.IstioAPIEnabled }
 func (c *FakeK8sClient) GetToken() string   { return c.Token }
 
+func (c *FakeK8sClient) GetAuthInfo() *api.AuthInfo { return &api.AuthInfo{Token: c.Token} }
+
+func (c *FakeK8sClient) Dynamic() dynamic.Interface { return c.DynamicClient }
+
 // The openshift resources are stubbed out because Kiali talks directly to the
 // kube api for these instead of using the openshift client-go.
 func (c *FakeK8sClient) GetProject(name string) (*osproject_v1.Project, error) {
@@ -24,8 +24,10 @@ import (
 //// Mock for the K8SClientFactory
 
 type K8SClientFactoryMock struct {
-	lock    sync.RWMutex
-	Clients map[string]kubernetes.ClientInterface
+	lock               sync.RWMutex
+	Clients            map[string]kubernetes.ClientInterface
+	RemoteClusterInfos map[string]kubernetes.RemoteClusterInfo
+	Listeners          []kubernetes.ClusterListener
 }
 
 // Constructor
@@ -74,6 +76,18 @@ func (o *K8SClientFactoryMock) GetSAHomeClusterClient() kubernetes.ClientInterfa
 	return o.Clients[config.Get().KubernetesConfig.ClusterName]
 }
 
+func (o *K8SClientFactoryMock) GetRemoteClusterInfos() map[string]kubernetes.RemoteClusterInfo {
+	o.lock.RLock()
+	defer o.lock.RUnlock()
+	return o.RemoteClusterInfos
+}
+
+func (o *K8SClientFactoryMock) AddClusterListener(listener kubernetes.ClusterListener) {
+	o.lock.Lock()
+	defer o.lock.Unlock()
+	o.Listeners = append(o.Listeners, listener)
+}
+
 /////
 
 type K8SClientMock struct {
@@ -8,9 +8,14 @@ import (
 	auth_v1 "k8s.io/api/authorization/v1"
 	batch_v1 "k8s.io/api/batch/v1"
 	core_v1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
 )
 
+func (o *K8SClientMock) Dynamic() dynamic.Interface {
+	return nil
+}
+
 func (o *K8SClientMock) Kube() kubernetes.Interface {
 	return nil
 }
@@ -110,6 +115,11 @@ func (o *K8SClientMock) GetSelfSubjectAccessReview(ctx context.Context, namespac
 	return args.Get(0).([]*auth_v1.SelfSubjectAccessReview), args.Error(1)
 }
 
+func (o *K8SClientMock) GetSelfSubjectRulesReview(ctx context.Context, namespace string) (*auth_v1.SelfSubjectRulesReview, error) {
+	args := o.Called(ctx, namespace)
+	return args.Get(0).(*auth_v1.SelfSubjectRulesReview), args.Error(1)
+}
+
 func (o *K8SClientMock) GetService(namespace string, name string) (*core_v1.Service, error) {
 	args := o.Called(namespace, name)
 	return args.Get(0).(*core_v1.Service), args.Error(1)
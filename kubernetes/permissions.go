@@ -0,0 +1,42 @@
+package kubernetes
+
+import (
+	auth_v1 "k8s.io/api/authorization/v1"
+)
+
+// NamespacePermissions are the permissions of a user in a namespace, as returned by a SelfSubjectRulesReview.
+// A single review replaces the SelfSubjectAccessReviews of every resource type and verb.
+type NamespacePermissions struct {
+	Rules []auth_v1.ResourceRule
+	// Incomplete is true when the API server couldn't list all the rules, e.g. when an external authorizer is
+	// configured: the verbs denied by the rules must then be checked with a SelfSubjectAccessReview.
+	Incomplete bool
+}
+
+// NewNamespacePermissions returns the permissions listed by the review
+func NewNamespacePermissions(review *auth_v1.SelfSubjectRulesReview) *NamespacePermissions {
+	return &NamespacePermissions{
+		Rules:      review.Status.ResourceRules,
+		Incomplete: review.Status.Incomplete,
+	}
+}
+
+// Allows returns true if a rule grants the verb on all the resources of the type. The rules restricted to some
+// resource names are ignored.
+func (p *NamespacePermissions) Allows(api, resourceType, verb string) bool {
+	for _, rule := range p.Rules {
+		if len(rule.ResourceNames) == 0 && matchesRule(rule.APIGroups, api) && matchesRule(rule.Resources, resourceType) && matchesRule(rule.Verbs, verb) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchesRule(values []string, value string) bool {
+	for _, v := range values {
+		if v == "*" || v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,34 @@
+package kubernetes
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	auth_v1 "k8s.io/api/authorization/v1"
+)
+
+func TestNamespacePermissionsAllows(t *testing.T) {
+	assert := assert.New(t)
+
+	permissions := NewNamespacePermissions(&auth_v1.SelfSubjectRulesReview{
+		Status: auth_v1.SubjectRulesReviewStatus{
+			ResourceRules: []auth_v1.ResourceRule{
+				{Verbs: []string{"get", "patch"}, APIGroups: []string{"networking.istio.io"}, Resources: []string{"virtualservices"}},
+				{Verbs: []string{"*"}, APIGroups: []string{"security.istio.io"}, Resources: []string{"*"}},
+				{Verbs: []string{"delete"}, APIGroups: []string{"networking.istio.io"}, Resources: []string{"virtualservices"}, ResourceNames: []string{"reviews"}},
+			},
+			Incomplete: true,
+		},
+	})
+
+	assert.True(permissions.Incomplete)
+	assert.True(permissions.Allows("networking.istio.io", "virtualservices", "patch"))
+	assert.False(permissions.Allows("networking.istio.io", "virtualservices", "create"))
+	assert.False(permissions.Allows("networking.istio.io", "destinationrules", "patch"))
+	assert.True(permissions.Allows("security.istio.io", "authorizationpolicies", "delete"))
+	assert.True(permissions.Allows("security.istio.io", "*", "create"))
+	// The rules restricted to some objects don't grant the verb on the resource type
+	assert.False(permissions.Allows("networking.istio.io", "virtualservices", "delete"))
+	// The rules of a resource type don't grant the verb on all the resources of the group
+	assert.False(permissions.Allows("networking.istio.io", "*", "patch"))
+}
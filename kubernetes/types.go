@@ -129,6 +129,12 @@ var (
 	}
 	ApiTelemetryV1Alpha1 = TelemetryGroupV1Alpha1.Group + "/" + TelemetryGroupV1Alpha1.Version
 
+	MonitoringDashboardGroupVersionV1Alpha1 = schema.GroupVersion{
+		Group:   "monitoring.kiali.io",
+		Version: "v1alpha1",
+	}
+	MonitoringDashboardsResource = MonitoringDashboardGroupVersionV1Alpha1.WithResource("monitoringdashboards")
+
 	PluralType = map[string]string{
 		// Networking
 		Gateways:         GatewayType,
@@ -0,0 +1,33 @@
+package models
+
+// AccessLogStats holds statistics computed from the proxy access logs of one or several pods
+type AccessLogStats struct {
+	// Number of access log entries matching the filters in the time window
+	Entries int `json:"entries"`
+	// Start of the time window (unix millis)
+	StartTime int64 `json:"startTime"`
+	// End of the time window (unix millis), or time of the last entry read
+	EndTime int64 `json:"endTime"`
+	// Number of entries per response code
+	StatusCodes map[string]int `json:"statusCodes"`
+	// Most frequent response flags, sorted by count
+	ResponseFlags []AccessLogCount `json:"responseFlags"`
+	// Upstream hosts sorted by their p90 duration, slowest first
+	SlowestUpstreams []AccessLogLatency `json:"slowestUpstreams"`
+	// Request routes (method and path) sorted by their p90 duration, slowest first
+	Routes []AccessLogLatency `json:"routes"`
+}
+
+// AccessLogCount holds the number of occurrences of a value in access logs
+type AccessLogCount struct {
+	Name  string `json:"name"`
+	Count int    `json:"count"`
+}
+
+// AccessLogLatency holds the response time stats of a group of access log entries
+type AccessLogLatency struct {
+	Name          string `json:"name"`
+	Count         int    `json:"count"`
+	ErrorCount    int    `json:"errorCount"`
+	ResponseTimes []Stat `json:"responseTimes"` // avg, p50, p90 and p99 in milliseconds
+}
@@ -26,6 +26,37 @@ func (q *DashboardQuery) FillDefaults() {
 	q.RawDataAggregator = "sum"
 }
 
+// MetricTemplateQuery holds query parameters for a metric template query. Variables are the values substituted in
+// the template, such as "workload" for $workload.
+type MetricTemplateQuery struct {
+	prometheus.RangeQuery
+	Cluster   string
+	Namespace string
+	Variables map[string]string
+}
+
+// MetricTemplate describes a PromQL template that can be run by the metric templates API
+type MetricTemplate struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description,omitempty"`
+	Unit        string   `json:"unit,omitempty"`
+	Variables   []string `json:"variables"`
+}
+
+// MetricTemplateResult is the result of a metric template query
+type MetricTemplateResult struct {
+	Name    string   `json:"name"`
+	Unit    string   `json:"unit,omitempty"`
+	Metrics []Metric `json:"metrics"`
+}
+
+// GrafanaDashboardImport is a Grafana dashboard converted to a MonitoringDashboard resource. Warnings describe the
+// parts of the Grafana dashboard lost in the conversion.
+type GrafanaDashboardImport struct {
+	Resource map[string]interface{} `json:"resource"`
+	Warnings []string               `json:"warnings"`
+}
+
 // MonitoringDashboard is the model representing custom monitoring dashboard, transformed from MonitoringDashboard config resource
 type MonitoringDashboard struct {
 	Name          string         `json:"name"`
@@ -6,6 +6,7 @@ import (
 	"strconv"
 	"time"
 
+	prom_v1 "github.com/prometheus/client_golang/api/prometheus/v1"
 	pmod "github.com/prometheus/common/model"
 
 	"github.com/kiali/kiali/prometheus"
@@ -18,17 +19,18 @@ import (
 // IstioMetricsQuery holds query parameters for a typical metrics query
 type IstioMetricsQuery struct {
 	prometheus.RangeQuery
-	Filters         []string
-	Cluster         string
-	Namespace       string
-	App             string
-	Workload        string
-	Service         string
-	Direction       string // outbound | inbound
-	RequestProtocol string // e.g. http | grpc
-	Reporter        string // source | destination | both, defaults to source if not provided
-	Aggregate       string
-	AggregateValue  string
+	Filters          []string
+	Cluster          string
+	Namespace        string
+	App              string
+	Workload         string
+	Service          string
+	Direction        string // outbound | inbound
+	RequestProtocol  string // e.g. http | grpc
+	Reporter         string // source | destination | both, defaults to source if not provided
+	Aggregate        string
+	AggregateValue   string
+	IncludeExemplars bool // when true, attach trace IDs from the Prometheus exemplars to the datapoints
 }
 
 // FillDefaults fills the struct with default parameters
@@ -73,6 +75,7 @@ type MetricsStatsQuery struct {
 	Direction    string    // outbound | inbound
 	Avg          bool
 	Quantiles    []string
+	IncludeSpans bool // when true, also compute per-operation stats from sampled traces
 }
 
 func (q *MetricsStatsQuery) Validate() *util.Errors {
@@ -129,6 +132,7 @@ func (q *ControlPlaneMetricsQuery) FillDefaults() {
 type Metric struct {
 	Labels     map[string]string `json:"labels"`
 	Datapoints []Datapoint       `json:"datapoints"`
+	Exemplars  []Exemplar        `json:"exemplars,omitempty"`
 	Stat       string            `json:"stat,omitempty"`
 	Name       string            `json:"name"`
 }
@@ -138,6 +142,15 @@ type Datapoint struct {
 	Value     float64
 }
 
+// Exemplar links a datapoint to a representative trace. Timestamp is the one of the datapoint, Value is the
+// exemplar's own value (e.g. the duration of the traced request).
+type Exemplar struct {
+	Timestamp int64   `json:"timestamp"`
+	Value     float64 `json:"value"`
+	TraceID   string  `json:"traceId"`
+	SpanID    string  `json:"spanId,omitempty"`
+}
+
 // MetricsMap contains all simple metrics and histograms data for standard timeseries queries
 type MetricsMap = map[string][]Metric
 
@@ -150,6 +163,18 @@ type Stat struct {
 // MetricsStats contains opinionated statistics on metrics on a single target. Currently limited to response times (avg/percentiles over interval)
 type MetricsStats struct {
 	ResponseTimes []Stat `json:"responseTimes"`
+	// Per-operation stats computed from sampled traces, only when requested (see MetricsStatsQuery.IncludeSpans)
+	Operations []OperationStats `json:"operations,omitempty"`
+}
+
+// OperationStats contains statistics on the spans of a single operation (span name), computed from sampled traces.
+// Response times use the same stat names as MetricsStats (avg and quantiles), throughput is in sampled spans per second.
+type OperationStats struct {
+	Operation     string  `json:"operation"`
+	SampleSize    int     `json:"sampleSize"`
+	ResponseTimes []Stat  `json:"responseTimes"`
+	ErrorRatio    float64 `json:"errorRatio"`
+	Throughput    float64 `json:"throughput"`
 }
 
 // MetricsStatsResult holds the MetricsStats per target, plus errors
@@ -244,6 +269,76 @@ func (s Datapoint) MarshalJSON() ([]byte, error) {
 	}.MarshalJSON()
 }
 
+// Label names used by the tracing libraries and proxies to store the trace and span IDs in exemplars
+var (
+	exemplarTraceIDLabels = []pmod.LabelName{"trace_id", "traceID", "traceId", "trace-id"}
+	exemplarSpanIDLabels  = []pmod.LabelName{"span_id", "spanID", "spanId", "span-id"}
+)
+
+// AttachExemplars attaches the exemplars of the matching series (having the same grouping labels) to the metrics.
+// Each exemplar goes to the first datapoint at or after its timestamp; when several exemplars fall on the same
+// datapoint, the highest value is kept, so that spikes point to their slowest / biggest requests.
+func AttachExemplars(metrics []Metric, from []prom_v1.ExemplarQueryResult, scale float64) {
+	for i := range metrics {
+		metric := &metrics[i]
+		if len(metric.Datapoints) == 0 {
+			continue
+		}
+		byPoint := make(map[int]Exemplar)
+		for _, result := range from {
+			if !matchesSeriesLabels(metric.Labels, result.SeriesLabels) {
+				continue
+			}
+			for _, e := range result.Exemplars {
+				traceID := firstLabelValue(e.Labels, exemplarTraceIDLabels)
+				if traceID == "" {
+					continue
+				}
+				point := sort.Search(len(metric.Datapoints), func(j int) bool {
+					return metric.Datapoints[j].Timestamp >= int64(e.Timestamp)
+				})
+				if point == len(metric.Datapoints) {
+					continue
+				}
+				value := scale * float64(e.Value)
+				if prev, ok := byPoint[point]; ok && prev.Value >= value {
+					continue
+				}
+				byPoint[point] = Exemplar{
+					Timestamp: metric.Datapoints[point].Timestamp,
+					Value:     value,
+					TraceID:   traceID,
+					SpanID:    firstLabelValue(e.Labels, exemplarSpanIDLabels),
+				}
+			}
+		}
+		metric.Exemplars = nil
+		for j := range metric.Datapoints {
+			if e, ok := byPoint[j]; ok {
+				metric.Exemplars = append(metric.Exemplars, e)
+			}
+		}
+	}
+}
+
+func matchesSeriesLabels(labels map[string]string, series pmod.LabelSet) bool {
+	for k, v := range labels {
+		if string(series[pmod.LabelName(k)]) != v {
+			return false
+		}
+	}
+	return true
+}
+
+func firstLabelValue(labels pmod.LabelSet, names []pmod.LabelName) string {
+	for _, name := range names {
+		if v, ok := labels[name]; ok && v != "" {
+			return string(v)
+		}
+	}
+	return ""
+}
+
 func convertSamplePair(from *pmod.SamplePair, scale float64) Datapoint {
 	return Datapoint{
 		Timestamp: int64(from.Timestamp),
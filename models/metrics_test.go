@@ -0,0 +1,44 @@
+package models
+
+import (
+	"testing"
+
+	prom_v1 "github.com/prometheus/client_golang/api/prometheus/v1"
+	pmod "github.com/prometheus/common/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAttachExemplars(t *testing.T) {
+	assert := assert.New(t)
+
+	metrics := []Metric{
+		{
+			Labels:     map[string]string{"destination_service_name": "reviews"},
+			Datapoints: []Datapoint{{Timestamp: 15000, Value: 10}, {Timestamp: 30000, Value: 50}},
+		},
+		{
+			Labels:     map[string]string{"destination_service_name": "ratings"},
+			Datapoints: []Datapoint{{Timestamp: 15000, Value: 5}, {Timestamp: 30000, Value: 5}},
+		},
+	}
+	exemplars := []prom_v1.ExemplarQueryResult{
+		{
+			SeriesLabels: pmod.LabelSet{"le": "100", "destination_service_name": "reviews"},
+			Exemplars: []prom_v1.Exemplar{
+				{Labels: pmod.LabelSet{"trace_id": "t1"}, Value: 8, Timestamp: 12000},
+				{Labels: pmod.LabelSet{"trace_id": "t2", "span_id": "s2"}, Value: 80, Timestamp: 20000},
+				{Labels: pmod.LabelSet{"trace_id": "t3"}, Value: 40, Timestamp: 25000},
+				{Labels: pmod.LabelSet{"trace_id": "t4"}, Value: 40, Timestamp: 35000},
+				{Labels: pmod.LabelSet{"other": "x"}, Value: 90, Timestamp: 14000},
+			},
+		},
+	}
+
+	AttachExemplars(metrics, exemplars, 0.001)
+
+	assert.Equal([]Exemplar{
+		{Timestamp: 15000, Value: 0.008, TraceID: "t1"},
+		{Timestamp: 30000, Value: 0.08, TraceID: "t2", SpanID: "s2"},
+	}, metrics[0].Exemplars)
+	assert.Empty(metrics[1].Exemplars)
+}
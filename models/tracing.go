@@ -5,6 +5,7 @@ import "time"
 type JaegerInfo struct {
 	Enabled              bool     `json:"enabled"`
 	Integration          bool     `json:"integration"`
+	Provider             string   `json:"provider"`
 	URL                  string   `json:"url"`
 	NamespaceSelector    bool     `json:"namespaceSelector"`
 	WhiteListIstioSystem []string `json:"whiteListIstioSystem"`
@@ -15,6 +16,91 @@ type TracingQuery struct {
 	End         time.Time
 	Tags        map[string]string
 	MinDuration time.Duration
+	MaxDuration time.Duration
 	Limit       int
 	Cluster     string
+	// Post-filters, applied on the traces returned by the tracing backend
+	SpanStatus  string        // error | ok: traces having at least one span in error, or none
+	StatusCodes []string      // traces having at least one span with any of these HTTP codes, classes (5xx) or ranges (500-504)
+	Calls       []TracingCall // traces having all of these service-to-service calls
+}
+
+// TracingCall matches the traces where a source service calls a destination service, optionally with a response
+// status code (same format as TracingQuery.StatusCodes)
+type TracingCall struct {
+	Source      string
+	Destination string
+	StatusCodes []string
+}
+
+// HasPostFilters returns true when some filters of the query can't be handled by the tracing backend
+func (q TracingQuery) HasPostFilters() bool {
+	return q.SpanStatus != "" || len(q.StatusCodes) > 0 || len(q.Calls) > 0
+}
+
+// TraceComparison compares two traces, or two sets of traces (e.g. before and after a deployment). Spans are
+// aligned by service and operation.
+type TraceComparison struct {
+	Baseline  TraceSetBreakdown `json:"baseline"`
+	Candidate TraceSetBreakdown `json:"candidate"`
+	// Operations found in both sets, sorted by decreasing absolute duration delta
+	Operations []OperationDelta `json:"operations"`
+	// Operations found only in the candidate set
+	NewOperations []SpanOperation `json:"newOperations"`
+	// Operations found only in the baseline set
+	MissingOperations []SpanOperation `json:"missingOperations"`
+}
+
+// SpanOperation identifies the spans of an operation of a service
+type SpanOperation struct {
+	Service   string `json:"service"`
+	Operation string `json:"operation"`
+}
+
+// TraceSetBreakdown provides the latency breakdown of a set of traces
+type TraceSetBreakdown struct {
+	Traces     []TraceBreakdown     `json:"traces"`
+	Operations []OperationBreakdown `json:"operations"`
+}
+
+// TraceBreakdown provides the duration and critical path of a trace. Durations are in milliseconds.
+type TraceBreakdown struct {
+	TraceID      string                `json:"traceId"`
+	Duration     float64               `json:"duration"`
+	CriticalPath []CriticalPathSegment `json:"criticalPath"`
+}
+
+// CriticalPathSegment is a part of a span on the critical path of a trace. Start is relative to the trace start,
+// in milliseconds.
+type CriticalPathSegment struct {
+	SpanOperation
+	SpanID   string  `json:"spanId"`
+	Start    float64 `json:"start"`
+	Duration float64 `json:"duration"`
+}
+
+// OperationBreakdown aggregates the spans of an operation. Durations are averages per span, in milliseconds. The
+// self time is the part of a span not covered by its children, the child time is the part covered by its children.
+type OperationBreakdown struct {
+	SpanOperation
+	Spans     int     `json:"spans"`
+	Traces    int     `json:"traces"`
+	Duration  float64 `json:"duration"`
+	SelfTime  float64 `json:"selfTime"`
+	ChildTime float64 `json:"childTime"`
+}
+
+// OperationDelta compares an operation between the baseline and the candidate sets. Deltas are candidate minus
+// baseline, durations in milliseconds. Counts are average numbers of spans per trace.
+type OperationDelta struct {
+	SpanOperation
+	BaselineCount     float64 `json:"baselineCount"`
+	CandidateCount    float64 `json:"candidateCount"`
+	CountDelta        float64 `json:"countDelta"`
+	BaselineDuration  float64 `json:"baselineDuration"`
+	CandidateDuration float64 `json:"candidateDuration"`
+	DurationDelta     float64 `json:"durationDelta"`
+	BaselineSelfTime  float64 `json:"baselineSelfTime"`
+	CandidateSelfTime float64 `json:"candidateSelfTime"`
+	SelfTimeDelta     float64 `json:"selfTimeDelta"`
 }
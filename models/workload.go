@@ -25,6 +25,9 @@ type WorkloadList struct {
 	Workloads []WorkloadListItem `json:"workloads"`
 
 	Validations IstioValidations `json:"validations"`
+
+	// The clusters of the federated Kiali instances whose workloads are missing, and why
+	Warnings []string `json:"warnings,omitempty"`
 }
 
 // WorkloadListItem has the necessary information to display the console workload list
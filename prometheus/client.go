@@ -14,6 +14,7 @@ import (
 	prom_v1 "github.com/prometheus/client_golang/api/prometheus/v1"
 	"github.com/prometheus/common/model"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 
 	"github.com/kiali/kiali/config"
 	"github.com/kiali/kiali/kubernetes"
@@ -27,6 +28,7 @@ var invalidLabelCharRE = regexp.MustCompile(`[^a-zA-Z0-9_]`)
 type ClientInterface interface {
 	FetchHistogramRange(metricName, labels, grouping string, q *RangeQuery) Histogram
 	FetchHistogramValues(metricName, labels, grouping, rateInterval string, avg bool, quantiles []string, queryTime time.Time) (map[string]model.Vector, error)
+	FetchQueryRange(query string, q *RangeQuery) Metric
 	FetchRange(metricName, labels, grouping, aggregator string, q *RangeQuery) Metric
 	FetchRateRange(metricName string, labels []string, grouping string, q *RangeQuery) Metric
 	GetAllRequestRates(namespace, cluster, ratesInterval string, queryTime time.Time) (model.Vector, error)
@@ -37,15 +39,17 @@ type ClientInterface interface {
 	GetServiceRequestRates(namespace, cluster, service, ratesInterval string, queryTime time.Time) (model.Vector, error)
 	GetWorkloadRequestRates(namespace, cluster, workload, ratesInterval string, queryTime time.Time) (model.Vector, model.Vector, error)
 	GetMetricsForLabels(metricNames []string, labels string) ([]string, error)
+	QueryExemplars(query string, start, end time.Time) ([]prom_v1.ExemplarQueryResult, error)
 }
 
 // Client for Prometheus API.
 // It hides the way we query Prometheus offering a layer with a high level defined API.
 type Client struct {
 	ClientInterface
-	p8s api.Client
-	api prom_v1.API
-	ctx context.Context
+	api         prom_v1.API
+	clusterAPIs map[string]prom_v1.API // per cluster backends, when federated
+	ctx         context.Context
+	warnings    *QueryWarnings
 }
 
 var (
@@ -71,20 +75,64 @@ func NewClient() (*Client, error) {
 // NewClient creates a new client to the Prometheus API.
 // It returns an error on any problem.
 func NewClientForConfig(cfg config.PrometheusConfig) (*Client, error) {
-	clientConfig := api.Config{Address: cfg.URL}
-
 	// Prom Cache will be initialized once at first use of Prometheus Client
 	once.Do(initPromCache)
 
-	// Be sure to copy config.Auth and not modify the existing
-	auth := cfg.Auth
+	if len(cfg.Clusters) > 0 {
+		return newFederatedClient(cfg)
+	}
+	promAPI, err := newAPI(config.Get().KubernetesConfig.ClusterName, cfg.URL, cfg.Auth, cfg.CustomHeaders)
+	if err != nil {
+		return nil, err
+	}
+	client := Client{api: promAPI, ctx: context.Background(), warnings: &QueryWarnings{}}
+	return &client, nil
+}
+
+// newFederatedClient creates a client querying the Prometheus of every cluster configured. The backends of the
+// remote clusters authenticated with the Kiali token are skipped until Kiali has the token of their cluster.
+func newFederatedClient(cfg config.PrometheusConfig) (*Client, error) {
+	homeCluster := config.Get().KubernetesConfig.ClusterName
+	clusterAPIs := make(map[string]prom_v1.API, len(cfg.Clusters)+1)
+	for cluster, clusterCfg := range cfg.Clusters {
+		promAPI, err := newAPI(cluster, clusterCfg.URL, clusterCfg.Auth, clusterCfg.CustomHeaders)
+		if errors.IsNotFound(err) {
+			log.Warningf("Skipping the Prometheus of cluster %s: %v", cluster, err)
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid Prometheus configuration for cluster %s: %w", cluster, err)
+		}
+		clusterAPIs[cluster] = &clusterBackend{API: promAPI, cluster: cluster}
+	}
+	if _, ok := clusterAPIs[homeCluster]; !ok && cfg.URL != "" {
+		promAPI, err := newAPI(homeCluster, cfg.URL, cfg.Auth, cfg.CustomHeaders)
+		if err != nil {
+			return nil, err
+		}
+		clusterAPIs[homeCluster] = &clusterBackend{API: promAPI, cluster: homeCluster}
+	}
+	homeAPI, ok := clusterAPIs[homeCluster]
+	if !ok {
+		return nil, fmt.Errorf("no Prometheus configured for the home cluster %s", homeCluster)
+	}
+	client := Client{api: newFederatedAPI(homeAPI, clusterAPIs), clusterAPIs: clusterAPIs, ctx: context.Background(), warnings: &QueryWarnings{}}
+	return &client, nil
+}
+
+// newAPI creates the API of the Prometheus of the cluster
+func newAPI(cluster, url string, auth config.Auth, customHeaders map[string]string) (prom_v1.API, error) {
+	clientConfig := api.Config{Address: url}
+
+	// Note: auth is a copy, modifying it doesn't change the configuration
 	if auth.UseKialiToken {
 		// Note: if we are using the 'bearer' authentication method then we want to use the Kiali
 		// service account token and not the user's token. This is because Kiali does filtering based
 		// on the user's token and prevents people who shouldn't have access to particular metrics.
-		token, err := kubernetes.GetKialiTokenForHomeCluster()
+		// The Prometheus of a remote cluster gets the token of the Kiali service account of that cluster.
+		token, err := kialiToken(cluster)
 		if err != nil {
-			log.Errorf("Could not read the Kiali Service Account token: %v", err)
+			log.Errorf("Could not read the Kiali Service Account token of cluster %s: %v", cluster, err)
 			return nil, err
 		}
 		auth.Token = token
@@ -101,7 +149,7 @@ func NewClientForConfig(cfg config.PrometheusConfig) (*Client, error) {
 		TLSHandshakeTimeout: 10 * time.Second,
 	}
 
-	transportConfig, err := httputil.CreateTransport(&auth, roundTripper, httputil.DefaultTimeout, cfg.CustomHeaders)
+	transportConfig, err := httputil.CreateTransport(&auth, roundTripper, httputil.DefaultTimeout, customHeaders)
 	if err != nil {
 		return nil, err
 	}
@@ -111,8 +159,24 @@ func NewClientForConfig(cfg config.PrometheusConfig) (*Client, error) {
 	if err != nil {
 		return nil, errors.NewServiceUnavailable(err.Error())
 	}
-	client := Client{p8s: p8s, api: prom_v1.NewAPI(p8s), ctx: context.Background()}
-	return &client, nil
+	return withLimits(prom_v1.NewAPI(p8s)), nil
+}
+
+// kialiToken returns the token of the Kiali service account of the cluster. The token of the home cluster is never
+// returned for a remote cluster.
+func kialiToken(cluster string) (string, error) {
+	if cluster == config.Get().KubernetesConfig.ClusterName {
+		return kubernetes.GetKialiTokenForHomeCluster()
+	}
+	clientFactory, err := kubernetes.GetClientFactory()
+	if err != nil {
+		return "", err
+	}
+	client := clientFactory.GetSAClient(cluster)
+	if client == nil {
+		return "", errors.NewNotFound(schema.GroupResource{Resource: "clusters"}, cluster)
+	}
+	return client.GetToken(), nil
 }
 
 // Inject allows for replacing the API with a mock For testing
@@ -120,6 +184,17 @@ func (in *Client) Inject(api prom_v1.API) {
 	in.api = api
 }
 
+// clusterAPI returns the API to query for data scoped to a cluster: the backend of that cluster when federated
+// and configured, else the default API. The request rates of the items of a cluster are routed to its backend
+// only, rather than fanned out: they don't discriminate on the reporter, and the proxies of the cluster report
+// both the inbound (destination reporter) and the outbound (source reporter) requests of its items.
+func (in *Client) clusterAPI(cluster string) prom_v1.API {
+	if api, ok := in.clusterAPIs[cluster]; ok {
+		return api
+	}
+	return in.api
+}
+
 // GetAllRequestRates queries Prometheus to fetch request counter rates, over a time interval, for requests
 // into, internal to, or out of the namespace. Note that it does not discriminate on "reporter", so rates can
 // be inflated due to duplication, and therefore should be used mainly for calculating ratios
@@ -132,7 +207,8 @@ func (in *Client) GetAllRequestRates(namespace, cluster string, ratesInterval st
 			return result, nil
 		}
 	}
-	result, err := getAllRequestRates(in.ctx, in.api, namespace, cluster, queryTime, ratesInterval)
+	result, warnings, err := getAllRequestRates(in.ctx, in.clusterAPI(cluster), namespace, cluster, queryTime, ratesInterval)
+	in.AddWarnings(warnings...)
 	if err != nil {
 		return result, err
 	}
@@ -154,7 +230,8 @@ func (in *Client) GetNamespaceServicesRequestRates(namespace, cluster string, ra
 			return result, nil
 		}
 	}
-	result, err := getNamespaceServicesRequestRates(in.ctx, in.api, namespace, cluster, queryTime, ratesInterval)
+	result, warnings, err := getNamespaceServicesRequestRates(in.ctx, in.clusterAPI(cluster), namespace, cluster, queryTime, ratesInterval)
+	in.AddWarnings(warnings...)
 	if err != nil {
 		return result, err
 	}
@@ -176,7 +253,8 @@ func (in *Client) GetServiceRequestRates(namespace, cluster, service, ratesInter
 			return result, nil
 		}
 	}
-	result, err := getServiceRequestRates(in.ctx, in.api, namespace, cluster, service, queryTime, ratesInterval)
+	result, warnings, err := getServiceRequestRates(in.ctx, in.clusterAPI(cluster), namespace, cluster, service, queryTime, ratesInterval)
+	in.AddWarnings(warnings...)
 	if err != nil {
 		return result, err
 	}
@@ -198,7 +276,8 @@ func (in *Client) GetAppRequestRates(namespace, cluster, app, ratesInterval stri
 			return inResult, outResult, nil
 		}
 	}
-	inResult, outResult, err := getItemRequestRates(in.ctx, in.api, namespace, cluster, app, "app", queryTime, ratesInterval)
+	inResult, outResult, warnings, err := getItemRequestRates(in.ctx, in.clusterAPI(cluster), namespace, cluster, app, "app", queryTime, ratesInterval)
+	in.AddWarnings(warnings...)
 	if err != nil {
 		return inResult, outResult, err
 	}
@@ -220,7 +299,8 @@ func (in *Client) GetWorkloadRequestRates(namespace, cluster, workload, ratesInt
 			return inResult, outResult, nil
 		}
 	}
-	inResult, outResult, err := getItemRequestRates(in.ctx, in.api, namespace, cluster, workload, "workload", queryTime, ratesInterval)
+	inResult, outResult, warnings, err := getItemRequestRates(in.ctx, in.clusterAPI(cluster), namespace, cluster, workload, "workload", queryTime, ratesInterval)
+	in.AddWarnings(warnings...)
 	if err != nil {
 		return inResult, outResult, err
 	}
@@ -239,6 +319,11 @@ func (in *Client) FetchRange(metricName, labels, grouping, aggregator string, q
 	return fetchRange(in.ctx, in.api, query, q.Range)
 }
 
+// FetchQueryRange fetches the result of a PromQL expression in given range
+func (in *Client) FetchQueryRange(query string, q *RangeQuery) Metric {
+	return fetchRange(in.ctx, in.api, query, q.Range)
+}
+
 // FetchRateRange fetches a counter's rate in given range
 func (in *Client) FetchRateRange(metricName string, labels []string, grouping string, q *RangeQuery) Metric {
 	return fetchRateRange(in.ctx, in.api, metricName, labels, grouping, q)
@@ -251,7 +336,22 @@ func (in *Client) FetchHistogramRange(metricName, labels, grouping string, q *Ra
 
 // FetchHistogramValues fetches bucketed metric as histogram at a given specific time
 func (in *Client) FetchHistogramValues(metricName, labels, grouping, rateInterval string, avg bool, quantiles []string, queryTime time.Time) (map[string]model.Vector, error) {
-	return fetchHistogramValues(in.ctx, in.api, metricName, labels, grouping, rateInterval, avg, quantiles, queryTime)
+	histogram, warnings, err := fetchHistogramValues(in.ctx, in.api, metricName, labels, grouping, rateInterval, avg, quantiles, queryTime)
+	in.AddWarnings(warnings...)
+	return histogram, err
+}
+
+// QueryExemplars fetches the exemplars of the series selected by a query, in given time range
+func (in *Client) QueryExemplars(query string, start, end time.Time) ([]prom_v1.ExemplarQueryResult, error) {
+	return queryExemplars(in.ctx, in.api, query, start, end)
+}
+
+// PlanQuery rewrites the rate queries to use the compatible recording rules, if any are configured or discovered
+func (in *Client) PlanQuery(query string) string {
+	if planner := getQueryPlanner(); planner != nil {
+		return planner.Plan(in.ctx, in.api, query)
+	}
+	return query
 }
 
 // API returns the Prometheus V1 HTTP API for performing calls not supported natively by this client
@@ -276,6 +376,30 @@ func (in *Client) GetContext() context.Context {
 	return in.ctx
 }
 
+// WithContext returns a copy of the client running its queries with the given context, e.g. bounded by the
+// request query budget, and collecting the warnings of these queries apart from the other requests.
+func (in *Client) WithContext(ctx context.Context) *Client {
+	client := *in
+	client.ctx = ctx
+	client.warnings = &QueryWarnings{}
+	return &client
+}
+
+// AddWarnings records warnings to be returned with the response, e.g. the warnings returned by Prometheus
+func (in *Client) AddWarnings(warnings ...string) {
+	if in.warnings != nil && len(warnings) > 0 {
+		in.warnings.Add(warnings...)
+	}
+}
+
+// Warnings returns the warnings recorded by the client
+func (in *Client) Warnings() []string {
+	if in.warnings == nil {
+		return []string{}
+	}
+	return in.warnings.List()
+}
+
 func (in *Client) GetFlags() (prom_v1.FlagsResult, error) {
 	flags, err := in.API().Flags(in.ctx)
 	if err != nil {
@@ -297,6 +421,7 @@ func (in *Client) GetMetricsForLabels(metricNames []string, labelQueryString str
 	results, warnings, err := in.api.Query(in.ctx, queryString, time.Now())
 	if len(warnings) > 0 {
 		log.Warningf("GetMetricsForLabels. Prometheus Warnings: [%s]", strings.Join(warnings, ","))
+		in.AddWarnings(warnings...)
 	}
 	if err != nil {
 		return nil, errors.NewServiceUnavailable(err.Error())
@@ -330,6 +455,7 @@ func (in *Client) GetExistingMetricNames(metricNames []string) ([]string, error)
 	results, warnings, err := in.api.LabelValues(in.ctx, "__name__", []string{}, time.Unix(0, 0), time.Now())
 	if len(warnings) > 0 {
 		log.Warningf("GetExistingMetricNames. Prometheus Warnings: [%s]", strings.Join(warnings, ","))
+		in.AddWarnings(warnings...)
 	}
 	if err != nil {
 		return nil, errors.NewServiceUnavailable(err.Error())
@@ -0,0 +1,735 @@
+package prometheus
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	prom_v1 "github.com/prometheus/client_golang/api/prometheus/v1"
+	"github.com/prometheus/common/model"
+)
+
+// federatedAPI fans out the queries to the Prometheus backends of several clusters and merges their results.
+// In a multi-cluster mesh, the telemetry about a cluster can be reported by the proxies of other clusters (e.g. the
+// source reporter of a cross-cluster request), so queries are sent to every backend. Only the additive aggregations
+// (sum, count, rate, increase) are merged by summing the series found in several backends; the minimums and the
+// maximums keep the lowest or the highest value. The other queries Kiali runs are split: the averages, the histogram
+// quantiles and the ratios of sums (e.g. average durations) are computed on their merged operands, and so are the
+// roundings, the unions and the "> 0" filters. Any other query is rejected, as its merged result would be wrong.
+// Other API calls (config, flags, rules...) go to the home cluster backend. The request rates of the items of a
+// cluster don't use the federated API: see Client.clusterAPI.
+type federatedAPI struct {
+	prom_v1.API
+	backends map[string]prom_v1.API
+}
+
+// clusterBackend is the Prometheus backend of a cluster, when federated
+type clusterBackend struct {
+	prom_v1.API
+	cluster string
+}
+
+func newFederatedAPI(home prom_v1.API, backends map[string]prom_v1.API) *federatedAPI {
+	return &federatedAPI{API: home, backends: backends}
+}
+
+type backendResult struct {
+	cluster  string
+	value    interface{}
+	warnings prom_v1.Warnings
+	err      error
+}
+
+// fanOut calls every backend concurrently. Results are sorted by cluster name, for a stable merge.
+func (in *federatedAPI) fanOut(call func(api prom_v1.API) (interface{}, prom_v1.Warnings, error)) []backendResult {
+	results := make([]backendResult, 0, len(in.backends))
+	var mutex sync.Mutex
+	var wg sync.WaitGroup
+	for cluster, api := range in.backends {
+		wg.Add(1)
+		go func(cluster string, api prom_v1.API) {
+			defer wg.Done()
+			value, warnings, err := call(api)
+			mutex.Lock()
+			results = append(results, backendResult{cluster: cluster, value: value, warnings: warnings, err: err})
+			mutex.Unlock()
+		}(cluster, api)
+	}
+	wg.Wait()
+	sort.Slice(results, func(i, j int) bool { return results[i].cluster < results[j].cluster })
+	return results
+}
+
+// collect returns the successful results and the warnings. A failing backend is reported as a warning, so that
+// the other clusters' data is still returned; it is an error only when all backends fail.
+func collect(results []backendResult) ([]interface{}, prom_v1.Warnings, error) {
+	var values []interface{}
+	var warnings prom_v1.Warnings
+	var firstErr error
+	for _, r := range results {
+		warnings = append(warnings, r.warnings...)
+		if r.err != nil {
+			if firstErr == nil {
+				firstErr = r.err
+			}
+			warnings = append(warnings, fmt.Sprintf("Prometheus of cluster %s: %v", r.cluster, r.err))
+			continue
+		}
+		values = append(values, r.value)
+	}
+	if len(values) == 0 && firstErr != nil {
+		return nil, warnings, firstErr
+	}
+	return values, warnings, nil
+}
+
+func (in *federatedAPI) Query(ctx context.Context, query string, ts time.Time) (model.Value, prom_v1.Warnings, error) {
+	return in.query(query, func(api prom_v1.API, query string) (model.Value, prom_v1.Warnings, error) {
+		return api.Query(ctx, query, ts)
+	})
+}
+
+func (in *federatedAPI) QueryRange(ctx context.Context, query string, r prom_v1.Range) (model.Value, prom_v1.Warnings, error) {
+	return in.query(query, func(api prom_v1.API, query string) (model.Value, prom_v1.Warnings, error) {
+		return api.QueryRange(ctx, query, r)
+	})
+}
+
+type queryFunc = func(api prom_v1.API, query string) (model.Value, prom_v1.Warnings, error)
+
+// query sends the query to the backends and merges their results. The non-additive queries are split, and computed
+// here on their merged operands.
+func (in *federatedAPI) query(query string, call queryFunc) (model.Value, prom_v1.Warnings, error) {
+	if len(in.backends) <= 1 {
+		return in.merge(query, call, sumValues)
+	}
+
+	query = trimParentheses(query)
+	if operands, ok := splitOr(query); ok {
+		var results []model.Value
+		var warnings prom_v1.Warnings
+		for _, operand := range operands {
+			value, operandWarnings, err := in.query(operand, call)
+			warnings = append(warnings, operandWarnings...)
+			if err != nil {
+				return nil, warnings, err
+			}
+			results = append(results, value)
+		}
+		return union(results), warnings, nil
+	}
+	if operand, toNearest, ok := splitRound(query); ok {
+		value, warnings, err := in.query(operand, call)
+		if err != nil {
+			return nil, warnings, err
+		}
+		return round(value, toNearest), warnings, nil
+	}
+	if operand, ok := splitPositiveFilter(query); ok {
+		value, warnings, err := in.query(operand, call)
+		if err != nil {
+			return nil, warnings, err
+		}
+		return filterPositive(value), warnings, nil
+	}
+	if quantile, buckets, ok := splitHistogramQuantile(query); ok {
+		value, warnings, err := in.query(buckets, call)
+		if err != nil {
+			return nil, warnings, err
+		}
+		return histogramQuantile(quantile, value), warnings, nil
+	}
+	if dividend, divisor, ok := splitRatioOfSums(query); ok {
+		return in.ratio(dividend, divisor, call)
+	}
+
+	aggregator, operand, grouping, _ := splitAggregation(query)
+	switch aggregator {
+	case "sum", "count", "rate", "irate", "increase":
+		return in.merge(query, call, sumValues)
+	case "max":
+		return in.merge(query, call, maxValue)
+	case "min":
+		return in.merge(query, call, minValue)
+	case "avg":
+		// The average of the series of all the backends is the ratio of their merged sum and count
+		return in.ratio("sum("+operand+")"+grouping, "count("+operand+")"+grouping, call)
+	default:
+		return nil, nil, fmt.Errorf("the query [%s] can't be merged across the Prometheus backends of the clusters", query)
+	}
+}
+
+// merge sends the query to the backends, and merges their results with the combine function
+func (in *federatedAPI) merge(query string, call queryFunc, combine combineFunc) (model.Value, prom_v1.Warnings, error) {
+	values, warnings, err := collect(in.fanOut(func(api prom_v1.API) (interface{}, prom_v1.Warnings, error) {
+		return call(api, query)
+	}))
+	if err != nil {
+		return nil, warnings, err
+	}
+	merged, err := mergeValues(values, combine)
+	return merged, warnings, err
+}
+
+// ratio divides the merged results of the dividend query by the merged results of the divisor query
+func (in *federatedAPI) ratio(dividend, divisor string, call queryFunc) (model.Value, prom_v1.Warnings, error) {
+	dividendValue, warnings, err := in.query(dividend, call)
+	if err != nil {
+		return nil, warnings, err
+	}
+	divisorValue, divisorWarnings, err := in.query(divisor, call)
+	warnings = append(warnings, divisorWarnings...)
+	if err != nil {
+		return nil, warnings, err
+	}
+	return divide(dividendValue, divisorValue), warnings, nil
+}
+
+func (in *federatedAPI) QueryExemplars(ctx context.Context, query string, startTime time.Time, endTime time.Time) ([]prom_v1.ExemplarQueryResult, error) {
+	values, _, err := collect(in.fanOut(func(api prom_v1.API) (interface{}, prom_v1.Warnings, error) {
+		result, err := api.QueryExemplars(ctx, query, startTime, endTime)
+		return result, nil, err
+	}))
+	if err != nil {
+		return nil, err
+	}
+	merged := []prom_v1.ExemplarQueryResult{}
+	for _, v := range values {
+		merged = append(merged, v.([]prom_v1.ExemplarQueryResult)...)
+	}
+	return merged, nil
+}
+
+func (in *federatedAPI) LabelValues(ctx context.Context, label string, matches []string, startTime time.Time, endTime time.Time) (model.LabelValues, prom_v1.Warnings, error) {
+	values, warnings, err := collect(in.fanOut(func(api prom_v1.API) (interface{}, prom_v1.Warnings, error) {
+		return api.LabelValues(ctx, label, matches, startTime, endTime)
+	}))
+	if err != nil {
+		return nil, warnings, err
+	}
+	seen := make(map[model.LabelValue]bool)
+	merged := model.LabelValues{}
+	for _, v := range values {
+		for _, lv := range v.(model.LabelValues) {
+			if !seen[lv] {
+				seen[lv] = true
+				merged = append(merged, lv)
+			}
+		}
+	}
+	sort.Sort(merged)
+	return merged, warnings, nil
+}
+
+func (in *federatedAPI) Series(ctx context.Context, matches []string, startTime time.Time, endTime time.Time) ([]model.LabelSet, prom_v1.Warnings, error) {
+	values, warnings, err := collect(in.fanOut(func(api prom_v1.API) (interface{}, prom_v1.Warnings, error) {
+		return api.Series(ctx, matches, startTime, endTime)
+	}))
+	if err != nil {
+		return nil, warnings, err
+	}
+	seen := make(map[model.Fingerprint]bool)
+	merged := []model.LabelSet{}
+	for _, v := range values {
+		for _, ls := range v.([]model.LabelSet) {
+			if fp := ls.Fingerprint(); !seen[fp] {
+				seen[fp] = true
+				merged = append(merged, ls)
+			}
+		}
+	}
+	return merged, warnings, nil
+}
+
+// combineFunc combines the values of the samples having the same labels in several backends
+type combineFunc func(a, b model.SampleValue) model.SampleValue
+
+func sumValues(a, b model.SampleValue) model.SampleValue {
+	return a + b
+}
+
+func maxValue(a, b model.SampleValue) model.SampleValue {
+	return model.SampleValue(math.Max(float64(a), float64(b)))
+}
+
+func minValue(a, b model.SampleValue) model.SampleValue {
+	return model.SampleValue(math.Min(float64(a), float64(b)))
+}
+
+// mergeValues merges the vectors, or the matrices, returned by the backends for the same query
+func mergeValues(values []interface{}, combine combineFunc) (model.Value, error) {
+	if len(values) == 1 {
+		return values[0].(model.Value), nil
+	}
+	switch first := values[0].(model.Value); first.Type() {
+	case model.ValVector:
+		vectors := make([]model.Vector, 0, len(values))
+		for _, v := range values {
+			vectors = append(vectors, v.(model.Vector))
+		}
+		return mergeVectors(combine, vectors...), nil
+	case model.ValMatrix:
+		matrices := make([]model.Matrix, 0, len(values))
+		for _, v := range values {
+			matrices = append(matrices, v.(model.Matrix))
+		}
+		return mergeMatrices(combine, matrices...), nil
+	case model.ValScalar:
+		// e.g. scalar functions: all backends return the same
+		return first, nil
+	default:
+		return nil, fmt.Errorf("cannot merge Prometheus results of type %v", first.Type())
+	}
+}
+
+// MergeVectors merges vectors from several backends: samples having the same labels are summed
+func MergeVectors(vectors ...model.Vector) model.Vector {
+	return mergeVectors(sumValues, vectors...)
+}
+
+func mergeVectors(combine combineFunc, vectors ...model.Vector) model.Vector {
+	merged := model.Vector{}
+	index := make(map[model.Fingerprint]*model.Sample)
+	for _, vector := range vectors {
+		for _, sample := range vector {
+			fp := sample.Metric.Fingerprint()
+			if prev, ok := index[fp]; ok {
+				prev.Value = combine(prev.Value, sample.Value)
+				continue
+			}
+			s := *sample
+			index[fp] = &s
+			merged = append(merged, &s)
+		}
+	}
+	return merged
+}
+
+// MergeMatrices merges matrices from several backends: series having the same labels are summed per timestamp
+func MergeMatrices(matrices ...model.Matrix) model.Matrix {
+	return mergeMatrices(sumValues, matrices...)
+}
+
+func mergeMatrices(combine combineFunc, matrices ...model.Matrix) model.Matrix {
+	merged := model.Matrix{}
+	index := make(map[model.Fingerprint]*model.SampleStream)
+	for _, matrix := range matrices {
+		for _, stream := range matrix {
+			fp := stream.Metric.Fingerprint()
+			prev, ok := index[fp]
+			if !ok {
+				s := &model.SampleStream{Metric: stream.Metric, Values: append([]model.SamplePair{}, stream.Values...)}
+				index[fp] = s
+				merged = append(merged, s)
+				continue
+			}
+			byTime := make(map[model.Time]int, len(prev.Values))
+			for i, pair := range prev.Values {
+				byTime[pair.Timestamp] = i
+			}
+			for _, pair := range stream.Values {
+				if i, found := byTime[pair.Timestamp]; found {
+					prev.Values[i].Value = combine(prev.Values[i].Value, pair.Value)
+				} else {
+					prev.Values = append(prev.Values, pair)
+				}
+			}
+			sort.Slice(prev.Values, func(i, j int) bool { return prev.Values[i].Timestamp < prev.Values[j].Timestamp })
+		}
+	}
+	return merged
+}
+
+// trimParentheses removes the parentheses around the whole query, e.g. "(a OR b)" becomes "a OR b"
+func trimParentheses(query string) string {
+	query = strings.TrimSpace(query)
+	for strings.HasPrefix(query, "(") && topLevelIndex(query[1:], ")") == len(query)-2 {
+		query = strings.TrimSpace(query[1 : len(query)-1])
+	}
+	return query
+}
+
+// splitOr returns the operands of a union, e.g. "a" and "b" for "(a) OR (b)"
+func splitOr(query string) ([]string, bool) {
+	var operands []string
+	for {
+		i := topLevelIndex(query, " OR ")
+		if j := topLevelIndex(query, " or "); j >= 0 && (i < 0 || j < i) {
+			i = j
+		}
+		if i < 0 {
+			break
+		}
+		operands = append(operands, query[:i])
+		query = query[i+len(" OR "):]
+	}
+	if len(operands) == 0 {
+		return nil, false
+	}
+	return append(operands, query), true
+}
+
+// splitRound returns the operand and the precision of a round query, e.g. "sum(m)" and 0.001 for
+// "round(sum(m),0.001)"
+func splitRound(query string) (string, float64, bool) {
+	aggregator, args, grouping, ok := splitAggregation(query)
+	if !ok || aggregator != "round" || grouping != "" {
+		return "", 0, false
+	}
+	comma := topLevelIndex(args, ",")
+	if comma < 0 {
+		return args, 1, true
+	}
+	toNearest, err := strconv.ParseFloat(strings.TrimSpace(args[comma+1:]), 64)
+	if err != nil {
+		return "", 0, false
+	}
+	return args[:comma], toNearest, true
+}
+
+var functionRegexp = regexp.MustCompile(`^([a-z_]+)\(`)
+
+// splitAggregation returns the function or the aggregation operator, its arguments and its grouping, of a query
+// only made of one call, e.g. "sum", "rate(m[1m])" and " by (app)" for "sum(rate(m[1m])) by (app)"
+func splitAggregation(query string) (string, string, string, bool) {
+	query = strings.TrimSpace(query)
+	match := functionRegexp.FindStringSubmatch(query)
+	if match == nil {
+		return "", "", "", false
+	}
+	args := query[len(match[0]):]
+	end := topLevelIndex(args, ")")
+	if end < 0 {
+		return "", "", "", false
+	}
+	grouping := args[end+1:]
+	if strings.TrimSpace(grouping) != "" && !groupingRegexp.MatchString(grouping) {
+		return "", "", "", false
+	}
+	return match[1], args[:end], grouping, true
+}
+
+// splitPositiveFilter returns the operand of a query keeping the positive values, e.g. "rate(m[1m])" for
+// "rate(m[1m]) > 0"
+func splitPositiveFilter(query string) (string, bool) {
+	query = strings.TrimSpace(query)
+	i := topLevelIndex(query, " > 0")
+	if i < 0 || query[i+len(" > 0"):] != "" {
+		return "", false
+	}
+	return query[:i], true
+}
+
+// splitHistogramQuantile returns the quantile and the buckets query of a histogram_quantile query
+func splitHistogramQuantile(query string) (float64, string, bool) {
+	const prefix = "histogram_quantile("
+	query = strings.TrimSpace(query)
+	if !strings.HasPrefix(query, prefix) {
+		return 0, "", false
+	}
+	args := query[len(prefix):]
+	if end := topLevelIndex(args, ")"); end != len(args)-1 {
+		return 0, "", false
+	}
+	args = args[:len(args)-1]
+	comma := topLevelIndex(args, ",")
+	if comma < 0 {
+		return 0, "", false
+	}
+	quantile, err := strconv.ParseFloat(strings.TrimSpace(args[:comma]), 64)
+	if err != nil {
+		return 0, "", false
+	}
+	return quantile, strings.TrimSpace(args[comma+1:]), true
+}
+
+// splitRatioOfSums returns the operands of a division of two sums, e.g. the average durations
+// "sum(rate(m_sum[1m])) by (app) / sum(rate(m_count[1m])) by (app)"
+func splitRatioOfSums(query string) (string, string, bool) {
+	i := topLevelIndex(query, " / ")
+	if i < 0 {
+		return "", "", false
+	}
+	dividend, divisor := query[:i], query[i+len(" / "):]
+	if !isSum(dividend) || !isSum(divisor) {
+		return "", "", false
+	}
+	return dividend, divisor, true
+}
+
+var groupingRegexp = regexp.MustCompile(`^\s*(by|without)\s*\([^()]*\)\s*$`)
+
+// isSum checks that the query is only a sum aggregation, e.g. "sum(rate(m[1m])) by (app)"
+func isSum(query string) bool {
+	aggregator, _, _, ok := splitAggregation(query)
+	return ok && aggregator == "sum"
+}
+
+// topLevelIndex returns the index of the first occurrence of sep outside of parentheses, braces, brackets and
+// strings, or -1 if there is none
+func topLevelIndex(query, sep string) int {
+	depth := 0
+	var quote byte
+	for i := 0; i < len(query); i++ {
+		c := query[i]
+		switch {
+		case quote != 0:
+			if c == '\\' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case depth == 0 && strings.HasPrefix(query[i:], sep):
+			return i
+		case c == '"' || c == '\'' || c == '`':
+			quote = c
+		case c == '(' || c == '{' || c == '[':
+			depth++
+		case c == ')' || c == '}' || c == ']':
+			depth--
+		}
+	}
+	return -1
+}
+
+// filterPositive keeps the positive values of a vector or a matrix
+func filterPositive(value model.Value) model.Value {
+	filter := func(vector model.Vector) model.Vector {
+		positive := model.Vector{}
+		for _, sample := range vector {
+			if sample.Value > 0 {
+				positive = append(positive, sample)
+			}
+		}
+		return positive
+	}
+	switch v := value.(type) {
+	case model.Vector:
+		return filter(v)
+	case model.Matrix:
+		return perTimestamp([]model.Matrix{v}, func(vectors []model.Vector) model.Vector { return filter(vectors[0]) })
+	}
+	return value
+}
+
+// union returns the samples of the first value, and the samples of the next values having labels not found in the
+// previous ones, as the PromQL "or" operator
+func union(values []model.Value) model.Value {
+	vectorUnion := func(vectors []model.Vector) model.Vector {
+		result := model.Vector{}
+		seen := make(map[model.Fingerprint]bool)
+		for _, vector := range vectors {
+			added := make(map[model.Fingerprint]bool)
+			for _, sample := range vector {
+				fp := sample.Metric.Fingerprint()
+				if !seen[fp] {
+					added[fp] = true
+					result = append(result, sample)
+				}
+			}
+			for fp := range added {
+				seen[fp] = true
+			}
+		}
+		return result
+	}
+	if len(values) == 0 {
+		return model.Vector{}
+	}
+	switch values[0].(type) {
+	case model.Vector:
+		vectors := make([]model.Vector, 0, len(values))
+		for _, value := range values {
+			if v, ok := value.(model.Vector); ok {
+				vectors = append(vectors, v)
+			}
+		}
+		return vectorUnion(vectors)
+	case model.Matrix:
+		matrices := make([]model.Matrix, 0, len(values))
+		for _, value := range values {
+			if m, ok := value.(model.Matrix); ok {
+				matrices = append(matrices, m)
+			}
+		}
+		return perTimestamp(matrices, vectorUnion)
+	}
+	return values[0]
+}
+
+// round rounds the values of a vector or a matrix to the nearest multiple of toNearest, as the PromQL round function
+func round(value model.Value, toNearest float64) model.Value {
+	inverse := 1 / toNearest
+	roundVector := func(vector model.Vector) model.Vector {
+		result := make(model.Vector, 0, len(vector))
+		for _, sample := range vector {
+			rounded := math.Floor(float64(sample.Value)*inverse+0.5) / inverse
+			result = append(result, &model.Sample{Metric: sample.Metric, Value: model.SampleValue(rounded), Timestamp: sample.Timestamp})
+		}
+		return result
+	}
+	switch v := value.(type) {
+	case model.Vector:
+		return roundVector(v)
+	case model.Matrix:
+		return perTimestamp([]model.Matrix{v}, func(vectors []model.Vector) model.Vector { return roundVector(vectors[0]) })
+	}
+	return value
+}
+
+// histogramQuantile computes the quantile of the histograms of a vector or a matrix of buckets, as the PromQL
+// histogram_quantile function
+func histogramQuantile(quantile float64, value model.Value) model.Value {
+	switch v := value.(type) {
+	case model.Vector:
+		return vectorQuantile(quantile, v)
+	case model.Matrix:
+		return perTimestamp([]model.Matrix{v}, func(vectors []model.Vector) model.Vector { return vectorQuantile(quantile, vectors[0]) })
+	}
+	return value
+}
+
+type bucket struct {
+	upperBound float64
+	count      float64
+}
+
+func vectorQuantile(quantile float64, vector model.Vector) model.Vector {
+	type histogram struct {
+		sample  *model.Sample
+		buckets []bucket
+	}
+	histograms := make(map[model.Fingerprint]*histogram)
+	result := model.Vector{}
+	for _, sample := range vector {
+		upperBound, err := strconv.ParseFloat(string(sample.Metric[model.BucketLabel]), 64)
+		if err != nil {
+			// Not a bucket
+			continue
+		}
+		metric := sample.Metric.Clone()
+		delete(metric, model.BucketLabel)
+		delete(metric, model.MetricNameLabel)
+		fp := metric.Fingerprint()
+		h, ok := histograms[fp]
+		if !ok {
+			h = &histogram{sample: &model.Sample{Metric: metric, Timestamp: sample.Timestamp}}
+			histograms[fp] = h
+			result = append(result, h.sample)
+		}
+		h.buckets = append(h.buckets, bucket{upperBound: upperBound, count: float64(sample.Value)})
+	}
+	for _, h := range histograms {
+		h.sample.Value = model.SampleValue(bucketQuantile(quantile, h.buckets))
+	}
+	return result
+}
+
+// bucketQuantile interpolates the quantile in the buckets of a histogram, as Prometheus does
+func bucketQuantile(quantile float64, buckets []bucket) float64 {
+	if quantile < 0 {
+		return math.Inf(-1)
+	}
+	if quantile > 1 {
+		return math.Inf(+1)
+	}
+	sort.Slice(buckets, func(i, j int) bool { return buckets[i].upperBound < buckets[j].upperBound })
+	if len(buckets) < 2 || !math.IsInf(buckets[len(buckets)-1].upperBound, +1) {
+		return math.NaN()
+	}
+	for i := 1; i < len(buckets); i++ {
+		if buckets[i].count < buckets[i-1].count {
+			buckets[i].count = buckets[i-1].count
+		}
+	}
+
+	rank := quantile * buckets[len(buckets)-1].count
+	b := sort.Search(len(buckets)-1, func(i int) bool { return buckets[i].count >= rank })
+	if b == len(buckets)-1 {
+		return buckets[len(buckets)-2].upperBound
+	}
+	if b == 0 && buckets[0].upperBound <= 0 {
+		return buckets[0].upperBound
+	}
+	bucketStart, bucketEnd, count := 0.0, buckets[b].upperBound, buckets[b].count
+	if b > 0 {
+		bucketStart = buckets[b-1].upperBound
+		count -= buckets[b-1].count
+		rank -= buckets[b-1].count
+	}
+	return bucketStart + (bucketEnd-bucketStart)*(rank/count)
+}
+
+// divide divides the samples of two vectors, or two matrices, having the same labels
+func divide(dividend, divisor model.Value) model.Value {
+	switch v := dividend.(type) {
+	case model.Vector:
+		if d, ok := divisor.(model.Vector); ok {
+			return divideVectors(v, d)
+		}
+	case model.Matrix:
+		if d, ok := divisor.(model.Matrix); ok {
+			return perTimestamp([]model.Matrix{v, d}, func(vectors []model.Vector) model.Vector {
+				return divideVectors(vectors[0], vectors[1])
+			})
+		}
+	}
+	return model.Vector{}
+}
+
+func divideVectors(dividend, divisor model.Vector) model.Vector {
+	divisors := make(map[model.Fingerprint]model.SampleValue, len(divisor))
+	for _, sample := range divisor {
+		divisors[sample.Metric.Fingerprint()] = sample.Value
+	}
+	result := model.Vector{}
+	for _, sample := range dividend {
+		if d, ok := divisors[sample.Metric.Fingerprint()]; ok {
+			result = append(result, &model.Sample{Metric: sample.Metric, Value: sample.Value / d, Timestamp: sample.Timestamp})
+		}
+	}
+	return result
+}
+
+// perTimestamp applies the function to the vectors of the samples of the matrices at each timestamp, and gathers
+// the resulting vectors in a matrix
+func perTimestamp(matrices []model.Matrix, f func(vectors []model.Vector) model.Vector) model.Matrix {
+	var timestamps []model.Time
+	byTime := make(map[model.Time][]model.Vector)
+	for i, matrix := range matrices {
+		for _, stream := range matrix {
+			for _, pair := range stream.Values {
+				vectors, ok := byTime[pair.Timestamp]
+				if !ok {
+					vectors = make([]model.Vector, len(matrices))
+					timestamps = append(timestamps, pair.Timestamp)
+				}
+				vectors[i] = append(vectors[i], &model.Sample{Metric: stream.Metric, Value: pair.Value, Timestamp: pair.Timestamp})
+				byTime[pair.Timestamp] = vectors
+			}
+		}
+	}
+	sort.Slice(timestamps, func(i, j int) bool { return timestamps[i] < timestamps[j] })
+
+	result := model.Matrix{}
+	index := make(map[model.Fingerprint]*model.SampleStream)
+	for _, ts := range timestamps {
+		for _, sample := range f(byTime[ts]) {
+			fp := sample.Metric.Fingerprint()
+			stream, ok := index[fp]
+			if !ok {
+				stream = &model.SampleStream{Metric: sample.Metric}
+				index[fp] = stream
+				result = append(result, stream)
+			}
+			stream.Values = append(stream.Values, model.SamplePair{Timestamp: ts, Value: sample.Value})
+		}
+	}
+	return result
+}
@@ -0,0 +1,213 @@
+package prometheus
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	prom_v1 "github.com/prometheus/client_golang/api/prometheus/v1"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeAPI returns the same vector, or error, to every query, or the vector of the query when vectors are set
+type fakeAPI struct {
+	prom_v1.API
+	vector  model.Vector
+	vectors map[string]model.Vector
+	err     error
+	queries int
+
+	rules    prom_v1.RulesResult
+	rulesErr error
+}
+
+func (f *fakeAPI) Rules(ctx context.Context) (prom_v1.RulesResult, error) {
+	return f.rules, f.rulesErr
+}
+
+func (f *fakeAPI) Query(ctx context.Context, query string, ts time.Time) (model.Value, prom_v1.Warnings, error) {
+	f.queries++
+	if f.err != nil {
+		return nil, nil, f.err
+	}
+	if f.vectors != nil {
+		vector, ok := f.vectors[query]
+		if !ok {
+			return nil, nil, errors.New("unexpected query " + query)
+		}
+		return vector, nil, nil
+	}
+	return f.vector, nil, nil
+}
+
+func sample(source, destination string, value float64) *model.Sample {
+	return &model.Sample{
+		Metric: model.Metric{"source_cluster": model.LabelValue(source), "destination_cluster": model.LabelValue(destination)},
+		Value:  model.SampleValue(value),
+	}
+}
+
+func TestFederatedQuery(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	east := &fakeAPI{vector: model.Vector{sample("east", "east", 1), sample("east", "west", 2)}}
+	west := &fakeAPI{vector: model.Vector{sample("west", "west", 3), sample("east", "west", 2)}}
+	api := newFederatedAPI(east, map[string]prom_v1.API{"east": east, "west": west})
+
+	value, warnings, err := api.Query(context.Background(), "sum(m) by (source_cluster,destination_cluster)", time.Now())
+	require.NoError(err)
+	assert.Empty(warnings)
+	vector := value.(model.Vector)
+	require.Len(vector, 3)
+	assert.Equal(sample("east", "east", 1), vector[0])
+	assert.Equal(sample("east", "west", 4), vector[1])
+	assert.Equal(sample("west", "west", 3), vector[2])
+
+	// A failing backend is a warning, unless all backends fail
+	west.err = errors.New("unreachable")
+	value, warnings, err = api.Query(context.Background(), "sum(m) by (source_cluster,destination_cluster)", time.Now())
+	require.NoError(err)
+	assert.Len(value.(model.Vector), 2)
+	assert.Equal(prom_v1.Warnings{"Prometheus of cluster west: unreachable"}, warnings)
+
+	east.err = errors.New("unreachable")
+	_, _, err = api.Query(context.Background(), "sum(m) by (source_cluster,destination_cluster)", time.Now())
+	assert.Error(err)
+}
+
+func TestMergeMatrices(t *testing.T) {
+	assert := assert.New(t)
+
+	metric := model.Metric{"destination_cluster": "west"}
+	merged := MergeMatrices(
+		model.Matrix{{Metric: metric, Values: []model.SamplePair{{Timestamp: 1, Value: 1}, {Timestamp: 2, Value: 1}}}},
+		model.Matrix{{Metric: metric, Values: []model.SamplePair{{Timestamp: 0, Value: 5}, {Timestamp: 2, Value: 2}}}},
+	)
+	assert.Len(merged, 1)
+	assert.Equal([]model.SamplePair{{Timestamp: 0, Value: 5}, {Timestamp: 1, Value: 1}, {Timestamp: 2, Value: 3}}, merged[0].Values)
+}
+
+func bucketSample(le string, value float64) *model.Sample {
+	return &model.Sample{Metric: model.Metric{"app": "reviews", "le": model.LabelValue(le)}, Value: model.SampleValue(value)}
+}
+
+func TestFederatedQuantilesAndAverages(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	buckets := `sum(rate(istio_request_duration_milliseconds_bucket{app="reviews"}[1m])) by (le,app)`
+	sum := `sum(rate(istio_request_duration_milliseconds_sum{app="reviews"}[1m])) by (app)`
+	count := `sum(rate(istio_request_duration_milliseconds_count{app="reviews"}[1m])) by (app)`
+	reviews := model.Metric{"app": "reviews"}
+	// The requests take 0-50ms in east, 50-100ms in west
+	east := &fakeAPI{vectors: map[string]model.Vector{
+		buckets: {bucketSample("50", 10), bucketSample("100", 10), bucketSample("+Inf", 10)},
+		sum:     {{Metric: reviews, Value: 100}},
+		count:   {{Metric: reviews, Value: 10}},
+	}}
+	west := &fakeAPI{vectors: map[string]model.Vector{
+		buckets: {bucketSample("50", 0), bucketSample("100", 10), bucketSample("+Inf", 10)},
+		sum:     {{Metric: reviews, Value: 300}},
+		count:   {{Metric: reviews, Value: 10}},
+	}}
+	api := newFederatedAPI(east, map[string]prom_v1.API{"east": east, "west": west})
+
+	value, _, err := api.Query(context.Background(), "histogram_quantile(0.5, "+buckets+") > 0", time.Now())
+	require.NoError(err)
+	require.Len(value.(model.Vector), 1)
+	assert.Equal(reviews, value.(model.Vector)[0].Metric)
+	assert.Equal(model.SampleValue(50), value.(model.Vector)[0].Value)
+
+	value, _, err = api.Query(context.Background(), sum+" / "+count, time.Now())
+	require.NoError(err)
+	require.Len(value.(model.Vector), 1)
+	assert.Equal(model.SampleValue(20), value.(model.Vector)[0].Value)
+
+	// Other divisions can't be merged
+	_, _, err = api.Query(context.Background(), sum+" / 1000", time.Now())
+	assert.EqualError(err, "the query ["+sum+" / 1000] can't be merged across the Prometheus backends of the clusters")
+}
+
+func TestFederatedAggregations(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	reviews := model.Metric{"app": "reviews"}
+	memory := `jvm_memory_bytes_used{app="reviews"}`
+	east := &fakeAPI{vectors: map[string]model.Vector{
+		"max(" + memory + ") by (app)":   {{Metric: reviews, Value: 300}},
+		"min(" + memory + ") by (app)":   {{Metric: reviews, Value: 100}},
+		"sum(" + memory + ") by (app)":   {{Metric: reviews, Value: 400}},
+		"count(" + memory + ") by (app)": {{Metric: reviews, Value: 2}},
+		"sum(m{a=\"1\"}) by (app)":       {{Metric: reviews, Value: 1.23456}},
+		"sum(m{a=\"2\"}) by (app)":       {{Metric: model.Metric{"app": "ratings"}, Value: 1}},
+	}}
+	west := &fakeAPI{vectors: map[string]model.Vector{
+		"max(" + memory + ") by (app)":   {{Metric: reviews, Value: 200}},
+		"min(" + memory + ") by (app)":   {{Metric: reviews, Value: 50}},
+		"sum(" + memory + ") by (app)":   {{Metric: reviews, Value: 200}},
+		"count(" + memory + ") by (app)": {{Metric: reviews, Value: 1}},
+		"sum(m{a=\"1\"}) by (app)":       {{Metric: reviews, Value: 1}},
+		"sum(m{a=\"2\"}) by (app)":       {{Metric: reviews, Value: 5}},
+	}}
+	api := newFederatedAPI(east, map[string]prom_v1.API{"east": east, "west": west})
+
+	for query, expected := range map[string]model.SampleValue{
+		"max(" + memory + ") by (app)": 300,
+		"min(" + memory + ") by (app)": 50,
+		"avg(" + memory + ") by (app)": 200,
+	} {
+		value, _, err := api.Query(context.Background(), query, time.Now())
+		require.NoError(err, query)
+		require.Len(value.(model.Vector), 1, query)
+		assert.Equal(expected, value.(model.Vector)[0].Value, query)
+	}
+
+	// The roundings and the unions are computed on the merged operands
+	value, _, err := api.Query(context.Background(), `round((sum(m{a="1"}) by (app)) OR (sum(m{a="2"}) by (app)),0.001)`, time.Now())
+	require.NoError(err)
+	require.Len(value.(model.Vector), 2)
+	assert.Equal(&model.Sample{Metric: reviews, Value: 2.235}, value.(model.Vector)[0])
+	assert.Equal(&model.Sample{Metric: model.Metric{"app": "ratings"}, Value: 1}, value.(model.Vector)[1])
+
+	// The other queries aren't merged
+	_, _, err = api.Query(context.Background(), "stddev("+memory+") by (app)", time.Now())
+	assert.Error(err)
+	_, _, err = api.Query(context.Background(), memory, time.Now())
+	assert.Error(err)
+}
+
+func TestRequestRatesAreQueriedFromTheClusterBackend(t *testing.T) {
+	east := &fakeAPI{vector: model.Vector{}}
+	west := &fakeAPI{vector: model.Vector{}}
+	backends := map[string]prom_v1.API{"east": east, "west": west}
+	client := Client{api: newFederatedAPI(east, backends), clusterAPIs: backends, ctx: context.Background(), warnings: &QueryWarnings{}}
+
+	_, err := client.GetAllRequestRates("bookinfo", "west", "1m", time.Now())
+	require.NoError(t, err)
+	assert.Zero(t, east.queries)
+	assert.NotZero(t, west.queries)
+
+	// The clusters without backend are queried from all the backends
+	_, err = client.GetAllRequestRates("bookinfo", "south", "1m", time.Now())
+	require.NoError(t, err)
+	assert.NotZero(t, east.queries)
+}
+
+func TestHistogramQuantileOfMatrix(t *testing.T) {
+	metric := func(le string) model.Metric { return model.Metric{"le": model.LabelValue(le)} }
+	matrix := model.Matrix{
+		{Metric: metric("10"), Values: []model.SamplePair{{Timestamp: 1, Value: 5}, {Timestamp: 2, Value: 0}}},
+		{Metric: metric("+Inf"), Values: []model.SamplePair{{Timestamp: 1, Value: 10}, {Timestamp: 2, Value: 0}}},
+	}
+
+	// No requests at timestamp 2
+	quantiles := filterPositive(histogramQuantile(0.5, matrix)).(model.Matrix)
+
+	require.Len(t, quantiles, 1)
+	assert.Equal(t, []model.SamplePair{{Timestamp: 1, Value: 10}}, quantiles[0].Values)
+}
@@ -0,0 +1,141 @@
+package prometheus
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	prom_v1 "github.com/prometheus/client_golang/api/prometheus/v1"
+	"github.com/prometheus/common/model"
+
+	"github.com/kiali/kiali/config"
+)
+
+var (
+	limiterMutex sync.Mutex
+	// querySlots are shared by the clients while max_concurrent_queries is unchanged
+	querySlots    chan struct{}
+	maxQuerySlots int
+)
+
+// limitedAPI bounds the queries sent to Prometheus: at most max_concurrent_queries run at once, shared by all the
+// clients and requests, and each query is cancelled after query_timeout. A query waiting for a slot gives up when
+// its context is done, e.g. when the request budget is exhausted.
+type limitedAPI struct {
+	prom_v1.API
+	slots   chan struct{}
+	timeout time.Duration
+}
+
+// withLimits wraps the API with the configured limits, when there are any. The query slots are created again when
+// max_concurrent_queries changes: the queries running in the previous slots aren't counted in the new ones.
+func withLimits(api prom_v1.API) prom_v1.API {
+	cfg := config.Get().ExternalServices.Prometheus
+	timeout := time.Duration(cfg.QueryTimeout) * time.Second
+	slots := getQuerySlots(cfg.MaxConcurrentQueries)
+	if slots == nil && timeout <= 0 {
+		return api
+	}
+	return newLimitedAPI(api, slots, timeout)
+}
+
+// getQuerySlots returns the slots shared by the clients, or nil when the concurrent queries aren't limited
+func getQuerySlots(max int) chan struct{} {
+	limiterMutex.Lock()
+	defer limiterMutex.Unlock()
+	if max != maxQuerySlots {
+		maxQuerySlots = max
+		querySlots = nil
+		if max > 0 {
+			querySlots = make(chan struct{}, max)
+		}
+	}
+	return querySlots
+}
+
+func newLimitedAPI(api prom_v1.API, slots chan struct{}, timeout time.Duration) *limitedAPI {
+	return &limitedAPI{API: api, slots: slots, timeout: timeout}
+}
+
+// acquire waits for a query slot and applies the query timeout. The returned function must be called once the
+// query is done.
+func (in *limitedAPI) acquire(ctx context.Context) (context.Context, func(), error) {
+	release := func() {}
+	if in.slots != nil {
+		select {
+		case in.slots <- struct{}{}:
+			release = func() { <-in.slots }
+		case <-ctx.Done():
+			return nil, nil, ctx.Err()
+		}
+	}
+	if in.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, in.timeout)
+		releaseSlot := release
+		release = func() {
+			cancel()
+			releaseSlot()
+		}
+	}
+	return ctx, release, nil
+}
+
+func (in *limitedAPI) Query(ctx context.Context, query string, ts time.Time) (model.Value, prom_v1.Warnings, error) {
+	ctx, release, err := in.acquire(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer release()
+	return in.API.Query(ctx, query, ts)
+}
+
+func (in *limitedAPI) QueryRange(ctx context.Context, query string, r prom_v1.Range) (model.Value, prom_v1.Warnings, error) {
+	ctx, release, err := in.acquire(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer release()
+	return in.API.QueryRange(ctx, query, r)
+}
+
+func (in *limitedAPI) QueryExemplars(ctx context.Context, query string, startTime time.Time, endTime time.Time) ([]prom_v1.ExemplarQueryResult, error) {
+	ctx, release, err := in.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	return in.API.QueryExemplars(ctx, query, startTime, endTime)
+}
+
+// QueryWarnings collects the warnings of the queries run for a request, e.g. the warnings returned by Prometheus
+// or the parts of the response skipped because their queries failed. It is safe for concurrent use.
+type QueryWarnings struct {
+	mutex    sync.Mutex
+	warnings []string
+}
+
+// Add records warnings, ignoring the ones already recorded
+func (in *QueryWarnings) Add(warnings ...string) {
+	in.mutex.Lock()
+	defer in.mutex.Unlock()
+	for _, w := range warnings {
+		found := false
+		for _, existing := range in.warnings {
+			if existing == w {
+				found = true
+				break
+			}
+		}
+		if !found {
+			in.warnings = append(in.warnings, w)
+		}
+	}
+}
+
+// List returns the recorded warnings
+func (in *QueryWarnings) List() []string {
+	in.mutex.Lock()
+	defer in.mutex.Unlock()
+	return append([]string{}, in.warnings...)
+}
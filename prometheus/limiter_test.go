@@ -0,0 +1,122 @@
+package prometheus
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	prom_v1 "github.com/prometheus/client_golang/api/prometheus/v1"
+	"github.com/prometheus/common/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kiali/kiali/config"
+)
+
+// slowAPI blocks every query until its context is done or it is released
+type slowAPI struct {
+	prom_v1.API
+	running  int32
+	maxSeen  int32
+	released chan struct{}
+}
+
+func (s *slowAPI) Query(ctx context.Context, query string, ts time.Time) (model.Value, prom_v1.Warnings, error) {
+	running := atomic.AddInt32(&s.running, 1)
+	defer atomic.AddInt32(&s.running, -1)
+	for {
+		seen := atomic.LoadInt32(&s.maxSeen)
+		if running <= seen || atomic.CompareAndSwapInt32(&s.maxSeen, seen, running) {
+			break
+		}
+	}
+	select {
+	case <-s.released:
+		return model.Vector{}, prom_v1.Warnings{"partial data"}, nil
+	case <-ctx.Done():
+		return nil, nil, ctx.Err()
+	}
+}
+
+func TestLimitedAPIConcurrency(t *testing.T) {
+	assert := assert.New(t)
+
+	slow := &slowAPI{released: make(chan struct{})}
+	api := newLimitedAPI(slow, make(chan struct{}, 2), 0)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, warnings, err := api.Query(context.Background(), "up", time.Now())
+			assert.NoError(err)
+			assert.Equal(prom_v1.Warnings{"partial data"}, warnings)
+		}()
+	}
+	time.Sleep(50 * time.Millisecond)
+	close(slow.released)
+	wg.Wait()
+	assert.Equal(int32(2), slow.maxSeen)
+}
+
+func TestLimitedAPITimeouts(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	// The query timeout cancels a slow query
+	slow := &slowAPI{released: make(chan struct{})}
+	api := newLimitedAPI(slow, make(chan struct{}, 1), 20*time.Millisecond)
+	_, _, err := api.Query(context.Background(), "up", time.Now())
+	require.Error(err)
+	assert.ErrorIs(err, context.DeadlineExceeded)
+
+	// A query waiting for a slot gives up when its request context is done
+	api.slots <- struct{}{}
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, _, err = api.Query(ctx, "up", time.Now())
+	assert.ErrorIs(err, context.DeadlineExceeded)
+	assert.Equal(int32(1), slow.maxSeen)
+}
+
+func TestLimitsFollowTheConfiguration(t *testing.T) {
+	assert := assert.New(t)
+	cfg := config.NewConfig()
+	config.Set(cfg)
+	t.Cleanup(func() {
+		config.Set(config.NewConfig())
+	})
+
+	api := &slowAPI{}
+	assert.Same(api, withLimits(api))
+
+	cfg.ExternalServices.Prometheus.MaxConcurrentQueries = 2
+	config.Set(cfg)
+	limited := withLimits(api).(*limitedAPI)
+	assert.Equal(2, cap(limited.slots))
+	assert.Zero(limited.timeout)
+	assert.Equal(limited.slots, withLimits(api).(*limitedAPI).slots)
+
+	cfg.ExternalServices.Prometheus.MaxConcurrentQueries = 4
+	cfg.ExternalServices.Prometheus.QueryTimeout = 30
+	config.Set(cfg)
+	limited = withLimits(api).(*limitedAPI)
+	assert.Equal(4, cap(limited.slots))
+	assert.Equal(30*time.Second, limited.timeout)
+}
+
+func TestClientWarnings(t *testing.T) {
+	assert := assert.New(t)
+
+	client := &Client{ctx: context.Background(), warnings: &QueryWarnings{}}
+	client.AddWarnings("a", "b")
+
+	// Warnings are collected per request
+	requestClient := client.WithContext(context.TODO())
+	requestClient.AddWarnings("c", "c")
+	assert.Equal([]string{"a", "b"}, client.Warnings())
+	assert.Equal([]string{"c"}, requestClient.Warnings())
+}
@@ -44,23 +44,25 @@ func fetchHistogramRange(ctx context.Context, api prom_v1.API, metricName, label
 	return histogram
 }
 
-func fetchHistogramValues(ctx context.Context, api prom_v1.API, metricName, labels, grouping, rateInterval string, avg bool, quantiles []string, queryTime time.Time) (map[string]model.Vector, error) {
+func fetchHistogramValues(ctx context.Context, api prom_v1.API, metricName, labels, grouping, rateInterval string, avg bool, quantiles []string, queryTime time.Time) (map[string]model.Vector, prom_v1.Warnings, error) {
 	// Note: the p8s queries are not run in parallel here, but they are at the caller's place.
 	//	This is because we may not want to create too many threads in the lowest layer
 	queries := buildHistogramQueries(metricName, labels, grouping, rateInterval, avg, quantiles)
 	histogram := make(map[string]model.Vector, len(queries))
+	var allWarnings prom_v1.Warnings
 	for k, query := range queries {
 		log.Tracef("[Prom] fetchHistogramValues: %s", query)
 		result, warnings, err := api.Query(ctx, query, queryTime)
 		if len(warnings) > 0 {
 			log.Warningf("fetchHistogramValues. Prometheus Warnings: [%s]", strings.Join(warnings, ","))
+			allWarnings = append(allWarnings, warnings...)
 		}
 		if err != nil {
-			return nil, errors.NewServiceUnavailable(err.Error())
+			return nil, allWarnings, errors.NewServiceUnavailable(err.Error())
 		}
 		histogram[k] = result.(model.Vector)
 	}
-	return histogram, nil
+	return histogram, allWarnings, nil
 }
 
 func buildHistogramQueries(metricName, labels, grouping, rateInterval string, avg bool, quantiles []string) map[string]string {
@@ -98,80 +100,97 @@ func fetchRange(ctx context.Context, api prom_v1.API, query string, bounds prom_
 		log.Warningf("fetchRange. Prometheus Warnings: [%s]", strings.Join(warnings, ","))
 	}
 	if err != nil {
-		return Metric{Err: err}
+		return Metric{Err: err, Warnings: warnings}
 	}
 	switch result.Type() {
 	case model.ValMatrix:
-		return Metric{Matrix: result.(model.Matrix)}
+		return Metric{Matrix: result.(model.Matrix), Warnings: warnings}
 	}
 	return Metric{Err: fmt.Errorf("invalid query, matrix expected: %s", query)}
 }
 
+func queryExemplars(ctx context.Context, api prom_v1.API, query string, start, end time.Time) ([]prom_v1.ExemplarQueryResult, error) {
+	log.Tracef("[Prom] queryExemplars: %s", query)
+	result, err := api.QueryExemplars(ctx, query, start, end)
+	if err != nil {
+		return nil, errors.NewServiceUnavailable(err.Error())
+	}
+	return result, nil
+}
+
 // getAllRequestRates retrieves traffic rates for requests entering, internal to, or exiting the namespace.
 // Note that it does not discriminate on "reporter", so rates can be inflated due to duplication, and therefore
 // should be used mainly for calculating ratios (e.g total rates / error rates)
-func getAllRequestRates(ctx context.Context, api prom_v1.API, namespace, cluster string, queryTime time.Time, ratesInterval string) (model.Vector, error) {
+func getAllRequestRates(ctx context.Context, api prom_v1.API, namespace, cluster string, queryTime time.Time, ratesInterval string) (model.Vector, prom_v1.Warnings, error) {
 	// traffic originating outside the namespace to destinations inside the namespace
 	lbl := fmt.Sprintf(`destination_service_namespace="%s",source_workload_namespace!="%s",destination_cluster="%s"`, namespace, namespace, cluster)
-	fromOutside, err := getRequestRatesForLabel(ctx, api, queryTime, lbl, ratesInterval)
+	fromOutside, warnings, err := getRequestRatesForLabel(ctx, api, queryTime, lbl, ratesInterval)
 	if err != nil {
-		return model.Vector{}, err
+		return model.Vector{}, warnings, err
 	}
 	// traffic originating inside the namespace to destinations inside or outside the namespace
 	lbl = fmt.Sprintf(`source_workload_namespace="%s",source_cluster="%s"`, namespace, cluster)
-	fromInside, err := getRequestRatesForLabel(ctx, api, queryTime, lbl, ratesInterval)
+	fromInside, insideWarnings, err := getRequestRatesForLabel(ctx, api, queryTime, lbl, ratesInterval)
+	warnings = append(warnings, insideWarnings...)
 	if err != nil {
-		return model.Vector{}, err
+		return model.Vector{}, warnings, err
 	}
 	// Merge results
 	all := append(fromOutside, fromInside...)
-	return all, nil
+	return all, warnings, nil
 }
 
 // getNamespaceServicesRequestRates retrieves traffic rates for requests entering or internal to the namespace.
 // Note that it does not discriminate on "reporter", so rates can be inflated due to duplication, and therefore
 // should be used mainly for calculating ratios (e.g total rates / error rates)
-func getNamespaceServicesRequestRates(ctx context.Context, api prom_v1.API, namespace, cluster string, queryTime time.Time, ratesInterval string) (model.Vector, error) {
+func getNamespaceServicesRequestRates(ctx context.Context, api prom_v1.API, namespace, cluster string, queryTime time.Time, ratesInterval string) (model.Vector, prom_v1.Warnings, error) {
 	// traffic for the namespace services
 	lblNs := fmt.Sprintf(`destination_service_namespace="%s",destination_cluster="%s"`, namespace, cluster)
-	ns, err := getRequestRatesForLabel(ctx, api, queryTime, lblNs, ratesInterval)
+	ns, warnings, err := getRequestRatesForLabel(ctx, api, queryTime, lblNs, ratesInterval)
 	if err != nil {
-		return model.Vector{}, err
+		return model.Vector{}, warnings, err
 	}
-	return ns, nil
+	return ns, warnings, nil
 }
 
 // getServiceRequestRates retrieves traffic rates for requests entering, or internal to the namespace, for a specific service name
 // Note that it does not discriminate on "reporter", so rates can be inflated due to duplication, and therefore
 // should be used mainly for calculating ratios (e.g total rates / error rates)
-func getServiceRequestRates(ctx context.Context, api prom_v1.API, namespace, cluster, service string, queryTime time.Time, ratesInterval string) (model.Vector, error) {
+func getServiceRequestRates(ctx context.Context, api prom_v1.API, namespace, cluster, service string, queryTime time.Time, ratesInterval string) (model.Vector, prom_v1.Warnings, error) {
 	lbl := fmt.Sprintf(`destination_service_name="%s",destination_service_namespace="%s",destination_cluster="%s"`, service, namespace, cluster)
-	in, err := getRequestRatesForLabel(ctx, api, queryTime, lbl, ratesInterval)
+	in, warnings, err := getRequestRatesForLabel(ctx, api, queryTime, lbl, ratesInterval)
 	if err != nil {
-		return model.Vector{}, err
+		return model.Vector{}, warnings, err
 	}
-	return in, nil
+	return in, warnings, nil
 }
 
 // getItemRequestRates retrieves traffic rates for requests entering, internal to, or exiting the namespace, for a specific destinatation_<itemLabelSuffix> value
 // Note that it does not discriminate on "reporter", so rates can be inflated due to duplication, and therefore
 // should be used mainly for calculating ratios (e.g total rates / error rates)
-func getItemRequestRates(ctx context.Context, api prom_v1.API, namespace, cluster, item, itemLabelSuffix string, queryTime time.Time, ratesInterval string) (model.Vector, model.Vector, error) {
+func getItemRequestRates(ctx context.Context, api prom_v1.API, namespace, cluster, item, itemLabelSuffix string, queryTime time.Time, ratesInterval string) (model.Vector, model.Vector, prom_v1.Warnings, error) {
 	lblIn := fmt.Sprintf(`destination_workload_namespace="%s",destination_%s="%s",destination_cluster="%s"`, namespace, itemLabelSuffix, item, cluster)
 	lblOut := fmt.Sprintf(`source_workload_namespace="%s",source_%s="%s",source_cluster="%s"`, namespace, itemLabelSuffix, item, cluster)
-	in, err := getRequestRatesForLabel(ctx, api, queryTime, lblIn, ratesInterval)
+	in, warnings, err := getRequestRatesForLabel(ctx, api, queryTime, lblIn, ratesInterval)
 	if err != nil {
-		return model.Vector{}, model.Vector{}, err
+		return model.Vector{}, model.Vector{}, warnings, err
 	}
-	out, err := getRequestRatesForLabel(ctx, api, queryTime, lblOut, ratesInterval)
+	out, outWarnings, err := getRequestRatesForLabel(ctx, api, queryTime, lblOut, ratesInterval)
+	warnings = append(warnings, outWarnings...)
 	if err != nil {
-		return model.Vector{}, model.Vector{}, err
+		return model.Vector{}, model.Vector{}, warnings, err
 	}
-	return in, out, nil
+	return in, out, warnings, nil
 }
 
-func getRequestRatesForLabel(ctx context.Context, api prom_v1.API, time time.Time, labels, ratesInterval string) (model.Vector, error) {
+func getRequestRatesForLabel(ctx context.Context, api prom_v1.API, time time.Time, labels, ratesInterval string) (model.Vector, prom_v1.Warnings, error) {
 	query := fmt.Sprintf("rate(istio_requests_total{%s}[%s]) > 0", labels, ratesInterval)
+	if planner := getQueryPlanner(); planner != nil {
+		// Aggregating on the labels used by the health gives the same results with a recording rule
+		if planned, ok := planner.SumRate(ctx, api, "istio_requests_total", labels, ratesInterval, healthLabels); ok {
+			query = planned + " > 0"
+		}
+	}
 	log.Tracef("[Prom] getRequestRatesForLabel: %s", query)
 	promtimer := internalmetrics.GetPrometheusProcessingTimePrometheusTimer("Metrics-GetRequestRates")
 	result, warnings, err := api.Query(ctx, query, time)
@@ -179,8 +198,8 @@ func getRequestRatesForLabel(ctx context.Context, api prom_v1.API, time time.Tim
 		log.Warningf("getRequestRatesForLabel. Prometheus Warnings: [%s]", strings.Join(warnings, ","))
 	}
 	if err != nil {
-		return model.Vector{}, errors.NewServiceUnavailable(err.Error())
+		return model.Vector{}, warnings, errors.NewServiceUnavailable(err.Error())
 	}
 	promtimer.ObserveDuration() // notice we only collect metrics for successful prom queries
-	return result.(model.Vector), nil
+	return result.(model.Vector), warnings, nil
 }
@@ -0,0 +1,281 @@
+package prometheus
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	prom_v1 "github.com/prometheus/client_golang/api/prometheus/v1"
+	"github.com/prometheus/common/model"
+
+	"github.com/kiali/kiali/config"
+	"github.com/kiali/kiali/log"
+)
+
+// rulesRefreshInterval is the minimum time between two discoveries of the recording rules
+const rulesRefreshInterval = 5 * time.Minute
+
+var (
+	// sum(rate(metric{selectors} [window])) by (labels), as built by the graph
+	sumRateRegexp = regexp.MustCompile(`sum\(rate\(([a-zA-Z_:][a-zA-Z0-9_:]*)\{([^}]*)\}\s*\[([0-9a-z]+)\]\)\)\s*by\s*\(([^)]*)\)`)
+	// Recording rule expression: sum(rate(metric[window])) by (labels), or sum by (labels) (rate(metric[window]))
+	ruleExprRegexp      = regexp.MustCompile(`^\s*sum\s*(?:by\s*\(([^)]*)\)\s*)?\(\s*rate\s*\(\s*([a-zA-Z_:][a-zA-Z0-9_:]*)\s*(?:\{\s*\})?\s*\[\s*([0-9a-z]+)\s*\]\s*\)\s*\)\s*(?:by\s*\(([^)]*)\))?\s*$`)
+	selectorLabelRegexp = regexp.MustCompile(`([a-zA-Z_][a-zA-Z0-9_]*)\s*(?:=~|!~|!=|=)\s*"`)
+
+	plannerMutex  sync.Mutex
+	plannerConfig config.PrometheusRecordingRules
+	queryPlanner  *QueryPlanner
+)
+
+// Labels read by the health from the request rates
+var healthLabels = []string{
+	"reporter", "request_protocol", "response_code", "grpc_response_status",
+	"source_workload", "source_canonical_service",
+	"destination_workload", "destination_canonical_service", "destination_service_name",
+}
+
+type recordingRule struct {
+	name   string
+	metric string
+	window time.Duration
+	labels map[string]bool
+}
+
+// QueryPlanner rewrites the rate queries to use pre-aggregated recording rules, when a rule keeps all the labels
+// used by the query (selectors and grouping) and its window is not longer than the query window. The rate over the
+// query window is then the average of the recorded rates: sum(avg_over_time(rule{selectors}[window])) by (labels).
+// The rules are discovered from each Prometheus backend. A discovered rule is only used when every backend queried
+// has it, else the raw expression is queried.
+type QueryPlanner struct {
+	configured []recordingRule
+	discovery  bool
+
+	mutex      sync.RWMutex
+	discovered map[string]*discoveredRules
+}
+
+// discoveredRules are the rules discovered from a backend
+type discoveredRules struct {
+	rules    []recordingRule
+	loadedAt time.Time
+}
+
+// NewQueryPlanner creates a planner for the configured recording rules
+func NewQueryPlanner(cfg config.PrometheusRecordingRules) *QueryPlanner {
+	planner := QueryPlanner{discovery: cfg.Discovery, discovered: map[string]*discoveredRules{}}
+	for _, r := range cfg.Rules {
+		window, err := model.ParseDuration(r.Window)
+		if err != nil || r.Name == "" || r.Metric == "" {
+			log.Errorf("Ignoring invalid recording rule configuration %s: [metric: %s, window: %s]", r.Name, r.Metric, r.Window)
+			continue
+		}
+		planner.configured = append(planner.configured, newRecordingRule(r.Name, r.Metric, time.Duration(window), r.Labels))
+	}
+	return &planner
+}
+
+// getQueryPlanner returns the planner of the Prometheus configuration, rebuilt when the configuration changes.
+// Returns nil when there's no recording rule to use.
+func getQueryPlanner() *QueryPlanner {
+	cfg := config.Get().ExternalServices.Prometheus.RecordingRules
+	plannerMutex.Lock()
+	defer plannerMutex.Unlock()
+	if queryPlanner == nil || !reflect.DeepEqual(cfg, plannerConfig) {
+		plannerConfig = cfg
+		queryPlanner = nil
+		if cfg.Discovery || len(cfg.Rules) > 0 {
+			queryPlanner = NewQueryPlanner(cfg)
+		}
+	}
+	return queryPlanner
+}
+
+func newRecordingRule(name, metric string, window time.Duration, labels []string) recordingRule {
+	rule := recordingRule{name: name, metric: metric, window: window, labels: make(map[string]bool, len(labels))}
+	for _, l := range labels {
+		if l = strings.TrimSpace(l); l != "" {
+			rule.labels[l] = true
+		}
+	}
+	return rule
+}
+
+// backendsOf returns the backends queried through the API, by cluster name. The API of a Prometheus that isn't
+// federated is returned with an empty name.
+func backendsOf(api prom_v1.API) map[string]prom_v1.API {
+	switch a := api.(type) {
+	case *federatedAPI:
+		return a.backends
+	case *clusterBackend:
+		return map[string]prom_v1.API{a.cluster: a}
+	default:
+		return map[string]prom_v1.API{"": api}
+	}
+}
+
+// rules returns the configured rules, and the discovered rules found in every backend queried through the API
+func (in *QueryPlanner) rules(ctx context.Context, api prom_v1.API) []recordingRule {
+	rules := append([]recordingRule{}, in.configured...)
+	if !in.discovery {
+		return rules
+	}
+
+	var common map[string]recordingRule
+	for name, backend := range backendsOf(api) {
+		found := make(map[string]recordingRule)
+		for _, rule := range in.refresh(ctx, name, backend) {
+			if _, ok := common[rule.name]; common == nil || ok {
+				found[rule.name] = rule
+			}
+		}
+		common = found
+	}
+	names := make([]string, 0, len(common))
+	for name := range common {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		rules = append(rules, common[name])
+	}
+	return rules
+}
+
+// refresh returns the recording rules discovered from the Prometheus rules API of the backend, discovering them
+// again once they're older than the refresh interval. On error, previous rules are kept.
+func (in *QueryPlanner) refresh(ctx context.Context, name string, api prom_v1.API) []recordingRule {
+	in.mutex.RLock()
+	discovered, ok := in.discovered[name]
+	upToDate := ok && time.Since(discovered.loadedAt) < rulesRefreshInterval
+	in.mutex.RUnlock()
+	if upToDate {
+		return discovered.rules
+	}
+
+	in.mutex.Lock()
+	defer in.mutex.Unlock()
+	discovered, ok = in.discovered[name]
+	if ok && time.Since(discovered.loadedAt) < rulesRefreshInterval {
+		return discovered.rules
+	}
+	if !ok {
+		discovered = &discoveredRules{}
+		in.discovered[name] = discovered
+	}
+	// Don't retry on every query when the rules API fails
+	discovered.loadedAt = time.Now()
+	result, err := api.Rules(ctx)
+	if err != nil {
+		log.Warningf("Could not discover the Prometheus recording rules of backend [%s]: %v", name, err)
+		return discovered.rules
+	}
+	discovered.rules = parseRecordingRules(result)
+	log.Debugf("Discovered %d usable Prometheus recording rules in backend [%s]", len(discovered.rules), name)
+	return discovered.rules
+}
+
+// parseRecordingRules keeps the recording rules that are a plain sum of the rate of a counter, without selectors
+func parseRecordingRules(result prom_v1.RulesResult) []recordingRule {
+	rules := []recordingRule{}
+	for _, group := range result.Groups {
+		for _, r := range group.Rules {
+			rr, ok := r.(prom_v1.RecordingRule)
+			if !ok {
+				continue
+			}
+			match := ruleExprRegexp.FindStringSubmatch(rr.Query)
+			if match == nil {
+				continue
+			}
+			window, err := model.ParseDuration(match[3])
+			if err != nil {
+				continue
+			}
+			labels := match[1]
+			if labels == "" {
+				labels = match[4]
+			}
+			rules = append(rules, newRecordingRule(rr.Name, match[2], time.Duration(window), strings.Split(labels, ",")))
+		}
+	}
+	return rules
+}
+
+// findRule returns the compatible rule keeping the fewest labels, i.e. the most aggregated one
+func findRule(rules []recordingRule, metric string, labels []string, window time.Duration) *recordingRule {
+	var best *recordingRule
+	for i := range rules {
+		rule := &rules[i]
+		if rule.metric != metric || rule.window > window {
+			continue
+		}
+		compatible := true
+		for _, l := range labels {
+			if !rule.labels[l] {
+				compatible = false
+				break
+			}
+		}
+		if compatible && (best == nil || len(rule.labels) < len(best.labels)) {
+			best = rule
+		}
+	}
+	return best
+}
+
+// sumRate returns sum(avg_over_time(rule{selectors}[window])) by (groupBy) when a compatible rule exists
+func sumRate(rules []recordingRule, metric, selectors, window string, groupBy []string) (string, bool) {
+	duration, err := model.ParseDuration(window)
+	if err != nil {
+		return "", false
+	}
+	labels := append(selectorLabels(selectors), groupBy...)
+	rule := findRule(rules, metric, labels, time.Duration(duration))
+	if rule == nil {
+		return "", false
+	}
+	return fmt.Sprintf("sum(avg_over_time(%s{%s}[%s])) by (%s)", rule.name, selectors, window, strings.Join(groupBy, ",")), true
+}
+
+// SumRate returns the rate of the metric, summed by the groupBy labels, from a compatible recording rule of the
+// backends queried through the API, if any
+func (in *QueryPlanner) SumRate(ctx context.Context, api prom_v1.API, metric, selectors, window string, groupBy []string) (string, bool) {
+	return sumRate(in.rules(ctx, api), metric, selectors, window, groupBy)
+}
+
+// Plan rewrites every sum(rate(metric{selectors} [window])) by (labels) of the query for which a compatible
+// recording rule exists in the backends queried through the API
+func (in *QueryPlanner) Plan(ctx context.Context, api prom_v1.API, query string) string {
+	rules := in.rules(ctx, api)
+	planned := sumRateRegexp.ReplaceAllStringFunc(query, func(expr string) string {
+		match := sumRateRegexp.FindStringSubmatch(expr)
+		groupBy := []string{}
+		for _, l := range strings.Split(match[4], ",") {
+			if l = strings.TrimSpace(l); l != "" {
+				groupBy = append(groupBy, l)
+			}
+		}
+		if rewritten, ok := sumRate(rules, match[1], match[2], match[3], groupBy); ok {
+			return rewritten
+		}
+		return expr
+	})
+	if planned != query {
+		log.Tracef("[Prom] query planned with recording rules: %s", planned)
+	}
+	return planned
+}
+
+func selectorLabels(selectors string) []string {
+	labels := []string{}
+	for _, match := range selectorLabelRegexp.FindAllStringSubmatch(selectors, -1) {
+		labels = append(labels, match[1])
+	}
+	sort.Strings(labels)
+	return labels
+}
@@ -0,0 +1,124 @@
+package prometheus
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	prom_v1 "github.com/prometheus/client_golang/api/prometheus/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kiali/kiali/config"
+)
+
+func TestPlanQuery(t *testing.T) {
+	assert := assert.New(t)
+
+	planner := NewQueryPlanner(config.PrometheusRecordingRules{
+		Rules: []config.PrometheusRecordingRule{
+			{
+				Name:   "namespace:istio_requests_total:rate1m",
+				Metric: "istio_requests_total",
+				Window: "1m",
+				Labels: []string{"reporter", "source_workload_namespace", "destination_workload_namespace", "response_code"},
+			},
+			{
+				Name:   "workload:istio_requests_total:rate1m",
+				Metric: "istio_requests_total",
+				Window: "1m",
+				Labels: []string{"reporter", "source_workload_namespace", "source_workload", "destination_workload_namespace", "destination_workload", "response_code"},
+			},
+		},
+	})
+
+	// The most aggregated compatible rule is used
+	query := `round(sum(rate(istio_requests_total{reporter="destination",destination_workload_namespace="bookinfo"} [600s])) by (response_code) > 0,0.001)`
+	assert.Equal(`round(sum(avg_over_time(namespace:istio_requests_total:rate1m{reporter="destination",destination_workload_namespace="bookinfo"}[600s])) by (response_code) > 0,0.001)`, planner.Plan(context.Background(), &fakeAPI{}, query))
+
+	query = `sum(rate(istio_requests_total{reporter="source",source_workload_namespace="bookinfo"} [600s])) by (source_workload,destination_workload)`
+	assert.Equal(`sum(avg_over_time(workload:istio_requests_total:rate1m{reporter="source",source_workload_namespace="bookinfo"}[600s])) by (source_workload,destination_workload)`, planner.Plan(context.Background(), &fakeAPI{}, query))
+
+	// Label not kept by any rule
+	query = `sum(rate(istio_requests_total{reporter="source",source_workload_namespace="bookinfo"} [600s])) by (response_flags)`
+	assert.Equal(query, planner.Plan(context.Background(), &fakeAPI{}, query))
+
+	// Window shorter than the rules window
+	query = `sum(rate(istio_requests_total{reporter="source"} [30s])) by (response_code)`
+	assert.Equal(query, planner.Plan(context.Background(), &fakeAPI{}, query))
+
+	// Other metric
+	query = `sum(rate(istio_tcp_sent_bytes_total{reporter="source"} [600s])) by (response_code)`
+	assert.Equal(query, planner.Plan(context.Background(), &fakeAPI{}, query))
+}
+
+func TestParseRecordingRules(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	rules := parseRecordingRules(prom_v1.RulesResult{
+		Groups: []prom_v1.RuleGroup{{
+			Rules: prom_v1.Rules{
+				prom_v1.RecordingRule{Name: "a", Query: "sum(rate(istio_requests_total[1m])) by (reporter, response_code)"},
+				prom_v1.RecordingRule{Name: "b", Query: "sum by (reporter) (rate(istio_requests_total[5m]))"},
+				prom_v1.RecordingRule{Name: "filtered", Query: `sum(rate(istio_requests_total{reporter="source"}[1m])) by (response_code)`},
+				prom_v1.RecordingRule{Name: "other", Query: "histogram_quantile(0.99, sum(rate(x_bucket[1m])) by (le))"},
+				prom_v1.AlertingRule{Name: "alert", Query: "sum(rate(istio_requests_total[1m])) by (reporter)"},
+			},
+		}},
+	})
+	require.Len(rules, 2)
+	assert.Equal("a", rules[0].name)
+	assert.Equal("istio_requests_total", rules[0].metric)
+	assert.Equal(map[string]bool{"reporter": true, "response_code": true}, rules[0].labels)
+	assert.Equal("b", rules[1].name)
+	assert.Equal(map[string]bool{"reporter": true}, rules[1].labels)
+	assert.Equal("5m0s", rules[1].window.String())
+}
+
+func TestPlanQueryWithDiscoveredRules(t *testing.T) {
+	assert := assert.New(t)
+
+	rules := prom_v1.RulesResult{Groups: []prom_v1.RuleGroup{{Rules: prom_v1.Rules{
+		prom_v1.RecordingRule{Name: "namespace:istio_requests_total:rate1m", Query: "sum(rate(istio_requests_total[1m])) by (reporter, destination_workload_namespace, response_code)"},
+	}}}}
+	east := &fakeAPI{rules: rules}
+	west := &fakeAPI{rulesErr: errors.New("unreachable")}
+	api := newFederatedAPI(east, map[string]prom_v1.API{"east": &clusterBackend{API: east, cluster: "east"}, "west": &clusterBackend{API: west, cluster: "west"}})
+	planner := NewQueryPlanner(config.PrometheusRecordingRules{Discovery: true})
+
+	query := `sum(rate(istio_requests_total{reporter="destination",destination_workload_namespace="bookinfo"} [600s])) by (response_code)`
+	planned := `sum(avg_over_time(namespace:istio_requests_total:rate1m{reporter="destination",destination_workload_namespace="bookinfo"}[600s])) by (response_code)`
+
+	// The rule is only used for the backends having it
+	assert.Equal(planned, planner.Plan(context.Background(), api.backends["east"], query))
+	assert.Equal(query, planner.Plan(context.Background(), api.backends["west"], query))
+	assert.Equal(query, planner.Plan(context.Background(), api, query))
+
+	// The rules are discovered again once they're outdated
+	west.rules, west.rulesErr = rules, nil
+	assert.Equal(query, planner.Plan(context.Background(), api, query))
+	planner.discovered["west"].loadedAt = time.Now().Add(-rulesRefreshInterval)
+	assert.Equal(planned, planner.Plan(context.Background(), api, query))
+}
+
+func TestQueryPlannerFollowsTheConfiguration(t *testing.T) {
+	cfg := config.NewConfig()
+	config.Set(cfg)
+	t.Cleanup(func() {
+		config.Set(config.NewConfig())
+	})
+	assert.Nil(t, getQueryPlanner())
+
+	cfg.ExternalServices.Prometheus.RecordingRules.Discovery = true
+	config.Set(cfg)
+	planner := getQueryPlanner()
+	require.NotNil(t, planner)
+	assert.Same(t, planner, getQueryPlanner())
+
+	cfg.ExternalServices.Prometheus.RecordingRules.Rules = []config.PrometheusRecordingRule{{Name: "rule", Metric: "istio_requests_total", Window: "1m"}}
+	config.Set(cfg)
+	assert.NotSame(t, planner, getQueryPlanner())
+	assert.Len(t, getQueryPlanner().configured, 1)
+}
@@ -334,6 +334,11 @@ func (o *PromClientMock) GetWorkloadRequestRates(namespace, cluster, workload, r
 	return args.Get(0).(model.Vector), args.Get(1).(model.Vector), args.Error(2)
 }
 
+func (o *PromClientMock) FetchQueryRange(query string, q *prometheus.RangeQuery) prometheus.Metric {
+	args := o.Called(query, q)
+	return args.Get(0).(prometheus.Metric)
+}
+
 func (o *PromClientMock) FetchRange(metricName, labels, grouping, aggregator string, q *prometheus.RangeQuery) prometheus.Metric {
 	args := o.Called(metricName, labels, grouping, aggregator, q)
 	return args.Get(0).(prometheus.Metric)
@@ -359,6 +364,11 @@ func (o *PromClientMock) GetMetricsForLabels(metricNames []string, labels string
 	return args.Get(0).([]string), args.Error(1)
 }
 
+func (o *PromClientMock) QueryExemplars(query string, start, end time.Time) ([]prom_v1.ExemplarQueryResult, error) {
+	args := o.Called(query, start, end)
+	return args.Get(0).([]prom_v1.ExemplarQueryResult), args.Error(1)
+}
+
 func (o *PromClientMock) MockMetric(name string, labels string, q *prometheus.RangeQuery, value float64) {
 	o.On("FetchRateRange", name, []string{labels}, "", q).Return(fakeMetric(value))
 }
@@ -35,8 +35,9 @@ type Metrics struct {
 
 // Metric holds the Prometheus Matrix model, which contains one or more time series (depending on grouping)
 type Metric struct {
-	Matrix model.Matrix `json:"matrix"`
-	Err    error        `json:"-"`
+	Matrix   model.Matrix `json:"matrix"`
+	Err      error        `json:"-"`
+	Warnings []string     `json:"-"` // Warnings returned by Prometheus
 }
 
 // Histogram contains Metric objects for several histogram-kind statistics
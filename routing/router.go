@@ -80,6 +80,7 @@ func NewRouter() *mux.Router {
 		} else {
 			handlerFunction = authenticationHandler.HandleUnauthenticated(handlerFunction)
 		}
+		handlerFunction = handlers.CheckPeerRequest(handlerFunction)
 		appRouter.
 			Methods(route.Method).
 			Path(route.Pattern).
@@ -87,7 +88,7 @@ func NewRouter() *mux.Router {
 			Handler(handlerFunction)
 	}
 
-	if authController := authentication.GetAuthController(); authController != nil {
+	if authController := authentication.GetStrategyAuthController(config.AuthStrategyOpenId); authController != nil {
 		if ac, ok := authController.(*authentication.OpenIdAuthController); ok {
 			ac.PostRoutes(appRouter)
 		}
@@ -99,7 +100,7 @@ func NewRouter() *mux.Router {
 		serveIndexFile(w)
 	})
 
-	if authController := authentication.GetAuthController(); authController != nil {
+	if authController := authentication.GetStrategyAuthController(config.AuthStrategyOpenId); authController != nil {
 		if ac, ok := authController.(*authentication.OpenIdAuthController); ok {
 			authCallback := ac.GetAuthCallbackHandler(http.HandlerFunc(fileServerHandler))
 			rootRouter.Methods("GET").Path(webRootWithSlash).Handler(authCallback)
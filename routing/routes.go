@@ -118,6 +118,177 @@ func NewRoutes() (r *Routes) {
 			handlers.Logout,
 			false,
 		},
+		// swagger:route DELETE /sessions auth logoutEverywhere
+		// ---
+		// Endpoint to terminate all the sessions of the current user. Requires a server-side session store.
+		//
+		//     Produces:
+		//     - application/json
+		//
+		//     Schemes: http, https
+		//
+		// responses:
+		//      400: badRequestError
+		//      500: internalError
+		//      503: serviceUnavailableError
+		//      200: revokedSessionsResponse
+		{
+			"LogoutEverywhere",
+			"DELETE",
+			"/api/sessions",
+			handlers.LogoutEverywhere,
+			true,
+		},
+		// swagger:route GET /admin/sessions auth sessions
+		// ---
+		// Endpoint to list the active sessions. Requires a server-side session store, and the permission to delete
+		// the secrets of the Kiali namespace.
+		//
+		//     Produces:
+		//     - application/json
+		//
+		//     Schemes: http, https
+		//
+		// responses:
+		//      500: internalError
+		//      503: serviceUnavailableError
+		//      200: sessionsResponse
+		{
+			"Sessions",
+			"GET",
+			"/api/admin/sessions",
+			handlers.Sessions,
+			true,
+		},
+		// swagger:route DELETE /admin/sessions auth userSessionsRevoke
+		// ---
+		// Endpoint to revoke all the sessions of a user. Requires a server-side session store, and the permission to
+		// delete the secrets of the Kiali namespace.
+		//
+		//     Produces:
+		//     - application/json
+		//
+		//     Schemes: http, https
+		//
+		// responses:
+		//      400: badRequestError
+		//      500: internalError
+		//      503: serviceUnavailableError
+		//      200: revokedSessionsResponse
+		{
+			"UserSessionsRevoke",
+			"DELETE",
+			"/api/admin/sessions",
+			handlers.UserSessionsRevoke,
+			true,
+		},
+		// swagger:route DELETE /admin/sessions/{session} auth sessionRevoke
+		// ---
+		// Endpoint to revoke a session. Requires a server-side session store, and the permission to delete the
+		// secrets of the Kiali namespace.
+		//
+		//     Schemes: http, https
+		//
+		// responses:
+		//      404: notFoundError
+		//      500: internalError
+		//      503: serviceUnavailableError
+		//      204: noContent
+		{
+			"SessionRevoke",
+			"DELETE",
+			"/api/admin/sessions/{session}",
+			handlers.SessionRevoke,
+			true,
+		},
+		// swagger:route GET /admin/audit auth auditEntries
+		// ---
+		// Endpoint to query the recent audit records of the write operations, newest first. Each Kiali replica only
+		// returns the records of the operations it did. Requires the permission to delete the secrets of the Kiali
+		// namespace.
+		//
+		//     Produces:
+		//     - application/json
+		//
+		//     Schemes: http, https
+		//
+		// responses:
+		//      400: badRequestError
+		//      500: internalError
+		//      503: serviceUnavailableError
+		//      200: auditEntriesResponse
+		{
+			"AuditEntries",
+			"GET",
+			"/api/admin/audit",
+			handlers.AuditEntries,
+			true,
+		},
+		// swagger:route GET /tokens auth apiTokens
+		// ---
+		// Endpoint to list the API tokens of the current user. Requires the API tokens to be enabled.
+		//
+		//     Produces:
+		//     - application/json
+		//
+		//     Schemes: http, https
+		//
+		// responses:
+		//      500: internalError
+		//      503: serviceUnavailableError
+		//      200: apiTokensResponse
+		{
+			"APITokens",
+			"GET",
+			"/api/tokens",
+			handlers.APITokens,
+			true,
+		},
+		// swagger:route POST /tokens auth apiTokenCreate
+		// ---
+		// Endpoint to create an API token for the scripts of the current user. The token is sent as a bearer token
+		// in the Authorization header, and calls the cluster API with the Kubernetes identity of the user. It can be
+		// restricted to some namespaces and to a Kiali role. The token is only returned by this call.
+		//
+		//     Consumes:
+		//     - application/json
+		//
+		//     Produces:
+		//     - application/json
+		//
+		//     Schemes: http, https
+		//
+		// responses:
+		//      400: badRequestError
+		//      500: internalError
+		//      503: serviceUnavailableError
+		//      201: createdAPITokenResponse
+		{
+			"APITokenCreate",
+			"POST",
+			"/api/tokens",
+			handlers.APITokenCreate,
+			true,
+		},
+		// swagger:route DELETE /tokens/{token} auth apiTokenRevoke
+		// ---
+		// Endpoint to revoke an API token of the current user. Revoking the tokens of the other users requires the
+		// permission to delete the secrets of the Kiali namespace.
+		//
+		//     Schemes: http, https
+		//
+		// responses:
+		//      404: notFoundError
+		//      500: internalError
+		//      503: serviceUnavailableError
+		//      204: noContent
+		{
+			"APITokenRevoke",
+			"DELETE",
+			"/api/tokens/{token}",
+			handlers.APITokenRevoke,
+			true,
+		},
 		// swagger:route GET /auth/info auth authenticationInfo
 		// ---
 		// Endpoint to get login info, such as strategy, authorization endpoints
@@ -157,7 +328,7 @@ func NewRoutes() (r *Routes) {
 			"Status",
 			"GET",
 			"/api/status",
-			handlers.Root,
+			handlers.Status,
 			true,
 		},
 		// swagger:route GET /config kiali getConfig
@@ -513,6 +684,27 @@ func NewRoutes() (r *Routes) {
 			handlers.ServiceTraces,
 			true,
 		},
+		// swagger:route GET /namespaces/{namespace}/services/{service}/traces/compare traces serviceTracesCompare
+		// ---
+		// Endpoint to compare the traces of a given service between a baseline time window and the requested time window
+		//
+		//     Produces:
+		//     - application/json
+		//
+		//     Schemes: http, https
+		//
+		// responses:
+		//      400: badRequestError
+		//      500: internalError
+		//      200: traceComparisonResponse
+		//
+		{
+			"ServiceTracesCompare",
+			"GET",
+			"/api/namespaces/{namespace}/services/{service}/traces/compare",
+			handlers.ServiceTracesCompare,
+			true,
+		},
 		// swagger:route GET /namespaces/{namespace}/workloads/{workload}/traces traces workloadTraces
 		// ---
 		// Endpoint to get the traces of a given workload
@@ -576,6 +768,72 @@ func NewRoutes() (r *Routes) {
 			handlers.TraceDetails,
 			true,
 		},
+		// swagger:route GET /traces/{traceID}/compare/{otherTraceID} traces tracesCompare
+		// ---
+		// Endpoint to compare two traces: per-operation duration deltas, self and child times, new or missing
+		// operations and critical paths
+		//
+		//     Produces:
+		//     - application/json
+		//
+		//     Schemes: http, https
+		//
+		// responses:
+		//      404: notFoundError
+		//      500: internalError
+		//      200: traceComparisonResponse
+		//
+		{
+			"TracesCompare",
+			"GET",
+			"/api/traces/{traceID}/compare/{otherTraceID}",
+			handlers.TracesCompare,
+			true,
+		},
+		// swagger:route GET /traces/{traceID}/logs traces traceLogs
+		// ---
+		// Endpoint to get the logs of all the pods involved in a trace, in the time window of the trace
+		//
+		//     Produces:
+		//     - application/json
+		//
+		//     Schemes: http, https
+		//
+		// responses:
+		//      400: badRequestError
+		//      404: notFoundError
+		//      500: internalError
+		//      200: traceLogsResponse
+		//
+		{
+			"TraceLogs",
+			"GET",
+			"/api/traces/{traceID}/logs",
+			handlers.TraceLogs,
+			true,
+		},
+		// swagger:route GET /namespaces/{namespace}/logs/trace traces logTrace
+		// ---
+		// Endpoint to get the trace of a log entry, by the trace ID or the request ID found in the entry
+		//
+		//     Produces:
+		//     - application/json
+		//
+		//     Schemes: http, https
+		//
+		// responses:
+		//      400: badRequestError
+		//      404: notFoundError
+		//      500: internalError
+		//      200: traceDetailsResponse
+		//
+		{
+			"LogTrace",
+			"GET",
+			"/api/namespaces/{namespace}/logs/trace",
+			handlers.LogTrace,
+			true,
+		},
 		// swagger:route GET /namespaces/{namespace}/workloads workloads workloadList
 		// ---
 		// Endpoint to get the list of workloads for a namespace
@@ -896,6 +1154,94 @@ func NewRoutes() (r *Routes) {
 			handlers.CustomDashboard,
 			true,
 		},
+		// swagger:route GET /namespaces/{namespace}/customdashboard/{dashboard}/grafana dashboards customDashboardGrafanaExport
+		// ---
+		// Endpoint to export a custom dashboard as a Grafana dashboard
+		//
+		//     Produces:
+		//     - application/json
+		//
+		//     Schemes: http, https
+		//
+		// responses:
+		//      404: notFoundError
+		//      500: internalError
+		//      503: serviceUnavailableError
+		//      200: grafanaDashboardResponse
+		//
+		{
+			"CustomDashboardGrafanaExport",
+			"GET",
+			"/api/namespaces/{namespace}/customdashboard/{dashboard}/grafana",
+			handlers.CustomDashboardGrafanaExport,
+			true,
+		},
+		// swagger:route POST /namespaces/{namespace}/customdashboard/grafana dashboards customDashboardGrafanaImport
+		// ---
+		// Endpoint to convert a Grafana dashboard to a MonitoringDashboard resource of the namespace
+		//
+		//     Consumes:
+		//     - application/json
+		//
+		//     Produces:
+		//     - application/json
+		//
+		//     Schemes: http, https
+		//
+		// responses:
+		//      400: badRequestError
+		//      500: internalError
+		//      200: grafanaDashboardImportResponse
+		//
+		{
+			"CustomDashboardGrafanaImport",
+			"POST",
+			"/api/namespaces/{namespace}/customdashboard/grafana",
+			handlers.CustomDashboardGrafanaImport,
+			true,
+		},
+		// swagger:route GET /metrictemplates dashboards metricTemplates
+		// ---
+		// Endpoint to list the PromQL templates that can be run in a namespace
+		//
+		//     Produces:
+		//     - application/json
+		//
+		//     Schemes: http, https
+		//
+		// responses:
+		//      200: metricTemplatesResponse
+		//
+		{
+			"MetricTemplates",
+			"GET",
+			"/api/metrictemplates",
+			handlers.MetricTemplates,
+			true,
+		},
+		// swagger:route GET /namespaces/{namespace}/metrictemplates/{template} dashboards metricTemplate
+		// ---
+		// Endpoint to run a PromQL template of the configuration in a namespace
+		//
+		//     Produces:
+		//     - application/json
+		//
+		//     Schemes: http, https
+		//
+		// responses:
+		//      400: badRequestError
+		//      404: notFoundError
+		//      500: internalError
+		//      503: serviceUnavailableError
+		//      200: metricTemplateResponse
+		//
+		{
+			"MetricTemplate",
+			"GET",
+			"/api/namespaces/{namespace}/metrictemplates/{template}",
+			handlers.MetricTemplate,
+			true,
+		},
 		// swagger:route GET /namespaces/{namespace}/metrics namespaces namespaceMetrics
 		// ---
 		// Endpoint to fetch metrics to be displayed, related to a namespace
@@ -1298,6 +1644,94 @@ func NewRoutes() (r *Routes) {
 			handlers.PodLogs,
 			true,
 		},
+		// swagger:route GET /namespaces/{namespace}/workloads/{workload}/logs workloads workloadLogs
+		// ---
+		// Endpoint to get the logs of all the pods of a workload, merged in timestamp order
+		//
+		//     Produces:
+		//     - application/json
+		//
+		//     Schemes: http, https
+		//
+		// responses:
+		//      500: internalError
+		//      404: notFoundError
+		//      400: badRequestError
+		//      200: podLogs
+		//
+		{
+			"WorkloadLogs",
+			"GET",
+			"/api/namespaces/{namespace}/workloads/{workload}/logs",
+			handlers.WorkloadLogs,
+			true,
+		},
+		// swagger:route GET /namespaces/{namespace}/apps/{app}/logs apps appLogs
+		// ---
+		// Endpoint to get the logs of all the pods of an app, merged in timestamp order
+		//
+		//     Produces:
+		//     - application/json
+		//
+		//     Schemes: http, https
+		//
+		// responses:
+		//      500: internalError
+		//      404: notFoundError
+		//      400: badRequestError
+		//      200: podLogs
+		//
+		{
+			"AppLogs",
+			"GET",
+			"/api/namespaces/{namespace}/apps/{app}/logs",
+			handlers.AppLogs,
+			true,
+		},
+		// swagger:route GET /namespaces/{namespace}/pods/{pod}/access_logs pods podAccessLogStats
+		// ---
+		// Endpoint to get statistics computed from the proxy access logs of a pod
+		//
+		//     Produces:
+		//     - application/json
+		//
+		//     Schemes: http, https
+		//
+		// responses:
+		//      500: internalError
+		//      404: notFoundError
+		//      400: badRequestError
+		//      200: accessLogStatsResponse
+		//
+		{
+			"PodAccessLogStats",
+			"GET",
+			"/api/namespaces/{namespace}/pods/{pod}/access_logs",
+			handlers.PodAccessLogStats,
+			true,
+		},
+		// swagger:route GET /namespaces/{namespace}/workloads/{workload}/access_logs workloads workloadAccessLogStats
+		// ---
+		// Endpoint to get statistics computed from the proxy access logs of all the pods of a workload
+		//
+		//     Produces:
+		//     - application/json
+		//
+		//     Schemes: http, https
+		//
+		// responses:
+		//      500: internalError
+		//      404: notFoundError
+		//      400: badRequestError
+		//      200: accessLogStatsResponse
+		//
+		{
+			"WorkloadAccessLogStats",
+			"GET",
+			"/api/namespaces/{namespace}/workloads/{workload}/access_logs",
+			handlers.WorkloadAccessLogStats,
+			true,
+		},
 		// swagger:route GET /namespaces/{namespace}/pods/{pod}/config_dump pods podProxyDump
 		// ---
 		// Endpoint to get pod proxy dump
@@ -2,8 +2,10 @@ package server
 
 import (
 	"crypto/tls"
+	"crypto/x509"
 	"fmt"
 	"net/http"
+	"os"
 	"time"
 
 	"github.com/NYTimes/gziphandler"
@@ -65,6 +67,21 @@ func NewServer() *Server {
 		NextProtos: []string{"h2", "http/1.1"},
 	}
 
+	// The client certificates are verified when a CA is configured, e.g. for the federated Kiali instances
+	if conf.Identity.ClientCAFile != "" {
+		if caCert, err := os.ReadFile(conf.Identity.ClientCAFile); err != nil {
+			log.Errorf("Failed to read the CA of the client certificates [%s]: %v", conf.Identity.ClientCAFile, err)
+		} else {
+			clientCAs := x509.NewCertPool()
+			if !clientCAs.AppendCertsFromPEM(caCert) {
+				log.Errorf("The CA of the client certificates [%s] could not be parsed", conf.Identity.ClientCAFile)
+			} else {
+				tlsConfig.ClientCAs = clientCAs
+				tlsConfig.ClientAuth = tls.VerifyClientCertIfGiven
+			}
+		}
+	}
+
 	// create the server definition that will handle both console and api server traffic
 	httpServer := &http.Server{
 		Addr:         fmt.Sprintf("%v:%v", conf.Server.Address, conf.Server.Port),
@@ -0,0 +1,128 @@
+package status
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/kiali/kiali/kubernetes"
+	"github.com/kiali/kiali/log"
+)
+
+// clusterCheckTimeout bounds the time waited for the API server of a cluster
+const clusterCheckTimeout = 10 * time.Second
+
+// clusterCheckInterval is how often the clusters are checked in the background
+const clusterCheckInterval = 30 * time.Second
+
+var (
+	// clusterStatuses are the results of the last check of the clusters
+	clusterStatuses     = []ClusterStatus{}
+	clusterStatusesLock sync.RWMutex
+	clusterChecksOnce   sync.Once
+)
+
+// ClusterStatus is the reachability of the API server of a cluster of the mesh
+// swagger:model clusterStatus
+type ClusterStatus struct {
+	// The name of the cluster
+	//
+	// required: true
+	// example: east
+	Name string `json:"name"`
+
+	// True if the API server of the cluster answered with the Kiali service account
+	//
+	// required: true
+	Reachable bool `json:"reachable"`
+
+	// The Kubernetes version of the cluster
+	//
+	// required: false
+	// example: v1.25.3
+	Version string `json:"version,omitempty"`
+
+	// The error returned when reaching the cluster
+	//
+	// required: false
+	Error string `json:"error,omitempty"`
+}
+
+// StartClusterChecks checks the clusters in the background, so that the status requests never wait for their API
+// servers. It can be called several times: the checks are only started once.
+func StartClusterChecks() {
+	clusterChecksOnce.Do(func() {
+		go func() {
+			for {
+				refreshClusterStatuses(getClusterStatuses)
+				time.Sleep(clusterCheckInterval)
+			}
+		}()
+	})
+}
+
+// GetClusterStatuses returns the results of the last background check of the clusters, empty until the first
+// check completes.
+func GetClusterStatuses() []ClusterStatus {
+	clusterStatusesLock.RLock()
+	defer clusterStatusesLock.RUnlock()
+	statuses := make([]ClusterStatus, len(clusterStatuses))
+	copy(statuses, clusterStatuses)
+	return statuses
+}
+
+func refreshClusterStatuses(check func() []ClusterStatus) {
+	statuses := check()
+	clusterStatusesLock.Lock()
+	defer clusterStatusesLock.Unlock()
+	clusterStatuses = statuses
+}
+
+// getClusterStatuses checks the API server of the home cluster and of every remote cluster, including the ones
+// registered at runtime. The clusters are checked concurrently.
+func getClusterStatuses() []ClusterStatus {
+	clientFactory, err := kubernetes.GetClientFactory()
+	if err != nil {
+		log.Debugf("Cannot check the clusters: %v", err)
+		return []ClusterStatus{}
+	}
+	return checkClusters(clientFactory.GetSAClients(), clusterCheckTimeout)
+}
+
+func checkClusters(clients map[string]kubernetes.ClientInterface, timeout time.Duration) []ClusterStatus {
+	results := make(chan ClusterStatus, len(clients))
+	for cluster, client := range clients {
+		go func(cluster string, client kubernetes.ClientInterface) {
+			clusterStatus := ClusterStatus{Name: cluster}
+			if serverVersion, err := client.GetServerVersion(); err != nil {
+				clusterStatus.Error = err.Error()
+			} else {
+				clusterStatus.Reachable = true
+				clusterStatus.Version = serverVersion.GitVersion
+			}
+			results <- clusterStatus
+		}(cluster, client)
+	}
+
+	statuses := make([]ClusterStatus, 0, len(clients))
+	checked := make(map[string]bool, len(clients))
+	deadline := time.After(timeout)
+	for len(statuses) < len(clients) {
+		select {
+		case clusterStatus := <-results:
+			statuses = append(statuses, clusterStatus)
+			checked[clusterStatus.Name] = true
+		case <-deadline:
+			for cluster := range clients {
+				if !checked[cluster] {
+					statuses = append(statuses, ClusterStatus{Name: cluster, Error: "the API server of the cluster didn't answer in time"})
+				}
+			}
+		}
+	}
+
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].Name < statuses[j].Name
+	})
+	return statuses
+}
@@ -0,0 +1,41 @@
+package status
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/version"
+
+	"github.com/kiali/kiali/kubernetes"
+	"github.com/kiali/kiali/kubernetes/kubetest"
+)
+
+func TestCheckClustersReportsEveryCluster(t *testing.T) {
+	home := new(kubetest.K8SClientMock)
+	home.On("GetServerVersion").Return(&version.Info{GitVersion: "v1.25.3"}, nil)
+	east := new(kubetest.K8SClientMock)
+	east.On("GetServerVersion").Return((*version.Info)(nil), errors.New("connection refused"))
+
+	statuses := checkClusters(map[string]kubernetes.ClientInterface{"home": home, "east": east}, time.Second)
+	assert.Equal(t, []ClusterStatus{
+		{Name: "east", Error: "connection refused"},
+		{Name: "home", Reachable: true, Version: "v1.25.3"},
+	}, statuses)
+}
+
+func TestClusterStatusesAreCached(t *testing.T) {
+	assert := assert.New(t)
+	checks := 0
+	refreshClusterStatuses(func() []ClusterStatus {
+		checks++
+		return []ClusterStatus{{Name: "home", Reachable: true}}
+	})
+
+	statuses := GetClusterStatuses()
+	assert.Equal([]ClusterStatus{{Name: "home", Reachable: true}}, statuses)
+	statuses[0].Reachable = false
+	assert.Equal([]ClusterStatus{{Name: "home", Reachable: true}}, GetClusterStatuses())
+	assert.Equal(1, checks)
+}
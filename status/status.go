@@ -54,6 +54,12 @@ type StatusInfo struct {
 	//
 	// required: true
 	IstioEnvironment *IstioEnvironment `json:"istioEnvironment"`
+	// The reachability of the home cluster and of every remote cluster, as last checked in the background. Only
+	// reported to the authenticated users.
+	//
+	// required: false
+	// swagger:allOf
+	Clusters []ClusterStatus `json:"clusters,omitempty"`
 }
 
 // info is a global var that contains information about Kiali status and what external services are available
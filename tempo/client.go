@@ -0,0 +1,267 @@
+package tempo
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kiali/kiali/config"
+	"github.com/kiali/kiali/jaeger"
+	jaegerModels "github.com/kiali/kiali/jaeger/model/json"
+	"github.com/kiali/kiali/log"
+	"github.com/kiali/kiali/models"
+	"github.com/kiali/kiali/util/httputil"
+)
+
+// errorTracesLimit is the maximum number of traces counted by GetErrorTraces
+const errorTracesLimit = 1000
+
+// Attribute names that can be used unquoted in TraceQL
+var simpleAttributeRegexp = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_.]*$`)
+
+// Client for the Grafana Tempo API. It implements jaeger.ClientInterface, mapping Tempo results to the Jaeger
+// models, so that the Kiali tracing features work the same with both backends.
+type Client struct {
+	baseURL     *url.URL
+	concurrency int
+	httpClient  http.Client
+}
+
+func NewClient(token string) (*Client, error) {
+	cfg := config.Get()
+	cfgTracing := cfg.ExternalServices.Tracing
+
+	if !cfgTracing.Enabled {
+		return nil, errors.New("tracing is not enabled")
+	}
+
+	auth := cfgTracing.Auth
+	if auth.UseKialiToken {
+		auth.Token = token
+	}
+
+	u, errParse := url.Parse(cfgTracing.InClusterURL)
+	if !cfg.InCluster {
+		u, errParse = url.Parse(cfgTracing.URL)
+	}
+	if errParse != nil {
+		log.Errorf("Error parsing Tempo URL: %s", errParse)
+		return nil, errParse
+	}
+
+	var customHeaders map[string]string
+	if cfgTracing.TempoConfig.OrgID != "" {
+		customHeaders = map[string]string{"X-Scope-OrgID": cfgTracing.TempoConfig.OrgID}
+	}
+	timeout := time.Duration(cfgTracing.QueryTimeout) * time.Second
+	transport, err := httputil.CreateTransport(&auth, &http.Transport{}, timeout, customHeaders)
+	if err != nil {
+		return nil, err
+	}
+
+	concurrency := cfgTracing.TempoConfig.QueryConcurrency
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+	log.Infof("Create Tempo HTTP client %s", u)
+	return &Client{baseURL: u, concurrency: concurrency, httpClient: http.Client{Transport: transport, Timeout: timeout}}, nil
+}
+
+// GetAppTraces searches the traces of an app with TraceQL, then fetches every trace found
+func (in *Client) GetAppTraces(namespace, app string, q models.TracingQuery) (*jaeger.JaegerResponse, error) {
+	serviceName := buildServiceName(namespace, app)
+	found, err := in.search(BuildTraceQL(serviceName, q), q.Start, q.End, q.Limit)
+	if err != nil {
+		return nil, err
+	}
+
+	r := jaeger.JaegerResponse{
+		Data:              []jaegerModels.Trace{},
+		JaegerServiceName: serviceName,
+	}
+	traces := make([]*jaegerModels.Trace, len(found))
+	errs := make([]error, len(found))
+	semaphore := make(chan struct{}, in.concurrency)
+	wg := sync.WaitGroup{}
+	for i, t := range found {
+		wg.Add(1)
+		go func(i int, traceID string) {
+			defer wg.Done()
+			semaphore <- struct{}{}
+			defer func() { <-semaphore }()
+			traces[i], errs[i] = in.getTrace(traceID)
+		}(i, t.TraceID)
+	}
+	wg.Wait()
+
+	for i, trace := range traces {
+		if errs[i] != nil {
+			// Keep the traces that could be fetched
+			log.Errorf("Tempo: error fetching trace %s: %v", found[i].TraceID, errs[i])
+			continue
+		}
+		if trace != nil {
+			r.Data = append(r.Data, *trace)
+		}
+	}
+	return &r, nil
+}
+
+// GetTraceDetail fetches a specific trace from its ID
+func (in *Client) GetTraceDetail(traceID string) (*jaeger.JaegerSingleTrace, error) {
+	trace, err := in.getTrace(traceID)
+	if err != nil || trace == nil {
+		return nil, err
+	}
+	return &jaeger.JaegerSingleTrace{Data: *trace}, nil
+}
+
+// GetErrorTraces fetches number of traces in error for the given app
+func (in *Client) GetErrorTraces(ns, app string, duration time.Duration) (int, error) {
+	now := time.Now()
+	query := models.TracingQuery{
+		Start: now.Add(-duration),
+		End:   now,
+		Tags:  map[string]string{"error": "true"},
+	}
+	for key, value := range config.Get().ExternalServices.Tracing.QueryScope {
+		query.Tags[key] = value
+	}
+	found, err := in.search(BuildTraceQL(buildServiceName(ns, app), query), query.Start, query.End, errorTracesLimit)
+	if err != nil {
+		return 0, err
+	}
+	return len(found), nil
+}
+
+func (in *Client) GetServiceStatus() (bool, error) {
+	u := *in.baseURL
+	u.Path = path.Join(u.Path, "/api/echo")
+	_, _, err := in.get(&u)
+	return err == nil, err
+}
+
+func (in *Client) search(traceQL string, start, end time.Time, limit int) ([]SearchTrace, error) {
+	u := *in.baseURL
+	u.Path = path.Join(u.Path, "/api/search")
+	q := url.Values{}
+	q.Set("q", traceQL)
+	if !start.IsZero() {
+		q.Set("start", strconv.FormatInt(start.Unix(), 10))
+	}
+	if !end.IsZero() {
+		q.Set("end", strconv.FormatInt(end.Unix(), 10))
+	}
+	if limit > 0 {
+		q.Set("limit", strconv.Itoa(limit))
+	}
+	u.RawQuery = q.Encode()
+	log.Debugf("Prepared Tempo query: %v", u)
+
+	resp, _, err := in.get(&u)
+	if err != nil {
+		return nil, err
+	}
+	var response SearchResponse
+	if err := json.Unmarshal(resp, &response); err != nil {
+		log.Errorf("Error unmarshalling Tempo search response: %s [URL: %v]", err, u)
+		return nil, err
+	}
+	return response.Traces, nil
+}
+
+// getTrace fetches a trace by ID, returns nil when not found
+func (in *Client) getTrace(traceID string) (*jaegerModels.Trace, error) {
+	u := *in.baseURL
+	u.Path = path.Join(u.Path, "/api/traces/"+traceID)
+	resp, code, err := in.get(&u)
+	if code == http.StatusNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var otlp OTLPTrace
+	if err := json.Unmarshal(resp, &otlp); err != nil {
+		log.Errorf("Error unmarshalling Tempo trace: %s [URL: %v]", err, u)
+		return nil, err
+	}
+	if len(otlp.Batches) == 0 {
+		return nil, nil
+	}
+	return toJaegerTrace(traceID, &otlp), nil
+}
+
+func (in *Client) get(u *url.URL) ([]byte, int, error) {
+	req, err := http.NewRequest(http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, 0, err
+	}
+	req.Header.Add("Accept", "application/json")
+	resp, err := in.httpClient.Do(req)
+	if err != nil {
+		log.Errorf("Tempo query error: %s [URL: %v]", err, u)
+		return nil, 0, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, resp.StatusCode, err
+	}
+	if resp.StatusCode >= 400 {
+		err = fmt.Errorf("Tempo query error [code: %d, URL: %v]: %s", resp.StatusCode, u, strings.TrimSpace(string(body)))
+		if resp.StatusCode != http.StatusNotFound {
+			log.Error(err.Error())
+		}
+		return nil, resp.StatusCode, err
+	}
+	return body, resp.StatusCode, nil
+}
+
+func buildServiceName(namespace, app string) string {
+	if config.Get().ExternalServices.Tracing.NamespaceSelector {
+		return app + "." + namespace
+	}
+	return app
+}
+
+// BuildTraceQL builds the TraceQL query equivalent to a Jaeger search of a service. The "error" tag is mapped to
+// the span status, other tags are matched against span or resource attributes.
+func BuildTraceQL(serviceName string, q models.TracingQuery) string {
+	conditions := []string{"resource.service.name = " + strconv.Quote(serviceName)}
+
+	keys := make([]string, 0, len(q.Tags))
+	for k := range q.Tags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		v := q.Tags[k]
+		if k == "error" && v == "true" {
+			conditions = append(conditions, "status = error")
+			continue
+		}
+		attribute := "." + k
+		if !simpleAttributeRegexp.MatchString(k) {
+			attribute = "." + strconv.Quote(k)
+		}
+		conditions = append(conditions, attribute+" = "+strconv.Quote(v))
+	}
+	if q.MinDuration > 0 {
+		conditions = append(conditions, fmt.Sprintf("duration >= %dus", q.MinDuration.Microseconds()))
+	}
+	if q.MaxDuration > 0 {
+		conditions = append(conditions, fmt.Sprintf("duration <= %dus", q.MaxDuration.Microseconds()))
+	}
+	return "{ " + strings.Join(conditions, " && ") + " }"
+}
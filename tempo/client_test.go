@@ -0,0 +1,121 @@
+package tempo
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kiali/kiali/config"
+	jaegerModels "github.com/kiali/kiali/jaeger/model/json"
+	"github.com/kiali/kiali/models"
+)
+
+const tempoTrace = `{
+  "batches": [
+    {
+      "resource": {
+        "attributes": [
+          {"key": "service.name", "value": {"stringValue": "reviews.bookinfo"}},
+          {"key": "host.name", "value": {"stringValue": "reviews-v1-abc"}}
+        ]
+      },
+      "scopeSpans": [
+        {
+          "spans": [
+            {
+              "traceId": "AAAAAAAAAAAAAAAAAAAAAA==",
+              "spanId": "AAAAAAAAAAI=",
+              "parentSpanId": "AAAAAAAAAAE=",
+              "name": "reviews.bookinfo.svc.cluster.local:9080/*",
+              "kind": "SPAN_KIND_SERVER",
+              "startTimeUnixNano": "1000000000",
+              "endTimeUnixNano": "1005000000",
+              "attributes": [{"key": "http.status_code", "value": {"intValue": "500"}}],
+              "status": {"code": "STATUS_CODE_ERROR"}
+            }
+          ]
+        }
+      ]
+    }
+  ]
+}`
+
+func setupTempo(t *testing.T, handler http.HandlerFunc) *Client {
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	conf := config.NewConfig()
+	conf.ExternalServices.Tracing.Enabled = true
+	conf.ExternalServices.Tracing.Provider = config.TempoProvider
+	conf.ExternalServices.Tracing.URL = server.URL
+	conf.ExternalServices.Tracing.NamespaceSelector = true
+	conf.ExternalServices.Tracing.TempoConfig.OrgID = "tenant"
+	conf.InCluster = false
+	config.Set(conf)
+
+	client, err := NewClient("")
+	require.NoError(t, err)
+	return client
+}
+
+func TestBuildTraceQL(t *testing.T) {
+	q := models.TracingQuery{
+		Tags:        map[string]string{"error": "true", "http.method": "GET", "guid:x-request-id": "abc"},
+		MinDuration: 15 * time.Millisecond,
+	}
+	assert.Equal(t,
+		`{ resource.service.name = "reviews.bookinfo" && status = error && ."guid:x-request-id" = "abc" && .http.method = "GET" && duration >= 15000us }`,
+		BuildTraceQL("reviews.bookinfo", q))
+}
+
+func TestGetAppTraces(t *testing.T) {
+	client := setupTempo(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "tenant", r.Header.Get("X-Scope-OrgID"))
+		switch r.URL.Path {
+		case "/api/search":
+			assert.Equal(t, `{ resource.service.name = "reviews.bookinfo" }`, r.URL.Query().Get("q"))
+			assert.Equal(t, "20", r.URL.Query().Get("limit"))
+			_, _ = w.Write([]byte(`{"traces": [{"traceID": "00000000000000000000000000000a0b"}, {"traceID": "missing"}]}`))
+		case "/api/traces/00000000000000000000000000000a0b":
+			_, _ = w.Write([]byte(tempoTrace))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+
+	r, err := client.GetAppTraces("bookinfo", "reviews", models.TracingQuery{Start: time.Now().Add(-time.Hour), End: time.Now(), Limit: 20})
+	require.NoError(t, err)
+	assert.Equal(t, "reviews.bookinfo", r.JaegerServiceName)
+	require.Len(t, r.Data, 1)
+
+	trace := r.Data[0]
+	assert.Equal(t, jaegerModels.TraceID("0000000000000a0b"), trace.TraceID)
+	require.Len(t, trace.Spans, 1)
+	span := trace.Spans[0]
+	assert.Equal(t, jaegerModels.SpanID("0000000000000002"), span.SpanID)
+	assert.Equal(t, uint64(1000000), span.StartTime)
+	assert.Equal(t, uint64(5000), span.Duration)
+	require.Len(t, span.References, 1)
+	assert.Equal(t, jaegerModels.SpanID("0000000000000001"), span.References[0].SpanID)
+	assert.Contains(t, span.Tags, jaegerModels.KeyValue{Key: "span.kind", Type: jaegerModels.StringType, Value: "server"})
+	assert.Contains(t, span.Tags, jaegerModels.KeyValue{Key: "error", Type: jaegerModels.BoolType, Value: true})
+	assert.Contains(t, span.Tags, jaegerModels.KeyValue{Key: "http.status_code", Type: jaegerModels.Int64Type, Value: int64(500)})
+
+	process := trace.Processes[span.ProcessID]
+	assert.Equal(t, "reviews.bookinfo", process.ServiceName)
+	assert.Contains(t, process.Tags, jaegerModels.KeyValue{Key: "hostname", Type: jaegerModels.StringType, Value: "reviews-v1-abc"})
+}
+
+func TestGetTraceDetailNotFound(t *testing.T) {
+	client := setupTempo(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	})
+
+	trace, err := client.GetTraceDetail("a0b")
+	assert.NoError(t, err)
+	assert.Nil(t, trace)
+}
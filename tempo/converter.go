@@ -0,0 +1,158 @@
+package tempo
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+
+	jaegerModels "github.com/kiali/kiali/jaeger/model/json"
+)
+
+// toHexID converts an OTLP trace or span ID to hex. Tempo encodes IDs in base64 (protobuf JSON mapping),
+// while the OTLP JSON specification uses hex, so both are accepted.
+func toHexID(id string, size int) string {
+	if len(id) == size*2 {
+		if _, err := hex.DecodeString(id); err == nil {
+			return strings.ToLower(id)
+		}
+	}
+	if b, err := base64.StdEncoding.DecodeString(id); err == nil && len(b) == size {
+		return hex.EncodeToString(b)
+	}
+	return strings.ToLower(id)
+}
+
+// toJaegerTraceID formats a trace ID the way Jaeger does, dropping the high part of 128 bits IDs when it is empty
+func toJaegerTraceID(id string) jaegerModels.TraceID {
+	hexID := toHexID(id, 16)
+	if len(hexID) == 32 && strings.TrimLeft(hexID[:16], "0") == "" {
+		hexID = hexID[16:]
+	}
+	return jaegerModels.TraceID(hexID)
+}
+
+func nanosToMicros(nanos string) uint64 {
+	n, err := strconv.ParseUint(nanos, 10, 64)
+	if err != nil {
+		return 0
+	}
+	return n / 1000
+}
+
+// toJaegerKeyValue converts an OTLP attribute to a Jaeger tag
+func toJaegerKeyValue(kv KeyValue) jaegerModels.KeyValue {
+	v := kv.Value
+	switch {
+	case v.BoolValue != nil:
+		return jaegerModels.KeyValue{Key: kv.Key, Type: jaegerModels.BoolType, Value: *v.BoolValue}
+	case v.IntValue != nil:
+		if i, err := v.IntValue.Int64(); err == nil {
+			return jaegerModels.KeyValue{Key: kv.Key, Type: jaegerModels.Int64Type, Value: i}
+		}
+		return jaegerModels.KeyValue{Key: kv.Key, Type: jaegerModels.StringType, Value: v.IntValue.String()}
+	case v.DoubleValue != nil:
+		return jaegerModels.KeyValue{Key: kv.Key, Type: jaegerModels.Float64Type, Value: *v.DoubleValue}
+	case v.StringValue != nil:
+		return jaegerModels.KeyValue{Key: kv.Key, Type: jaegerModels.StringType, Value: *v.StringValue}
+	}
+	return jaegerModels.KeyValue{Key: kv.Key, Type: jaegerModels.StringType, Value: ""}
+}
+
+func toJaegerKeyValues(kvs []KeyValue) []jaegerModels.KeyValue {
+	tags := make([]jaegerModels.KeyValue, 0, len(kvs))
+	for _, kv := range kvs {
+		tags = append(tags, toJaegerKeyValue(kv))
+	}
+	return tags
+}
+
+// toJaegerProcess converts an OTLP resource to a Jaeger process. The host.name attribute is also set as the
+// hostname tag, which is what Jaeger clients report and what Kiali uses to match spans with workloads.
+func toJaegerProcess(resource Resource) jaegerModels.Process {
+	process := jaegerModels.Process{Tags: []jaegerModels.KeyValue{}}
+	for _, kv := range resource.Attributes {
+		tag := toJaegerKeyValue(kv)
+		switch kv.Key {
+		case "service.name":
+			process.ServiceName, _ = tag.Value.(string)
+			continue
+		case "host.name":
+			process.Tags = append(process.Tags, jaegerModels.KeyValue{Key: "hostname", Type: tag.Type, Value: tag.Value})
+		}
+		process.Tags = append(process.Tags, tag)
+	}
+	return process
+}
+
+// toJaegerSpan converts an OTLP span to a Jaeger span. The span kind and an error status are mapped to the
+// span.kind and error tags used by Jaeger.
+func toJaegerSpan(span Span, traceID jaegerModels.TraceID, processID jaegerModels.ProcessID) jaegerModels.Span {
+	start := nanosToMicros(span.StartTimeUnixNano)
+	end := nanosToMicros(span.EndTimeUnixNano)
+	duration := uint64(0)
+	if end > start {
+		duration = end - start
+	}
+
+	tags := toJaegerKeyValues(span.Attributes)
+	if kind := strings.ToLower(strings.TrimPrefix(span.Kind, "SPAN_KIND_")); kind != "" && kind != "unspecified" && kind != "internal" {
+		tags = append(tags, jaegerModels.KeyValue{Key: "span.kind", Type: jaegerModels.StringType, Value: kind})
+	}
+	if span.Status.Code == "STATUS_CODE_ERROR" || span.Status.Code == "2" {
+		tags = append(tags, jaegerModels.KeyValue{Key: "error", Type: jaegerModels.BoolType, Value: true})
+		tags = append(tags, jaegerModels.KeyValue{Key: "otel.status_code", Type: jaegerModels.StringType, Value: "ERROR"})
+		if span.Status.Message != "" {
+			tags = append(tags, jaegerModels.KeyValue{Key: "otel.status_description", Type: jaegerModels.StringType, Value: span.Status.Message})
+		}
+	}
+
+	logs := make([]jaegerModels.Log, 0, len(span.Events))
+	for _, event := range span.Events {
+		fields := append([]jaegerModels.KeyValue{{Key: "event", Type: jaegerModels.StringType, Value: event.Name}}, toJaegerKeyValues(event.Attributes)...)
+		logs = append(logs, jaegerModels.Log{Timestamp: nanosToMicros(event.TimeUnixNano), Fields: fields})
+	}
+
+	references := []jaegerModels.Reference{}
+	if span.ParentSpanID != "" {
+		references = append(references, jaegerModels.Reference{
+			RefType: jaegerModels.ChildOf,
+			TraceID: traceID,
+			SpanID:  jaegerModels.SpanID(toHexID(span.ParentSpanID, 8)),
+		})
+	}
+
+	return jaegerModels.Span{
+		TraceID:       traceID,
+		SpanID:        jaegerModels.SpanID(toHexID(span.SpanID, 8)),
+		OperationName: span.Name,
+		References:    references,
+		StartTime:     start,
+		Duration:      duration,
+		Tags:          tags,
+		Logs:          logs,
+		ProcessID:     processID,
+		Warnings:      []string{},
+	}
+}
+
+// toJaegerTrace converts an OTLP trace to a Jaeger trace. Every resource becomes a Jaeger process.
+func toJaegerTrace(traceID string, otlp *OTLPTrace) *jaegerModels.Trace {
+	trace := &jaegerModels.Trace{
+		TraceID:   toJaegerTraceID(traceID),
+		Spans:     []jaegerModels.Span{},
+		Processes: map[jaegerModels.ProcessID]jaegerModels.Process{},
+		Warnings:  []string{},
+	}
+	for i, batch := range otlp.Batches {
+		processID := jaegerModels.ProcessID(fmt.Sprintf("p%d", i+1))
+		trace.Processes[processID] = toJaegerProcess(batch.Resource)
+		for _, scope := range append(batch.ScopeSpans, batch.InstrumentationLibrarySpans...) {
+			for _, span := range scope.Spans {
+				trace.Spans = append(trace.Spans, toJaegerSpan(span, trace.TraceID, processID))
+			}
+		}
+	}
+	return trace
+}
@@ -0,0 +1,81 @@
+package tempo
+
+import "encoding/json"
+
+// SearchResponse is the response of the Tempo search API
+type SearchResponse struct {
+	Traces []SearchTrace `json:"traces"`
+}
+
+// SearchTrace holds the metadata of a trace found by a search
+type SearchTrace struct {
+	TraceID           string `json:"traceID"`
+	RootServiceName   string `json:"rootServiceName"`
+	RootTraceName     string `json:"rootTraceName"`
+	StartTimeUnixNano string `json:"startTimeUnixNano"`
+	DurationMs        int64  `json:"durationMs"`
+}
+
+// OTLPTrace is a trace returned by the Tempo trace-by-ID API, in the OTLP JSON format
+type OTLPTrace struct {
+	Batches []ResourceSpans `json:"batches"`
+}
+
+// ResourceSpans holds the spans emitted by a single resource (service instance)
+type ResourceSpans struct {
+	Resource   Resource     `json:"resource"`
+	ScopeSpans []ScopeSpans `json:"scopeSpans"`
+	// Name used by Tempo versions before OTLP 0.19
+	InstrumentationLibrarySpans []ScopeSpans `json:"instrumentationLibrarySpans"`
+}
+
+// Resource describes the entity that produced the spans
+type Resource struct {
+	Attributes []KeyValue `json:"attributes"`
+}
+
+// ScopeSpans holds the spans produced by an instrumentation scope
+type ScopeSpans struct {
+	Spans []Span `json:"spans"`
+}
+
+// Span is an OTLP span
+type Span struct {
+	TraceID           string     `json:"traceId"`
+	SpanID            string     `json:"spanId"`
+	ParentSpanID      string     `json:"parentSpanId"`
+	Name              string     `json:"name"`
+	Kind              string     `json:"kind"`
+	StartTimeUnixNano string     `json:"startTimeUnixNano"`
+	EndTimeUnixNano   string     `json:"endTimeUnixNano"`
+	Attributes        []KeyValue `json:"attributes"`
+	Events            []Event    `json:"events"`
+	Status            Status     `json:"status"`
+}
+
+// Event is a timestamped annotation of a span
+type Event struct {
+	TimeUnixNano string     `json:"timeUnixNano"`
+	Name         string     `json:"name"`
+	Attributes   []KeyValue `json:"attributes"`
+}
+
+// Status of a span
+type Status struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// KeyValue is an OTLP attribute
+type KeyValue struct {
+	Key   string   `json:"key"`
+	Value AnyValue `json:"value"`
+}
+
+// AnyValue holds the value of an attribute. Only one of the fields is set.
+type AnyValue struct {
+	StringValue *string      `json:"stringValue,omitempty"`
+	BoolValue   *bool        `json:"boolValue,omitempty"`
+	IntValue    *json.Number `json:"intValue,omitempty"` // int64 may be encoded as strings in JSON
+	DoubleValue *float64     `json:"doubleValue,omitempty"`
+}
@@ -156,7 +156,7 @@ func CreateTransport(auth *config.Auth, transportConfig *http.Transport, timeout
 }
 
 func GetTLSConfig(auth *config.Auth) (*tls.Config, error) {
-	if auth.InsecureSkipVerify || auth.CAFile != "" {
+	if auth.InsecureSkipVerify || auth.CAFile != "" || auth.CertFile != "" {
 		var certPool *x509.CertPool
 		if auth.CAFile != "" {
 			certPool = x509.NewCertPool()
@@ -170,7 +170,16 @@ func GetTLSConfig(auth *config.Auth) (*tls.Config, error) {
 				return nil, fmt.Errorf("supplied CA file could not be parsed")
 			}
 		}
+		var certificates []tls.Certificate
+		if auth.CertFile != "" {
+			cert, err := tls.LoadX509KeyPair(auth.CertFile, auth.KeyFile)
+			if err != nil {
+				return nil, fmt.Errorf("failed to load the client certificate: %s", err)
+			}
+			certificates = append(certificates, cert)
+		}
 		return &tls.Config{
+			Certificates:       certificates,
 			InsecureSkipVerify: auth.InsecureSkipVerify,
 			RootCAs:            certPool,
 		}, nil
@@ -242,3 +251,22 @@ func GuessKialiURL(r *http.Request) string {
 
 	return strings.TrimRight(guessedKialiURL, "/")
 }
+
+// HasClientCertificate returns true if the request was sent with a client certificate verified by the server,
+// issued to one of the common names
+func HasClientCertificate(r *http.Request, commonNames []string) bool {
+	if r.TLS == nil {
+		return false
+	}
+	for _, chain := range r.TLS.VerifiedChains {
+		if len(chain) == 0 {
+			continue
+		}
+		for _, commonName := range commonNames {
+			if chain[0].Subject.CommonName == commonName {
+				return true
+			}
+		}
+	}
+	return false
+}
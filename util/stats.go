@@ -0,0 +1,42 @@
+package util
+
+import (
+	"math"
+	"sort"
+)
+
+// Percentile returns the p-th percentile (0 < p <= 100) of the given values, using the nearest-rank method.
+// Values must be sorted in ascending order. Returns 0 for empty values.
+func Percentile(sorted []float64, p float64) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	if rank < 1 {
+		rank = 1
+	}
+	if rank > len(sorted) {
+		rank = len(sorted)
+	}
+	return sorted[rank-1]
+}
+
+// Average returns the arithmetic mean of the given values. Returns 0 for empty values.
+func Average(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sum := 0.0
+	for _, v := range values {
+		sum += v
+	}
+	return sum / float64(len(values))
+}
+
+// SortedCopy returns a copy of the values sorted in ascending order.
+func SortedCopy(values []float64) []float64 {
+	sorted := make([]float64, len(values))
+	copy(sorted, values)
+	sort.Float64s(sorted)
+	return sorted
+}
@@ -0,0 +1,25 @@
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPercentile(t *testing.T) {
+	assert := assert.New(t)
+
+	values := SortedCopy([]float64{15, 20, 35, 40, 50})
+	assert.Equal(35.0, Percentile(values, 50))
+	assert.Equal(50.0, Percentile(values, 90))
+	assert.Equal(50.0, Percentile(values, 100))
+	assert.Equal(15.0, Percentile(values, 1))
+	assert.Equal(0.0, Percentile([]float64{}, 50))
+}
+
+func TestAverage(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Equal(32.0, Average([]float64{15, 20, 35, 40, 50}))
+	assert.Equal(0.0, Average(nil))
+}